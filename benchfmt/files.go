@@ -18,6 +18,9 @@ import (
 // be disambiguated by appending "#N". If AllowLabels is true, then
 // entries in Path may be of the form label=path, and the label part
 // will be used for .label (without any disambiguation).
+//
+// It also sets the FileIndex of each Result to the index of the
+// corresponding path in Paths.
 type Files struct {
 	// Paths is the list of file names to read in.
 	//
@@ -50,10 +53,12 @@ type Files struct {
 	reader  Reader
 	file    *os.File
 	isStdin bool
+	index   int // FileIndex of the current input
 	err     error
 }
 
 type input struct {
+	index     int
 	path      string
 	label     string
 	isStdin   bool
@@ -70,9 +75,9 @@ func (f *Files) init() {
 	// disambiguation.
 	pathCount := make(map[string]int)
 	if f.AllowStdin && len(f.Paths) == 0 {
-		f.inputs = append(f.inputs, input{"-", "-", true, false})
+		f.inputs = append(f.inputs, input{0, "-", "-", true, false})
 	}
-	for _, path := range f.Paths {
+	for index, path := range f.Paths {
 		// Parse the label.
 		label := path
 		isLabeled := false
@@ -84,7 +89,7 @@ func (f *Files) init() {
 		}
 
 		isStdin := f.AllowStdin && path == "-"
-		f.inputs = append(f.inputs, input{path, label, isStdin, isLabeled})
+		f.inputs = append(f.inputs, input{index, path, label, isStdin, isLabeled})
 	}
 
 	// If the same path is given multiple times, disambiguate its
@@ -144,6 +149,7 @@ func (f *Files) Scan() bool {
 			// the file itself, there's no danger of it
 			// being overwritten.
 			f.reader.Reset(f.file, inp.path, ".label", inp.label)
+			f.index = inp.index
 		}
 
 		// Try to get the next result.
@@ -178,6 +184,7 @@ func (f *Files) Result() (*Result, error) {
 	if err != nil {
 		return nil, err
 	}
+	r.FileIndex = f.index
 	return r, nil
 }
 
@@ -6,6 +6,7 @@ package benchfmt
 
 import (
 	"os"
+	"reflect"
 	"strings"
 	"syscall"
 	"testing"
@@ -125,6 +126,20 @@ func TestFiles(t *testing.T) {
 		},
 		"foo X", "foo Y", "foo X", "foo Y",
 	)
+
+	// File indexes.
+	f := &Files{Paths: []string{"foo=a", "foo=a", "b"}, AllowLabels: true}
+	var got []int
+	for f.Scan() {
+		res, err := f.Result()
+		if err != nil {
+			t.Fatalf("unexpected Result error %s", err)
+		}
+		got = append(got, res.FileIndex)
+	}
+	if want := []int{0, 0, 1, 1, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got file indexes %v, want %v", got, want)
+	}
 }
 
 func fakeStdin(content string, cb func()) {
@@ -59,6 +59,12 @@ type Result struct {
 	// Units is the set of unit metadata in effect for this result.
 	Units Units
 
+	// FileIndex is the index of the input this result was read
+	// from in a sequence of inputs. Files sets this to the index
+	// of the input in Files.Paths. It is 0 for results from a
+	// single Reader.
+	FileIndex int
+
 	// configPos maps from Config.Key to index in FileConfig. This
 	// may be nil, which indicates the index needs to be
 	// constructed.
@@ -103,6 +109,7 @@ func (r *Result) Clone() *Result {
 		Iters:      r.Iters,
 		Values:     append([]Value(nil), r.Values...),
 		Units:      Units{Metadata: append([]UnitMetadata(nil), r.Units.Metadata...)},
+		FileIndex:  r.FileIndex,
 	}
 	for i, cfg := range r.FileConfig {
 		r2.FileConfig[i].Key = cfg.Key
@@ -267,11 +274,16 @@ func (n Name) splitGomaxprocs() (prefix, gomaxprocs []byte) {
 // better={higher,lower} indicates whether higher or lower values of
 // this unit are better (indicate an improvement).
 //
-// assume={nothing,exact} indicates what statistical assumption to
-// make when considering distributions of values.
-// `nothing` means to make no statistical assumptions (e.g., use
-// non-parametric methods) and `exact` means to assume measurements are
-// exact (repeated measurement does not increase confidence).
+// assume={nothing,exact,normal,lognormal,...} indicates what
+// statistical assumption to make when considering distributions of
+// values. `nothing` means to make no statistical assumptions (e.g.,
+// use non-parametric methods), `exact` means to assume measurements
+// are exact (repeated measurement does not increase confidence),
+// `normal` means to assume measurements are normally distributed (use
+// the mean and Welch's t-test), and `lognormal` means to assume the
+// logarithms of measurements are normally distributed (use the
+// geometric mean and compare in log space). Tools may support other
+// assumptions; see benchstat's documentation for its full list.
 // The default is `nothing`.
 type Units struct {
 	// Metadata is a slice of unit metadata values. It is only
@@ -0,0 +1,213 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/aclements/go-moremath/stats"
+)
+
+// DefaultBootstrapResamples is the default number of resamples used
+// by Bootstrap.
+const DefaultBootstrapResamples = 10000
+
+// Bootstrap is an assumption that makes no assumptions about the
+// distribution of a sample, but estimates confidence intervals and
+// p-values by bootstrap resampling rather than by the order
+// statistics used by AssumeNothing.
+//
+// AssumeNothing's intervals and tests require a minimum number of
+// samples before they can say anything at all. The bootstrap can
+// produce meaningful (if less reliable) intervals and comparisons
+// from very few samples, which makes it useful for expensive
+// benchmarks where collecting many samples is impractical.
+//
+// Resampling uses a fixed pseudo-random seed, so results are
+// deterministic for a given input.
+type Bootstrap struct {
+	// Mean, if true, uses the sample mean as the summary
+	// statistic. Otherwise, the summary statistic is the median.
+	Mean bool
+
+	// Resamples is the number of bootstrap resamples to draw. If
+	// 0, DefaultBootstrapResamples is used.
+	Resamples int
+}
+
+// AssumeBootstrap is a Bootstrap assumption using the median and the
+// default number of resamples.
+var AssumeBootstrap = Bootstrap{}
+
+// AssumeBootstrapMean is a Bootstrap assumption using the mean and the
+// default number of resamples.
+var AssumeBootstrapMean = Bootstrap{Mean: true}
+
+var _ Assumption = Bootstrap{}
+
+// bootstrapSeed is the seed for bootstrap resampling.
+const bootstrapSeed = 1
+
+func (b Bootstrap) SummaryLabel() string {
+	if b.Mean {
+		return "mean"
+	}
+	return "median"
+}
+
+func (b Bootstrap) resamples() int {
+	if b.Resamples <= 0 {
+		return DefaultBootstrapResamples
+	}
+	return b.Resamples
+}
+
+// stat computes b's summary statistic of xs. It may reorder xs.
+func (b Bootstrap) stat(xs []float64) float64 {
+	if b.Mean {
+		return stats.Mean(xs)
+	}
+	sort.Float64s(xs)
+	n := len(xs)
+	if n%2 == 1 {
+		return xs[n/2]
+	}
+	return (xs[n/2-1] + xs[n/2]) / 2
+}
+
+// boot returns b.resamples() bootstrap replicates of b's summary
+// statistic for s. Replicates are drawn using rng.
+func (b Bootstrap) boot(s *Sample, rng *rand.Rand) []float64 {
+	return bootstrap(s.Values, b.resamples(), rng, b.stat)
+}
+
+// bootstrap returns n bootstrap replicates of stat for xs. Replicates
+// are drawn using rng. stat may reorder its argument.
+func bootstrap(xs []float64, n int, rng *rand.Rand, stat func([]float64) float64) []float64 {
+	buf := make([]float64, len(xs))
+	reps := make([]float64, n)
+	for i := range reps {
+		for j := range buf {
+			buf[j] = xs[rng.Intn(len(xs))]
+		}
+		reps[i] = stat(buf)
+	}
+	return reps
+}
+
+// bootstrapP returns the two-sided p-value of the null hypothesis
+// that the difference between bootstrap replicates reps2 and reps1 is
+// 0.
+func bootstrapP(reps1, reps2 []float64) float64 {
+	// Find how much of the bootstrap distribution of the
+	// difference lies on either side of 0.
+	var below, above int
+	for i := range reps1 {
+		d := reps2[i] - reps1[i]
+		if d <= 0 {
+			below++
+		}
+		if d >= 0 {
+			above++
+		}
+	}
+	tail := below
+	if above < tail {
+		tail = above
+	}
+	// Add one to avoid reporting a p-value of 0, which a finite
+	// number of resamples can't justify.
+	return math.Min(1, 2*float64(tail+1)/float64(len(reps1)+1))
+}
+
+// bootstrapRatioCI returns a bootstrap confidence interval for the
+// ratio of stat of s2 to stat of s1 using n resamples. label names
+// the statistic in warnings. stat may reorder its argument.
+func bootstrapRatioCI(s1, s2 *Sample, n int, stat func([]float64) float64, label string, confidence float64) Summary {
+	c1 := stat(append([]float64(nil), s1.Values...))
+	c2 := stat(append([]float64(nil), s2.Values...))
+	summary := Summary{Center: c2 / c1, Lo: math.Inf(-1), Hi: math.Inf(1), Confidence: confidence}
+	if c1 == 0 {
+		summary.Center = math.NaN()
+		summary.Warnings = []error{fmt.Errorf("ratio is undefined because base %s is 0", label)}
+		return summary
+	}
+	if len(s1.Values) < 2 || len(s2.Values) < 2 {
+		summary.Warnings = []error{fmt.Errorf("need >= 2 samples for bootstrap interval")}
+		return summary
+	}
+
+	rng := rand.New(rand.NewSource(bootstrapSeed))
+	reps1 := bootstrap(s1.Values, n, rng, stat)
+	reps2 := bootstrap(s2.Values, n, rng, stat)
+	for i := range reps1 {
+		reps2[i] /= reps1[i]
+	}
+	summary.Lo, summary.Hi = percentileCI(reps2, confidence)
+	return summary
+}
+
+// percentileCI returns the percentile bootstrap interval of reps at
+// the given confidence level. It sorts reps.
+func percentileCI(reps []float64, confidence float64) (lo, hi float64) {
+	sort.Float64s(reps)
+	sample := stats.Sample{Xs: reps, Sorted: true}
+	tail := (1 - confidence) / 2
+	return sample.Quantile(tail), sample.Quantile(1 - tail)
+}
+
+func (b Bootstrap) Summary(s *Sample, confidence float64) Summary {
+	center := b.stat(append([]float64(nil), s.Values...))
+	if len(s.Values) < 2 {
+		// A single value resamples to itself, which would
+		// give a misleadingly tight interval.
+		return Summary{
+			Center:     center,
+			Lo:         math.Inf(-1),
+			Hi:         math.Inf(1),
+			Confidence: confidence,
+			Warnings:   append(unsupported(b, s), fmt.Errorf("need >= 2 samples for bootstrap interval")),
+		}
+	}
+
+	rng := rand.New(rand.NewSource(bootstrapSeed))
+	lo, hi := percentileCI(b.boot(s, rng), confidence)
+	return Summary{Center: center, Lo: lo, Hi: hi, Confidence: confidence, Warnings: unsupported(b, s)}
+}
+
+func (b Bootstrap) Compare(s1, s2 *Sample) Comparison {
+	cmp := Comparison{N1: len(s1.Values), N2: len(s2.Values), Alpha: s1.Thresholds.CompareAlpha}
+	cmp.Warnings = unsupported(b, s1, s2)
+	if len(s1.Values) < 2 || len(s2.Values) < 2 {
+		cmp.P = 1
+		cmp.Warnings = append(cmp.Warnings, fmt.Errorf("need >= 2 samples to bootstrap a comparison"))
+		return cmp
+	}
+
+	// Test that the bootstrap distribution of the difference of
+	// the statistic is centered on 0.
+	rng := rand.New(rand.NewSource(bootstrapSeed))
+	cmp.P = bootstrapP(b.boot(s1, rng), b.boot(s2, rng))
+	cmp.Test = BootstrapTest
+	cmp.Statistic = b.stat(append([]float64(nil), s2.Values...)) - b.stat(append([]float64(nil), s1.Values...))
+	if b.Mean {
+		cmp.setCohensD(s1.sample(), s2.sample())
+	} else {
+		cmp.Effect, cmp.EffectMeasure = cliffsDelta(s1.Values, s2.Values), EffectCliffsDelta
+	}
+	addCompareWarnings(&cmp, s1, s2)
+	return cmp
+}
+
+// RatioCI returns a bootstrap confidence interval for the ratio of
+// b's summary statistic of s2 to that of s1. In the result, Center is
+// the ratio of the statistics of the two samples, and Lo and Hi are
+// the bounds of the interval at the given confidence level.
+func (b Bootstrap) RatioCI(s1, s2 *Sample, confidence float64) Summary {
+	return bootstrapRatioCI(s1, s2, b.resamples(), b.stat, b.SummaryLabel(), confidence)
+}
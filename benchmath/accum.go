@@ -0,0 +1,446 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/aclements/go-moremath/stats"
+)
+
+// DefaultAccumulatorAccuracy is the default relative accuracy of the
+// quantiles reported by an Accumulator.
+const DefaultAccumulatorAccuracy = 0.01
+
+// maxAccumulatorBuckets is the maximum number of buckets in each of an
+// Accumulator's positive and negative stores. At the default
+// accuracy, this covers values spanning over 17 orders of magnitude
+// before the sketch has to collapse its lowest buckets.
+const maxAccumulatorBuckets = 2048
+
+// An Accumulator summarizes a stream of measurements of a benchmark in
+// constant memory. Unlike a Sample, it doesn't retain individual
+// values, so it's suitable for services that ingest very large
+// numbers of measurements. Accumulators can be merged, so a stream
+// can be split across many Accumulators and combined later.
+//
+// An Accumulator tracks the exact count, mean, and variance of its
+// values, along with a quantile sketch in which every quantile is
+// accurate to within a fixed relative error. Summaries and
+// comparisons of Accumulators are therefore approximations of the
+// same operations on the equivalent Sample.
+//
+// The zero value of Accumulator is not usable. Use NewAccumulator.
+type Accumulator struct {
+	// Thresholds stores the statistical thresholds used by
+	// comparisons of this accumulator.
+	Thresholds *Thresholds
+
+	accuracy float64
+	logGamma float64
+
+	n        int
+	mean, m2 float64
+	min, max float64
+
+	// pos and neg are the bucket counts of positive values and
+	// the magnitudes of negative values. zero is the count of
+	// values that are exactly zero.
+	pos, neg ddStore
+	zero     int
+}
+
+// NewAccumulator returns a new, empty Accumulator whose quantiles are
+// accurate to within relative error accuracy, which must be in the
+// range (0, 1). If accuracy is 0, it uses DefaultAccumulatorAccuracy.
+func NewAccumulator(accuracy float64, t *Thresholds) *Accumulator {
+	if accuracy == 0 {
+		accuracy = DefaultAccumulatorAccuracy
+	}
+	if !(0 < accuracy && accuracy < 1) {
+		panic(fmt.Sprintf("accumulator accuracy %v not in range (0, 1)", accuracy))
+	}
+	gamma := (1 + accuracy) / (1 - accuracy)
+	return &Accumulator{
+		Thresholds: t,
+		accuracy:   accuracy,
+		logGamma:   math.Log(gamma),
+		min:        math.Inf(1),
+		max:        math.Inf(-1),
+	}
+}
+
+// Add adds value x to a.
+func (a *Accumulator) Add(x float64) {
+	a.n++
+	delta := x - a.mean
+	a.mean += delta / float64(a.n)
+	a.m2 += delta * (x - a.mean)
+	if x < a.min {
+		a.min = x
+	}
+	if x > a.max {
+		a.max = x
+	}
+
+	switch {
+	case x > 0:
+		a.pos.add(a.index(x), 1)
+	case x < 0:
+		a.neg.add(a.index(-x), 1)
+	default:
+		a.zero++
+	}
+}
+
+// Merge adds all of the values accumulated in b to a. a and b must
+// have been created with the same accuracy.
+func (a *Accumulator) Merge(b *Accumulator) error {
+	if a.accuracy != b.accuracy {
+		return fmt.Errorf("cannot merge accumulators with accuracy %v and %v", a.accuracy, b.accuracy)
+	}
+	if b.n == 0 {
+		return nil
+	}
+	// Combine moments using Chan et al.'s parallel algorithm.
+	n := a.n + b.n
+	delta := b.mean - a.mean
+	a.mean += delta * float64(b.n) / float64(n)
+	a.m2 += b.m2 + delta*delta*float64(a.n)*float64(b.n)/float64(n)
+	a.n = n
+	a.min = math.Min(a.min, b.min)
+	a.max = math.Max(a.max, b.max)
+
+	a.pos.merge(&b.pos)
+	a.neg.merge(&b.neg)
+	a.zero += b.zero
+	return nil
+}
+
+// N returns the number of values added to a.
+func (a *Accumulator) N() int {
+	return a.n
+}
+
+// Mean returns the exact mean of the values added to a.
+func (a *Accumulator) Mean() float64 {
+	if a.n == 0 {
+		return math.NaN()
+	}
+	return a.mean
+}
+
+// Variance returns the exact sample variance of the values added to
+// a.
+func (a *Accumulator) Variance() float64 {
+	if a.n < 2 {
+		return math.NaN()
+	}
+	return a.m2 / float64(a.n-1)
+}
+
+// Quantile returns an approximation of the q'th quantile of the
+// values added to a, where q is in the range [0, 1]. The result is
+// within a relative error of the accumulator's accuracy of an actual
+// value in a near the exact quantile.
+func (a *Accumulator) Quantile(q float64) float64 {
+	if a.n == 0 {
+		return math.NaN()
+	}
+	return a.valueAt(int(math.Round(q * float64(a.n-1))))
+}
+
+// Summary returns an approximate summary statistic and its confidence
+// interval for the values added to a, following the given
+// assumption.
+//
+// Only AssumeNothing and AssumeNormal are supported. Under any other
+// assumption, Summary summarizes as if by AssumeNothing and adds a
+// warning to the result.
+func (a *Accumulator) Summary(assumption Assumption, confidence float64) Summary {
+	if assumption == AssumeNormal {
+		return a.meanSummary(confidence)
+	}
+
+	ci := medianCI(a.n, confidence)
+	median, lo, hi := math.NaN(), math.Inf(-1), math.Inf(1)
+	if a.n > 0 {
+		median = a.Quantile(0.5)
+	}
+	if ci.LoOrder >= 1 {
+		lo = a.valueAt(ci.LoOrder - 1)
+	}
+	if ci.HiOrder <= a.n {
+		hi = a.valueAt(ci.HiOrder - 1)
+	}
+
+	var warnings []error
+	if assumption != AssumeNothing {
+		warnings = append(warnings, fmt.Errorf("%s not supported for accumulated samples; using median", assumption.SummaryLabel()))
+	}
+	if math.IsInf(lo, 0) || math.IsInf(hi, 0) {
+		op, need := medianSamples(confidence)
+		warnings = append(warnings, fmt.Errorf("need %s %d samples for confidence interval at level %v", op, need, confidence))
+	}
+	return Summary{median, lo, hi, ci.Confidence, 0, warnings}
+}
+
+func (a *Accumulator) meanSummary(confidence float64) Summary {
+	mean := a.Mean()
+	var w float64
+	var warnings []error
+	if confidence <= 0 {
+		w = 0
+	} else if confidence >= 1 || a.n < 2 {
+		w = math.Inf(1)
+		if a.n < 2 {
+			warnings = append(warnings, fmt.Errorf("need >= 2 samples for confidence interval at level %v", confidence))
+		}
+	} else {
+		tdist := stats.TDist{V: float64(a.n - 1)}
+		t := -stats.InvCDF(tdist)((1 - confidence) / 2)
+		w = t * math.Sqrt(a.Variance()/float64(a.n))
+	}
+	return Summary{
+		Center:     mean,
+		Lo:         mean - w,
+		Hi:         mean + w,
+		Confidence: confidence,
+		Warnings:   warnings,
+	}
+}
+
+// CompareAccumulators tests whether the values accumulated in a1 and
+// a2 come from the same distribution, following the given
+// assumption.
+//
+// Under AssumeNormal, this performs Welch's t-test, which is exact
+// because Accumulators track exact moments. Otherwise, it performs an
+// approximate Mann-Whitney U test in which values that fall in the
+// same sketch bucket are treated as ties. Other assumptions are
+// treated like AssumeNothing, with a warning.
+func CompareAccumulators(assumption Assumption, a1, a2 *Accumulator) Comparison {
+	alpha := a1.Thresholds.CompareAlpha
+	if assumption == AssumeNormal {
+		t, err := stats.TwoSampleWelchTTest(a1.moments(), a2.moments(), stats.LocationDiffers)
+		if err != nil {
+			return Comparison{P: 1, N1: a1.n, N2: a2.n, Alpha: alpha, Warnings: []error{err}}
+		}
+		cmp := Comparison{P: t.P, N1: a1.n, N2: a2.n, Alpha: alpha}
+		cmp.Test, cmp.Statistic, cmp.DF = WelchTTest, t.T, t.DoF
+		if n := a1.n + a2.n; n > 2 {
+			sp := math.Sqrt((a1.m2 + a2.m2) / float64(n-2))
+			if sp != 0 {
+				cmp.Effect, cmp.EffectMeasure = (a2.mean-a1.mean)/sp, EffectCohensD
+			}
+		}
+		return cmp
+	}
+
+	cmp := uTestAccumulators(a1, a2)
+	cmp.Alpha = alpha
+	if assumption != AssumeNothing {
+		cmp.Warnings = append(cmp.Warnings, fmt.Errorf("%s not supported for accumulated samples; using U-test", assumption.SummaryLabel()))
+	}
+	return cmp
+}
+
+func (a *Accumulator) moments() tMoments {
+	return tMoments{float64(a.n), a.Mean(), a.Variance()}
+}
+
+// uTestAccumulators performs a Mann-Whitney U test on the sketches of
+// a1 and a2 using the normal approximation with a tie correction.
+func uTestAccumulators(a1, a2 *Accumulator) Comparison {
+	n1, n2 := a1.n, a2.n
+	cmp := Comparison{P: 1, N1: n1, N2: n2}
+	if a1.accuracy != a2.accuracy {
+		cmp.Warnings = append(cmp.Warnings, fmt.Errorf("cannot compare accumulators with accuracy %v and %v", a1.accuracy, a2.accuracy))
+		return cmp
+	}
+	if n1 == 0 || n2 == 0 {
+		cmp.Warnings = append(cmp.Warnings, stats.ErrSampleSize)
+		return cmp
+	}
+
+	// Walk the buckets of both accumulators in value order,
+	// giving each bucket the mid-rank of its values.
+	b1, b2 := a1.buckets(), a2.buckets()
+	var r1, ties, dom float64
+	var before1, before2 int
+	for i, j := 0, 0; i < len(b1) || j < len(b2); {
+		var c1, c2 int
+		switch {
+		case j == len(b2) || (i < len(b1) && b1[i].less(b2[j])):
+			c1 = b1[i].count
+			i++
+		case i == len(b1) || b2[j].less(b1[i]):
+			c2 = b2[j].count
+			j++
+		default:
+			c1, c2 = b1[i].count, b2[j].count
+			i, j = i+1, j+1
+		}
+		t := float64(c1 + c2)
+		midRank := float64(before1+before2) + (t+1)/2
+		r1 += float64(c1) * midRank
+		ties += t*t*t - t
+		// Cliff's delta counts, for each value of a2, the
+		// values of a1 below it minus the values above it.
+		dom += float64(c2) * float64(before1-(n1-before1-c1))
+		before1 += c1
+		before2 += c2
+	}
+
+	fn1, fn2 := float64(n1), float64(n2)
+	n := fn1 + fn2
+	u := r1 - fn1*(fn1+1)/2
+	mu := fn1 * fn2 / 2
+	sigma := math.Sqrt(fn1 * fn2 / 12 * ((n + 1) - ties/(n*(n-1))))
+	if sigma == 0 {
+		// All values are tied.
+		cmp.Warnings = append(cmp.Warnings, stats.ErrSamplesEqual)
+		return cmp
+	}
+	z := math.Max(math.Abs(u-mu)-0.5, 0) / sigma
+	cmp.P = 2 * (1 - stats.NormalDist{Mu: 0, Sigma: 1}.CDF(z))
+	// Our U counts the values of a2 below the values of a1, like
+	// stats.MannWhitneyUTest.
+	cmp.Test, cmp.Statistic = UTest, u
+	cmp.Effect, cmp.EffectMeasure = dom/(fn1*fn2), EffectCliffsDelta
+	return cmp
+}
+
+// index returns the sketch bucket index of positive value x. Bucket i
+// covers the range (γ^(i-1), γ^i].
+func (a *Accumulator) index(x float64) int {
+	return int(math.Ceil(math.Log(x) / a.logGamma))
+}
+
+// bucketValue returns the representative value of bucket i, which is
+// within the accumulator's relative accuracy of every value in the
+// bucket.
+func (a *Accumulator) bucketValue(i int) float64 {
+	return 2 * math.Exp(float64(i)*a.logGamma) / (1 + math.Exp(a.logGamma))
+}
+
+// valueAt returns the approximate value of the 0-based rank'th
+// smallest value in a.
+func (a *Accumulator) valueAt(rank int) float64 {
+	if rank <= 0 {
+		return a.min
+	}
+	if rank >= a.n-1 {
+		return a.max
+	}
+	for _, b := range a.buckets() {
+		if rank >= b.count {
+			rank -= b.count
+			continue
+		}
+		var x float64
+		switch b.sign {
+		case -1:
+			x = -a.bucketValue(b.index)
+		case 1:
+			x = a.bucketValue(b.index)
+		}
+		return math.Max(a.min, math.Min(a.max, x))
+	}
+	return a.max
+}
+
+// An accumBucket is a non-empty sketch bucket.
+type accumBucket struct {
+	sign  int // -1 for negative values, 0 for zero, 1 for positive
+	index int
+	count int
+}
+
+func (b accumBucket) less(o accumBucket) bool {
+	if b.sign != o.sign {
+		return b.sign < o.sign
+	}
+	if b.sign < 0 {
+		// Larger magnitudes are smaller values.
+		return b.index > o.index
+	}
+	return b.index < o.index
+}
+
+// buckets returns the non-empty buckets of a in ascending value order.
+func (a *Accumulator) buckets() []accumBucket {
+	var bs []accumBucket
+	for i := len(a.neg.counts) - 1; i >= 0; i-- {
+		if c := a.neg.counts[i]; c > 0 {
+			bs = append(bs, accumBucket{-1, a.neg.offset + i, c})
+		}
+	}
+	if a.zero > 0 {
+		bs = append(bs, accumBucket{0, 0, a.zero})
+	}
+	for i, c := range a.pos.counts {
+		if c > 0 {
+			bs = append(bs, accumBucket{1, a.pos.offset + i, c})
+		}
+	}
+	return bs
+}
+
+// A ddStore is a dense store of bucket counts. It holds at most
+// maxAccumulatorBuckets buckets. If it would need more, it collapses
+// its lowest buckets, sacrificing accuracy for the smallest
+// magnitudes.
+type ddStore struct {
+	offset int   // Bucket index of counts[0]
+	counts []int // Count of each bucket
+}
+
+func (s *ddStore) add(idx, count int) {
+	if len(s.counts) == 0 {
+		s.offset = idx
+		s.counts = append(s.counts, count)
+		return
+	}
+	top := s.offset + len(s.counts) - 1
+	switch {
+	case idx < s.offset:
+		lo := idx
+		if top-lo+1 > maxAccumulatorBuckets {
+			// Collapse into the lowest bucket.
+			lo = top - maxAccumulatorBuckets + 1
+		}
+		if lo < s.offset {
+			grow := make([]int, s.offset-lo, top-lo+1)
+			s.counts = append(grow, s.counts...)
+			s.offset = lo
+		}
+		s.counts[0] += count
+	case idx > top:
+		for i := top + 1; i <= idx; i++ {
+			s.counts = append(s.counts, 0)
+		}
+		if extra := len(s.counts) - maxAccumulatorBuckets; extra > 0 {
+			// Collapse the lowest buckets.
+			for _, c := range s.counts[:extra] {
+				s.counts[extra] += c
+			}
+			s.counts = append(s.counts[:0], s.counts[extra:]...)
+			s.offset += extra
+		}
+		s.counts[len(s.counts)-1] += count
+	default:
+		s.counts[idx-s.offset] += count
+	}
+}
+
+func (s *ddStore) merge(o *ddStore) {
+	for i, c := range o.counts {
+		if c > 0 {
+			s.add(o.offset+i, c)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func accumValues(xs []float64) *Accumulator {
+	a := NewAccumulator(0, &DefaultThresholds)
+	for _, x := range xs {
+		a.Add(x)
+	}
+	return a
+}
+
+func randValues(rng *rand.Rand, n int, mean, sd float64) []float64 {
+	xs := make([]float64, n)
+	for i := range xs {
+		xs[i] = mean + sd*rng.NormFloat64()
+	}
+	return xs
+}
+
+func TestAccumulatorQuantile(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	xs := randValues(rng, 10000, 0, 10)
+	a := accumValues(xs)
+	s := NewSample(xs, &DefaultThresholds)
+
+	for _, q := range []float64{0, 0.01, 0.25, 0.5, 0.75, 0.99, 1} {
+		want := s.sample().Quantile(q)
+		got := a.Quantile(q)
+		// Allow for both the sketch's relative error and the
+		// different interpolation of Sample.Quantile.
+		if math.Abs(got-want) > 0.02*math.Abs(want)+0.01 {
+			t.Errorf("quantile %v: got %v, want %v", q, got, want)
+		}
+	}
+	if got, want := a.Quantile(0), s.Values[0]; got != want {
+		t.Errorf("min: got %v, want %v", got, want)
+	}
+	if got, want := a.Quantile(1), s.Values[len(s.Values)-1]; got != want {
+		t.Errorf("max: got %v, want %v", got, want)
+	}
+	if got := NewAccumulator(0, nil).Quantile(0.5); !math.IsNaN(got) {
+		t.Errorf("empty: got %v, want NaN", got)
+	}
+}
+
+func TestAccumulatorMerge(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	xs := randValues(rng, 1000, 100, 5)
+	whole := accumValues(xs)
+	part := accumValues(xs[:300])
+	if err := part.Merge(accumValues(xs[300:])); err != nil {
+		t.Fatal(err)
+	}
+
+	if whole.N() != part.N() {
+		t.Errorf("N: got %v, want %v", part.N(), whole.N())
+	}
+	if !aeq(whole.Mean(), part.Mean()) || !aeq(whole.Variance(), part.Variance()) {
+		t.Errorf("moments: got %v, %v, want %v, %v", part.Mean(), part.Variance(), whole.Mean(), whole.Variance())
+	}
+	for _, q := range []float64{0, 0.1, 0.5, 0.9, 1} {
+		if got, want := part.Quantile(q), whole.Quantile(q); got != want {
+			t.Errorf("quantile %v: got %v, want %v", q, got, want)
+		}
+	}
+
+	if err := part.Merge(NewAccumulator(0.05, nil)); err == nil {
+		t.Errorf("merging different accuracies: want error")
+	}
+}
+
+func TestAccumulatorCollapse(t *testing.T) {
+	// Values spanning a huge range collapse the lowest buckets,
+	// but keep the high quantiles accurate.
+	a := NewAccumulator(0, nil)
+	for e := -200; e <= 200; e++ {
+		a.Add(math.Pow(10, float64(e)))
+	}
+	if n := len(a.pos.counts); n > maxAccumulatorBuckets {
+		t.Errorf("got %d buckets, want <= %d", n, maxAccumulatorBuckets)
+	}
+	if got, want := a.Quantile(0.99), 1e196; math.Abs(got-want) > 0.01*want {
+		t.Errorf("quantile 0.99: got %v, want %v", got, want)
+	}
+}
+
+func TestAccumulatorSummary(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	xs := randValues(rng, 100, 10, 1)
+	a := accumValues(xs)
+	s := NewSample(xs, &DefaultThresholds)
+
+	// The mean summary is exact.
+	got, want := a.Summary(AssumeNormal, 0.95), AssumeNormal.Summary(s, 0.95)
+	if !aeq(got.Center, want.Center) || !aeq(got.Lo, want.Lo) || !aeq(got.Hi, want.Hi) {
+		t.Errorf("normal: got %+v, want %+v", got, want)
+	}
+
+	// The median summary is approximate.
+	got, want = a.Summary(AssumeNothing, 0.95), AssumeNothing.Summary(s, 0.95)
+	for _, v := range [][2]float64{{got.Center, want.Center}, {got.Lo, want.Lo}, {got.Hi, want.Hi}} {
+		if math.Abs(v[0]-v[1]) > 0.02*math.Abs(v[1]) {
+			t.Errorf("nothing: got %+v, want %+v", got, want)
+			break
+		}
+	}
+	if got.Confidence != want.Confidence {
+		t.Errorf("nothing: got confidence %v, want %v", got.Confidence, want.Confidence)
+	}
+
+	got = accumValues([]float64{1, 2}).Summary(AssumeNothing, 0.95)
+	checkSummary(t, got, Summary{Center: 2, Lo: math.Inf(-1), Hi: math.Inf(1), Confidence: 1}, "need >= 6 samples for confidence interval at level 0.95")
+
+	got = a.Summary(AssumeLogNormal, 0.95)
+	if len(got.Warnings) != 1 || got.Warnings[0].Error() != "geomean not supported for accumulated samples; using median" {
+		t.Errorf("lognormal: got warnings %v", got.Warnings)
+	}
+}
+
+func TestCompareAccumulators(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	xs, ys := randValues(rng, 200, 10, 1), randValues(rng, 200, 10.2, 1)
+	a1, a2 := accumValues(xs), accumValues(ys)
+	s1, s2 := NewSample(xs, &DefaultThresholds), NewSample(ys, &DefaultThresholds)
+
+	// Welch's t-test is exact.
+	got, want := CompareAccumulators(AssumeNormal, a1, a2), AssumeNormal.Compare(s1, s2)
+	if !aeq(got.P, want.P) || !aeq(got.Effect, want.Effect) {
+		t.Errorf("normal: got %+v, want %+v", got, want)
+	}
+
+	// The U-test is approximate. At the default accuracy, the
+	// buckets are about a fifth of a standard deviation wide
+	// here, so there are many ties.
+	got, want = CompareAccumulators(AssumeNothing, a1, a2), AssumeNothing.Compare(s1, s2)
+	if math.Abs(got.P-want.P) > 0.2*want.P {
+		t.Errorf("nothing: got P %v, want %v", got.P, want.P)
+	}
+	if math.Abs(got.Effect-want.Effect) > 0.01 {
+		t.Errorf("nothing: got effect %v, want %v", got.Effect, want.Effect)
+	}
+
+	// Identical values are tied.
+	same := accumValues([]float64{1, 1, 1})
+	checkComparison(t, CompareAccumulators(AssumeNothing, same, same), Comparison{P: 1, N1: 3, N2: 3, Alpha: 0.05}, "all samples are equal")
+}
+
+func aeq(x, y float64) bool {
+	return math.Abs(x-y) <= 1e-9*math.Max(math.Abs(x), math.Abs(y))
+}
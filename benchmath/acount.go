@@ -0,0 +1,191 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/aclements/go-moremath/mathx"
+	"github.com/aclements/go-moremath/stats"
+)
+
+// AssumeCount is an assumption for metrics that count discrete events,
+// such as allocations per operation. Such metrics are usually exact,
+// and when they do vary, they take small integer values for which
+// percent intervals from continuous assumptions are meaningless.
+//
+// If all values of a sample are the same, the summary is that value
+// with no uncertainty, like AssumeExact. Otherwise, the summary is
+// the mean with an exact Poisson confidence interval. Samples that are
+// both constant compare as different if their values differ at all.
+// Otherwise, comparisons use an exact test of whether the two samples
+// have the same Poisson rate.
+//
+// Values must be non-negative integers. For other samples, AssumeCount
+// falls back to AssumeNothing with a warning. It also warns if a
+// sample varies much more than a Poisson distribution, in which case
+// its intervals and tests are too optimistic.
+var AssumeCount = assumeCount{}
+
+type assumeCount struct{}
+
+var _ Assumption = assumeCount{}
+
+// poissonExactLimit is the largest total count for which AssumeCount
+// computes exact p-values. Above this, it uses a normal
+// approximation, which is very accurate for such large counts.
+const poissonExactLimit = 10000
+
+// maxPoissonDispersion is the ratio of variance to mean above which
+// AssumeCount warns that a sample is overdispersed.
+const maxPoissonDispersion = 2
+
+func (assumeCount) SummaryLabel() string {
+	return "mean"
+}
+
+// countSample returns the total of s's values and whether s's values
+// are all the same, or an error if s isn't a sample of counts.
+func countSample(s *Sample) (total float64, constant bool, err error) {
+	for _, x := range s.Values {
+		if !(x >= 0) || x != math.Floor(x) || math.IsInf(x, 0) {
+			return 0, false, fmt.Errorf("count distribution requires non-negative integer values, but sample contains %v", x)
+		}
+		total += x
+	}
+	constant = len(s.Values) > 0 && s.Values[0] == s.Values[len(s.Values)-1]
+	return total, constant, nil
+}
+
+// overdispersed returns a warning if the values of s vary much more
+// than a Poisson distribution with the same mean.
+func overdispersed(s *Sample) []error {
+	if len(s.Values) < 2 {
+		return nil
+	}
+	mean, v := meanVar(s.Values)
+	if v > maxPoissonDispersion*mean {
+		return []error{fmt.Errorf("values vary more than expected for counts (variance %.3g, mean %.3g)", v, mean)}
+	}
+	return nil
+}
+
+func (a assumeCount) Summary(s *Sample, confidence float64) Summary {
+	total, constant, err := countSample(s)
+	if err != nil {
+		summary := AssumeNothing.Summary(s, confidence)
+		summary.Warnings = append([]error{err}, summary.Warnings...)
+		return summary
+	}
+	warnings := unsupported(a, s)
+	if constant {
+		v := s.Values[0]
+		return Summary{Center: v, Lo: v, Hi: v, Confidence: 1, Warnings: warnings}
+	}
+
+	// The Garwood interval for the total count, scaled to the
+	// mean.
+	n := float64(len(s.Values))
+	lo, hi := 0.0, math.Inf(1)
+	if confidence >= 1 {
+		// Leave the interval unbounded.
+	} else if confidence > 0 {
+		tail := (1 - confidence) / 2
+		if total > 0 {
+			lo = gammaQuantile(total, tail) / n
+		}
+		hi = gammaQuantile(total+1, 1-tail) / n
+	} else {
+		lo, hi = total/n, total/n
+	}
+	warnings = append(warnings, overdispersed(s)...)
+	return Summary{Center: total / n, Lo: lo, Hi: hi, Confidence: confidence, Warnings: warnings}
+}
+
+func (a assumeCount) Compare(s1, s2 *Sample) Comparison {
+	total1, const1, err1 := countSample(s1)
+	total2, const2, err2 := countSample(s2)
+	if err1 != nil || err2 != nil {
+		cmp := AssumeNothing.Compare(s1, s2)
+		for _, err := range []error{err2, err1} {
+			if err != nil {
+				cmp.Warnings = append([]error{err}, cmp.Warnings...)
+			}
+		}
+		return cmp
+	}
+
+	cmp := Comparison{N1: len(s1.Values), N2: len(s2.Values), Alpha: s1.Thresholds.CompareAlpha}
+	cmp.Warnings = unsupported(a, s1, s2)
+	if cmp.N1 == 0 || cmp.N2 == 0 {
+		cmp.P = 1
+		cmp.Warnings = append(cmp.Warnings, stats.ErrSampleSize)
+		return cmp
+	}
+	if const1 && const2 {
+		// Like AssumeExact, any difference is significant.
+		cmp.Exact = true
+		if s1.Values[0] == s2.Values[0] {
+			cmp.P = 1
+		}
+		return cmp
+	}
+
+	// Conditional on the total count, the count in s2 is binomial
+	// with probability n2/(n1+n2) if the rates are the same.
+	n1, n2 := float64(cmp.N1), float64(cmp.N2)
+	cmp.P = poissonRateTest(total1+total2, total2, n2/(n1+n2))
+	cmp.Test, cmp.Statistic, cmp.Exact = PoissonRateTest, total2, total1+total2 <= poissonExactLimit
+	cmp.Warnings = append(cmp.Warnings, overdispersed(s1)...)
+	cmp.Warnings = append(cmp.Warnings, overdispersed(s2)...)
+	addCompareWarnings(&cmp, s1, s2)
+	return cmp
+}
+
+// poissonRateTest returns the two-sided p-value of observing k of n
+// events in the second of two samples, where p is the expected
+// fraction of events in the second sample.
+func poissonRateTest(n, k, p float64) float64 {
+	if n == 0 {
+		return 1
+	}
+	if n > poissonExactLimit {
+		mu, sigma := n*p, math.Sqrt(n*p*(1-p))
+		z := math.Max(math.Abs(k-mu)-0.5, 0) / sigma
+		return 2 * (1 - stats.NormalDist{Mu: 0, Sigma: 1}.CDF(z))
+	}
+	// Sum the probabilities of all outcomes no more likely than k.
+	logPMF := func(i int) float64 {
+		return mathx.Lchoose(int(n), i) + float64(i)*math.Log(p) + (n-float64(i))*math.Log1p(-p)
+	}
+	obs := logPMF(int(k))
+	var sum float64
+	for i := 0; i <= int(n); i++ {
+		if lp := logPMF(i); lp <= obs+1e-7 {
+			sum += math.Exp(lp)
+		}
+	}
+	return math.Min(sum, 1)
+}
+
+// gammaQuantile returns the p'th quantile of the gamma distribution
+// with shape a and scale 1.
+func gammaQuantile(a, p float64) float64 {
+	// Bracket the quantile, then bisect.
+	lo, hi := 0.0, a+1
+	for mathx.GammaInc(a, hi) < p {
+		lo, hi = hi, 2*hi
+	}
+	for i := 0; i < 100 && hi-lo > 1e-12*hi; i++ {
+		mid := (lo + hi) / 2
+		if mathx.GammaInc(a, mid) < p {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
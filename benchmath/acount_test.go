@@ -0,0 +1,80 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestSummaryCount(t *testing.T) {
+	check := func(xs []float64, want Summary, warnings ...string) {
+		t.Helper()
+		s := NewSample(xs, &DefaultThresholds)
+		checkSummary(t, AssumeCount.Summary(s, 0.95), want, warnings...)
+	}
+	// Constant counts have no uncertainty.
+	check([]float64{3, 3, 3}, Summary{3, 3, 3, 1, 0, nil})
+
+	// The Garwood interval for a total of 8 is [3.4538, 15.763],
+	// scaled by 1/4.
+	got := AssumeCount.Summary(NewSample([]float64{1, 2, 3, 2}, &DefaultThresholds), 0.95)
+	if got.Center != 2 || math.Abs(got.Lo-3.4538/4) > 1e-4 || math.Abs(got.Hi-15.763/4) > 1e-3 || len(got.Warnings) != 0 {
+		t.Errorf("got %v, want 2 [0.8635, 3.9408]", got)
+	}
+
+	// A total of 0 has a lower bound of 0.
+	got = AssumeCount.Summary(NewSample([]float64{0, 0, 0}, &DefaultThresholds), 0.95)
+	checkSummary(t, got, Summary{0, 0, 0, 1, 0, nil})
+
+	// Non-counts fall back to AssumeNothing.
+	xs := []float64{1, 1.5, 2}
+	want := AssumeNothing.Summary(NewSample(xs, &DefaultThresholds), 0.95)
+	want.Warnings = append([]error{errors.New("count distribution requires non-negative integer values, but sample contains 1.5")}, want.Warnings...)
+	check(xs, want)
+
+	// Counts that vary too much get a warning.
+	got = AssumeCount.Summary(NewSample([]float64{0, 10, 0, 10}, &DefaultThresholds), 0.95)
+	if len(got.Warnings) != 1 || got.Warnings[0].Error() != "values vary more than expected for counts (variance 33.3, mean 5)" {
+		t.Errorf("overdispersed: got warnings %v", got.Warnings)
+	}
+}
+
+func TestCompareCount(t *testing.T) {
+	check := func(a, b []float64, want Comparison) {
+		t.Helper()
+		s1, s2 := NewSample(a, &DefaultThresholds), NewSample(b, &DefaultThresholds)
+		got := AssumeCount.Compare(s1, s2)
+		if !aeq(got.P, want.P) || got.Exact != want.Exact || got.Test != want.Test || len(got.Warnings) != 0 {
+			t.Errorf("%v vs %v: got %+v, want %+v", a, b, got, want)
+		}
+	}
+	// Constant counts are different if they differ at all.
+	check([]float64{3, 3}, []float64{4, 4}, Comparison{P: 0, Exact: true})
+	check([]float64{3, 3}, []float64{3, 3}, Comparison{P: 1, Exact: true})
+
+	// Given 16 events split between equal-sized samples, 14 or more
+	// in either sample has probability 2·(1+16+120)/2^16.
+	check([]float64{1, 0, 1, 0}, []float64{3, 4, 3, 4}, Comparison{P: 274.0 / 65536, Exact: true, Test: PoissonRateTest})
+
+	// With unequal sample sizes, the expected split is uneven. Here
+	// s2 has 3 of 5 events, but expects 1/3 of them. The outcomes
+	// no more likely than that are 0, 3, 4, and 5, with
+	// probabilities 32, 40, 10, and 1 out of 3^5.
+	check([]float64{0, 1, 0, 1}, []float64{1, 2}, Comparison{P: 83.0 / 243, Exact: true, Test: PoissonRateTest})
+}
+
+func TestPoissonRateTestApprox(t *testing.T) {
+	// With large counts, the normal approximation should be close
+	// to the exact test.
+	const n, p = poissonExactLimit, 0.5
+	k := n*p + 2*math.Sqrt(n*p*(1-p))
+	exact := poissonRateTest(n, k, p)
+	approx := poissonRateTest(n+1, k, p)
+	if math.Abs(exact-approx) > 0.005 {
+		t.Errorf("exact p=%v, approximate p=%v", exact, approx)
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/aclements/go-moremath/stats"
+)
+
+// AssumeLogNormal is an assumption that a sample is log-normally
+// distributed. This is often a good model for latencies and other
+// positive, right-skewed measurements. The summary statistic is the
+// geometric mean with a multiplicative confidence interval, and
+// comparisons are done using Welch's two-sample t-test on the
+// logarithms of the values.
+//
+// Log-normal distributions are only defined for positive values. If a
+// sample contains zero or negative values, this falls back to
+// AssumeNormal and reports a warning.
+var AssumeLogNormal = assumeLogNormal{}
+
+type assumeLogNormal struct{}
+
+var _ Assumption = assumeLogNormal{}
+
+func (assumeLogNormal) SummaryLabel() string {
+	return "geomean"
+}
+
+// logSample returns the logarithms of the values in s, or an error if
+// any value in s is not positive.
+func logSample(s *Sample) (stats.Sample, error) {
+	// s.Values is sorted, so we only need to check the first.
+	if len(s.Values) > 0 && !(s.Values[0] > 0) {
+		return stats.Sample{}, fmt.Errorf("log-normal distribution requires positive values, but sample contains %v", s.Values[0])
+	}
+	logs := make([]float64, len(s.Values))
+	for i, v := range s.Values {
+		logs[i] = math.Log(v)
+	}
+	// Log is monotonic, so logs is also sorted.
+	return stats.Sample{Xs: logs, Weights: s.Weights, Sorted: true}, nil
+}
+
+func (assumeLogNormal) Summary(s *Sample, confidence float64) Summary {
+	logs, err := logSample(s)
+	if err != nil {
+		summary := AssumeNormal.Summary(s, confidence)
+		summary.Warnings = append([]error{err}, summary.Warnings...)
+		return summary
+	}
+
+	mean, lo, hi := meanCI(logs, confidence)
+
+	warnings := censoringIgnored(AssumeLogNormal, s)
+	if math.IsInf(lo, 0) || math.IsInf(hi, 0) {
+		// Explain to the user why the interval is unbounded.
+		// See assumeNormal.Summary.
+		if len(s.Values) < 2 {
+			warnings = append(warnings, fmt.Errorf("need >= 2 samples for confidence interval at level %v", confidence))
+		}
+	}
+
+	// exp maps the interval in log space to a multiplicative
+	// interval around the geometric mean. Note that exp(-Inf) is
+	// 0, which is the correct lower bound for an unbounded
+	// log-normal interval.
+	return Summary{
+		Center:     math.Exp(mean),
+		Lo:         math.Exp(lo),
+		Hi:         math.Exp(hi),
+		Confidence: confidence,
+		Warnings:   warnings,
+	}
+}
+
+func (assumeLogNormal) Compare(s1, s2 *Sample) Comparison {
+	logs1, err1 := logSample(s1)
+	logs2, err2 := logSample(s2)
+	if err1 != nil || err2 != nil {
+		cmp := AssumeNormal.Compare(s1, s2)
+		for _, err := range []error{err2, err1} {
+			if err != nil {
+				cmp.Warnings = append([]error{err}, cmp.Warnings...)
+			}
+		}
+		return cmp
+	}
+
+	alpha := s1.Thresholds.CompareAlpha
+	t, err := welchTTest(logs1, logs2)
+	if err != nil {
+		// The t-test failed. Report as if there's no
+		// significant difference, along with the error.
+		return Comparison{P: 1, N1: len(s1.Values), N2: len(s2.Values), Alpha: alpha, Warnings: []error{err}}
+	}
+	cmp := Comparison{P: t.P, N1: len(s1.Values), N2: len(s2.Values), Alpha: alpha}
+	cmp.Test, cmp.Statistic, cmp.DF = WelchTTest, t.T, t.DoF
+	cmp.setCohensD(logs1, logs2)
+	cmp.Warnings = censoringIgnored(AssumeLogNormal, s1, s2)
+	addCompareWarnings(&cmp, s1, s2)
+	return cmp
+}
@@ -63,18 +63,41 @@ func (assumeNothing) SummaryLabel() string {
 }
 
 func (assumeNothing) Summary(s *Sample, confidence float64) Summary {
-	ci := medianCI(len(s.Values), confidence)
-	median, lo, hi := ci.SampleCI(s.sample())
-
+	var ci stats.QuantileCIResult
+	var median, lo, hi float64
 	var warnings []error
-	if math.IsInf(lo, 0) || math.IsInf(hi, 0) {
+	// unbounded is whether the interval would be unbounded even
+	// without censoring.
+	var unbounded bool
+	if s.Censored != nil {
+		var censored bool
+		ci, median, lo, hi, censored = censoredMedianCI(s, confidence)
+		if censored {
+			warnings = append(warnings, fmt.Errorf("median is censored; it is at least %v", median))
+		}
+		_, plainLo, plainHi := ci.SampleCI(s.sample())
+		unbounded = math.IsInf(plainLo, 0) || math.IsInf(plainHi, 0)
+		if math.IsInf(hi, 1) && !unbounded {
+			warnings = append(warnings, fmt.Errorf("confidence interval is unbounded because too many values are censored"))
+		}
+	} else {
+		if s.Weights != nil {
+			ci, median, lo, hi = weightedMedianCI(s, confidence)
+		} else {
+			ci = medianCI(len(s.Values), confidence)
+			median, lo, hi = ci.SampleCI(s.sample())
+		}
+		unbounded = math.IsInf(lo, 0) || math.IsInf(hi, 0)
+	}
+
+	if unbounded {
 		// Explain to the user why there's a ±∞
 		op, need := medianSamples(confidence)
 		msg := fmt.Errorf("need %s %d samples for confidence interval at level %v", op, need, confidence)
 		warnings = append(warnings, msg)
 	}
 
-	return Summary{median, lo, hi, ci.Confidence, warnings}
+	return Summary{median, lo, hi, ci.Confidence, 0, warnings}
 }
 
 // uTestMinP[n] is the minimum possible P value for the U-test with
@@ -109,6 +132,16 @@ func uTestSamples(alpha float64) (op string, n int) {
 }
 
 func (assumeNothing) Compare(s1, s2 *Sample) Comparison {
+	if s1.Censored != nil || s2.Censored != nil {
+		cmp := gehanTest(s1, s2)
+		addCompareWarnings(&cmp, s1, s2)
+		return cmp
+	}
+	if s1.Weights != nil || s2.Weights != nil {
+		cmp := weightedUTest(s1, s2)
+		addCompareWarnings(&cmp, s1, s2)
+		return cmp
+	}
 	res, err := stats.MannWhitneyUTest(s1.Values, s2.Values, stats.LocationDiffers)
 	if err != nil {
 		// The U-test failed. Report as if there's no
@@ -116,6 +149,8 @@ func (assumeNothing) Compare(s1, s2 *Sample) Comparison {
 		return Comparison{P: 1, N1: len(s1.Values), N2: len(s2.Values), Alpha: s1.Thresholds.CompareAlpha, Warnings: []error{err}}
 	}
 	cmp := Comparison{P: res.P, N1: res.N1, N2: res.N2, Alpha: s1.Thresholds.CompareAlpha}
+	cmp.Test, cmp.Statistic, cmp.Exact = UTest, res.U, uTestExact(s1.Values, s2.Values)
+	cmp.Effect, cmp.EffectMeasure = cliffsDelta(s1.Values, s2.Values), EffectCliffsDelta
 	// Warn if there aren't enough samples to report a difference
 	// even if they were maximally diverged.
 	if cmp.P > cmp.Alpha {
@@ -130,5 +165,6 @@ func (assumeNothing) Compare(s1, s2 *Sample) Comparison {
 			cmp.Warnings = append(cmp.Warnings, msg)
 		}
 	}
+	addCompareWarnings(&cmp, s1, s2)
 	return cmp
 }
@@ -4,11 +4,19 @@
 
 package benchmath
 
-import "github.com/aclements/go-moremath/stats"
+import (
+	"fmt"
+	"math"
+)
 
 // AssumeNormal is an assumption that a sample is normally distributed.
-// The summary statistic is the sample mean and comparisons are done
-// using the two-sample t-test.
+// The summary statistic is the sample mean with a confidence interval
+// based on the standard error, and comparisons are done using Welch's
+// two-sample t-test, which doesn't assume the samples have equal
+// variance.
+//
+// This is more statistically powerful than AssumeNothing, but is only
+// appropriate for measurements that are known to be well-behaved.
 var AssumeNormal = assumeNormal{}
 
 type assumeNormal struct{}
@@ -22,23 +30,39 @@ func (assumeNormal) SummaryLabel() string {
 func (assumeNormal) Summary(s *Sample, confidence float64) Summary {
 	// TODO: Perform a normality test.
 
-	sample := s.sample()
-	mean, lo, hi := sample.MeanCI(confidence)
+	mean, lo, hi := meanCI(s.weightedSample(), confidence)
+
+	warnings := censoringIgnored(AssumeNormal, s)
+	if math.IsInf(lo, 0) || math.IsInf(hi, 0) {
+		// Explain to the user why there's a ±∞. The CI is
+		// also infinite at confidence level 1, but then more
+		// samples won't help.
+		if len(s.Values) < 2 {
+			warnings = append(warnings, fmt.Errorf("need >= 2 samples for confidence interval at level %v", confidence))
+		}
+	}
 
 	return Summary{
 		Center:     mean,
 		Lo:         lo,
 		Hi:         hi,
 		Confidence: confidence,
+		Warnings:   warnings,
 	}
 }
 
 func (assumeNormal) Compare(s1, s2 *Sample) Comparison {
-	t, err := stats.TwoSampleWelchTTest(s1.sample(), s2.sample(), stats.LocationDiffers)
+	alpha := s1.Thresholds.CompareAlpha
+	t, err := welchTTest(s1.weightedSample(), s2.weightedSample())
 	if err != nil {
 		// The t-test failed. Report as if there's no
 		// significant difference, along with the error.
-		return Comparison{P: 1, N1: len(s1.Values), N2: len(s2.Values), Warnings: []error{err}}
+		return Comparison{P: 1, N1: len(s1.Values), N2: len(s2.Values), Alpha: alpha, Warnings: []error{err}}
 	}
-	return Comparison{P: t.P, N1: len(s1.Values), N2: len(s2.Values)}
+	cmp := Comparison{P: t.P, N1: len(s1.Values), N2: len(s2.Values), Alpha: alpha}
+	cmp.Test, cmp.Statistic, cmp.DF = WelchTTest, t.T, t.DoF
+	cmp.setCohensD(s1.weightedSample(), s2.weightedSample())
+	cmp.Warnings = censoringIgnored(AssumeNormal, s1, s2)
+	addCompareWarnings(&cmp, s1, s2)
+	return cmp
 }
@@ -0,0 +1,112 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/aclements/go-moremath/stats"
+)
+
+// Quantile is a non-parametric assumption that summarizes a sample by
+// an arbitrary quantile, such as the 90th or 99th percentile, rather
+// than its median. This is useful when the tail of a distribution is
+// more interesting than its center, such as for tail latency.
+//
+// Like AssumeNothing, the confidence interval of the summary is
+// computed from order statistics, and so requires no distributional
+// assumptions. Extreme quantiles require many samples to get a finite
+// interval. Comparisons test whether the quantiles of the two samples
+// differ using a bootstrap test.
+type Quantile struct {
+	// Q is the quantile to summarize by, in the range (0, 1).
+	// For example, 0.9 for the 90th percentile.
+	Q float64
+}
+
+var _ Assumption = Quantile{}
+
+// SummaryLabel returns a label for the quantile such as "p90" or
+// "p99.9".
+func (a Quantile) SummaryLabel() string {
+	return "p" + strconv.FormatFloat(a.Q*100, 'g', -1, 64)
+}
+
+// quantileCache maps from a ciKey to stats.QuantileCIResult.
+var quantileCache sync.Map
+
+func quantileCI(n int, q, confidence float64) stats.QuantileCIResult {
+	type ciKey struct {
+		n             int
+		q, confidence float64
+	}
+	key := ciKey{n, q, confidence}
+	if ciX, ok := quantileCache.Load(key); ok {
+		return ciX.(stats.QuantileCIResult)
+	}
+	ci := stats.QuantileCI(n, q, confidence)
+	quantileCache.Store(key, ci)
+	return ci
+}
+
+// quantileSamples returns the minimum number of samples required to
+// get a finite confidence interval for quantile q at the given
+// confidence level.
+func quantileSamples(q, confidence float64) (op string, n int) {
+	const limit = 1000
+	// The interval gets narrower as n grows, so binary search
+	// for the smallest n with a finite interval.
+	finite := func(n int) bool {
+		ci := quantileCI(n, q, confidence)
+		return 0 < ci.LoOrder && ci.HiOrder <= n
+	}
+	n = 2 + sort.Search(limit-1, func(i int) bool { return finite(i + 2) })
+	if n > limit {
+		return ">", limit
+	}
+	return ">=", n
+}
+
+// stat computes a's quantile of xs. It may reorder xs.
+func (a Quantile) stat(xs []float64) float64 {
+	sort.Float64s(xs)
+	return stats.Sample{Xs: xs, Sorted: true}.Quantile(a.Q)
+}
+
+func (a Quantile) Summary(s *Sample, confidence float64) Summary {
+	ci := quantileCI(len(s.Values), a.Q, confidence)
+	center, lo, hi := ci.SampleCI(s.sample())
+	summary := Summary{Center: center, Lo: lo, Hi: hi, Confidence: ci.Confidence, Warnings: unsupported(a, s)}
+
+	if !(0 < ci.LoOrder && ci.HiOrder <= len(s.Values)) {
+		// Explain to the user why there's a ±∞.
+		op, need := quantileSamples(a.Q, confidence)
+		summary.Warnings = append(summary.Warnings, fmt.Errorf("need %s %d samples for %s confidence interval at level %v", op, need, a.SummaryLabel(), confidence))
+	}
+	return summary
+}
+
+func (a Quantile) Compare(s1, s2 *Sample) Comparison {
+	cmp := Comparison{N1: len(s1.Values), N2: len(s2.Values), Alpha: s1.Thresholds.CompareAlpha}
+	cmp.Warnings = unsupported(a, s1, s2)
+	if len(s1.Values) < 2 || len(s2.Values) < 2 {
+		cmp.P = 1
+		cmp.Warnings = append(cmp.Warnings, fmt.Errorf("need >= 2 samples to bootstrap a comparison"))
+		return cmp
+	}
+
+	rng := rand.New(rand.NewSource(bootstrapSeed))
+	reps1 := bootstrap(s1.Values, DefaultBootstrapResamples, rng, a.stat)
+	reps2 := bootstrap(s2.Values, DefaultBootstrapResamples, rng, a.stat)
+	cmp.P = bootstrapP(reps1, reps2)
+	cmp.Test = BootstrapTest
+	cmp.Statistic = a.stat(append([]float64(nil), s2.Values...)) - a.stat(append([]float64(nil), s1.Values...))
+	addCompareWarnings(&cmp, s1, s2)
+	return cmp
+}
@@ -120,6 +120,256 @@ func TestSummaryNormal(t *testing.T) {
 		Summary{Center: 2, Lo: -3.351092806089359, Hi: 7.351092806089359, Confidence: 0.95})
 }
 
+func TestSummaryNormalSmall(t *testing.T) {
+	a := AssumeNormal
+	inf := math.Inf(1)
+	sample := NewSample([]float64{1}, &DefaultThresholds)
+	checkSummary(t, a.Summary(sample, 0.95),
+		Summary{Center: 1, Lo: -inf, Hi: inf, Confidence: 0.95},
+		"need >= 2 samples for confidence interval at level 0.95")
+}
+
+func TestCompareNormal(t *testing.T) {
+	a := AssumeNormal
+	thr := DefaultThresholds
+	thr.CompareAlpha = 0.05
+	s1 := NewSample([]float64{1, 2, 3, 4}, &thr)
+	s2 := NewSample([]float64{5, 6, 7, 8}, &thr)
+	checkComparison(t, a.Compare(s1, s2),
+		Comparison{P: 0.004659214943993906, N1: 4, N2: 4, Alpha: 0.05})
+
+	// Too-small samples.
+	s1 = NewSample([]float64{1}, &thr)
+	checkComparison(t, a.Compare(s1, s2),
+		Comparison{P: 1, N1: 1, N2: 4, Alpha: 0.05},
+		"sample is too small")
+}
+
+func TestSummaryLogNormal(t *testing.T) {
+	a := AssumeLogNormal
+	sample := NewSample([]float64{1, 4, 16}, &DefaultThresholds)
+	got := a.Summary(sample, 0.95)
+	if math.Abs(got.Center-4) > 1e-9 {
+		t.Errorf("want geomean 4, got %v", got.Center)
+	}
+	// The interval should be multiplicatively symmetric around
+	// the geometric mean.
+	if math.Abs(got.Lo*got.Hi-16) > 1e-9 || !(got.Lo < 1) {
+		t.Errorf("want multiplicative interval around 4, got [%v, %v]", got.Lo, got.Hi)
+	}
+
+	// Too-small samples.
+	sample = NewSample([]float64{2}, &DefaultThresholds)
+	checkSummary(t, a.Summary(sample, 0.95),
+		Summary{Center: 2, Lo: 0, Hi: math.Inf(1), Confidence: 0.95},
+		"need >= 2 samples for confidence interval at level 0.95")
+
+	// Non-positive values fall back to a normal assumption.
+	sample = NewSample([]float64{0, 2}, &DefaultThresholds)
+	want := AssumeNormal.Summary(sample, 0.95)
+	checkSummary(t, a.Summary(sample, 0.95), want,
+		"log-normal distribution requires positive values, but sample contains 0")
+}
+
+func TestCompareLogNormal(t *testing.T) {
+	a := AssumeLogNormal
+	thr := DefaultThresholds
+	thr.CompareAlpha = 0.05
+
+	// Multiplying a sample by a constant shouldn't affect how it
+	// compares in log space.
+	s1 := NewSample([]float64{1, 2, 4, 8}, &thr)
+	s2 := NewSample([]float64{4, 8, 16, 32}, &thr)
+	s3 := NewSample([]float64{40, 80, 160, 320}, &thr)
+	s4 := NewSample([]float64{160, 320, 640, 1280}, &thr)
+	c1, c2 := a.Compare(s1, s2), a.Compare(s3, s4)
+	if math.Abs(c1.P-c2.P) > 1e-9 || c1.Alpha != 0.05 {
+		t.Errorf("want equal comparisons for scaled samples, got %v and %v", c1, c2)
+	}
+
+	// Non-positive values fall back to a normal assumption.
+	s5 := NewSample([]float64{-1, 1, 2, 3}, &thr)
+	want := AssumeNormal.Compare(s5, s1)
+	checkComparison(t, a.Compare(s5, s1), want,
+		"log-normal distribution requires positive values, but sample contains -1")
+}
+
+func TestSummaryBootstrap(t *testing.T) {
+	for _, a := range []Bootstrap{AssumeBootstrap, AssumeBootstrapMean} {
+		sample := NewSample([]float64{1, 2, 3, 4, 10}, &DefaultThresholds)
+		got := a.Summary(sample, 0.95)
+		wantCenter := 3.0
+		if a.Mean {
+			wantCenter = 4
+		}
+		if got.Center != wantCenter || !(got.Lo < got.Center && got.Center < got.Hi) || got.Lo < 1 || got.Hi > 10 {
+			t.Errorf("%s: got %v, want center %v within (1, 10)", a.SummaryLabel(), got, wantCenter)
+		}
+		// Results should be deterministic.
+		if got2 := a.Summary(sample, 0.95); got.Lo != got2.Lo || got.Hi != got2.Hi {
+			t.Errorf("%s: results not deterministic: %v, %v", a.SummaryLabel(), got, got2)
+		}
+		// A higher confidence level should widen the interval.
+		if got2 := a.Summary(sample, 0.99); got2.Lo > got.Lo || got2.Hi < got.Hi {
+			t.Errorf("%s: 99%% interval %v narrower than 95%% interval %v", a.SummaryLabel(), got2, got)
+		}
+	}
+
+	inf := math.Inf(1)
+	sample := NewSample([]float64{1}, &DefaultThresholds)
+	checkSummary(t, AssumeBootstrap.Summary(sample, 0.95),
+		Summary{Center: 1, Lo: -inf, Hi: inf, Confidence: 0.95},
+		"need >= 2 samples for bootstrap interval")
+}
+
+func TestCompareBootstrap(t *testing.T) {
+	a := AssumeBootstrap
+	thr := DefaultThresholds
+	thr.CompareAlpha = 0.05
+
+	// Samples that are far apart should be significantly
+	// different, even with few samples.
+	s1 := NewSample([]float64{1, 2, 3}, &thr)
+	s2 := NewSample([]float64{11, 12, 13}, &thr)
+	want := 2 / float64(DefaultBootstrapResamples+1)
+	checkComparison(t, a.Compare(s1, s2), Comparison{P: want, N1: 3, N2: 3, Alpha: 0.05})
+
+	// Identical samples should not.
+	checkComparison(t, a.Compare(s1, s1), Comparison{P: 1, N1: 3, N2: 3, Alpha: 0.05})
+
+	s3 := NewSample([]float64{1}, &thr)
+	checkComparison(t, a.Compare(s1, s3), Comparison{P: 1, N1: 3, N2: 1, Alpha: 0.05},
+		"need >= 2 samples to bootstrap a comparison")
+}
+
+func TestBootstrapRatioCI(t *testing.T) {
+	a := AssumeBootstrap
+	s1 := NewSample([]float64{9, 10, 11}, &DefaultThresholds)
+	s2 := NewSample([]float64{18, 20, 22}, &DefaultThresholds)
+	got := a.RatioCI(s1, s2, 0.95)
+	if got.Center != 2 || !(got.Lo <= 2 && 2 <= got.Hi) || got.Lo < 18.0/11 || got.Hi > 22.0/9 {
+		t.Errorf("got %v, want ratio 2 within [%v, %v]", got, 18.0/11, 22.0/9)
+	}
+
+	zero := NewSample([]float64{0, 0}, &DefaultThresholds)
+	if got := a.RatioCI(zero, s2, 0.95); !math.IsNaN(got.Center) || !errorsEq(got.Warnings, []error{fmt.Errorf("ratio is undefined because base median is 0")}) {
+		t.Errorf("zero base: got %v", got)
+	}
+}
+
+func TestSummaryTrimmed(t *testing.T) {
+	sample := NewSample([]float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 100}, &DefaultThresholds)
+	for _, a := range []Trimmed{AssumeTrimmed, AssumeWinsorized} {
+		got := a.Summary(sample, 0.95)
+		// The trimmed mean is mean(3..8) and the winsorized
+		// mean is mean(3, 3, 3, 4, ..., 8, 8, 8). Both are 5.5.
+		if got.Center != 5.5 || got.Trim != 0.2 || !(got.Lo < 5.5 && got.Hi > 5.5) || got.Hi > 10 {
+			t.Errorf("%s: got %+v", a.SummaryLabel(), got)
+		}
+	}
+
+	// A different trim fraction.
+	a := Trimmed{Fraction: 0.1}
+	if got := a.Summary(sample, 0.95); got.Center != 5.5 || got.Trim != 0.1 {
+		t.Errorf("10%% trim: got %+v", got)
+	}
+
+	inf := math.Inf(1)
+	sample = NewSample([]float64{1}, &DefaultThresholds)
+	checkSummary(t, AssumeTrimmed.Summary(sample, 0.95),
+		Summary{Center: 1, Lo: -inf, Hi: inf, Confidence: 0.95},
+		"need >= 2 samples after trimming for confidence interval at level 0.95")
+}
+
+func TestCompareTrimmed(t *testing.T) {
+	a := AssumeTrimmed
+	thr := DefaultThresholds
+	// An extreme outlier in s2 would ruin a t-test, but is
+	// trimmed here.
+	s1 := NewSample([]float64{10, 11, 12, 10, 11, 12, 10, 11, 12, 11}, &thr)
+	s2 := NewSample([]float64{13, 14, 15, 13, 14, 15, 13, 14, 15, 1000}, &thr)
+	if cmp := a.Compare(s1, s2); !(cmp.P < 0.05) {
+		t.Errorf("want significant difference, got %v", cmp)
+	}
+	if cmp := AssumeNormal.Compare(s1, s2); cmp.P < 0.05 {
+		t.Errorf("want t-test to miss difference, got %v", cmp)
+	}
+	if cmp := a.Compare(s1, s1); cmp.P != 1 {
+		t.Errorf("want no difference, got %v", cmp)
+	}
+
+	s3 := NewSample([]float64{1}, &thr)
+	checkComparison(t, a.Compare(s1, s3), Comparison{P: 1, N1: 10, N2: 1, Alpha: 0.05},
+		"need >= 2 samples after trimming to compare")
+	s4 := NewSample([]float64{5, 5, 5}, &thr)
+	checkComparison(t, a.Compare(s4, s4), Comparison{P: 1, N1: 3, N2: 3, Alpha: 0.05},
+		"sample has zero variance")
+}
+
+func TestSummaryQuantile(t *testing.T) {
+	a := Quantile{Q: 0.9}
+	if got, want := a.SummaryLabel(), "p90"; got != want {
+		t.Errorf("want label %q, got %q", want, got)
+	}
+	if got, want := (Quantile{Q: 0.999}).SummaryLabel(), "p99.9"; got != want {
+		t.Errorf("want label %q, got %q", want, got)
+	}
+
+	var vs []float64
+	for i := 1; i <= 100; i++ {
+		vs = append(vs, float64(i))
+	}
+	sample := NewSample(vs, &DefaultThresholds)
+	got := a.Summary(sample, 0.95)
+	if got.Center < 89 || got.Center > 91 || !(got.Lo < got.Center && got.Center < got.Hi) || got.Lo < 80 || got.Hi > 100 || got.Warnings != nil {
+		t.Errorf("got %+v", got)
+	}
+
+	// The 99th percentile needs many more samples.
+	got = Quantile{Q: 0.99}.Summary(sample, 0.95)
+	inf := math.Inf(1)
+	checkSummary(t, got, Summary{Center: got.Center, Lo: got.Lo, Hi: inf, Confidence: got.Confidence},
+		"need >= 441 samples for p99 confidence interval at level 0.95")
+}
+
+func TestQuantileSamples(t *testing.T) {
+	// The median should agree with medianSamples.
+	for _, confidence := range []float64{0.95, 0.99} {
+		op1, n1 := medianSamples(confidence)
+		op2, n2 := quantileSamples(0.5, confidence)
+		if op1 != op2 || n1 != n2 {
+			t.Errorf("at confidence %v, medianSamples = %s %d, quantileSamples = %s %d", confidence, op1, n1, op2, n2)
+		}
+	}
+	if op, n := quantileSamples(0.99999, 0.95); op != ">" || n != 1000 {
+		t.Errorf("want > 1000, got %s %d", op, n)
+	}
+}
+
+func TestCompareQuantile(t *testing.T) {
+	thr := DefaultThresholds
+	// Same median, but s2 has a much heavier tail.
+	var v1, v2 []float64
+	for i := 0; i < 50; i++ {
+		v1 = append(v1, 100+float64(i%10))
+		if i%10 >= 8 {
+			v2 = append(v2, 200+float64(i%10))
+		} else {
+			v2 = append(v2, 100+float64(i%10))
+		}
+	}
+	s1, s2 := NewSample(v1, &thr), NewSample(v2, &thr)
+	if cmp := (Quantile{Q: 0.9}).Compare(s1, s2); !(cmp.P < 0.05) || cmp.N1 != 50 || cmp.N2 != 50 {
+		t.Errorf("p90: want significant difference, got %v", cmp)
+	}
+	if cmp := (Quantile{Q: 0.5}).Compare(s1, s2); cmp.P < 0.05 {
+		t.Errorf("p50: want no difference, got %v", cmp)
+	}
+	s3 := NewSample([]float64{1}, &thr)
+	checkComparison(t, (Quantile{Q: 0.9}).Compare(s1, s3), Comparison{P: 1, N1: 50, N2: 1, Alpha: 0.05},
+		"need >= 2 samples to bootstrap a comparison")
+}
+
 func TestSummaryExact(t *testing.T) {
 	a := AssumeExact
 	sample := NewSample([]float64{1, 1, 1, 1}, &DefaultThresholds)
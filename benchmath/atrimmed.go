@@ -0,0 +1,161 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/aclements/go-moremath/stats"
+)
+
+// DefaultTrim is the default fraction of values trimmed or winsorized
+// from each end of a sample by Trimmed.
+const DefaultTrim = 0.2
+
+// Trimmed is an assumption that a sample is roughly symmetric, but
+// may have heavy tails. The summary statistic is a trimmed or
+// winsorized mean, which is less sensitive to extreme values than
+// the mean, but, unlike the median, still uses most of the sample.
+// Comparisons are done using Yuen's test, a generalization of
+// Welch's t-test to trimmed means.
+//
+// A trimmed mean discards a fraction of the values from each end of
+// the sample. A winsorized mean instead replaces them with the most
+// extreme values that remain, so extreme values still count, but
+// only as much as the least extreme trimmed value.
+type Trimmed struct {
+	// Fraction is the fraction of values to trim or winsorize
+	// from each end of the sample, in the range [0, 0.5). If 0,
+	// DefaultTrim is used.
+	Fraction float64
+
+	// Winsorize, if true, uses the winsorized mean as the summary
+	// statistic rather than the trimmed mean.
+	Winsorize bool
+}
+
+// AssumeTrimmed is a Trimmed assumption using the 20% trimmed mean.
+var AssumeTrimmed = Trimmed{}
+
+// AssumeWinsorized is a Trimmed assumption using the 20% winsorized
+// mean.
+var AssumeWinsorized = Trimmed{Winsorize: true}
+
+var _ Assumption = Trimmed{}
+
+func (a Trimmed) SummaryLabel() string {
+	if a.Winsorize {
+		return "winsorized mean"
+	}
+	return "trimmed mean"
+}
+
+func (a Trimmed) fraction() float64 {
+	if a.Fraction <= 0 || a.Fraction >= 0.5 {
+		return DefaultTrim
+	}
+	return a.Fraction
+}
+
+// stat computes a's summary statistic of xs. It may reorder xs.
+func (a Trimmed) stat(xs []float64) float64 {
+	sort.Float64s(xs)
+	_, _, tmean, wmean, _ := trimStats(xs, a.fraction())
+	if a.Winsorize {
+		return wmean
+	}
+	return tmean
+}
+
+// trimStats computes the trimmed statistics of sorted sample xs with
+// trim fraction gamma. g is the number of values trimmed from each
+// end, h is the number of values remaining, tmean is the trimmed
+// mean, wmean is the winsorized mean, and wvar is the winsorized
+// sample variance.
+func trimStats(xs []float64, gamma float64) (g, h int, tmean, wmean, wvar float64) {
+	n := len(xs)
+	g = int(math.Floor(gamma * float64(n)))
+	h = n - 2*g
+	tmean = stats.Mean(xs[g : n-g])
+	w := make([]float64, n)
+	for i, x := range xs {
+		switch {
+		case i < g:
+			x = xs[g]
+		case i >= n-g:
+			x = xs[n-g-1]
+		}
+		w[i] = x
+	}
+	wmean = stats.Mean(w)
+	if n > 1 {
+		wvar = stats.Variance(w)
+	}
+	return
+}
+
+func (a Trimmed) Summary(s *Sample, confidence float64) Summary {
+	gamma := a.fraction()
+	n := len(s.Values)
+	g, h, tmean, wmean, wvar := trimStats(s.Values, gamma)
+
+	center := tmean
+	// se is the standard error of the center, following Tukey
+	// and McLaughlin for the trimmed mean, and Wilcox for the
+	// winsorized mean.
+	se := math.Sqrt(wvar) / ((1 - 2*gamma) * math.Sqrt(float64(n)))
+	if a.Winsorize {
+		center = wmean
+		se = float64(n-1) * math.Sqrt(wvar) / (float64(h-1) * math.Sqrt(float64(n)))
+	}
+	summary := Summary{Center: center, Confidence: confidence, Trim: float64(g) / float64(n), Warnings: unsupported(a, s)}
+
+	var w float64
+	switch {
+	case confidence <= 0:
+		w = 0
+	case confidence >= 1 || h < 2:
+		w = math.Inf(1)
+		if h < 2 {
+			summary.Warnings = append(summary.Warnings, fmt.Errorf("need >= 2 samples after trimming for confidence interval at level %v", confidence))
+		}
+	default:
+		t := -stats.InvCDF(stats.TDist{V: float64(h - 1)})((1 - confidence) / 2)
+		w = t * se
+	}
+	summary.Lo, summary.Hi = center-w, center+w
+	return summary
+}
+
+func (a Trimmed) Compare(s1, s2 *Sample) Comparison {
+	cmp := Comparison{N1: len(s1.Values), N2: len(s2.Values), Alpha: s1.Thresholds.CompareAlpha}
+	cmp.Warnings = unsupported(a, s1, s2)
+
+	// Yuen's test.
+	gamma := a.fraction()
+	_, h1, tm1, _, wv1 := trimStats(s1.Values, gamma)
+	_, h2, tm2, _, wv2 := trimStats(s2.Values, gamma)
+	if h1 < 2 || h2 < 2 {
+		cmp.P = 1
+		cmp.Warnings = append(cmp.Warnings, fmt.Errorf("need >= 2 samples after trimming to compare"))
+		return cmp
+	}
+	d1 := float64(cmp.N1-1) * wv1 / float64(h1*(h1-1))
+	d2 := float64(cmp.N2-1) * wv2 / float64(h2*(h2-1))
+	if d1+d2 == 0 {
+		cmp.P = 1
+		cmp.Warnings = append(cmp.Warnings, stats.ErrZeroVariance)
+		return cmp
+	}
+	t := (tm2 - tm1) / math.Sqrt(d1+d2)
+	df := (d1 + d2) * (d1 + d2) / (d1*d1/float64(h1-1) + d2*d2/float64(h2-1))
+	cmp.P = 2 * (1 - stats.TDist{V: df}.CDF(math.Abs(t)))
+	cmp.Test, cmp.Statistic, cmp.DF = YuenTTest, t, df
+
+	addCompareWarnings(&cmp, s1, s2)
+	return cmp
+}
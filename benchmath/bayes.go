@@ -0,0 +1,122 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// A BayesComparison is the result of a Bayesian comparison of two
+// samples. Rather than a p-value, it gives the posterior probability
+// that one sample's center is lower than the other's and a credible
+// interval for the ratio of their centers, which are often easier to
+// interpret.
+type BayesComparison struct {
+	// ProbLower is the posterior probability that the median of
+	// the second sample's distribution is lower than the first's.
+	// The probability that it is higher is 1 - ProbLower.
+	ProbLower float64
+
+	// Ratio is the posterior median of the ratio of the median of
+	// the second sample's distribution to that of the first.
+	Ratio float64
+
+	// Lo and Hi are the bounds of the central credible interval
+	// of the ratio at level Confidence. That is, the posterior
+	// probability that the ratio is between Lo and Hi is
+	// Confidence.
+	Lo, Hi     float64
+	Confidence float64
+
+	// N1 and N2 are the sizes of the two samples.
+	N1, N2 int
+
+	// Warnings is a list of warnings about this comparison.
+	Warnings []error
+}
+
+// String summarizes the comparison, such as
+// "Pr(lower)=97% ratio=0.95 (0.93–0.98)".
+func (c BayesComparison) String() string {
+	return fmt.Sprintf("Pr(lower)=%.0f%% ratio=%.2f (%.2f–%.2f)", c.ProbLower*100, c.Ratio, c.Lo, c.Hi)
+}
+
+// CompareBayes compares the medians of s1 and s2 using the Bayesian
+// bootstrap, and returns the posterior probability that the median
+// of s2 is lower than the median of s1 and a credible interval for
+// the ratio of the medians at the given confidence level.
+//
+// The Bayesian bootstrap makes no assumptions about the distributions
+// of the samples beyond assuming that the observed values are
+// representative of the values that could be observed. Like
+// Bootstrap, it uses a fixed pseudo-random seed, so results are
+// deterministic.
+func CompareBayes(s1, s2 *Sample, confidence float64) BayesComparison {
+	cmp := BayesComparison{N1: len(s1.Values), N2: len(s2.Values), Confidence: confidence}
+	if len(s1.Values) < 2 || len(s2.Values) < 2 {
+		cmp.ProbLower = 0.5
+		cmp.Ratio, cmp.Lo, cmp.Hi = math.NaN(), 0, math.Inf(1)
+		cmp.Warnings = []error{fmt.Errorf("need >= 2 samples for Bayesian comparison")}
+		return cmp
+	}
+	if !(s1.Values[0] > 0 && s2.Values[0] > 0) {
+		cmp.ProbLower = 0.5
+		cmp.Ratio, cmp.Lo, cmp.Hi = math.NaN(), 0, math.Inf(1)
+		cmp.Warnings = []error{fmt.Errorf("Bayesian comparison requires positive values")}
+		return cmp
+	}
+
+	rng := rand.New(rand.NewSource(bootstrapSeed))
+	ratios := make([]float64, DefaultBootstrapResamples)
+	w1 := make([]float64, len(s1.Values))
+	w2 := make([]float64, len(s2.Values))
+	lower := 0.0
+	for i := range ratios {
+		m1 := weightedMedian(s1.Values, dirichlet(w1, rng))
+		m2 := weightedMedian(s2.Values, dirichlet(w2, rng))
+		switch {
+		case m2 < m1:
+			lower++
+		case m2 == m1:
+			// Split ties evenly.
+			lower += 0.5
+		}
+		ratios[i] = m2 / m1
+	}
+	cmp.ProbLower = lower / float64(len(ratios))
+	cmp.Lo, cmp.Hi = percentileCI(ratios, confidence)
+	// percentileCI sorted ratios.
+	cmp.Ratio = ratios[len(ratios)/2]
+	return cmp
+}
+
+// dirichlet fills w with a draw from the flat Dirichlet distribution,
+// which gives the weights for the Bayesian bootstrap, and returns w.
+func dirichlet(w []float64, rng *rand.Rand) []float64 {
+	total := 0.0
+	for i := range w {
+		w[i] = rng.ExpFloat64()
+		total += w[i]
+	}
+	for i := range w {
+		w[i] /= total
+	}
+	return w
+}
+
+// weightedMedian returns the weighted median of sorted values xs with
+// weights ws, which must sum to 1.
+func weightedMedian(xs, ws []float64) float64 {
+	cum := 0.0
+	for i, w := range ws {
+		cum += w
+		if cum >= 0.5 {
+			return xs[i]
+		}
+	}
+	return xs[len(xs)-1]
+}
@@ -0,0 +1,59 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestCompareBayes(t *testing.T) {
+	thr := DefaultThresholds
+	s1 := NewSample([]float64{100, 101, 102, 103, 104, 100, 101, 102, 103, 104}, &thr)
+	s2 := NewSample([]float64{90, 91, 92, 93, 94, 90, 91, 92, 93, 94}, &thr)
+
+	cmp := CompareBayes(s1, s2, 0.95)
+	if cmp.ProbLower != 1 || cmp.N1 != 10 || cmp.N2 != 10 {
+		t.Errorf("want certainly lower, got %+v", cmp)
+	}
+	if math.Abs(cmp.Ratio-92.0/102) > 0.02 || !(cmp.Lo <= cmp.Ratio && cmp.Ratio <= cmp.Hi) || cmp.Lo < 90.0/104 || cmp.Hi > 94.0/100 {
+		t.Errorf("want ratio about 0.9, got %+v", cmp)
+	}
+	// The reverse comparison.
+	if cmp := CompareBayes(s2, s1, 0.95); cmp.ProbLower != 0 || !(cmp.Ratio > 1) {
+		t.Errorf("want certainly higher, got %+v", cmp)
+	}
+	// Identical samples are a toss-up.
+	if cmp := CompareBayes(s1, s1, 0.95); math.Abs(cmp.ProbLower-0.5) > 0.05 {
+		t.Errorf("want probability about 0.5, got %+v", cmp)
+	}
+
+	small := NewSample([]float64{1}, &thr)
+	cmp = CompareBayes(s1, small, 0.95)
+	if cmp.ProbLower != 0.5 || !errorsEq(cmp.Warnings, []error{fmt.Errorf("need >= 2 samples for Bayesian comparison")}) {
+		t.Errorf("want warning for small sample, got %+v", cmp)
+	}
+}
+
+func TestBayesComparisonString(t *testing.T) {
+	c := BayesComparison{ProbLower: 0.974, Ratio: 0.951, Lo: 0.93, Hi: 0.982}
+	if got, want := c.String(), "Pr(lower)=97% ratio=0.95 (0.93–0.98)"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestWeightedMedian(t *testing.T) {
+	xs := []float64{1, 2, 3, 4}
+	check := func(ws []float64, want float64) {
+		t.Helper()
+		if got := weightedMedian(xs, ws); got != want {
+			t.Errorf("weightedMedian(%v, %v) = %v, want %v", xs, ws, got, want)
+		}
+	}
+	check([]float64{0.25, 0.25, 0.25, 0.25}, 2)
+	check([]float64{0.1, 0.1, 0.1, 0.7}, 4)
+	check([]float64{0.6, 0.2, 0.1, 0.1}, 1)
+}
@@ -0,0 +1,127 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"fmt"
+	"math"
+)
+
+// minBimodalSamples is the minimum sample size for bimodality
+// detection. With fewer samples, chance gaps between values are too
+// common to distinguish from separate modes.
+const minBimodalSamples = 10
+
+// bimodalSplit finds the split of sorted sample xs into a lower and
+// an upper cluster that maximizes the between-cluster variance, and
+// returns the index k of the first value of the upper cluster and
+// Ashman's D between the clusters,
+//
+//	D = √2 |μ1 - μ2| / √(σ1² + σ2²)
+//
+// Each cluster must have at least a quarter of the values. If xs is
+// too small to split, it returns 0, 0.
+//
+// Measurements are often quantized, such as by timer resolution, so
+// the variance of each cluster is at least the variance of the
+// rounding error at the smallest step between distinct values. This
+// keeps a sample that alternates between two adjacent steps from
+// looking like two infinitely separated modes.
+func bimodalSplit(xs []float64) (k int, d float64) {
+	n := len(xs)
+	minK := n / 4
+	if minK < 3 {
+		minK = 3
+	}
+	if n < 2*minK {
+		return 0, 0
+	}
+
+	// Use prefix sums to compute the cluster means for each split.
+	var sum float64
+	for _, x := range xs {
+		sum += x
+	}
+	mean := sum / float64(n)
+	var lo float64
+	best := -1.0
+	for i, x := range xs[:n-minK] {
+		lo += x
+		n1 := i + 1
+		if n1 < minK {
+			continue
+		}
+		m1, m2 := lo/float64(n1), (sum-lo)/float64(n-n1)
+		between := float64(n1)*(m1-mean)*(m1-mean) + float64(n-n1)*(m2-mean)*(m2-mean)
+		if between > best {
+			k, best = n1, between
+		}
+	}
+
+	step := math.Inf(1)
+	for i := 1; i < n; i++ {
+		if d := xs[i] - xs[i-1]; d > 0 && d < step {
+			step = d
+		}
+	}
+	if math.IsInf(step, 1) {
+		// All values are equal.
+		return k, 0
+	}
+	m1, v1 := meanVar(xs[:k])
+	m2, v2 := meanVar(xs[k:])
+	v1 = math.Max(v1, step*step/12)
+	v2 = math.Max(v2, step*step/12)
+	return k, math.Sqrt2 * math.Abs(m2-m1) / math.Sqrt(v1+v2)
+}
+
+// meanVar returns the mean and unbiased variance of xs.
+func meanVar(xs []float64) (mean, variance float64) {
+	for _, x := range xs {
+		mean += x
+	}
+	mean /= float64(len(xs))
+	for _, x := range xs {
+		variance += (x - mean) * (x - mean)
+	}
+	return mean, variance / float64(len(xs)-1)
+}
+
+// A BimodalWarning is the warning NewSample adds to a Sample that
+// appears to have two modes. Lower and Upper are the medians of the
+// lower and upper modes, and NLower and NUpper are the number of
+// values in each.
+type BimodalWarning struct {
+	NLower, NUpper int
+	Lower, Upper   float64
+}
+
+func (w *BimodalWarning) Error() string {
+	return w.Format(func(v float64) string { return fmt.Sprintf("%.4g", v) })
+}
+
+// Format is like Error, but formats the median of each mode using
+// format, such as to scale it for its unit.
+func (w *BimodalWarning) Format(format func(float64) string) string {
+	return fmt.Sprintf("sample may be bimodal: %d values near %s and %d near %s", w.NLower, format(w.Lower), w.NUpper, format(w.Upper))
+}
+
+// checkBimodal returns a *BimodalWarning if sorted sample xs is
+// strongly bimodal according to t, or nil otherwise.
+func checkBimodal(xs []float64, t *Thresholds) error {
+	if t.Bimodality <= 0 || len(xs) < minBimodalSamples {
+		return nil
+	}
+	k, d := bimodalSplit(xs)
+	if d < t.Bimodality {
+		return nil
+	}
+	return &BimodalWarning{
+		NLower: k,
+		NUpper: len(xs) - k,
+		Lower:  xs[k/2],
+		Upper:  xs[k+(len(xs)-k)/2],
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// bimodalThresholds enables the bimodality check, which is off in
+// DefaultThresholds.
+var bimodalThresholds = Thresholds{CompareAlpha: 0.05, Bimodality: 8}
+
+func TestBimodalSplit(t *testing.T) {
+	xs := []float64{10, 10.1, 10.2, 10.3, 10.4, 20, 20.1, 20.2, 20.3, 20.4}
+	k, d := bimodalSplit(xs)
+	if k != 5 || d < 50 {
+		t.Errorf("got k=%d D=%v, want k=5 and large D", k, d)
+	}
+
+	// Too few values to split.
+	if k, d := bimodalSplit([]float64{1, 2, 3, 4, 5}); k != 0 || d != 0 {
+		t.Errorf("small sample: got k=%d D=%v, want 0, 0", k, d)
+	}
+
+	// Alternating between two adjacent quantization steps isn't
+	// bimodal.
+	var steps []float64
+	for i := 0; i < 10; i++ {
+		steps = append(steps, 100, 101)
+	}
+	sort.Float64s(steps)
+	if _, d := bimodalSplit(steps); d >= bimodalThresholds.Bimodality {
+		t.Errorf("quantized sample: got D=%v, want < %v", d, bimodalThresholds.Bimodality)
+	}
+
+	// All values equal.
+	if _, d := bimodalSplit(make([]float64, 12)); d != 0 {
+		t.Errorf("constant sample: got D=%v, want 0", d)
+	}
+}
+
+func TestBimodalWarning(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	gen := func(n int, sep float64) []float64 {
+		xs := make([]float64, n)
+		for i := range xs {
+			xs[i] = 100 + rng.NormFloat64()
+			if i%2 == 1 {
+				xs[i] += sep
+			}
+		}
+		return xs
+	}
+
+	s := NewSample(gen(20, 20), &bimodalThresholds)
+	if len(s.Warnings) != 1 {
+		t.Errorf("bimodal: got warnings %v", s.Warnings)
+	} else if w, ok := s.Warnings[0].(*BimodalWarning); !ok || w.NLower != 10 || w.NUpper != 10 || w.Upper-w.Lower < 15 {
+		t.Errorf("bimodal: got warning %#v", s.Warnings[0])
+	}
+
+	// Unimodal samples, including skewed ones, shouldn't warn.
+	for i := 0; i < 100; i++ {
+		s = NewSample(gen(20, 0), &bimodalThresholds)
+		if len(s.Warnings) != 0 {
+			t.Fatalf("normal: got warnings %v", s.Warnings)
+		}
+		xs := make([]float64, 20)
+		for j := range xs {
+			xs[j] = rng.ExpFloat64()
+		}
+		s = NewSample(xs, &bimodalThresholds)
+		if len(s.Warnings) != 0 {
+			t.Fatalf("exponential: got warnings %v", s.Warnings)
+		}
+	}
+
+	// Bimodality is off by default, and small samples aren't checked.
+	if s := NewSample(gen(20, 20), &DefaultThresholds); len(s.Warnings) != 0 {
+		t.Errorf("disabled: got warnings %v", s.Warnings)
+	}
+	if s := NewSample(gen(8, 20), &bimodalThresholds); len(s.Warnings) != 0 {
+		t.Errorf("small: got warnings %v", s.Warnings)
+	}
+}
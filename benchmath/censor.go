@@ -0,0 +1,177 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/aclements/go-moremath/stats"
+)
+
+// NewCensoredSample is like NewSample, but some values may be
+// censored: only known to be at least as large as the measured value.
+// For example, a benchmark run that hit a timeout took at least the
+// timeout, and a measurement capped at some maximum is at least the
+// cap. censored[i] indicates that values[i] is censored.
+//
+// AssumeNothing handles censored values using ranks: a censored value
+// is known to be larger than any uncensored value below its bound,
+// but its order relative to larger values is unknown. Other
+// assumptions treat censored values as if they were measurements and
+// add a warning to their results. Analyses other than
+// Assumption.Summary and Assumption.Compare ignore censoring.
+//
+// NewCensoredSample doesn't detect outliers, since censored values
+// are often the most extreme values, and trimming them would hide
+// exactly the behavior censoring records.
+func NewCensoredSample(values []float64, censored []bool, t *Thresholds) *Sample {
+	if len(values) != len(censored) {
+		panic(fmt.Sprintf("%d values, but %d censoring flags", len(values), len(censored)))
+	}
+	some := false
+	for _, c := range censored {
+		some = some || c
+	}
+	if !some {
+		return NewSample(values, t)
+	}
+	sort.Sort(&censorSorter{values, censored})
+	return &Sample{Values: values, Censored: censored, Thresholds: t}
+}
+
+type censorSorter struct {
+	xs []float64
+	cs []bool
+}
+
+func (s *censorSorter) Len() int {
+	return len(s.xs)
+}
+
+func (s *censorSorter) Less(i, j int) bool {
+	// Order censored values after uncensored values with the same
+	// bound, since they're at least as large.
+	if s.xs[i] == s.xs[j] {
+		return !s.cs[i] && s.cs[j]
+	}
+	return s.xs[i] < s.xs[j]
+}
+
+func (s *censorSorter) Swap(i, j int) {
+	s.xs[i], s.xs[j] = s.xs[j], s.xs[i]
+	s.cs[i], s.cs[j] = s.cs[j], s.cs[i]
+}
+
+// censoredMedianCI returns the median of censored sample s and its
+// confidence interval. Each censored value is at least its bound, so
+// the order statistics of the bounds are lower bounds on the true
+// order statistics. An order statistic is known exactly if no
+// censored value sorts at or below it, since then a censored value
+// can't take its place. Otherwise, the upper bound of the interval is
+// unbounded and, if the median is censored, this returns a lower
+// bound on it.
+func censoredMedianCI(s *Sample, confidence float64) (ci stats.QuantileCIResult, median, lo, hi float64, censored bool) {
+	n := len(s.Values)
+	ci = medianCI(n, confidence)
+	median, lo, hi = ci.SampleCI(s.sample())
+	first := 0
+	for first < n && !s.Censored[first] {
+		first++
+	}
+	// The median is an order statistic, or the mean of two, the
+	// larger of which has index n/2.
+	censored = first <= n/2
+	if first < ci.HiOrder {
+		hi = math.Inf(1)
+	}
+	return
+}
+
+// censoredGreater reports whether a value x, which is censored if xc,
+// is certainly greater than a value y, which is censored if yc. A
+// censored value is greater than an uncensored value equal to its
+// bound, following Gehan.
+func censoredGreater(x float64, xc bool, y float64, yc bool) bool {
+	return !yc && (x > y || (x == y && xc))
+}
+
+// gehanTest compares s1 and s2, which may contain censored values,
+// using Gehan's generalization of the Wilcoxon rank-sum test, with
+// Mantel's permutation variance and the normal approximation.
+//
+// Each pair of a value from s1 and a value from s2 scores +1 if the
+// value from s2 is certainly larger, -1 if it's certainly smaller, and
+// 0 if their order is unknown. The statistic is the sum of these
+// scores, and Effect is their mean, which generalizes Cliff's delta.
+func gehanTest(s1, s2 *Sample) Comparison {
+	cmp := Comparison{P: 1, N1: len(s1.Values), N2: len(s2.Values), Alpha: s1.Thresholds.CompareAlpha}
+	if cmp.N1 == 0 || cmp.N2 == 0 {
+		cmp.Warnings = append(cmp.Warnings, stats.ErrSampleSize)
+		return cmp
+	}
+	type obs struct {
+		x float64
+		c bool
+	}
+	var all []obs
+	for _, s := range []*Sample{s1, s2} {
+		for i, x := range s.Values {
+			all = append(all, obs{x, s.Censored != nil && s.Censored[i]})
+		}
+	}
+
+	// Score each observation by the number of observations it's
+	// certainly greater than, less the number it's certainly less
+	// than. The Gehan statistic is the sum of the scores of s2.
+	scores := make([]float64, len(all))
+	for i, a := range all {
+		for _, b := range all {
+			if censoredGreater(a.x, a.c, b.x, b.c) {
+				scores[i]++
+			} else if censoredGreater(b.x, b.c, a.x, a.c) {
+				scores[i]--
+			}
+		}
+	}
+	var w, ss float64
+	for i, u := range scores {
+		if i >= cmp.N1 {
+			w += u
+		}
+		ss += u * u
+	}
+	// Pairs within s2 cancel out of w, so w is the sum of the
+	// pairwise scores between s1 and s2.
+	n1, n2, n := float64(cmp.N1), float64(cmp.N2), float64(len(all))
+	cmp.Test, cmp.Statistic = GehanTest, w
+	cmp.Effect, cmp.EffectMeasure = w/(n1*n2), EffectCliffsDelta
+	if ss == 0 {
+		// Every pair's order is unknown.
+		return cmp
+	}
+	v := n1 * n2 * ss / (n * (n - 1))
+	z := math.Abs(w) / math.Sqrt(v)
+	cmp.P = 2 * (1 - stats.NormalDist{Mu: 0, Sigma: 1}.CDF(z))
+	return cmp
+}
+
+// censoringIgnored returns a warning if any of samples is censored,
+// for use by assumptions that don't support censoring.
+func censoringIgnored(a Assumption, samples ...*Sample) []error {
+	for _, s := range samples {
+		if s.Censored != nil {
+			return []error{fmt.Errorf("%s treats censored values as measurements", a.SummaryLabel())}
+		}
+	}
+	return nil
+}
+
+// unsupported returns warnings if any of samples is weighted or
+// censored, for use by assumptions that support neither.
+func unsupported(a Assumption, samples ...*Sample) []error {
+	return append(weightsIgnored(a, samples...), censoringIgnored(a, samples...)...)
+}
@@ -0,0 +1,100 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/aclements/go-moremath/stats"
+)
+
+func TestNewCensoredSample(t *testing.T) {
+	s := NewCensoredSample([]float64{5, 3, 10, 10}, []bool{false, false, true, false}, &DefaultThresholds)
+	if want := []float64{3, 5, 10, 10}; !reflect.DeepEqual(s.Values, want) {
+		t.Errorf("got values %v, want %v", s.Values, want)
+	}
+	// Censored values sort after uncensored values with the same
+	// bound.
+	if want := []bool{false, false, false, true}; !reflect.DeepEqual(s.Censored, want) {
+		t.Errorf("got censored %v, want %v", s.Censored, want)
+	}
+
+	s = NewCensoredSample([]float64{2, 1}, []bool{false, false}, &DefaultThresholds)
+	if s.Censored != nil || !reflect.DeepEqual(s.Values, []float64{1, 2}) {
+		t.Errorf("uncensored: got %+v, want plain sample", s)
+	}
+}
+
+func TestCensoredSummary(t *testing.T) {
+	xs := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9}
+	censorFrom := func(k float64) *Sample {
+		cs := make([]bool, len(xs))
+		for i, x := range xs {
+			cs[i] = x >= k
+		}
+		return NewCensoredSample(append([]float64(nil), xs...), cs, &DefaultThresholds)
+	}
+	plain := AssumeNothing.Summary(NewSample(append([]float64(nil), xs...), &DefaultThresholds), 0.95)
+
+	// Censoring only the largest value doesn't affect the median
+	// or its interval.
+	checkSummary(t, AssumeNothing.Summary(censorFrom(9), 0.95), plain)
+
+	// Censoring more makes the upper bound unknown.
+	want := plain
+	want.Hi = math.Inf(1)
+	checkSummary(t, AssumeNothing.Summary(censorFrom(6), 0.95), want, "confidence interval is unbounded because too many values are censored")
+
+	// And censoring the median makes it a lower bound.
+	checkSummary(t, AssumeNothing.Summary(censorFrom(5), 0.95), want, "median is censored; it is at least 5", "confidence interval is unbounded because too many values are censored")
+}
+
+func TestGehanTest(t *testing.T) {
+	s1 := NewSample([]float64{1, 2, 3, 4, 5, 6}, &DefaultThresholds)
+	s2 := NewCensoredSample([]float64{7, 8, 9, 10, 11, 12}, []bool{false, false, false, false, true, true}, &DefaultThresholds)
+
+	// Every value of s2 is certainly larger, so W = 36. The scores
+	// of the pooled values are -11, -9, ..., 7, and 10 and 10 for
+	// the censored values, which can't be ordered with each other.
+	// Their squares sum to 570, so the variance of W is
+	// 36·570/(12·11).
+	cmp := AssumeNothing.Compare(s1, s2)
+	wantP := 2 * (1 - stats.NormalDist{Mu: 0, Sigma: 1}.CDF(36/math.Sqrt(36*570.0/132)))
+	if cmp.Test != GehanTest || cmp.Statistic != 36 || cmp.Effect != 1 || !aeq(cmp.P, wantP) {
+		t.Errorf("got %s W=%v effect=%v P=%v, want Gehan W=36 effect=1 P=%v", cmp.Test, cmp.Statistic, cmp.Effect, cmp.P, wantP)
+	}
+
+	// A value censored below the other sample's values can't be
+	// ordered with them.
+	s3 := NewCensoredSample([]float64{0, 7, 8}, []bool{true, false, false}, &DefaultThresholds)
+	cmp = AssumeNothing.Compare(s1, s3)
+	if cmp.Statistic != 12 {
+		t.Errorf("low censored value: got W=%v, want 12", cmp.Statistic)
+	}
+
+	// If no pair can be ordered, there's no evidence of a
+	// difference.
+	c := NewCensoredSample([]float64{5, 5}, []bool{true, true}, &DefaultThresholds)
+	if cmp := AssumeNothing.Compare(c, c); cmp.P != 1 || cmp.Statistic != 0 {
+		t.Errorf("all censored: got %+v, want P=1", cmp)
+	}
+}
+
+func TestCensoringIgnored(t *testing.T) {
+	c := NewCensoredSample([]float64{1, 2, 3, 4}, []bool{false, false, false, true}, &DefaultThresholds)
+	for _, a := range []Assumption{AssumeNormal, AssumeLogNormal, AssumeBootstrap, AssumeTrimmed, Quantile{0.9}} {
+		want := a.SummaryLabel() + " treats censored values as measurements"
+		summary := a.Summary(c, 0.95)
+		if len(summary.Warnings) == 0 || summary.Warnings[0].Error() != want {
+			t.Errorf("%s: got summary warnings %v", a.SummaryLabel(), summary.Warnings)
+		}
+		cmp := a.Compare(c, c)
+		if len(cmp.Warnings) == 0 || cmp.Warnings[0].Error() != want {
+			t.Errorf("%s: got comparison warnings %v", a.SummaryLabel(), cmp.Warnings)
+		}
+	}
+}
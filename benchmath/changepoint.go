@@ -0,0 +1,196 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/aclements/go-moremath/stats"
+)
+
+// changePointPermutations is the number of permutations used to
+// compute the significance of each change point.
+const changePointPermutations = 999
+
+// A ChangePoint is a step change in an ordered sequence of Samples.
+type ChangePoint struct {
+	// Index is the index in the sequence of the first Sample after
+	// the change.
+	Index int
+
+	// Before and After are the medians of the values in the
+	// segments of the sequence immediately before and after the
+	// change.
+	Before, After float64
+
+	// P is the p-value of the change: the probability of seeing a
+	// step at least this large somewhere in its segment if the
+	// segment had no change. The confidence in the change is 1-P.
+	P float64
+}
+
+// String returns a summary of the change point, such as
+// "@12 +5.20% (p=0.001)".
+func (c ChangePoint) String() string {
+	return fmt.Sprintf("@%d %+.2f%% (p=%0.3f)", c.Index, (c.After/c.Before-1)*100, c.P)
+}
+
+// ChangePoints is the result of DetectChangePoints.
+type ChangePoints struct {
+	// Points are the detected change points, in sequence order.
+	Points []ChangePoint
+
+	// Warnings is a list of warnings about this detection that
+	// should be reported to the user.
+	Warnings []error
+}
+
+// DetectChangePoints finds step changes in samples, which must be in
+// the order they were measured, such as by time or by commit.
+//
+// It uses binary segmentation: it finds the split of the sequence
+// that maximizes the Mann-Whitney U statistic between the values
+// before and after the split, tests its significance with a
+// permutation test, and, if it's significant at
+// Thresholds.CompareAlpha, recursively searches each side of the
+// split. This makes no distributional assumptions, but assumes the
+// values within each segment are independent.
+//
+// Changes are only detected between Samples, never within one.
+func DetectChangePoints(samples []*Sample) ChangePoints {
+	var res ChangePoints
+	if len(samples) < 2 {
+		res.Warnings = append(res.Warnings, fmt.Errorf("need >= 2 samples to detect change points"))
+		return res
+	}
+	alpha := samples[0].Thresholds.CompareAlpha
+	if minP := 1 / float64(changePointPermutations+1); alpha < minP {
+		res.Warnings = append(res.Warnings, fmt.Errorf("cannot detect change points at alpha level %v; minimum is %v", alpha, minP))
+		return res
+	}
+
+	rng := rand.New(rand.NewSource(bootstrapSeed))
+	var segment func(lo, hi int)
+	segment = func(lo, hi int) {
+		if hi-lo < 2 {
+			return
+		}
+		k, p := splitSegment(samples[lo:hi], rng)
+		if p > alpha {
+			return
+		}
+		res.Points = append(res.Points, ChangePoint{Index: lo + k, P: p})
+		segment(lo, lo+k)
+		segment(lo+k, hi)
+	}
+	segment(0, len(samples))
+
+	sort.Slice(res.Points, func(i, j int) bool {
+		return res.Points[i].Index < res.Points[j].Index
+	})
+	median := func(lo, hi int) float64 {
+		var xs []float64
+		for _, s := range samples[lo:hi] {
+			xs = append(xs, s.Values...)
+		}
+		return stats.Sample{Xs: xs}.Quantile(0.5)
+	}
+	for i := range res.Points {
+		lo, hi := 0, len(samples)
+		if i > 0 {
+			lo = res.Points[i-1].Index
+		}
+		if i+1 < len(res.Points) {
+			hi = res.Points[i+1].Index
+		}
+		cp := &res.Points[i]
+		cp.Before, cp.After = median(lo, cp.Index), median(cp.Index, hi)
+	}
+	return res
+}
+
+// splitSegment returns the index k in samples of the split that
+// maximizes the standardized U statistic between samples[:k] and
+// samples[k:], and the permutation p-value of that maximum.
+func splitSegment(samples []*Sample, rng *rand.Rand) (k int, p float64) {
+	// Rank all of the values in the segment, using mid-ranks for
+	// ties.
+	type val struct {
+		x   float64
+		pos int
+	}
+	var vals []val
+	ends := make([]int, len(samples))
+	for i, s := range samples {
+		for _, x := range s.Values {
+			vals = append(vals, val{x, len(vals)})
+		}
+		ends[i] = len(vals)
+	}
+	n := len(vals)
+	if n < 2 {
+		return 0, 1
+	}
+	sort.Slice(vals, func(i, j int) bool { return vals[i].x < vals[j].x })
+	ranks := make([]float64, n)
+	var ties float64
+	for i := 0; i < n; {
+		j := i + 1
+		for j < n && vals[j].x == vals[i].x {
+			j++
+		}
+		t := float64(j - i)
+		ties += t*t*t - t
+		for _, v := range vals[i:j] {
+			ranks[v.pos] = float64(i+j+1) / 2
+		}
+		i = j
+	}
+	if ties == float64(n)*float64(n)*float64(n)-float64(n) {
+		// All values are equal.
+		return 0, 1
+	}
+
+	fn := float64(n)
+	// maxZ returns the split of ranks with the largest |z|.
+	maxZ := func(ranks []float64) (int, float64) {
+		bestK, best := 0, -1.0
+		var r1 float64
+		start := 0
+		for i, end := range ends[:len(ends)-1] {
+			for _, r := range ranks[start:end] {
+				r1 += r
+			}
+			start = end
+			n1, n2 := float64(end), fn-float64(end)
+			if n1 == 0 || n2 == 0 {
+				continue
+			}
+			sigma := math.Sqrt(n1 * n2 / 12 * ((fn + 1) - ties/(fn*(fn-1))))
+			z := math.Abs(r1-n1*(fn+1)/2) / sigma
+			if z > best {
+				bestK, best = i+1, z
+			}
+		}
+		return bestK, best
+	}
+
+	k, obs := maxZ(ranks)
+	if k == 0 {
+		return 0, 1
+	}
+	perm := append([]float64(nil), ranks...)
+	count := 0
+	for i := 0; i < changePointPermutations; i++ {
+		rng.Shuffle(len(perm), func(i, j int) { perm[i], perm[j] = perm[j], perm[i] })
+		if _, z := maxZ(perm); z >= obs {
+			count++
+		}
+	}
+	return k, float64(count+1) / float64(changePointPermutations+1)
+}
@@ -0,0 +1,62 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestDetectChangePoints(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+	seq := func(means ...float64) []*Sample {
+		var samples []*Sample
+		for _, mean := range means {
+			samples = append(samples, NewSample(randValues(rng, 5, mean, 1), &DefaultThresholds))
+		}
+		return samples
+	}
+	check := func(samples []*Sample, want ...int) ChangePoints {
+		t.Helper()
+		res := DetectChangePoints(samples)
+		var got []int
+		for _, cp := range res.Points {
+			got = append(got, cp.Index)
+			if cp.P > DefaultThresholds.CompareAlpha {
+				t.Errorf("change point %v not significant", cp)
+			}
+		}
+		if len(got) != len(want) {
+			t.Errorf("got change points %v, want %v", got, want)
+			return res
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Errorf("got change points %v, want %v", got, want)
+				break
+			}
+		}
+		return res
+	}
+
+	check(seq(100, 100, 100, 100, 100, 100, 100, 100))
+	res := check(seq(100, 100, 100, 100, 110, 110, 110, 110), 4)
+	if cp := res.Points[0]; cp.Before < 99 || cp.Before > 101 || cp.After < 109 || cp.After > 111 {
+		t.Errorf("got medians %v and %v, want about 100 and 110", cp.Before, cp.After)
+	}
+	check(seq(100, 100, 100, 120, 120, 120, 90, 90, 90), 3, 6)
+
+	res = DetectChangePoints(seq(100))
+	if len(res.Points) != 0 || len(res.Warnings) != 1 {
+		t.Errorf("single sample: got %+v, want one warning", res)
+	}
+}
+
+func TestChangePointString(t *testing.T) {
+	cp := ChangePoint{Index: 12, Before: 100, After: 105.2, P: 0.001}
+	if got, want := cp.String(), "@12 +5.20% (p=0.001)"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
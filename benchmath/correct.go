@@ -0,0 +1,81 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"math"
+	"sort"
+)
+
+// HolmBonferroni adjusts the p-values of a family of comparisons
+// using the Holm–Bonferroni method, which controls the family-wise
+// error rate: the probability of rejecting any true null hypothesis
+// in the family is at most each comparison's Alpha.
+//
+// When many comparisons are made, some are expected to have P < Alpha
+// by chance alone. HolmBonferroni replaces each P with its adjusted
+// p-value, so the usual test P < Alpha accounts for the number of
+// comparisons. It records the original p-value in RawP.
+//
+// Comparisons with P == 0 are exact and are left unchanged and not
+// counted as part of the family.
+func HolmBonferroni(cmps []*Comparison) {
+	family := correctionFamily(cmps)
+	m := len(family)
+	// With p-values in ascending order p_1 ... p_m, the adjusted
+	// p-value of p_i is max_{j<=i} min(1, (m-j+1) p_j).
+	adj := 0.0
+	for i, c := range family {
+		adj = math.Max(adj, math.Min(1, float64(m-i)*c.RawP))
+		c.P = adj
+		c.Correction = "holm"
+	}
+}
+
+// BenjaminiHochberg adjusts the p-values of a family of comparisons
+// using the Benjamini–Hochberg method, which controls the false
+// discovery rate: among the comparisons with P < Alpha, the expected
+// fraction of true null hypotheses is at most Alpha. This is less
+// conservative than HolmBonferroni, which makes it better suited to
+// large families of comparisons, where a small number of false
+// discoveries is acceptable.
+//
+// BenjaminiHochberg replaces each P with its q-value, the smallest
+// false discovery rate at which the comparison would be considered
+// significant, and records the original p-value in RawP. Like
+// HolmBonferroni, it ignores exact comparisons with P == 0.
+func BenjaminiHochberg(cmps []*Comparison) {
+	family := correctionFamily(cmps)
+	m := len(family)
+	// With p-values in ascending order p_1 ... p_m, the q-value
+	// of p_i is min_{j>=i} min(1, m/j p_j).
+	q := 1.0
+	for i := m - 1; i >= 0; i-- {
+		c := family[i]
+		q = math.Min(q, float64(m)/float64(i+1)*c.RawP)
+		c.P = q
+		c.Correction = "fdr"
+	}
+}
+
+// correctionFamily returns the comparisons in cmps with P != 0,
+// sorted by ascending P, and sets RawP of each to its unadjusted
+// p-value.
+func correctionFamily(cmps []*Comparison) []*Comparison {
+	var family []*Comparison
+	for _, c := range cmps {
+		if c.P == 0 {
+			continue
+		}
+		if c.Correction == "" {
+			c.RawP = c.P
+		}
+		family = append(family, c)
+	}
+	sort.SliceStable(family, func(i, j int) bool {
+		return family[i].RawP < family[j].RawP
+	})
+	return family
+}
@@ -0,0 +1,68 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"math"
+	"testing"
+)
+
+func checkCorrection(t *testing.T, correct func([]*Comparison), name string, ps []float64, want []float64) {
+	t.Helper()
+	cmps := make([]*Comparison, len(ps))
+	for i, p := range ps {
+		cmps[i] = &Comparison{P: p, Alpha: 0.05}
+	}
+	correct(cmps)
+	for i, c := range cmps {
+		if math.Abs(c.P-want[i]) > 1e-12 {
+			t.Errorf("p=%v: got adjusted %v, want %v", ps[i], c.P, want[i])
+		}
+		wantCorrection := name
+		if ps[i] == 0 {
+			wantCorrection = ""
+		}
+		if c.Correction != wantCorrection {
+			t.Errorf("p=%v: got correction %q, want %q", ps[i], c.Correction, wantCorrection)
+		}
+		if wantCorrection != "" && c.RawP != ps[i] {
+			t.Errorf("p=%v: got RawP %v", ps[i], c.RawP)
+		}
+	}
+
+	// Correcting again should give the same result.
+	correct(cmps)
+	for i, c := range cmps {
+		if math.Abs(c.P-want[i]) > 1e-12 {
+			t.Errorf("p=%v: got re-adjusted %v, want %v", ps[i], c.P, want[i])
+		}
+	}
+}
+
+func TestHolmBonferroni(t *testing.T) {
+	check := func(ps []float64, want ...float64) {
+		t.Helper()
+		checkCorrection(t, HolmBonferroni, "holm", ps, want)
+	}
+	check(nil)
+	check([]float64{0.01}, 0.01)
+	check([]float64{0.01, 0.04, 0.03, 0.005}, 0.03, 0.06, 0.06, 0.02)
+	// Adjusted p-values are capped at 1.
+	check([]float64{0.5, 0.6}, 1, 1)
+	// Exact comparisons are not part of the family.
+	check([]float64{0, 0.01, 0.02}, 0, 0.02, 0.02)
+}
+
+func TestBenjaminiHochberg(t *testing.T) {
+	check := func(ps []float64, want ...float64) {
+		t.Helper()
+		checkCorrection(t, BenjaminiHochberg, "fdr", ps, want)
+	}
+	check(nil)
+	check([]float64{0.01}, 0.01)
+	check([]float64{0.01, 0.04, 0.03, 0.005}, 0.02, 0.04, 0.04, 0.02)
+	check([]float64{0.5, 0.6}, 0.6, 0.6)
+	check([]float64{0, 0.01, 0.02}, 0, 0.02, 0.02)
+}
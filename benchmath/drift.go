@@ -0,0 +1,226 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/aclements/go-moremath/stats"
+)
+
+// minDriftSamples is the minimum sample size for drift detection.
+// With fewer values, the normal approximations used by the tests
+// aren't reliable.
+const minDriftSamples = 8
+
+// A Drift is the result of CheckDrift.
+type Drift struct {
+	// Tau is Kendall's tau between the run order and the values,
+	// in the range [-1, 1]. It is positive if the values tend to
+	// increase over the runs.
+	Tau float64
+
+	// TrendP is the p-value of the Mann-Kendall test for a
+	// monotonic trend in the values.
+	TrendP float64
+
+	// Runs is the number of runs of consecutive values on the same
+	// side of the median. Too few runs indicates that neighboring
+	// values are positively correlated; too many indicates that
+	// they alternate.
+	Runs int
+
+	// RunsP is the p-value of the Wald-Wolfowitz runs test for
+	// independence of consecutive values.
+	RunsP float64
+
+	// Stable is the smallest index i such that values[i:] shows
+	// neither a trend nor dependence, or -1 if no suffix of at
+	// least half of the values is stable. It is 0 if all of the
+	// values are stable.
+	Stable int
+
+	// Warnings is a list of warnings about the values that should
+	// be reported to the user.
+	Warnings []error
+}
+
+// CheckDrift tests whether values, which must be in the order they
+// were measured, are independent of their order. Most of this
+// package's analyses assume the values of a Sample are independent,
+// but a warming file cache or thermal ramp makes later runs
+// systematically faster or slower than earlier runs, and slow changes
+// in machine conditions make neighboring runs more alike than distant
+// ones.
+//
+// CheckDrift uses the Mann-Kendall test to detect a monotonic trend
+// and the Wald-Wolfowitz runs test to detect dependence between
+// consecutive values. If either test rejects independence at the
+// given alpha level, CheckDrift adds a warning, which suggests a
+// stable suffix of the runs to use if there is one.
+func CheckDrift(values []float64, alpha float64) Drift {
+	d := Drift{TrendP: 1, RunsP: 1}
+	if len(values) < minDriftSamples {
+		d.Warnings = append(d.Warnings, fmt.Errorf("need >= %d values to detect drift", minDriftSamples))
+		return d
+	}
+	d.Tau, d.TrendP = mannKendall(values)
+	d.Runs, d.RunsP = runsTest(values)
+	if d.TrendP > alpha && d.RunsP > alpha {
+		return d
+	}
+
+	var tests []string
+	if d.TrendP <= alpha {
+		dir := "upward"
+		if d.Tau < 0 {
+			dir = "downward"
+		}
+		tests = append(tests, fmt.Sprintf("%s trend p=%0.3f", dir, d.TrendP))
+	}
+	if d.RunsP <= alpha {
+		tests = append(tests, fmt.Sprintf("runs test p=%0.3f", d.RunsP))
+	}
+	msg := fmt.Sprintf("values are not independent across runs (%s)", strings.Join(tests, ", "))
+
+	d.Stable = -1
+	for i := 1; len(values)-i >= minDriftSamples && 2*(len(values)-i) >= len(values); i++ {
+		_, trendP := mannKendall(values[i:])
+		_, runsP := runsTest(values[i:])
+		if trendP > alpha && runsP > alpha {
+			d.Stable = i
+			msg += fmt.Sprintf("; last %d of %d runs appear stable", len(values)-i, len(values))
+			break
+		}
+	}
+	d.Warnings = append(d.Warnings, fmt.Errorf("%s", msg))
+	return d
+}
+
+// mannKendall returns Kendall's tau between the indexes and values of
+// xs, and the two-sided p-value of the Mann-Kendall trend test, using
+// the normal approximation with a tie correction.
+func mannKendall(xs []float64) (tau, p float64) {
+	n := len(xs)
+	var s float64
+	for i := range xs {
+		for _, y := range xs[i+1:] {
+			if y > xs[i] {
+				s++
+			} else if y < xs[i] {
+				s--
+			}
+		}
+	}
+
+	fn := float64(n)
+	v := fn * (fn - 1) * (2*fn + 5)
+	sorted := append([]float64(nil), xs...)
+	sort.Float64s(sorted)
+	for i := 0; i < n; {
+		j := i + 1
+		for j < n && sorted[j] == sorted[i] {
+			j++
+		}
+		t := float64(j - i)
+		v -= t * (t - 1) * (2*t + 5)
+		i = j
+	}
+	v /= 18
+
+	tau = s / (fn * (fn - 1) / 2)
+	if v == 0 {
+		return tau, 1
+	}
+	// Apply a continuity correction toward 0.
+	z := math.Max(math.Abs(s)-1, 0) / math.Sqrt(v)
+	return tau, 2 * (1 - stats.NormalDist{Mu: 0, Sigma: 1}.CDF(z))
+}
+
+// runsTest returns the number of runs of values in xs above and below
+// the median, ignoring values equal to the median, and the two-sided
+// p-value of the Wald-Wolfowitz runs test, using the normal
+// approximation.
+func runsTest(xs []float64) (runs int, p float64) {
+	sorted := append([]float64(nil), xs...)
+	sort.Float64s(sorted)
+	med := stats.Sample{Xs: sorted, Sorted: true}.Quantile(0.5)
+
+	var n1, n2 float64
+	last := 0
+	for _, x := range xs {
+		side := 0
+		if x > med {
+			side, n1 = 1, n1+1
+		} else if x < med {
+			side, n2 = -1, n2+1
+		} else {
+			continue
+		}
+		if side != last {
+			runs++
+			last = side
+		}
+	}
+	if n1 == 0 || n2 == 0 {
+		return runs, 1
+	}
+
+	n := n1 + n2
+	mean := 2*n1*n2/n + 1
+	v := 2 * n1 * n2 * (2*n1*n2 - n) / (n * n * (n - 1))
+	if v == 0 {
+		return runs, 1
+	}
+	// Apply a continuity correction toward the mean.
+	z := math.Max(math.Abs(float64(runs)-mean)-0.5, 0) / math.Sqrt(v)
+	return runs, 2 * (1 - stats.NormalDist{Mu: 0, Sigma: 1}.CDF(z))
+}
+
+// driftWarnings returns the warnings from CheckDrift for values in run
+// order if t enables drift detection.
+func driftWarnings(values []float64, t *Thresholds) []error {
+	if t == nil || t.DriftAlpha <= 0 || len(values) < minDriftSamples {
+		return nil
+	}
+	return CheckDrift(values, t.DriftAlpha).Warnings
+}
+
+// interleaved returns an error if s1 and s2 both have run orders and
+// all runs of one sample preceded all runs of the other. In that
+// case, any slow change in machine conditions between the two blocks
+// of runs is indistinguishable from a difference between the samples.
+func interleaved(s1, s2 *Sample) error {
+	if len(s1.Runs) < 2 || len(s2.Runs) < 2 {
+		return nil
+	}
+	lo1, hi1 := runRange(s1.Runs)
+	lo2, hi2 := runRange(s2.Runs)
+	var order string
+	if hi1 < lo2 {
+		order = "before"
+	} else if hi2 < lo1 {
+		order = "after"
+	} else {
+		return nil
+	}
+	return fmt.Errorf("runs were not interleaved: all %d runs of the first sample came %s all %d runs of the second, so changes in machine conditions may look like a difference", len(s1.Runs), order, len(s2.Runs))
+}
+
+func runRange(runs []int) (lo, hi int) {
+	lo, hi = runs[0], runs[0]
+	for _, r := range runs[1:] {
+		if r < lo {
+			lo = r
+		}
+		if r > hi {
+			hi = r
+		}
+	}
+	return
+}
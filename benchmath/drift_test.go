@@ -0,0 +1,119 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestCheckDrift(t *testing.T) {
+	check := func(name string, xs []float64, wantWarning string, wantStable int) {
+		t.Helper()
+		d := CheckDrift(xs, 0.05)
+		if d.Stable != wantStable {
+			t.Errorf("%s: got Stable %d, want %d", name, d.Stable, wantStable)
+		}
+		var got string
+		if len(d.Warnings) > 0 {
+			got = d.Warnings[0].Error()
+		}
+		if got != wantWarning {
+			t.Errorf("%s: got warning %q, want %q", name, got, wantWarning)
+		}
+	}
+
+	// A warming cache that settles after a few runs.
+	check("warmup", []float64{130, 120, 112, 106, 101, 100, 99, 101, 100, 99, 101, 100, 99, 101, 100, 99},
+		"values are not independent across runs (downward trend p=0.003); last 13 of 16 runs appear stable", 3)
+
+	// A steady thermal ramp never settles.
+	check("ramp", []float64{100, 101, 102, 103, 104, 105, 106, 107, 108, 109},
+		"values are not independent across runs (upward trend p=0.000, runs test p=0.019)", -1)
+
+	// Alternating values have too many runs, but no trend.
+	check("alternating", []float64{1, 9, 2, 8, 1, 9, 2, 8, 1, 9, 2, 8},
+		"values are not independent across runs (runs test p=0.006); last 11 of 12 runs appear stable", 1)
+
+	check("small", []float64{1, 2, 3}, "need >= 8 values to detect drift", 0)
+
+	// Independent values usually don't warn.
+	rng := rand.New(rand.NewSource(1))
+	warnings := 0
+	for i := 0; i < 200; i++ {
+		xs := make([]float64, 20)
+		for j := range xs {
+			xs[j] = rng.NormFloat64()
+		}
+		if d := CheckDrift(xs, 0.05); len(d.Warnings) > 0 {
+			warnings++
+		}
+	}
+	// Two tests at alpha 0.05 each.
+	if warnings > 30 {
+		t.Errorf("independent values: got %d warnings in 200 samples, want <= 30", warnings)
+	}
+}
+
+func TestMannKendall(t *testing.T) {
+	tau, p := mannKendall([]float64{1, 2, 3, 4, 5, 6, 7, 8})
+	if tau != 1 || p > 0.001 {
+		t.Errorf("increasing: got tau=%v p=%v, want tau=1 and small p", tau, p)
+	}
+	tau, p = mannKendall([]float64{5, 5, 5, 5, 5, 5, 5, 5})
+	if tau != 0 || p != 1 {
+		t.Errorf("constant: got tau=%v p=%v, want 0, 1", tau, p)
+	}
+}
+
+func TestNewSampleDrift(t *testing.T) {
+	thr := DefaultThresholds
+	thr.DriftAlpha = 0.05
+	xs := []float64{100, 101, 102, 103, 104, 105, 106, 107, 108, 109}
+	s := NewSample(append([]float64(nil), xs...), &thr)
+	if len(s.Warnings) != 1 {
+		t.Errorf("got warnings %v, want drift warning", s.Warnings)
+	}
+	s = NewWeightedSample(append([]float64(nil), xs...), make([]float64, len(xs)), &thr)
+	if len(s.Warnings) != 1 {
+		t.Errorf("weighted: got warnings %v, want drift warning", s.Warnings)
+	}
+	// Drift detection is off by default.
+	if s := NewSample(xs, &DefaultThresholds); len(s.Warnings) != 0 {
+		t.Errorf("default: got warnings %v", s.Warnings)
+	}
+}
+
+func TestInterleaved(t *testing.T) {
+	const want = "runs were not interleaved: all 3 runs of the first sample came before all 3 runs of the second, so changes in machine conditions may look like a difference"
+	sample := func(runs ...int) *Sample {
+		s := NewSample([]float64{1, 2, 3}, &DefaultThresholds)
+		s.Runs = runs
+		return s
+	}
+	hasWarning := func(cmp Comparison) bool {
+		for _, w := range cmp.Warnings {
+			if w.Error() == want {
+				return true
+			}
+		}
+		return false
+	}
+	for _, a := range []Assumption{AssumeNothing, AssumeNormal, AssumeLogNormal} {
+		if cmp := a.Compare(sample(0, 1, 2), sample(3, 4, 5)); !hasWarning(cmp) {
+			t.Errorf("%s: blocked runs: got warnings %v, want %q", a.SummaryLabel(), cmp.Warnings, want)
+		}
+		if cmp := a.Compare(sample(0, 2, 4), sample(1, 3, 5)); hasWarning(cmp) {
+			t.Errorf("%s: interleaved runs: got warnings %v", a.SummaryLabel(), cmp.Warnings)
+		}
+		if cmp := a.Compare(sample(), sample(3, 4, 5)); hasWarning(cmp) {
+			t.Errorf("%s: unknown runs: got warnings %v", a.SummaryLabel(), cmp.Warnings)
+		}
+	}
+	if err := interleaved(sample(5, 3, 4), sample(2, 0, 1)); err == nil || !strings.Contains(err.Error(), "came after") {
+		t.Errorf("reversed blocks: got %v", err)
+	}
+}
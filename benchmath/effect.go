@@ -0,0 +1,124 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/aclements/go-moremath/stats"
+)
+
+// Effect size measures reported in Comparison.EffectMeasure.
+const (
+	// EffectCliffsDelta is Cliff's delta, a non-parametric effect
+	// size. It is the probability that a value from the second
+	// sample is greater than a value from the first, minus the
+	// probability that it is less. It ranges from -1 to 1.
+	EffectCliffsDelta = "δ"
+
+	// EffectCohensD is Cohen's d, the difference in sample means
+	// divided by the pooled standard deviation.
+	EffectCohensD = "d"
+
+	// EffectRankBiserial is the matched-pairs rank-biserial
+	// correlation, a non-parametric effect size for paired
+	// samples. It ranges from -1 to 1.
+	EffectRankBiserial = "r"
+)
+
+// effectThresholds gives the conventional upper bounds of
+// "negligible", "small", and "medium" effects for each measure. Cliff's
+// delta thresholds are from Romano et al. (2006); Cohen's d and
+// correlation thresholds are Cohen's (1988).
+var effectThresholds = map[string][3]float64{
+	EffectCliffsDelta:  {0.147, 0.33, 0.474},
+	EffectCohensD:      {0.2, 0.5, 0.8},
+	EffectRankBiserial: {0.1, 0.3, 0.5},
+}
+
+// cliffsDelta returns Cliff's delta of ys relative to xs. Both must be
+// sorted and non-empty.
+func cliffsDelta(xs, ys []float64) float64 {
+	// For each y, count the xs less than and greater than y.
+	// Since ys is sorted, these counts only move forward.
+	var lt, le int
+	var sum int
+	for _, y := range ys {
+		for lt < len(xs) && xs[lt] < y {
+			lt++
+		}
+		if le < lt {
+			le = lt
+		}
+		for le < len(xs) && xs[le] <= y {
+			le++
+		}
+		sum += lt - (len(xs) - le)
+	}
+	return float64(sum) / float64(len(xs)*len(ys))
+}
+
+// cohensD returns Cohen's d of ys relative to xs, or NaN if it's
+// undefined.
+func cohensD(xs, ys []float64) float64 {
+	return momentsCohensD(moments(stats.Sample{Xs: xs}), moments(stats.Sample{Xs: ys}))
+}
+
+// momentsCohensD returns Cohen's d of the sample with moments m2
+// relative to the sample with moments m1, or NaN if it's undefined.
+func momentsCohensD(m1, m2 tMoments) float64 {
+	n1, n2 := m1.n, m2.n
+	if n1+n2 <= 2 {
+		return math.NaN()
+	}
+	sd := math.Sqrt(((n1-1)*m1.variance + (n2-1)*m2.variance) / (n1 + n2 - 2))
+	if sd == 0 {
+		if m1.mean == m2.mean {
+			return 0
+		}
+		return math.Copysign(math.Inf(1), m2.mean-m1.mean)
+	}
+	return (m2.mean - m1.mean) / sd
+}
+
+// setCohensD sets c's effect size to Cohen's d of ys relative to xs,
+// if it's defined. xs and ys may be weighted.
+func (c *Comparison) setCohensD(xs, ys stats.Sample) {
+	if d := momentsCohensD(moments(xs), moments(ys)); !math.IsNaN(d) {
+		c.Effect, c.EffectMeasure = d, EffectCohensD
+	}
+}
+
+// FormatEffect formats the effect size of c, such as "δ=+0.42". It
+// returns "" if c has no effect size.
+func (c Comparison) FormatEffect() string {
+	if c.EffectMeasure == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s=%+.2f", c.EffectMeasure, c.Effect)
+}
+
+// EffectMagnitude classifies the magnitude of c's effect size using
+// conventional thresholds for its measure. It returns "negligible",
+// "small", "medium", or "large", or "" if c has no effect size.
+//
+// A statistically significant comparison with a negligible effect
+// size indicates a real but practically unimportant change.
+func (c Comparison) EffectMagnitude() string {
+	th, ok := effectThresholds[c.EffectMeasure]
+	if !ok {
+		return ""
+	}
+	switch e := math.Abs(c.Effect); {
+	case e < th[0]:
+		return "negligible"
+	case e < th[1]:
+		return "small"
+	case e < th[2]:
+		return "medium"
+	}
+	return "large"
+}
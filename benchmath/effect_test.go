@@ -0,0 +1,111 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func cliffsDeltaSlow(xs, ys []float64) float64 {
+	var sum int
+	for _, x := range xs {
+		for _, y := range ys {
+			if y > x {
+				sum++
+			} else if y < x {
+				sum--
+			}
+		}
+	}
+	return float64(sum) / float64(len(xs)*len(ys))
+}
+
+func TestCliffsDelta(t *testing.T) {
+	check := func(xs, ys []float64, want float64) {
+		t.Helper()
+		if got := cliffsDelta(xs, ys); got != want {
+			t.Errorf("cliffsDelta(%v, %v) = %v, want %v", xs, ys, got, want)
+		}
+	}
+	check([]float64{1, 2, 3}, []float64{4, 5, 6}, 1)
+	check([]float64{4, 5, 6}, []float64{1, 2, 3}, -1)
+	check([]float64{1, 2, 3}, []float64{1, 2, 3}, 0)
+	check([]float64{1, 1, 1}, []float64{1, 1, 2}, 1.0/3)
+
+	// Compare against the quadratic definition, with lots of
+	// ties.
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		xs := make([]float64, 1+rng.Intn(10))
+		ys := make([]float64, 1+rng.Intn(10))
+		for j := range xs {
+			xs[j] = float64(rng.Intn(5))
+		}
+		for j := range ys {
+			ys[j] = float64(rng.Intn(5))
+		}
+		sort.Float64s(xs)
+		sort.Float64s(ys)
+		check(xs, ys, cliffsDeltaSlow(xs, ys))
+	}
+}
+
+func TestCohensD(t *testing.T) {
+	// Both samples have variance 1, so d is the difference in
+	// means.
+	if got := cohensD([]float64{1, 2, 3}, []float64{3, 4, 5}); got != 2 {
+		t.Errorf("want 2, got %v", got)
+	}
+	if got := cohensD([]float64{1, 1}, []float64{2, 2}); !math.IsInf(got, 1) {
+		t.Errorf("zero variance: want +Inf, got %v", got)
+	}
+	if got := cohensD([]float64{1, 1}, []float64{1, 1}); got != 0 {
+		t.Errorf("zero variance: want 0, got %v", got)
+	}
+	if got := cohensD([]float64{1}, []float64{2}); !math.IsNaN(got) {
+		t.Errorf("too few samples: want NaN, got %v", got)
+	}
+}
+
+func TestCompareEffect(t *testing.T) {
+	s1 := NewSample([]float64{1, 2, 3, 4, 5}, &DefaultThresholds)
+	s2 := NewSample([]float64{3, 4, 5, 6, 7}, &DefaultThresholds)
+	check := func(a Assumption, measure string) {
+		t.Helper()
+		c := a.Compare(s1, s2)
+		if c.EffectMeasure != measure {
+			t.Errorf("%T: want measure %q, got %q", a, measure, c.EffectMeasure)
+		}
+		if measure != "" && !(c.Effect > 0) {
+			t.Errorf("%T: want positive effect, got %v", a, c.Effect)
+		}
+	}
+	check(AssumeNothing, EffectCliffsDelta)
+	check(AssumeNormal, EffectCohensD)
+	check(AssumeLogNormal, EffectCohensD)
+	check(AssumeBootstrap, EffectCliffsDelta)
+	check(AssumeBootstrapMean, EffectCohensD)
+	check(AssumeExact, "")
+}
+
+func TestFormatEffect(t *testing.T) {
+	check := func(c Comparison, want, wantMag string) {
+		t.Helper()
+		if got := c.FormatEffect(); got != want {
+			t.Errorf("%+v: want %q, got %q", c, want, got)
+		}
+		if got := c.EffectMagnitude(); got != wantMag {
+			t.Errorf("%+v: want magnitude %q, got %q", c, wantMag, got)
+		}
+	}
+	check(Comparison{}, "", "")
+	check(Comparison{Effect: 0.1, EffectMeasure: EffectCliffsDelta}, "δ=+0.10", "negligible")
+	check(Comparison{Effect: -0.4, EffectMeasure: EffectCliffsDelta}, "δ=-0.40", "medium")
+	check(Comparison{Effect: 0.3, EffectMeasure: EffectCohensD}, "d=+0.30", "small")
+	check(Comparison{Effect: math.Inf(-1), EffectMeasure: EffectCohensD}, "d=-Inf", "large")
+}
@@ -0,0 +1,160 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/aclements/go-moremath/stats"
+)
+
+// An Equivalence is the result of testing whether two samples are
+// equivalent within some margin.
+type Equivalence struct {
+	// P is the p-value of the null hypothesis that the centers of
+	// the two samples differ by at least Margin. If P is less
+	// than Alpha, we reject this hypothesis and conclude that the
+	// samples are equivalent.
+	//
+	// P can be 0, which indicates this is an exact result.
+	P float64
+
+	// N1 and N2 are the sizes of the two samples.
+	N1, N2 int
+
+	// Alpha is the alpha threshold for this test.
+	Alpha float64
+
+	// Margin is the equivalence margin, relative to the center of
+	// the first sample. For example, 0.02 means ±2%.
+	Margin float64
+
+	// Warnings is a list of warnings about this result.
+	Warnings []error
+}
+
+// Equivalent returns whether the test concluded that the samples are
+// equivalent within the margin.
+func (e Equivalence) Equivalent() bool {
+	return e.P < e.Alpha || e.P == 0
+}
+
+// String summarizes the equivalence test result, such as
+// "equiv (p=0.012)" or "not equiv (p=0.300)".
+func (e Equivalence) String() string {
+	s := "not equiv"
+	if e.Equivalent() {
+		s = "equiv"
+	}
+	if e.P != 0 {
+		s += fmt.Sprintf(" (p=%0.3f)", e.P)
+	}
+	return s
+}
+
+// Equivalent tests whether the centers of s1 and s2 are equivalent
+// within a relative margin, such as 0.02 for ±2% of the center of s1,
+// using the two one-sided tests (TOST) procedure.
+//
+// This is different from Assumption.Compare failing to find a
+// difference, which can simply mean the samples are too small or too
+// noisy. Equivalent positively tests that any difference is smaller
+// than the margin: if the samples are small or noisy, it will fail to
+// conclude that they're equivalent.
+//
+// Like Assumption.Compare, the test depends on the assumption a.
+// Under AssumeNormal, this uses Welch's t-test. Under AssumeLogNormal,
+// it uses Welch's t-test in log space, so the margin is a ratio of
+// geometric means. Under AssumeExact, the result is exact. Otherwise,
+// it uses the Mann-Whitney U-test, so the margin is in terms of a
+// shift in the distribution. The significance level is
+// s1.Thresholds.CompareAlpha.
+func Equivalent(a Assumption, s1, s2 *Sample, margin float64) Equivalence {
+	eq := Equivalence{N1: len(s1.Values), N2: len(s2.Values), Alpha: s1.Thresholds.CompareAlpha, Margin: margin}
+	if !(margin > 0 && margin < 1) {
+		eq.P = 1
+		eq.Warnings = []error{fmt.Errorf("equivalence margin must be between 0 and 1, got %v", margin)}
+		return eq
+	}
+
+	var err error
+	useUTest := true
+	switch a.(type) {
+	case assumeExact:
+		c1, c2 := AssumeExact.Summary(s1, 1).Center, AssumeExact.Summary(s2, 1).Center
+		if !(math.Abs(c2-c1) <= margin*math.Abs(c1)) {
+			eq.P = 1
+		}
+		return eq
+	case assumeNormal:
+		d := margin * math.Abs(stats.Mean(s1.Values))
+		eq.P, err = tost(s1.Values, s2.Values, -d, d, welchLess)
+		useUTest = false
+	case assumeLogNormal:
+		logs1, err1 := logSample(s1)
+		logs2, err2 := logSample(s2)
+		if err1 == nil && err2 == nil {
+			eq.P, err = tost(logs1.Xs, logs2.Xs, math.Log(1-margin), math.Log(1+margin), welchLess)
+			useUTest = false
+		}
+		// Otherwise, fall back to the U-test, like Compare
+		// falls back to AssumeNormal.
+	}
+	if useUTest {
+		d := margin * math.Abs(s1.sample().Quantile(0.5))
+		eq.P, err = tost(s1.Values, s2.Values, -d, d, uTestLess)
+	}
+	if err != nil {
+		// The test failed. Report as if the samples are not
+		// equivalent, along with the error.
+		eq.P = 1
+		eq.Warnings = []error{err}
+	}
+	return eq
+}
+
+// tost performs the two one-sided tests procedure to test whether the
+// difference in location of ys relative to xs is within (lo, hi).
+// less(a, b) must return the p-value of a one-sided test of the null
+// hypothesis that the location of a is not less than that of b.
+func tost(xs, ys []float64, lo, hi float64, less func(a, b []float64) (float64, error)) (float64, error) {
+	shift := func(vs []float64, d float64) []float64 {
+		out := make([]float64, len(vs))
+		for i, v := range vs {
+			out[i] = v + d
+		}
+		return out
+	}
+	// Test that ys - lo is greater than xs, that is, the
+	// difference is greater than lo.
+	p1, err := less(xs, shift(ys, -lo))
+	if err != nil {
+		return 0, err
+	}
+	// Test that ys - hi is less than xs, that is, the
+	// difference is less than hi.
+	p2, err := less(shift(ys, -hi), xs)
+	if err != nil {
+		return 0, err
+	}
+	return math.Max(p1, p2), nil
+}
+
+func welchLess(a, b []float64) (float64, error) {
+	t, err := stats.TwoSampleWelchTTest(stats.Sample{Xs: a}, stats.Sample{Xs: b}, stats.LocationLess)
+	if err != nil {
+		return 0, err
+	}
+	return t.P, nil
+}
+
+func uTestLess(a, b []float64) (float64, error) {
+	u, err := stats.MannWhitneyUTest(a, b, stats.LocationLess)
+	if err != nil {
+		return 0, err
+	}
+	return u.P, nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestEquivalent(t *testing.T) {
+	thr := DefaultThresholds
+	sample := func(center float64) *Sample {
+		var vs []float64
+		for i := 0; i < 10; i++ {
+			vs = append(vs, center+float64(i%5)-2)
+		}
+		return NewSample(vs, &thr)
+	}
+	base := sample(1000)
+	same := sample(1001)
+	far := sample(1050)
+
+	for _, a := range []Assumption{AssumeNothing, AssumeNormal, AssumeLogNormal, AssumeBootstrap, AssumeExact} {
+		// Samples differing by 0.1% are equivalent within 2%.
+		if eq := Equivalent(a, base, same, 0.02); !eq.Equivalent() || eq.N1 != 10 || eq.N2 != 10 || eq.Warnings != nil {
+			t.Errorf("%T: want equivalent, got %+v", a, eq)
+		}
+		// Samples differing by 5% are not.
+		if eq := Equivalent(a, base, far, 0.02); eq.Equivalent() {
+			t.Errorf("%T: want not equivalent, got %+v", a, eq)
+		}
+		// Nor are they equivalent in the other direction.
+		if eq := Equivalent(a, far, base, 0.02); eq.Equivalent() {
+			t.Errorf("%T: want not equivalent, got %+v", a, eq)
+		}
+	}
+
+	// Small samples can't establish equivalence, even if they
+	// show no difference.
+	small1 := NewSample([]float64{1000, 1002}, &thr)
+	small2 := NewSample([]float64{1001, 1003}, &thr)
+	if eq := Equivalent(AssumeNothing, small1, small2, 0.02); eq.Equivalent() {
+		t.Errorf("small samples: want not equivalent, got %+v", eq)
+	}
+
+	eq := Equivalent(AssumeNothing, base, same, 0)
+	if eq.Equivalent() || !errorsEq(eq.Warnings, []error{fmt.Errorf("equivalence margin must be between 0 and 1, got 0")}) {
+		t.Errorf("zero margin: got %+v", eq)
+	}
+}
+
+func TestEquivalenceString(t *testing.T) {
+	check := func(eq Equivalence, want string) {
+		t.Helper()
+		if got := eq.String(); got != want {
+			t.Errorf("want %q, got %q", want, got)
+		}
+	}
+	check(Equivalence{P: 0.012, Alpha: 0.05}, "equiv (p=0.012)")
+	check(Equivalence{P: 0.3, Alpha: 0.05}, "not equiv (p=0.300)")
+	check(Equivalence{P: 0, Alpha: 0.05}, "equiv")
+	check(Equivalence{P: 1, Alpha: 0.05}, "not equiv (p=1.000)")
+}
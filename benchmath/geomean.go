@@ -0,0 +1,76 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/aclements/go-moremath/stats"
+)
+
+// A GeoMeanSummary summarizes a set of positive values, such as the
+// ratios of many benchmarks between two configurations, by their
+// geometric mean.
+type GeoMeanSummary struct {
+	// Summary gives the geometric mean as its Center and a
+	// confidence interval for it as Lo and Hi.
+	Summary
+
+	// GeoStdDev is the geometric standard deviation of the
+	// values: the typical multiplicative factor by which a value
+	// differs from the geometric mean. It is always >= 1, and is 1
+	// if all of the values are the same.
+	GeoStdDev float64
+}
+
+// SummarizeGeoMean returns the geometric mean of xs and a confidence
+// interval for it at the given confidence level. All values of xs
+// must be positive.
+//
+// The interval is the exponentiated t-interval for the mean of the
+// logs of xs. This treats xs as a sample from a larger population,
+// such as all benchmarks that could have been run, so it reflects how
+// much the values vary from each other, not how noisy each value is.
+// For the ratios of a set of benchmarks, a narrow interval means the
+// benchmarks changed consistently, while a wide one means the
+// geometric mean hides large differences between benchmarks.
+func SummarizeGeoMean(xs []float64, confidence float64) GeoMeanSummary {
+	res := GeoMeanSummary{
+		Summary:   Summary{Center: math.NaN(), Lo: math.Inf(-1), Hi: math.Inf(1), Confidence: confidence},
+		GeoStdDev: math.NaN(),
+	}
+	if len(xs) == 0 {
+		res.Warnings = []error{fmt.Errorf("need >= 1 value to compute geomean")}
+		return res
+	}
+	logs := make([]float64, len(xs))
+	for i, x := range xs {
+		if !(x > 0) {
+			res.Warnings = []error{fmt.Errorf("values must be >0 to compute geomean")}
+			return res
+		}
+		logs[i] = math.Log(x)
+	}
+	if len(xs) == 1 {
+		res.Center = xs[0]
+		res.GeoStdDev = 1
+		res.Warnings = []error{fmt.Errorf("need >= 2 values for confidence interval at level %v", confidence)}
+		return res
+	}
+
+	m, v := meanVar(logs)
+	n := float64(len(logs))
+	var w float64
+	if confidence >= 1 {
+		w = math.Inf(1)
+	} else if confidence > 0 {
+		w = -stats.InvCDF(stats.TDist{V: n - 1})((1-confidence)/2) * math.Sqrt(v/n)
+	}
+	res.Center = math.Exp(m)
+	res.Lo, res.Hi = math.Exp(m-w), math.Exp(m+w)
+	res.GeoStdDev = math.Exp(math.Sqrt(v))
+	return res
+}
@@ -0,0 +1,45 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSummarizeGeoMean(t *testing.T) {
+	// The logs of these are -1, 0, and 1 (times log 2), so the
+	// geomean is 1, and the logs have standard deviation log 2.
+	got := SummarizeGeoMean([]float64{0.5, 1, 2}, 0.95)
+	if !aeq(got.Center, 1) || !aeq(got.GeoStdDev, 2) {
+		t.Errorf("got %+v, want geomean 1 and geometric standard deviation 2", got)
+	}
+	// The interval is multiplicatively symmetric around 1. With 2
+	// degrees of freedom, t ≈ 4.303, so the half-width in log space
+	// is 4.303·log(2)/√3.
+	if want := math.Exp(4.302652729749464 * math.Ln2 / math.Sqrt(3)); !aeq(got.Hi, want) || !aeq(got.Lo, 1/want) {
+		t.Errorf("got interval [%v, %v], want [%v, %v]", got.Lo, got.Hi, 1/want, want)
+	}
+
+	// Identical values have no spread.
+	got = SummarizeGeoMean([]float64{3, 3, 3}, 0.95)
+	if !aeq(got.Center, 3) || !aeq(got.Lo, 3) || !aeq(got.Hi, 3) || got.GeoStdDev != 1 {
+		t.Errorf("identical: got %+v, want geomean 3 with no spread", got)
+	}
+
+	got = SummarizeGeoMean([]float64{2}, 0.95)
+	checkSummary(t, got.Summary, Summary{Center: 2, Lo: math.Inf(-1), Hi: math.Inf(1), Confidence: 0.95}, "need >= 2 values for confidence interval at level 0.95")
+	got = SummarizeGeoMean([]float64{2, 0}, 0.95)
+	if !math.IsNaN(got.Center) || len(got.Warnings) != 1 || got.Warnings[0].Error() != "values must be >0 to compute geomean" {
+		t.Errorf("zero: got %+v", got)
+	}
+}
+
+func TestPctDeltaRangeString(t *testing.T) {
+	s := Summary{Lo: 0.5, Hi: math.Inf(1)}
+	if got, want := s.PctDeltaRangeString(), "[-50.00%, +∞]"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import "math"
+
+// maxHistogramBins limits the number of bins Sample.Histogram chooses
+// automatically, so a few extreme values can't produce a histogram
+// that's mostly empty bins.
+const maxHistogramBins = 100
+
+// A Histogram divides the range of a Sample into equal-width bins and
+// counts the values in each.
+type Histogram struct {
+	// Lo is the lower bound of the first bin.
+	Lo float64
+
+	// Width is the width of each bin. Bin i covers the interval
+	// [Lo + i*Width, Lo + (i+1)*Width), except the last bin also
+	// includes its upper bound.
+	Width float64
+
+	// Counts is the number of values in each bin. If the Sample is
+	// weighted, this is the total weight of the values in each
+	// bin.
+	Counts []float64
+}
+
+// Bin returns the bounds of bin i.
+func (h Histogram) Bin(i int) (lo, hi float64) {
+	return h.Lo + float64(i)*h.Width, h.Lo + float64(i+1)*h.Width
+}
+
+// Histogram bins the values of s into a histogram with the given
+// number of bins. If bins is 0, Histogram chooses the number of bins
+// automatically using the Freedman–Diaconis rule, which picks a bin
+// width of 2·IQR/∛n. This adapts to the spread of the bulk of the
+// sample without being distorted by outliers. If the IQR is 0, it
+// falls back to Sturges' rule, ⌈log₂ n⌉+1 bins.
+//
+// If all values of s are the same, the histogram has a single bin of
+// width 0. If s has no values, the histogram has no bins.
+func (s *Sample) Histogram(bins int) Histogram {
+	xs := s.Values
+	if len(xs) == 0 {
+		return Histogram{}
+	}
+	lo, hi := xs[0], xs[len(xs)-1]
+	if lo == hi {
+		return Histogram{Lo: lo, Counts: []float64{s.totalWeight()}}
+	}
+
+	if bins <= 0 {
+		n := float64(len(xs))
+		sample := s.sample()
+		iqr := sample.Quantile(0.75) - sample.Quantile(0.25)
+		if iqr > 0 {
+			bins = int(math.Ceil((hi - lo) / (2 * iqr / math.Cbrt(n))))
+		} else {
+			bins = int(math.Ceil(math.Log2(n))) + 1
+		}
+		if bins > maxHistogramBins {
+			bins = maxHistogramBins
+		}
+	}
+
+	h := Histogram{Lo: lo, Width: (hi - lo) / float64(bins), Counts: make([]float64, bins)}
+	for i, x := range xs {
+		b := int((x - lo) / h.Width)
+		if b >= bins {
+			// The maximum value goes in the last bin.
+			b = bins - 1
+		}
+		w := 1.0
+		if s.Weights != nil {
+			w = s.Weights[i]
+		}
+		h.Counts[b] += w
+	}
+	return h
+}
+
+// totalWeight returns the total weight of the values of s.
+func (s *Sample) totalWeight() float64 {
+	if s.Weights == nil {
+		return float64(len(s.Values))
+	}
+	var total float64
+	for _, w := range s.Weights {
+		total += w
+	}
+	return total
+}
@@ -0,0 +1,52 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHistogram(t *testing.T) {
+	s := NewSample([]float64{0, 1, 2, 3, 4, 5, 6, 7, 8, 10}, &DefaultThresholds)
+	h := s.Histogram(5)
+	if h.Lo != 0 || h.Width != 2 {
+		t.Errorf("got Lo %v Width %v, want 0, 2", h.Lo, h.Width)
+	}
+	// The maximum goes in the last bin.
+	if want := []float64{2, 2, 2, 2, 2}; !reflect.DeepEqual(h.Counts, want) {
+		t.Errorf("got counts %v, want %v", h.Counts, want)
+	}
+	if lo, hi := h.Bin(2); lo != 4 || hi != 6 {
+		t.Errorf("Bin(2) = %v, %v, want 4, 6", lo, hi)
+	}
+
+	// The IQR is about 5.17, so the Freedman–Diaconis width is
+	// 2·5.17/∛10 ≈ 4.8, giving 3 bins.
+	if h := s.Histogram(0); len(h.Counts) != 3 {
+		t.Errorf("automatic: got %d bins, want 3", len(h.Counts))
+	}
+
+	// With no IQR, use Sturges' rule: ⌈log₂ 8⌉+1 = 4 bins.
+	s = NewSample([]float64{1, 1, 1, 1, 1, 1, 1, 9}, &DefaultThresholds)
+	if h := s.Histogram(0); len(h.Counts) != 4 || h.Counts[0] != 7 || h.Counts[3] != 1 {
+		t.Errorf("no IQR: got %+v, want 4 bins", h)
+	}
+
+	// Weighted samples sum their weights.
+	s = NewWeightedSample([]float64{1, 2, 3}, []float64{0.5, 1, 2}, &DefaultThresholds)
+	if h := s.Histogram(2); !reflect.DeepEqual(h.Counts, []float64{0.5, 3}) {
+		t.Errorf("weighted: got counts %v, want [0.5 3]", h.Counts)
+	}
+
+	// Degenerate samples.
+	s = NewSample([]float64{4, 4}, &DefaultThresholds)
+	if h := s.Histogram(0); h.Lo != 4 || h.Width != 0 || !reflect.DeepEqual(h.Counts, []float64{2}) {
+		t.Errorf("constant: got %+v", h)
+	}
+	if h := NewSample(nil, &DefaultThresholds).Histogram(0); len(h.Counts) != 0 {
+		t.Errorf("empty: got %+v", h)
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/aclements/go-moremath/mathx"
+)
+
+// A KComparison is the result of comparing several samples at once to
+// test if they all come from the same distribution.
+type KComparison struct {
+	// P is the p-value of the null hypothesis that all of the
+	// samples come from the same distribution. If P is less than
+	// Alpha, at least one sample differs from the others, but
+	// this doesn't say which. Pairwise comparisons can find that
+	// out.
+	P float64
+
+	// Ns are the sizes of the samples.
+	Ns []int
+
+	// Alpha is the alpha threshold for this test.
+	Alpha float64
+
+	// Test is the name of the statistical test that computed P,
+	// such as KruskalWallisTest, or "" if no test was performed.
+	Test string
+
+	// Statistic is the value of Test's test statistic.
+	Statistic float64
+
+	// DF and DF2 are the degrees of freedom of the distribution
+	// of Statistic. DF2 is 0 unless Test is an F test.
+	DF, DF2 float64
+
+	// Warnings is a list of warnings about this comparison
+	// result.
+	Warnings []error
+}
+
+// String summarizes the comparison. The general form of this string
+// is "p=0.PPP n=N1+N2+N3", like Comparison.String.
+func (c KComparison) String() string {
+	var s string
+	if c.P != 0 {
+		s = fmt.Sprintf("p=%0.3f ", c.P)
+	}
+	same := true
+	ns := make([]string, len(c.Ns))
+	for i, n := range c.Ns {
+		ns[i] = fmt.Sprint(n)
+		same = same && n == c.Ns[0]
+	}
+	if same && len(ns) > 0 {
+		return s + "n=" + ns[0]
+	}
+	return s + "n=" + strings.Join(ns, "+")
+}
+
+// CompareK tests whether all of samples come from the same
+// distribution under assumption a. This is a single test across any
+// number of samples, so it doesn't need a multiple comparison
+// correction, and is a useful first step before comparing three or
+// more samples pairwise.
+//
+// Under AssumeNormal, this uses a one-way analysis of variance, and
+// under AssumeLogNormal, an analysis of variance of the logs of the
+// values. Under AssumeExact, the samples differ if their values
+// differ at all. Otherwise, it uses the Kruskal-Wallis test, which
+// generalizes the Mann-Whitney U test. All of these ignore sample
+// weights.
+func CompareK(a Assumption, samples ...*Sample) KComparison {
+	cmp := KComparison{P: 1, Ns: make([]int, len(samples))}
+	groups := make([][]float64, len(samples))
+	for i, s := range samples {
+		cmp.Ns[i] = len(s.Values)
+		groups[i] = s.Values
+	}
+	if len(samples) < 2 {
+		cmp.Warnings = append(cmp.Warnings, fmt.Errorf("need >= 2 samples to compare"))
+		return cmp
+	}
+	cmp.Alpha = samples[0].Thresholds.CompareAlpha
+	for _, s := range samples {
+		if s.Weights != nil {
+			cmp.Warnings = append(cmp.Warnings, fmt.Errorf("comparing %d samples ignores sample weights", len(samples)))
+			break
+		}
+	}
+
+	switch a.(type) {
+	case assumeExact:
+		for _, g := range groups {
+			if len(g) > 0 && len(groups[0]) > 0 && g[0] != groups[0][0] {
+				cmp.P = 0
+			}
+		}
+		return cmp
+	case assumeLogNormal:
+		logGroups := make([][]float64, len(samples))
+		for i, s := range samples {
+			logs, err := logSample(s)
+			if err != nil {
+				// Fall back to a rank test on the values.
+				cmp.Warnings = append(cmp.Warnings, err)
+				logGroups = nil
+				a = AssumeNothing
+				break
+			}
+			logGroups[i] = logs.Xs
+		}
+		if logGroups != nil {
+			groups = logGroups
+		}
+	}
+	switch a.(type) {
+	case assumeNormal, assumeLogNormal:
+		oneWayANOVA(groups, &cmp)
+	default:
+		kruskalWallis(groups, &cmp)
+	}
+	return cmp
+}
+
+// oneWayANOVA performs a one-way analysis of variance of groups and
+// stores the result in cmp.
+func oneWayANOVA(groups [][]float64, cmp *KComparison) {
+	var n, sum float64
+	for _, g := range groups {
+		for _, x := range g {
+			sum += x
+		}
+		n += float64(len(g))
+	}
+	k := float64(len(groups))
+	if n-k < 1 {
+		cmp.Warnings = append(cmp.Warnings, fmt.Errorf("need more than %d values to compare %d samples", len(groups), len(groups)))
+		return
+	}
+	mean := sum / n
+
+	var between, within float64
+	for _, g := range groups {
+		if len(g) == 0 {
+			continue
+		}
+		m, _ := meanVar(g)
+		between += float64(len(g)) * (m - mean) * (m - mean)
+		for _, x := range g {
+			within += (x - m) * (x - m)
+		}
+	}
+	cmp.Test, cmp.DF, cmp.DF2 = ANOVATest, k-1, n-k
+	if within == 0 {
+		// There's no noise within the groups, so any
+		// difference between them is significant.
+		cmp.Statistic = math.Inf(1)
+		if between == 0 {
+			cmp.Statistic = 0
+		} else {
+			cmp.P = 0
+		}
+		return
+	}
+	f := (between / cmp.DF) / (within / cmp.DF2)
+	cmp.Statistic = f
+	cmp.P = mathx.BetaInc(cmp.DF2/(cmp.DF2+cmp.DF*f), cmp.DF2/2, cmp.DF/2)
+}
+
+// kruskalWallis performs the Kruskal-Wallis test on groups and stores
+// the result in cmp. It uses the chi-squared approximation with a tie
+// correction.
+func kruskalWallis(groups [][]float64, cmp *KComparison) {
+	type val struct {
+		x     float64
+		group int
+	}
+	var vals []val
+	for i, g := range groups {
+		for _, x := range g {
+			vals = append(vals, val{x, i})
+		}
+	}
+	n := len(vals)
+	if n < 2 {
+		cmp.Warnings = append(cmp.Warnings, fmt.Errorf("need >= 2 values to compare samples"))
+		return
+	}
+	sort.Slice(vals, func(i, j int) bool { return vals[i].x < vals[j].x })
+
+	// Sum the mid-ranks of each group.
+	rankSums := make([]float64, len(groups))
+	var ties float64
+	for i := 0; i < n; {
+		j := i + 1
+		for j < n && vals[j].x == vals[i].x {
+			j++
+		}
+		t := float64(j - i)
+		ties += t*t*t - t
+		for _, v := range vals[i:j] {
+			rankSums[v.group] += float64(i+j+1) / 2
+		}
+		i = j
+	}
+
+	fn := float64(n)
+	var h float64
+	for i, g := range groups {
+		if len(g) > 0 {
+			h += rankSums[i] * rankSums[i] / float64(len(g))
+		}
+	}
+	h = 12/(fn*(fn+1))*h - 3*(fn+1)
+	cmp.Test, cmp.DF = KruskalWallisTest, float64(len(groups)-1)
+	c := 1 - ties/(fn*fn*fn-fn)
+	if c == 0 {
+		// All values are equal.
+		return
+	}
+	cmp.Statistic = h / c
+	cmp.P = mathx.GammaIncComp(cmp.DF/2, cmp.Statistic/2)
+	for _, g := range groups {
+		if len(g) < 5 {
+			cmp.Warnings = append(cmp.Warnings, fmt.Errorf("%s p-value may be inaccurate with < 5 values per sample", KruskalWallisTest))
+			break
+		}
+	}
+}
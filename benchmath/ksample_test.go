@@ -0,0 +1,75 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCompareK(t *testing.T) {
+	s := func(xs ...float64) *Sample {
+		return NewSample(xs, &DefaultThresholds)
+	}
+	a, b, c := s(1, 2, 3, 4, 5), s(6, 7, 8, 9, 10), s(11, 12, 13, 14, 15)
+
+	// The rank sums are 15, 40, and 65, so H = 12.5, and with 2
+	// degrees of freedom, P = exp(-H/2).
+	cmp := CompareK(AssumeNothing, a, b, c)
+	if cmp.Test != KruskalWallisTest || !aeq(cmp.Statistic, 12.5) || cmp.DF != 2 || !aeq(cmp.P, math.Exp(-6.25)) {
+		t.Errorf("Kruskal-Wallis: got %+v", cmp)
+	}
+	if got, want := cmp.String(), "p=0.002 n=5"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	// The group means are 3, 8, and 13, so the between-group sum
+	// of squares is 250 and the within-group sum of squares is 30,
+	// giving F = 125/2.5 = 50 with 2 and 12 degrees of freedom. For
+	// 2 numerator degrees of freedom, P = (1 + 2F/DF2)^(-DF2/2).
+	cmp = CompareK(AssumeNormal, a, b, c)
+	if cmp.Test != ANOVATest || !aeq(cmp.Statistic, 50) || cmp.DF != 2 || cmp.DF2 != 12 || !aeq(cmp.P, math.Pow(1+100.0/12, -6)) {
+		t.Errorf("ANOVA: got %+v", cmp)
+	}
+
+	// The same samples, shuffled between groups, don't differ.
+	cmp = CompareK(AssumeNothing, s(1, 6, 11, 4, 15), s(2, 7, 12, 9, 10), s(3, 8, 13, 5, 14))
+	if cmp.P < 0.5 {
+		t.Errorf("mixed: got P %v, want large P", cmp.P)
+	}
+
+	// Log-normal compares the logs.
+	cmp = CompareK(AssumeLogNormal, s(1, 2, 4), s(2, 4, 8), s(4, 8, 16))
+	if cmp.Test != ANOVATest || !aeq(cmp.Statistic, 3) {
+		t.Errorf("lognormal: got %+v", cmp)
+	}
+	// And falls back to Kruskal-Wallis for non-positive values.
+	cmp = CompareK(AssumeLogNormal, s(0, 1), s(2, 3), s(4, 5))
+	if cmp.Test != KruskalWallisTest || len(cmp.Warnings) != 2 {
+		t.Errorf("lognormal with 0: got %+v", cmp)
+	}
+
+	// Exact samples differ if any value differs.
+	if cmp := CompareK(AssumeExact, s(1), s(1), s(2)); cmp.P != 0 {
+		t.Errorf("exact: got P %v, want 0", cmp.P)
+	}
+	if cmp := CompareK(AssumeExact, s(1), s(1), s(1)); cmp.P != 1 {
+		t.Errorf("exact: got P %v, want 1", cmp.P)
+	}
+
+	// Degenerate cases.
+	if cmp := CompareK(AssumeNothing, s(1, 1, 1), s(1, 1), s(1, 1, 1)); cmp.P != 1 {
+		t.Errorf("all equal: got P %v, want 1", cmp.P)
+	}
+	if cmp := CompareK(AssumeNormal, s(1, 1), s(2, 2), s(3, 3)); cmp.P != 0 || !math.IsInf(cmp.Statistic, 1) {
+		t.Errorf("no noise: got %+v", cmp)
+	}
+	if cmp := CompareK(AssumeNothing, a); cmp.P != 1 || len(cmp.Warnings) != 1 {
+		t.Errorf("one sample: got %+v", cmp)
+	}
+	if got, want := (KComparison{P: 0.5, Ns: []int{3, 4, 5}}).String(), "p=0.500 n=3+4+5"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
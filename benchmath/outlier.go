@@ -0,0 +1,126 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/aclements/go-moremath/stats"
+)
+
+// An OutlierMethod is a method for detecting outliers in a Sample.
+type OutlierMethod int
+
+const (
+	// OutliersNone disables outlier detection.
+	OutliersNone OutlierMethod = iota
+
+	// OutliersTukey flags values outside Tukey's fences,
+	// [Q1 - k*IQR, Q3 + k*IQR], where Q1 and Q3 are the first and
+	// third quartiles, IQR is the interquartile range Q3 - Q1, and
+	// k is Thresholds.OutlierK, or 1.5 by default.
+	OutliersTukey
+
+	// OutliersMAD flags values whose distance from the median is
+	// more than k times the scaled median absolute deviation,
+	// where k is Thresholds.OutlierK, or 3.5 by default. The MAD
+	// is scaled by 1.4826 to be a consistent estimator of the
+	// standard deviation of a normal distribution.
+	OutliersMAD
+)
+
+// String returns the name of m, which can be parsed by
+// ParseOutlierMethod.
+func (m OutlierMethod) String() string {
+	switch m {
+	case OutliersNone:
+		return "none"
+	case OutliersTukey:
+		return "tukey"
+	case OutliersMAD:
+		return "mad"
+	}
+	return fmt.Sprintf("OutlierMethod(%d)", int(m))
+}
+
+// ParseOutlierMethod returns the OutlierMethod named name, which must
+// be "none", "tukey", or "mad".
+func ParseOutlierMethod(name string) (OutlierMethod, error) {
+	for _, m := range []OutlierMethod{OutliersNone, OutliersTukey, OutliersMAD} {
+		if m.String() == name {
+			return m, nil
+		}
+	}
+	return OutliersNone, fmt.Errorf("unknown outlier method %q", name)
+}
+
+// minOutlierSamples is the minimum sample size for outlier detection.
+// With fewer samples, the quartiles and MAD are too unstable to
+// meaningfully distinguish outliers.
+const minOutlierSamples = 5
+
+// outlierBounds returns the range of values in sorted sample xs that
+// are not outliers according to t. If xs is too small or has no
+// spread, it returns an unbounded range.
+func outlierBounds(xs []float64, t *Thresholds) (lo, hi float64) {
+	lo, hi = math.Inf(-1), math.Inf(1)
+	if len(xs) < minOutlierSamples {
+		return
+	}
+	sample := stats.Sample{Xs: xs, Sorted: true}
+	k := t.OutlierK
+	switch t.Outliers {
+	case OutliersTukey:
+		if k == 0 {
+			k = 1.5
+		}
+		q1, q3 := sample.Quantile(0.25), sample.Quantile(0.75)
+		if iqr := q3 - q1; iqr > 0 {
+			lo, hi = q1-k*iqr, q3+k*iqr
+		}
+	case OutliersMAD:
+		if k == 0 {
+			k = 3.5
+		}
+		med := sample.Quantile(0.5)
+		devs := make([]float64, len(xs))
+		for i, x := range xs {
+			devs[i] = math.Abs(x - med)
+		}
+		mad := stats.Sample{Xs: devs}.Quantile(0.5) * 1.4826
+		if mad > 0 {
+			lo, hi = med-k*mad, med+k*mad
+		}
+	}
+	return
+}
+
+// findOutliers partitions sorted sample xs into values that are not
+// outliers and values that are, according to t. Both results are
+// sorted. If there are no outliers, inliers is xs.
+func findOutliers(xs []float64, t *Thresholds) (inliers, outliers []float64) {
+	i, j := inlierRange(xs, t)
+	if i == 0 && j == len(xs) {
+		return xs, nil
+	}
+	outliers = append(append(outliers, xs[:i]...), xs[j:]...)
+	return xs[i:j], outliers
+}
+
+// inlierRange returns the range xs[i:j] of sorted sample xs that are
+// not outliers according to t.
+func inlierRange(xs []float64, t *Thresholds) (i, j int) {
+	lo, hi := outlierBounds(xs, t)
+	// xs is sorted, so the inliers are a contiguous range.
+	i, j = 0, len(xs)
+	for i < j && xs[i] < lo {
+		i++
+	}
+	for j > i && xs[j-1] > hi {
+		j--
+	}
+	return i, j
+}
@@ -0,0 +1,90 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestOutliers(t *testing.T) {
+	values := func() []float64 {
+		// One slow run, like from thermal throttling.
+		return []float64{10, 11, 10, 12, 11, 10, 11, 30}
+	}
+
+	for _, method := range []OutlierMethod{OutliersTukey, OutliersMAD} {
+		t.Run(method.String(), func(t *testing.T) {
+			thr := DefaultThresholds
+			thr.Outliers = method
+			s := NewSample(values(), &thr)
+			if want := []float64{30}; !reflect.DeepEqual(s.Outliers, want) {
+				t.Errorf("want outliers %v, got %v", want, s.Outliers)
+			}
+			if len(s.Values) != 8 {
+				t.Errorf("want all 8 values kept, got %v", s.Values)
+			}
+			want := []error{fmt.Errorf("1 of 8 values are outliers (%s)", method)}
+			if !errorsEq(s.Warnings, want) {
+				t.Errorf("want warnings %v, got %v", want, s.Warnings)
+			}
+
+			thr.TrimOutliers = true
+			s = NewSample(values(), &thr)
+			if want := []float64{10, 10, 10, 11, 11, 11, 12}; !reflect.DeepEqual(s.Values, want) {
+				t.Errorf("want trimmed values %v, got %v", want, s.Values)
+			}
+			want = []error{fmt.Errorf("excluded 1 of 8 values as outliers (%s)", method)}
+			if !errorsEq(s.Warnings, want) {
+				t.Errorf("want warnings %v, got %v", want, s.Warnings)
+			}
+		})
+	}
+
+	t.Run("none", func(t *testing.T) {
+		s := NewSample(values(), &DefaultThresholds)
+		if s.Outliers != nil || s.Warnings != nil || len(s.Values) != 8 {
+			t.Errorf("want no outlier detection, got %+v", s)
+		}
+	})
+
+	t.Run("k", func(t *testing.T) {
+		// A large enough multiplier accepts everything.
+		thr := DefaultThresholds
+		thr.Outliers, thr.OutlierK = OutliersTukey, 100
+		if s := NewSample(values(), &thr); s.Outliers != nil {
+			t.Errorf("want no outliers, got %v", s.Outliers)
+		}
+	})
+
+	t.Run("small", func(t *testing.T) {
+		thr := DefaultThresholds
+		thr.Outliers = OutliersTukey
+		if s := NewSample([]float64{10, 11, 100}, &thr); s.Outliers != nil {
+			t.Errorf("want no outliers in small sample, got %v", s.Outliers)
+		}
+	})
+
+	t.Run("no spread", func(t *testing.T) {
+		thr := DefaultThresholds
+		thr.Outliers = OutliersMAD
+		if s := NewSample([]float64{10, 10, 10, 10, 11}, &thr); s.Outliers != nil {
+			t.Errorf("want no outliers with zero MAD, got %v", s.Outliers)
+		}
+	})
+}
+
+func TestParseOutlierMethod(t *testing.T) {
+	for _, m := range []OutlierMethod{OutliersNone, OutliersTukey, OutliersMAD} {
+		got, err := ParseOutlierMethod(m.String())
+		if err != nil || got != m {
+			t.Errorf("ParseOutlierMethod(%q) = %v, %v; want %v", m.String(), got, err, m)
+		}
+	}
+	if _, err := ParseOutlierMethod("iqr"); err == nil || err.Error() != `unknown outlier method "iqr"` {
+		t.Errorf("want unknown method error, got %v", err)
+	}
+}
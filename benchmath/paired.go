@@ -0,0 +1,181 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/aclements/go-moremath/stats"
+)
+
+// ComparePaired tests whether paired measurements come from the same
+// distribution. Measurements xs[i] and ys[i] form a pair, for example
+// because they were taken in the same interleaved run of a before and
+// after benchmark. Pairing removes variation that's common to both
+// measurements of a pair, such as slow drift in machine performance,
+// so a paired test is often much more sensitive than an unpaired one.
+//
+// Like Assumption.Compare, the test is chosen based on assumption a.
+// Under AssumeNormal, this uses a paired t-test. Under
+// AssumeLogNormal, it uses a paired t-test on the logarithms of the
+// values. Under AssumeExact, the result is exact. Otherwise, it uses
+// the Wilcoxon signed-rank test, which makes no distributional
+// assumptions.
+//
+// xs and ys must be in pair order. ComparePaired does not modify them.
+func ComparePaired(a Assumption, xs, ys []float64, t *Thresholds) Comparison {
+	cmp := Comparison{N1: len(xs), N2: len(ys), Alpha: t.CompareAlpha}
+	if len(xs) != len(ys) {
+		cmp.P = 1
+		cmp.Warnings = []error{fmt.Errorf("paired comparison requires equal sample sizes, but have %d and %d", len(xs), len(ys))}
+		return cmp
+	}
+
+	switch a.(type) {
+	case assumeExact:
+		return cmp
+	case assumeNormal:
+		pairedTTest(&cmp, xs, ys)
+		return cmp
+	case assumeLogNormal:
+		logs1, err1 := logSample(&Sample{Values: xs})
+		logs2, err2 := logSample(&Sample{Values: ys})
+		if err1 == nil && err2 == nil {
+			pairedTTest(&cmp, logs1.Xs, logs2.Xs)
+			return cmp
+		}
+		// Fall back to the Wilcoxon test, which is invariant
+		// under monotonic transformations anyway.
+	}
+
+	wilcoxonSignedRank(&cmp, xs, ys)
+	// Warn if there aren't enough pairs to report a difference
+	// even if they were maximally different.
+	if cmp.P > cmp.Alpha && cmp.Alpha > 0 {
+		// The smallest possible p-value with n pairs is
+		// 2 / 2^n.
+		need := int(math.Ceil(1 - math.Log2(cmp.Alpha)))
+		if len(xs) < need {
+			cmp.Warnings = append(cmp.Warnings, fmt.Errorf("need >= %d pairs to detect a difference at alpha level %v", need, cmp.Alpha))
+		}
+	}
+	return cmp
+}
+
+// pairedTTest fills in cmp using a paired t-test of xs and ys.
+func pairedTTest(cmp *Comparison, xs, ys []float64) {
+	// PairedTTest tests xs - ys; we want ys relative to xs, but
+	// the two-sided p-value is the same.
+	t, err := stats.PairedTTest(xs, ys, 0, stats.LocationDiffers)
+	if err != nil {
+		// The t-test failed. Report as if there's no
+		// significant difference, along with the error.
+		cmp.P = 1
+		cmp.Warnings = []error{err}
+		return
+	}
+	cmp.P = t.P
+	cmp.Test, cmp.Statistic, cmp.DF, cmp.Exact = PairedTTest, t.T, t.DoF, true
+
+	// Cohen's d for paired samples (sometimes called d_z) is the
+	// mean difference over its standard deviation.
+	diffs := make([]float64, len(xs))
+	for i := range xs {
+		diffs[i] = ys[i] - xs[i]
+	}
+	cmp.Effect = stats.Mean(diffs) / stats.StdDev(diffs)
+	cmp.EffectMeasure = EffectCohensD
+}
+
+// wilcoxonExactMax is the maximum number of pairs for which
+// wilcoxonSignedRank computes an exact p-value.
+const wilcoxonExactMax = 50
+
+// wilcoxonSignedRank fills in cmp using a two-sided Wilcoxon
+// signed-rank test of ys relative to xs. Pairs with no difference are
+// dropped, following Wilcoxon.
+func wilcoxonSignedRank(cmp *Comparison, xs, ys []float64) {
+	var diffs []float64
+	for i := range xs {
+		if d := ys[i] - xs[i]; d != 0 {
+			diffs = append(diffs, d)
+		}
+	}
+	n := len(diffs)
+	cmp.Test = SignedRankTest
+	if n == 0 {
+		// All pairs are identical.
+		cmp.P = 1
+		return
+	}
+
+	// Rank the absolute differences, assigning tied values their
+	// average rank.
+	sort.Slice(diffs, func(i, j int) bool { return math.Abs(diffs[i]) < math.Abs(diffs[j]) })
+	var wPlus, tieCorrection float64
+	ties := false
+	for i := 0; i < n; {
+		j := i + 1
+		for j < n && math.Abs(diffs[j]) == math.Abs(diffs[i]) {
+			j++
+		}
+		rank := float64(i+j+1) / 2 // Average of ranks i+1 ... j
+		for _, d := range diffs[i:j] {
+			if d > 0 {
+				wPlus += rank
+			}
+		}
+		if t := float64(j - i); t > 1 {
+			ties = true
+			tieCorrection += t*t*t - t
+		}
+		i = j
+	}
+	total := float64(n*(n+1)) / 2
+	cmp.Statistic = wPlus
+	cmp.Effect = (2*wPlus - total) / total
+	cmp.EffectMeasure = EffectRankBiserial
+
+	if !ties && n <= wilcoxonExactMax {
+		// Compute the exact null distribution of W+. counts[w]
+		// is the number of subsets of {1, ..., n} that sum to
+		// w.
+		counts := make([]float64, int(total)+1)
+		counts[0] = 1
+		for k := 1; k <= n; k++ {
+			for w := len(counts) - 1; w >= k; w-- {
+				counts[w] += counts[w-k]
+			}
+		}
+		w := int(wPlus)
+		if float64(w) > total/2 {
+			w = int(total) - w
+		}
+		var tail float64
+		for _, c := range counts[:w+1] {
+			tail += c
+		}
+		cmp.P = math.Min(1, 2*tail/math.Pow(2, float64(n)))
+		cmp.Exact = true
+		return
+	}
+
+	// Use the normal approximation with tie and continuity
+	// corrections.
+	fn := float64(n)
+	mean := total / 2
+	sd := math.Sqrt(fn*(fn+1)*(2*fn+1)/24 - tieCorrection/48)
+	if sd == 0 {
+		cmp.P = 1
+		return
+	}
+	z := (math.Abs(wPlus-mean) - 0.5) / sd
+	if z < 0 {
+		z = 0
+	}
+	cmp.P = math.Min(1, 2*(1-stats.StdNormal.CDF(z)))
+}
@@ -0,0 +1,73 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWilcoxonSignedRank(t *testing.T) {
+	check := func(diffs []float64, wantP, wantEffect float64) {
+		t.Helper()
+		xs := make([]float64, len(diffs))
+		ys := make([]float64, len(diffs))
+		for i, d := range diffs {
+			xs[i] = 100 + float64(i)
+			ys[i] = xs[i] + d
+		}
+		var cmp Comparison
+		wilcoxonSignedRank(&cmp, xs, ys)
+		if math.Abs(cmp.P-wantP) > 1e-4 || math.Abs(cmp.Effect-wantEffect) > 1e-9 {
+			t.Errorf("%v: want P=%v effect=%v, got P=%v effect=%v", diffs, wantP, wantEffect, cmp.P, cmp.Effect)
+		}
+	}
+	// Exact distribution. These agree with R's wilcox.test.
+	check([]float64{1, 2, 3, 4, 5, 6}, 0.03125, 1)
+	check([]float64{-1, -2, -3, -4, -5, -6}, 0.03125, -1)
+	check([]float64{1, -2, 3, 4, 5, 6}, 0.09375, 17.0/21)
+	// Zero differences are dropped.
+	check([]float64{0, 0, 1, 2, 3, 4, 5, 6}, 0.03125, 1)
+	check([]float64{0, 0}, 1, 0)
+	// Ties use the normal approximation.
+	check([]float64{1, 1, 2, 2, -3, 4}, 0.2918, 11.0/21)
+}
+
+func TestComparePaired(t *testing.T) {
+	thr := DefaultThresholds
+	xs := []float64{10, 11, 12, 13, 14, 15}
+	ys := []float64{11, 13, 13, 15, 15, 17}
+
+	// Paired tests should see a difference that an unpaired test
+	// can't.
+	unpaired := AssumeNothing.Compare(NewSample(append([]float64(nil), xs...), &thr), NewSample(append([]float64(nil), ys...), &thr))
+	if unpaired.P < thr.CompareAlpha {
+		t.Fatalf("unpaired test unexpectedly significant: %v", unpaired)
+	}
+	for _, a := range []Assumption{AssumeNothing, AssumeNormal, AssumeLogNormal, AssumeBootstrap} {
+		cmp := ComparePaired(a, xs, ys, &thr)
+		if !(cmp.P < thr.CompareAlpha) || cmp.N1 != 6 || cmp.N2 != 6 || cmp.Warnings != nil {
+			t.Errorf("%T: want significant difference, got %+v", a, cmp)
+		}
+		if !(cmp.Effect > 0) {
+			t.Errorf("%T: want positive effect, got %v", a, cmp.Effect)
+		}
+	}
+	if xs[0] != 10 || ys[1] != 13 {
+		t.Errorf("ComparePaired modified its arguments")
+	}
+
+	checkComparison(t, ComparePaired(AssumeExact, xs, ys, &thr),
+		Comparison{P: 0, N1: 6, N2: 6, Alpha: 0.05})
+	checkComparison(t, ComparePaired(AssumeNothing, xs, ys[:5], &thr),
+		Comparison{P: 1, N1: 6, N2: 5, Alpha: 0.05},
+		"paired comparison requires equal sample sizes, but have 6 and 5")
+	checkComparison(t, ComparePaired(AssumeNothing, []float64{1, 2, 3}, []float64{2, 4, 6}, &thr),
+		Comparison{P: 0.25, N1: 3, N2: 3, Alpha: 0.05},
+		"need >= 6 pairs to detect a difference at alpha level 0.05")
+	checkComparison(t, ComparePaired(AssumeNormal, xs[:1], ys[:1], &thr),
+		Comparison{P: 1, N1: 1, N2: 1, Alpha: 0.05},
+		"sample is too small")
+}
@@ -0,0 +1,61 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+// PairwiseComparisons is the result of ComparePairwise.
+type PairwiseComparisons struct {
+	// Overall is the result of comparing all of the samples at
+	// once with CompareK. If it doesn't find a difference, any
+	// significant pairwise comparisons should be treated with
+	// suspicion.
+	Overall KComparison
+
+	// Comparisons[i][j] is the comparison of samples[j] with
+	// samples[i] as the base. It is the zero Comparison if i == j.
+	// Comparisons[i][j] and Comparisons[j][i] have the same P, but
+	// Effect and Ratio are relative to different bases.
+	Comparisons [][]Comparison
+}
+
+// ComparePairwise compares every pair of samples under assumption a.
+// This is useful when no single sample is a natural baseline, such as
+// when comparing several alternative implementations.
+//
+// Comparing k samples makes k(k-1)/2 comparisons, so ComparePairwise
+// adjusts their p-values as a family using correct, which is
+// typically HolmBonferroni, or BenjaminiHochberg for many samples. If
+// correct is nil, it doesn't adjust the p-values.
+func ComparePairwise(a Assumption, samples []*Sample, correct func([]*Comparison)) PairwiseComparisons {
+	k := len(samples)
+	res := PairwiseComparisons{
+		Overall:     CompareK(a, samples...),
+		Comparisons: make([][]Comparison, k),
+	}
+	for i := range res.Comparisons {
+		res.Comparisons[i] = make([]Comparison, k)
+	}
+
+	// Each pair is compared in both directions so Effect is
+	// relative to the right base, but only one direction counts
+	// as a member of the family.
+	var family []*Comparison
+	for i := 0; i < k; i++ {
+		for j := i + 1; j < k; j++ {
+			res.Comparisons[i][j] = a.Compare(samples[i], samples[j])
+			res.Comparisons[j][i] = a.Compare(samples[j], samples[i])
+			family = append(family, &res.Comparisons[i][j])
+		}
+	}
+	if correct != nil {
+		correct(family)
+	}
+	for i := 0; i < k; i++ {
+		for j := i + 1; j < k; j++ {
+			c, r := &res.Comparisons[i][j], &res.Comparisons[j][i]
+			r.P, r.RawP, r.Correction = c.P, c.RawP, c.Correction
+		}
+	}
+	return res
+}
@@ -0,0 +1,57 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import "testing"
+
+func TestComparePairwise(t *testing.T) {
+	s := func(xs ...float64) *Sample {
+		return NewSample(xs, &DefaultThresholds)
+	}
+	samples := []*Sample{
+		s(1, 2, 3, 4, 5, 6),
+		s(11, 12, 13, 14, 15, 16),
+		s(1.5, 2.5, 3.5, 4.5, 5.5, 6.5),
+	}
+
+	res := ComparePairwise(AssumeNothing, samples, HolmBonferroni)
+	if res.Overall.Test != KruskalWallisTest || res.Overall.P > 0.05 {
+		t.Errorf("overall: got %+v, want significant Kruskal-Wallis test", res.Overall)
+	}
+	for i := range samples {
+		if c := res.Comparisons[i][i]; c.P != 0 || c.N1 != 0 {
+			t.Errorf("[%d][%d]: got %+v, want zero Comparison", i, i, c)
+		}
+		for j := range samples {
+			if i == j {
+				continue
+			}
+			c, r := res.Comparisons[i][j], res.Comparisons[j][i]
+			if c.Correction != "holm" || c.P != r.P || c.RawP != r.RawP {
+				t.Errorf("[%d][%d]: got %+v and mirror %+v, want same corrected P", i, j, c, r)
+			}
+			if c.Effect != -r.Effect {
+				t.Errorf("[%d][%d]: got effect %v and mirror %v, want opposite", i, j, c.Effect, r.Effect)
+			}
+			// Only samples 0 and 2 are similar.
+			if want := i+j != 2; (c.P <= c.Alpha) != want {
+				t.Errorf("[%d][%d]: got P %v, want significant=%v", i, j, c.P, want)
+			}
+		}
+	}
+
+	// Holm-Bonferroni multiplies the smallest p-value by the
+	// family size, which is 3.
+	raw := AssumeNothing.Compare(samples[0], samples[1])
+	if c := res.Comparisons[0][1]; !aeq(c.RawP, raw.P) || !aeq(c.P, 3*raw.P) {
+		t.Errorf("[0][1]: got P %v RawP %v, want %v and %v", c.P, c.RawP, 3*raw.P, raw.P)
+	}
+
+	// Without a correction, P is unadjusted.
+	res = ComparePairwise(AssumeNothing, samples, nil)
+	if c := res.Comparisons[0][1]; c.Correction != "" || !aeq(c.P, raw.P) {
+		t.Errorf("uncorrected: got %+v, want P %v", c, raw.P)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/aclements/go-moremath/stats"
+)
+
+// DefaultPower is a conventional statistical power: the probability
+// of detecting a real difference of a given size.
+const DefaultPower = 0.8
+
+// relNoise returns the standard deviation of s relative to its mean.
+func relNoise(s *Sample) (float64, error) {
+	if len(s.Values) < 2 {
+		return 0, fmt.Errorf("need >= 2 samples to estimate variability")
+	}
+	mean := stats.Mean(s.Values)
+	if mean == 0 {
+		return 0, fmt.Errorf("cannot compute relative variability of sample with mean 0")
+	}
+	return math.Abs(stats.StdDev(s.Values) / mean), nil
+}
+
+// powerZ returns the sum of the standard normal quantiles for a
+// two-sided test at level alpha and the given power.
+func powerZ(alpha, power float64) float64 {
+	return stats.StdNormal.InvCDF(1-alpha/2) + stats.StdNormal.InvCDF(power)
+}
+
+// MinDetectableEffect returns the smallest relative change in the
+// center of a distribution, such as 0.05 for 5%, that a comparison of
+// two samples of n runs each can be expected to detect with the given
+// power, assuming both samples have the same variability as s. The
+// significance level is s.Thresholds.CompareAlpha.
+//
+// This is based on a normal approximation of a two-sample t-test
+// using the standard deviation of s. The tests used by AssumeNothing
+// have similar power for normally distributed samples, so this is a
+// reasonable guide for any Assumption, but it is only a guide.
+func MinDetectableEffect(s *Sample, n int, power float64) (float64, error) {
+	noise, err := relNoise(s)
+	if err != nil {
+		return 0, err
+	}
+	if n < 2 {
+		return math.Inf(1), nil
+	}
+	return powerZ(s.Thresholds.CompareAlpha, power) * noise * math.Sqrt(2/float64(n)), nil
+}
+
+// RunsToDetect returns the number of runs in each of two samples
+// needed to detect a relative change of effect in the center of a
+// distribution, such as 0.05 for 5%, with the given power, assuming
+// both samples have the same variability as s. The significance level
+// is s.Thresholds.CompareAlpha. The result is always at least 2.
+//
+// Like MinDetectableEffect, this is based on a normal approximation
+// and is only a guide.
+func RunsToDetect(s *Sample, effect, power float64) (int, error) {
+	if !(effect > 0) {
+		return 0, fmt.Errorf("effect must be positive")
+	}
+	noise, err := relNoise(s)
+	if err != nil {
+		return 0, err
+	}
+	z := powerZ(s.Thresholds.CompareAlpha, power)
+	n := math.Ceil(2 * (z * noise / effect) * (z * noise / effect))
+	if n < 2 {
+		n = 2
+	}
+	return int(n), nil
+}
+
+// RunsToResolve returns how many more runs in each of s1 and s2 would
+// be needed for a comparison to detect the currently observed
+// difference between their means with the given power, such as
+// DefaultPower. This lets tools suggest how many more runs would
+// confirm a change that isn't yet significant. The significance level
+// is s1.Thresholds.CompareAlpha. The result is 0 if the samples are
+// already large enough.
+//
+// This treats the observed difference and variabilities as the true
+// ones, so it's optimistic if the observed difference is an
+// overestimate, which is likely for a difference that isn't yet
+// significant. Like RunsToDetect, this is based on a normal
+// approximation and is only a guide.
+func RunsToResolve(s1, s2 *Sample, power float64) (int, error) {
+	if len(s1.Values) < 2 || len(s2.Values) < 2 {
+		return 0, fmt.Errorf("need >= 2 samples to estimate variability")
+	}
+	delta := stats.Mean(s2.Values) - stats.Mean(s1.Values)
+	if delta == 0 {
+		return 0, fmt.Errorf("observed difference is 0, so no number of runs can resolve it")
+	}
+	v := stats.Variance(s1.Values) + stats.Variance(s2.Values)
+	z := powerZ(s1.Thresholds.CompareAlpha, power)
+	need := math.Ceil(z * z * v / (delta * delta))
+	have := len(s1.Values)
+	if len(s2.Values) < have {
+		have = len(s2.Values)
+	}
+	if more := need - float64(have); more > 0 {
+		return int(more), nil
+	}
+	return 0, nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPower(t *testing.T) {
+	// This sample has mean 100 and standard deviation 2, so its
+	// relative noise is 2%.
+	s := NewSample([]float64{98, 100, 102, 98, 100, 102, 98, 100, 102, 100}, &DefaultThresholds)
+	noise := 2 * math.Sqrt(6.0/9) / 100
+
+	// At alpha 0.05 and power 0.8, z ≈ 1.96 + 0.84 = 2.80.
+	const z = 2.801585218
+	mde, err := MinDetectableEffect(s, 10, DefaultPower)
+	if want := z * noise * math.Sqrt(0.2); err != nil || math.Abs(mde-want) > 1e-6 {
+		t.Errorf("MinDetectableEffect: want %v, got %v, %v", want, mde, err)
+	}
+	// More runs should detect smaller changes.
+	if mde20, _ := MinDetectableEffect(s, 20, DefaultPower); !(mde20 < mde) {
+		t.Errorf("want smaller MDE with more runs, got %v >= %v", mde20, mde)
+	}
+
+	// RunsToDetect should be consistent with MinDetectableEffect.
+	// Nudge the effect up to avoid rounding up to 11.
+	n, err := RunsToDetect(s, mde*1.0001, DefaultPower)
+	if err != nil || n != 10 {
+		t.Errorf("RunsToDetect(%v): want 10, got %v, %v", mde, n, err)
+	}
+	n, err = RunsToDetect(s, 0.01, DefaultPower)
+	if want := int(math.Ceil(2 * math.Pow(z*noise/0.01, 2))); err != nil || n != want {
+		t.Errorf("RunsToDetect(1%%): want %v, got %v, %v", want, n, err)
+	}
+	if n, _ := RunsToDetect(s, 1, DefaultPower); n != 2 {
+		t.Errorf("RunsToDetect(100%%): want minimum of 2, got %v", n)
+	}
+
+	if _, err := RunsToDetect(s, 0, DefaultPower); err == nil {
+		t.Errorf("want error for zero effect")
+	}
+	one := NewSample([]float64{1}, &DefaultThresholds)
+	if _, err := MinDetectableEffect(one, 10, DefaultPower); err == nil || err.Error() != "need >= 2 samples to estimate variability" {
+		t.Errorf("want error for small sample, got %v", err)
+	}
+}
+
+func TestRunsToResolve(t *testing.T) {
+	xs := []float64{98, 100, 102, 98, 100, 102, 98, 100, 102, 100}
+	shifted := func(d float64) *Sample {
+		ys := make([]float64, len(xs))
+		for i, x := range xs {
+			ys[i] = x + d
+		}
+		return NewSample(ys, &DefaultThresholds)
+	}
+	s := shifted(0)
+
+	// Both samples have variance 8/3, so detecting a difference
+	// of 1 needs z²·(16/3) runs each.
+	const z = 2.801585218
+	n, err := RunsToResolve(s, shifted(1), DefaultPower)
+	if want := int(math.Ceil(z*z*16/3)) - 10; err != nil || n != want {
+		t.Errorf("shift 1: want %v, got %v, %v", want, n, err)
+	}
+	// The sign of the difference doesn't matter.
+	if n2, _ := RunsToResolve(shifted(1), s, DefaultPower); n2 != n {
+		t.Errorf("shift -1: want %v, got %v", n, n2)
+	}
+	// A large difference is already resolved.
+	if n, err := RunsToResolve(s, shifted(5), DefaultPower); err != nil || n != 0 {
+		t.Errorf("shift 5: want 0, got %v, %v", n, err)
+	}
+
+	if _, err := RunsToResolve(s, s, DefaultPower); err == nil {
+		t.Errorf("want error for zero difference")
+	}
+	one := NewSample([]float64{1}, &DefaultThresholds)
+	if _, err := RunsToResolve(one, s, DefaultPower); err == nil || err.Error() != "need >= 2 samples to estimate variability" {
+		t.Errorf("want error for small sample, got %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/aclements/go-moremath/stats"
+)
+
+// RatioCI returns a confidence interval for the ratio of the center of
+// s2 to the center of s1 under assumption a. In the result, Center is
+// the ratio of the summary statistics of the two samples, and Lo and
+// Hi are the bounds of the interval at the given confidence level.
+//
+// Under AssumeNormal, this uses the delta method. Under
+// AssumeLogNormal, this is the exponentiated Welch interval for the
+// difference of the log means. Otherwise, it bootstraps the ratio of
+// the assumption's summary statistic, or the median for AssumeNothing
+// and AssumeExact.
+//
+// Assigning the result to Comparison.Ratio lets
+// Comparison.FormatDeltaCI report the interval.
+func RatioCI(a Assumption, s1, s2 *Sample, confidence float64) Summary {
+	switch a := a.(type) {
+	case assumeNormal:
+		return normalRatioCI(s1, s2, confidence)
+	case assumeLogNormal:
+		return logNormalRatioCI(s1, s2, confidence)
+	case Bootstrap:
+		return a.RatioCI(s1, s2, confidence)
+	case Trimmed:
+		return bootstrapRatioCI(s1, s2, DefaultBootstrapResamples, a.stat, a.SummaryLabel(), confidence)
+	case Quantile:
+		return bootstrapRatioCI(s1, s2, DefaultBootstrapResamples, a.stat, a.SummaryLabel(), confidence)
+	}
+	return AssumeBootstrap.RatioCI(s1, s2, confidence)
+}
+
+func normalRatioCI(s1, s2 *Sample, confidence float64) Summary {
+	m1, m2 := moments(s1.weightedSample()), moments(s2.weightedSample())
+	r := m2.mean / m1.mean
+	summary := Summary{Center: r, Lo: math.Inf(-1), Hi: math.Inf(1), Confidence: confidence}
+	if m1.mean == 0 {
+		summary.Center = math.NaN()
+		summary.Warnings = []error{fmt.Errorf("ratio is undefined because base mean is 0")}
+		return summary
+	}
+	if m1.n < 2 || m2.n < 2 {
+		summary.Warnings = []error{fmt.Errorf("need >= 2 samples for confidence interval at level %v", confidence)}
+		return summary
+	}
+	// By the delta method, the relative variance of the ratio is
+	// approximately the sum of the relative variances of the
+	// means.
+	u1 := m1.variance / (m1.n * m1.mean * m1.mean)
+	u2 := m2.variance / (m2.n * m2.mean * m2.mean)
+	w := welchWidth(u1, u2, m1.n, m2.n, confidence) * math.Abs(r)
+	summary.Lo, summary.Hi = r-w, r+w
+	return summary
+}
+
+func logNormalRatioCI(s1, s2 *Sample, confidence float64) Summary {
+	logs1, err1 := logSample(s1)
+	logs2, err2 := logSample(s2)
+	if err1 != nil || err2 != nil {
+		summary := normalRatioCI(s1, s2, confidence)
+		for _, err := range []error{err2, err1} {
+			if err != nil {
+				summary.Warnings = append([]error{err}, summary.Warnings...)
+			}
+		}
+		return summary
+	}
+
+	m1, m2 := moments(logs1), moments(logs2)
+	d := m2.mean - m1.mean
+	summary := Summary{Center: math.Exp(d), Lo: 0, Hi: math.Inf(1), Confidence: confidence}
+	if m1.n < 2 || m2.n < 2 {
+		summary.Warnings = []error{fmt.Errorf("need >= 2 samples for confidence interval at level %v", confidence)}
+		return summary
+	}
+	w := welchWidth(m1.variance/m1.n, m2.variance/m2.n, m1.n, m2.n, confidence)
+	summary.Lo, summary.Hi = math.Exp(d-w), math.Exp(d+w)
+	return summary
+}
+
+// welchWidth returns the half-width of a confidence interval for the
+// difference of two means whose squared standard errors are v1 and
+// v2, using the Welch–Satterthwaite degrees of freedom for sample
+// sizes n1 and n2.
+func welchWidth(v1, v2, n1, n2, confidence float64) float64 {
+	if confidence <= 0 || v1+v2 == 0 {
+		return 0
+	} else if confidence >= 1 {
+		return math.Inf(1)
+	}
+	df := (v1 + v2) * (v1 + v2) / (v1*v1/(n1-1) + v2*v2/(n2-1))
+	t := -stats.InvCDF(stats.TDist{V: df})((1 - confidence) / 2)
+	return t * math.Sqrt(v1+v2)
+}
@@ -0,0 +1,80 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRatioCI(t *testing.T) {
+	xs := []float64{9, 10, 10.5, 11, 12}
+	scaled := func(k float64) *Sample {
+		ys := make([]float64, len(xs))
+		for i, x := range xs {
+			ys[i] = k * x
+		}
+		return NewSample(ys, &DefaultThresholds)
+	}
+	s1, s2 := scaled(1), scaled(2)
+
+	// The normal interval is symmetric around the ratio.
+	got := RatioCI(AssumeNormal, s1, s2, 0.95)
+	if !aeq(got.Center, 2) || !(got.Lo < 2 && 2 < got.Hi) || !aeq(got.Hi-2, 2-got.Lo) {
+		t.Errorf("normal: got %+v, want symmetric interval around 2", got)
+	}
+
+	// The log-normal interval is multiplicatively symmetric.
+	got = RatioCI(AssumeLogNormal, s1, s2, 0.95)
+	if !aeq(got.Center, 2) || !(got.Lo < 2 && 2 < got.Hi) || !aeq(got.Lo*got.Hi, 4) {
+		t.Errorf("lognormal: got %+v, want multiplicatively symmetric interval around 2", got)
+	}
+	// And it's exact for a constant shift in log space.
+	got = RatioCI(AssumeLogNormal, s1, s1, 0.95)
+	if !aeq(got.Center, 1) {
+		t.Errorf("lognormal: got ratio %v, want 1", got.Center)
+	}
+
+	// AssumeNothing bootstraps the median.
+	got, want := RatioCI(AssumeNothing, s1, s2, 0.95), AssumeBootstrap.RatioCI(s1, s2, 0.95)
+	checkSummary(t, got, want)
+	if got.Center != 2 {
+		t.Errorf("nothing: got ratio %v, want 2", got.Center)
+	}
+
+	// Trimmed and quantile assumptions bootstrap their own
+	// statistic.
+	for _, a := range []Assumption{AssumeTrimmed, Quantile{0.9}} {
+		got = RatioCI(a, s1, s2, 0.95)
+		if !aeq(got.Center, 2) || !(got.Lo <= 2 && 2 <= got.Hi) {
+			t.Errorf("%s: got %+v, want interval around 2", a.SummaryLabel(), got)
+		}
+	}
+
+	// Small and degenerate samples.
+	one := NewSample([]float64{1}, &DefaultThresholds)
+	checkSummary(t, RatioCI(AssumeNormal, one, s2, 0.95), Summary{Center: 21, Lo: math.Inf(-1), Hi: math.Inf(1), Confidence: 0.95}, "need >= 2 samples for confidence interval at level 0.95")
+	zero := NewSample([]float64{0, 0}, &DefaultThresholds)
+	got = RatioCI(AssumeNormal, zero, s2, 0.95)
+	if !math.IsNaN(got.Center) || len(got.Warnings) != 1 || got.Warnings[0].Error() != "ratio is undefined because base mean is 0" {
+		t.Errorf("zero base: got %+v", got)
+	}
+}
+
+func TestFormatDeltaCI(t *testing.T) {
+	c := Comparison{P: 0.01, Alpha: 0.05}
+	if got, want := c.FormatDeltaCI(100, 83), "-17.00%"; got != want {
+		t.Errorf("without ratio: got %s, want %s", got, want)
+	}
+	c.Ratio = Summary{Center: 0.83, Lo: 0.81, Hi: 0.85, Confidence: 0.95}
+	if got, want := c.FormatDeltaCI(100, 83), "-17.00% [-19.00%, -15.00%]"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	c.P = 0.5
+	c.Ratio.Lo, c.Ratio.Hi = math.Inf(-1), math.Inf(1)
+	if got, want := c.FormatDeltaCI(100, 83), "~ [-∞, +∞]"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
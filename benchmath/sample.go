@@ -25,9 +25,36 @@ import (
 
 // A Sample is a set of repeated measurements of a given benchmark.
 type Sample struct {
-	// Values are the measured values, in ascending order.
+	// Values are the measured values, in ascending order. If
+	// Thresholds.TrimOutliers is set, this excludes any outliers.
 	Values []float64
 
+	// Outliers are the values detected as outliers according to
+	// Thresholds.Outliers, in ascending order. If
+	// Thresholds.TrimOutliers is set, these values are not
+	// included in Values.
+	Outliers []float64
+
+	// Weights are the weights of each value in Values, or nil if
+	// all values have equal weight. See NewWeightedSample.
+	Weights []float64
+
+	// Censored indicates which values in Values are censored,
+	// meaning the true value is only known to be at least the
+	// recorded value, or is nil if no values are censored. See
+	// NewCensoredSample.
+	Censored []bool
+
+	// Runs are the positions in the overall run order at which
+	// the measurements of this sample were taken, or nil if the
+	// run order is unknown. Unlike Values, Runs are in no
+	// particular order and aren't trimmed with outliers, since
+	// they describe how the sample was collected. Constructors
+	// leave Runs nil; callers that know the run order may set it.
+	// If both samples of a comparison have Runs, the Comparison
+	// warns if their runs weren't interleaved.
+	Runs []int
+
 	// Thresholds stores the statistical thresholds used by tests
 	// on this sample.
 	Thresholds *Thresholds
@@ -37,14 +64,49 @@ type Sample struct {
 	Warnings []error
 }
 
-// NewSample constructs a Sample from a set of measurements.
+// NewSample constructs a Sample from a set of measurements. It detects
+// outliers as configured by t. If t.DriftAlpha is set, values must be
+// in the order they were measured, and NewSample checks that they
+// don't drift over the runs. NewSample takes ownership of values and
+// sorts it in place.
 func NewSample(values []float64, t *Thresholds) *Sample {
-	// TODO: Analyze stationarity and put results in Warnings.
-	// Consider Augmented Dickey–Fuller (based on Maricq et al.)
+	// This must happen before sorting, which loses the run order.
+	drift := driftWarnings(values, t)
 
 	// Sort values for fast order statistics.
 	sort.Float64s(values)
-	return &Sample{values, t, nil}
+	s := newSample(values, nil, t)
+	s.Warnings = append(drift, s.Warnings...)
+	return s
+}
+
+// newSample constructs a Sample from sorted values and their weights,
+// which may be nil.
+func newSample(values, weights []float64, t *Thresholds) *Sample {
+	s := &Sample{Values: values, Weights: weights, Thresholds: t}
+
+	if t != nil && t.Outliers != OutliersNone {
+		inliers, outliers := findOutliers(values, t)
+		if len(outliers) > 0 {
+			s.Outliers = outliers
+			if t.TrimOutliers {
+				s.Values = inliers
+				if weights != nil {
+					i, j := inlierRange(values, t)
+					s.Weights = weights[i:j]
+				}
+				s.Warnings = append(s.Warnings, fmt.Errorf("excluded %d of %d values as outliers (%s)", len(outliers), len(values), t.Outliers))
+			} else {
+				s.Warnings = append(s.Warnings, fmt.Errorf("%d of %d values are outliers (%s)", len(outliers), len(values), t.Outliers))
+			}
+		}
+	}
+	if t != nil {
+		if err := checkBimodal(s.Values, t); err != nil {
+			s.Warnings = append(s.Warnings, err)
+		}
+	}
+	return s
 }
 
 func (s *Sample) sample() stats.Sample {
@@ -62,6 +124,51 @@ type Thresholds struct {
 	//
 	// This is typically 0.05.
 	CompareAlpha float64
+
+	// ShapeAlpha is the alpha level below which CompareShape
+	// rejects the null hypothesis that two samples come from the
+	// same distribution. If this is positive, Assumption.Compare
+	// also performs this test and adds a warning to the
+	// Comparison if it finds no difference in the centers of the
+	// samples, but does find a difference in their shapes. If 0,
+	// Assumption.Compare does not compare shapes.
+	ShapeAlpha float64
+
+	// Outliers is the method NewSample uses to detect outliers.
+	// If outliers are detected, NewSample records them in
+	// Sample.Outliers and adds a warning to the Sample.
+	Outliers OutlierMethod
+
+	// OutlierK is the multiplier that determines how far from the
+	// bulk of a sample a value must be to be an outlier. Its
+	// meaning depends on Outliers. If 0, a method-specific
+	// default is used.
+	OutlierK float64
+
+	// TrimOutliers, if true, causes NewSample to exclude
+	// outliers from Sample.Values, so they don't affect summaries
+	// or comparisons.
+	TrimOutliers bool
+
+	// Bimodality is the separation above which NewSample warns
+	// that a sample appears to have two modes, such as when a
+	// benchmark flips between two code layouts. A median or mean
+	// of such a sample describes neither mode. The sample is split
+	// into the two clusters that maximize the between-cluster
+	// variance, and the separation is Ashman's D between them,
+	// which is above 2 for a clean separation. Samples with fewer
+	// than 10 values aren't checked. If 0, NewSample doesn't check
+	// for bimodality.
+	//
+	// A value of 8 rarely flags unimodal samples, even skewed
+	// ones. This check is off by default.
+	Bimodality float64
+
+	// DriftAlpha is the alpha level below which NewSample warns
+	// that the values of a sample are not independent of the
+	// order they were measured in. See CheckDrift. If 0,
+	// NewSample doesn't check for drift.
+	DriftAlpha float64
 }
 
 // Note: Thresholds exists so we can extend it in the future with
@@ -73,6 +180,30 @@ var DefaultThresholds = Thresholds{
 	CompareAlpha: 0.05,
 }
 
+// UnitThresholds configures Thresholds separately for each unit. This
+// lets exact, noisy, and latency units have different sensitivities
+// in one analysis.
+type UnitThresholds struct {
+	// Default is the Thresholds for units that don't appear in
+	// Units. If nil, Get uses DefaultThresholds.
+	Default *Thresholds
+
+	// Units maps from unit name, such as "sec/op", to the
+	// Thresholds for that unit.
+	Units map[string]*Thresholds
+}
+
+// Get returns the Thresholds for the given unit.
+func (u *UnitThresholds) Get(unit string) *Thresholds {
+	if t, ok := u.Units[unit]; ok && t != nil {
+		return t
+	}
+	if u.Default != nil {
+		return u.Default
+	}
+	return &DefaultThresholds
+}
+
 // An Assumption indicates a distributional assumption about a sample.
 type Assumption interface {
 	// SummaryLabel returns the string name for the summary
@@ -106,6 +237,12 @@ type Summary struct {
 	// confidence level.
 	Confidence float64
 
+	// Trim is the fraction of values that were trimmed or
+	// winsorized from each end of the sample to compute Center,
+	// or 0 if the summary uses all values as they are. See
+	// Trimmed.
+	Trim float64
+
 	// Warnings is a list of warnings about this summary or its
 	// confidence interval.
 	Warnings []error
@@ -156,17 +293,68 @@ type Comparison struct {
 	// from the same distribution.
 	Alpha float64
 
+	// Correction is the name of the multiple comparison
+	// correction that has been applied to P, or "" if P has not
+	// been adjusted. See HolmBonferroni and BenjaminiHochberg.
+	Correction string
+
+	// RawP is the p-value before any multiple comparison
+	// correction. It is only meaningful if Correction is not "".
+	RawP float64
+
+	// Effect is an estimate of the size of the difference between
+	// the two samples, using the measure given by EffectMeasure.
+	// Unlike P, this doesn't shrink as the sample sizes grow, so
+	// it distinguishes changes that are statistically significant
+	// but tiny from practically meaningful ones. It is positive
+	// if the second sample tends to be larger than the first.
+	Effect float64
+
+	// EffectMeasure is the effect size measure used for Effect,
+	// such as EffectCliffsDelta or EffectCohensD, or "" if this
+	// comparison has no effect size.
+	EffectMeasure string
+
+	// Test is the name of the statistical test that computed P,
+	// such as UTest, or "" if no test was performed.
+	Test string
+
+	// Statistic is the value of Test's test statistic. Its
+	// meaning depends on Test.
+	Statistic float64
+
+	// DF is the degrees of freedom of the distribution of
+	// Statistic, or 0 if Test doesn't have degrees of freedom.
+	DF float64
+
+	// Exact indicates that P was computed from the exact null
+	// distribution of Statistic, rather than an approximation
+	// such as a normal approximation or resampling.
+	Exact bool
+
+	// Ratio is the ratio of the center of the second sample to
+	// the center of the first, with its confidence interval.
+	// Comparisons don't compute this themselves; callers that
+	// want it should set it using RatioCI. Its Confidence is 0 if
+	// it hasn't been set.
+	Ratio Summary
+
 	// Warnings is a list of warnings about this comparison
 	// result.
 	Warnings []error
 }
 
 // String summarizes the comparison. The general form of this string
-// is "p=0.PPP n=N1+N2" but can be shortened.
+// is "p=0.PPP n=N1+N2" but can be shortened. If P is a q-value from
+// BenjaminiHochberg, it is reported as "q=0.QQQ".
 func (c Comparison) String() string {
 	var s string
 	if c.P != 0 {
-		s = fmt.Sprintf("p=%0.3f ", c.P)
+		label := "p"
+		if c.Correction == "fdr" {
+			label = "q"
+		}
+		s = fmt.Sprintf("%s=%0.3f ", label, c.P)
 	}
 	if c.N1 == c.N2 {
 		// Slightly shorter form for a common case.
@@ -194,3 +382,34 @@ func (c Comparison) FormatDelta(old, new float64) string {
 	pct := ((new / old) - 1.0) * 100.0
 	return fmt.Sprintf("%+.2f%%", pct)
 }
+
+// FormatDeltaCI is like FormatDelta, but also reports the confidence
+// interval of c.Ratio as a percent change, such as
+// "-17.20% [-19.10%, -15.00%]". If c.Ratio hasn't been set, this is the
+// same as FormatDelta.
+func (c Comparison) FormatDeltaCI(old, new float64) string {
+	d := c.FormatDelta(old, new)
+	if c.Ratio.Confidence == 0 {
+		return d
+	}
+	return d + " " + c.Ratio.PctDeltaRangeString()
+}
+
+// PctDeltaRangeString returns a string representation of the
+// confidence interval of a Summary of a ratio, such as
+// Comparison.Ratio, as a range of percent changes, such as
+// "[-19.10%, -15.00%]".
+func (s Summary) PctDeltaRangeString() string {
+	pct := func(r float64) string {
+		switch {
+		case math.IsNaN(r):
+			return "?"
+		case math.IsInf(r, 1):
+			return "+∞"
+		case math.IsInf(r, -1):
+			return "-∞"
+		}
+		return fmt.Sprintf("%+.2f%%", (r-1)*100)
+	}
+	return fmt.Sprintf("[%s, %s]", pct(s.Lo), pct(s.Hi))
+}
@@ -53,6 +53,10 @@ func TestComparisonFormat(t *testing.T) {
 	check(0, 1, 2, "n=1+2")
 	check(0, 2, 2, "n=2")
 
+	if got, want := (Comparison{P: 0.5, N1: 2, N2: 2, Correction: "fdr"}).String(), "q=0.500 n=2"; got != want {
+		t.Errorf("for q-value, got %s, want %s", got, want)
+	}
+
 	checkD := func(p, old, new, alpha float64, want string) {
 		got := Comparison{P: p, Alpha: alpha}.FormatDelta(old, new)
 		if got != want {
@@ -66,3 +70,22 @@ func TestComparisonFormat(t *testing.T) {
 	checkD(0.01, 1, 1.5, 0.05, "+50.00%")
 	checkD(0.01, 1, 0.5, 0.05, "-50.00%")
 }
+
+func TestUnitThresholds(t *testing.T) {
+	strict := DefaultThresholds
+	strict.CompareAlpha = 0.01
+	def := DefaultThresholds
+	def.CompareAlpha = 0.1
+
+	ut := UnitThresholds{Units: map[string]*Thresholds{"sec/op": &strict}}
+	if got := ut.Get("sec/op"); got != &strict {
+		t.Errorf("sec/op: got %+v, want %+v", got, strict)
+	}
+	if got := ut.Get("B/op"); got != &DefaultThresholds {
+		t.Errorf("B/op: got %+v, want DefaultThresholds", got)
+	}
+	ut.Default = &def
+	if got := ut.Get("B/op"); got != &def {
+		t.Errorf("B/op with default: got %+v, want %+v", got, def)
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/aclements/go-moremath/stats"
+)
+
+// A ScalingFit is the result of FitScaling. It describes how a
+// measurement y scales with a parameter x as y ≈ Coefficient ·
+// x^Exponent.
+type ScalingFit struct {
+	// Exponent is the estimated scaling exponent, with its
+	// confidence interval. For example, an exponent of 1 means the
+	// measurement scales linearly with the parameter, and 2 means
+	// it scales quadratically.
+	Exponent Summary
+
+	// Coefficient is the estimated constant factor.
+	Coefficient float64
+
+	// R2 is the coefficient of determination of the fit in
+	// log-log space. Values close to 1 indicate that a power law
+	// describes the measurements well; lower values suggest that
+	// the scaling changes over the range of the parameter, such as
+	// when a working set outgrows a cache.
+	R2 float64
+
+	// Warnings is a list of warnings about this fit that should
+	// be reported to the user.
+	Warnings []error
+}
+
+// String returns the fit as a complexity, such as "O(n^1.32)".
+func (f ScalingFit) String() string {
+	return fmt.Sprintf("O(n^%.2f)", f.Exponent.Center)
+}
+
+// FitScaling estimates how the measurements in samples scale with a
+// numeric parameter, such as the input size of a benchmark. xs[i] is
+// the value of the parameter for samples[i], and all of xs and the
+// values of samples must be positive.
+//
+// FitScaling fits a line to the logs of the parameters and the logs
+// of all of the measured values using least squares, so the slope of
+// the line is the scaling exponent. The confidence interval of the
+// exponent assumes the log values are normally distributed around the
+// line.
+//
+// Over a limited range of parameters, logarithmic factors look like
+// small increases in the exponent. For example, n log n from n=1k to
+// n=1M fits an exponent of about 1.1.
+func FitScaling(xs []float64, samples []*Sample, confidence float64) ScalingFit {
+	fit := ScalingFit{
+		Exponent:    Summary{Center: math.NaN(), Lo: math.Inf(-1), Hi: math.Inf(1), Confidence: confidence},
+		Coefficient: math.NaN(),
+		R2:          math.NaN(),
+	}
+	if len(xs) != len(samples) {
+		panic(fmt.Sprintf("%d parameters, but %d samples", len(xs), len(samples)))
+	}
+
+	var lx, ly []float64
+	distinct := make(map[float64]bool)
+	for i, s := range samples {
+		if !(xs[i] > 0) {
+			fit.Warnings = append(fit.Warnings, fmt.Errorf("parameters must be >0 to fit scaling, but got %v", xs[i]))
+			return fit
+		}
+		for _, y := range s.Values {
+			if !(y > 0) {
+				fit.Warnings = append(fit.Warnings, fmt.Errorf("values must be >0 to fit scaling, but got %v", y))
+				return fit
+			}
+			lx = append(lx, math.Log(xs[i]))
+			ly = append(ly, math.Log(y))
+		}
+		if len(s.Values) > 0 {
+			distinct[xs[i]] = true
+		}
+	}
+	if len(distinct) < 2 {
+		fit.Warnings = append(fit.Warnings, fmt.Errorf("need >= 2 distinct parameters to fit scaling"))
+		return fit
+	}
+
+	// Least squares fit of ly = a + b lx.
+	n := float64(len(lx))
+	mx, my := stats.Mean(lx), stats.Mean(ly)
+	var sxx, sxy, syy float64
+	for i := range lx {
+		dx, dy := lx[i]-mx, ly[i]-my
+		sxx += dx * dx
+		sxy += dx * dy
+		syy += dy * dy
+	}
+	b := sxy / sxx
+	a := my - b*mx
+	fit.Exponent.Center = b
+	fit.Coefficient = math.Exp(a)
+	sse := syy - b*sxy
+	if sse < 0 {
+		// Rounding error in a perfect fit.
+		sse = 0
+	}
+	if syy > 0 {
+		fit.R2 = 1 - sse/syy
+	} else {
+		fit.R2 = 1
+	}
+
+	if n <= 2 {
+		fit.Warnings = append(fit.Warnings, fmt.Errorf("need >= 3 values for confidence interval at level %v", confidence))
+		return fit
+	}
+	var w float64
+	if confidence >= 1 {
+		w = math.Inf(1)
+	} else if confidence > 0 {
+		se := math.Sqrt(sse / (n - 2) / sxx)
+		w = -stats.InvCDF(stats.TDist{V: n - 2})((1-confidence)/2) * se
+	}
+	fit.Exponent.Lo, fit.Exponent.Hi = b-w, b+w
+	return fit
+}
@@ -0,0 +1,60 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFitScaling(t *testing.T) {
+	// y = 3·x^1.5 with multiplicative noise.
+	xs := []float64{10, 100, 1000, 10000}
+	var samples []*Sample
+	for _, x := range xs {
+		y := 3 * math.Pow(x, 1.5)
+		samples = append(samples, NewSample([]float64{y / 1.01, y, y * 1.01}, &DefaultThresholds))
+	}
+	fit := FitScaling(xs, samples, 0.95)
+	if !aeq(fit.Exponent.Center, 1.5) || !(math.Abs(fit.Coefficient-3) < 1e-6) {
+		t.Errorf("got exponent %v coefficient %v, want 1.5 and 3", fit.Exponent.Center, fit.Coefficient)
+	}
+	if !(fit.Exponent.Lo < 1.5 && 1.5 < fit.Exponent.Hi && fit.Exponent.Hi-fit.Exponent.Lo < 0.01) {
+		t.Errorf("got interval [%v, %v], want narrow interval around 1.5", fit.Exponent.Lo, fit.Exponent.Hi)
+	}
+	if !(fit.R2 > 0.9999) || len(fit.Warnings) != 0 {
+		t.Errorf("got R2 %v warnings %v, want near-perfect fit", fit.R2, fit.Warnings)
+	}
+	if got, want := fit.String(), "O(n^1.50)"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	// n log n looks slightly superlinear.
+	samples = nil
+	for _, x := range xs {
+		samples = append(samples, NewSample([]float64{x * math.Log(x)}, &DefaultThresholds))
+	}
+	if fit := FitScaling(xs, samples, 0.95); !(fit.Exponent.Center > 1.05 && fit.Exponent.Center < 1.2) {
+		t.Errorf("n log n: got exponent %v, want about 1.1", fit.Exponent.Center)
+	}
+
+	// Errors.
+	one := []*Sample{NewSample([]float64{1, 2}, &DefaultThresholds), NewSample([]float64{3}, &DefaultThresholds)}
+	for _, test := range []struct {
+		xs      []float64
+		samples []*Sample
+		want    string
+	}{
+		{[]float64{1, 1}, one, "need >= 2 distinct parameters to fit scaling"},
+		{[]float64{0, 1}, one, "parameters must be >0 to fit scaling, but got 0"},
+		{[]float64{1, 2}, []*Sample{one[0], NewSample([]float64{-1}, &DefaultThresholds)}, "values must be >0 to fit scaling, but got -1"},
+		{[]float64{1, 2}, []*Sample{one[1], one[1]}, "need >= 3 values for confidence interval at level 0.95"},
+	} {
+		fit := FitScaling(test.xs, test.samples, 0.95)
+		if len(fit.Warnings) != 1 || fit.Warnings[0].Error() != test.want {
+			t.Errorf("FitScaling(%v): got warnings %v, want %s", test.xs, fit.Warnings, test.want)
+		}
+	}
+}
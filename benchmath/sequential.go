@@ -0,0 +1,180 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/aclements/go-moremath/stats"
+)
+
+// A SequentialDecision is the decision of a Sequential test after
+// some number of runs.
+type SequentialDecision int
+
+const (
+	// SequentialContinue indicates that there isn't enough evidence
+	// to decide yet, so the runner should collect more runs.
+	SequentialContinue SequentialDecision = iota
+
+	// SequentialDifferent indicates that the two streams differ.
+	SequentialDifferent
+
+	// SequentialEquivalent indicates that the two streams are
+	// equivalent within the margin.
+	SequentialEquivalent
+)
+
+// String returns the name of d, such as "continue".
+func (d SequentialDecision) String() string {
+	switch d {
+	case SequentialContinue:
+		return "continue"
+	case SequentialDifferent:
+		return "different"
+	case SequentialEquivalent:
+		return "equivalent"
+	}
+	return fmt.Sprintf("SequentialDecision(%d)", int(d))
+}
+
+// defaultSequentialEffect is the relative effect a Sequential test is
+// tuned for if none is given.
+const defaultSequentialEffect = 0.05
+
+// minSequentialSamples is the number of values each stream needs
+// before a Sequential test estimates its variance. With fewer, a
+// chance run of similar values can make the variance look tiny.
+const minSequentialSamples = 3
+
+// A Sequential is an anytime-valid test of whether two streams of
+// measurements differ, for benchmark runners that alternate runs of
+// an old and a new version and want to stop as soon as the answer is
+// clear. Unlike Assumption.Compare, which is only valid if the number
+// of runs is fixed in advance, a Sequential test's result may be
+// checked after every run without inflating its false-positive rate.
+//
+// Sequential uses the mixture sequential probability ratio test
+// (mSPRT) on the logs of the measurements, so, like AssumeLogNormal,
+// it compares geometric means, and the corresponding confidence
+// sequence bounds their ratio. The variance of each stream is
+// estimated from the measurements so far, so the guarantees are
+// approximate, but close once each stream has more than a few
+// values.
+type Sequential struct {
+	alpha, tau, margin float64
+
+	logs [2][]float64
+
+	// p is the running always-valid p-value, and lo and hi are the
+	// running confidence sequence of the log ratio.
+	p, lo, hi float64
+}
+
+// A SequentialResult is the state of a Sequential test after some
+// number of runs.
+type SequentialResult struct {
+	// Decision is whether the runner should stop, and why.
+	Decision SequentialDecision
+
+	// P is the always-valid p-value of the null hypothesis that
+	// the two streams have the same geometric mean. It never
+	// increases as runs are added.
+	P float64
+
+	// Ratio is the ratio of the geometric mean of the second
+	// stream to that of the first, with a confidence interval that
+	// holds simultaneously over all numbers of runs.
+	Ratio Summary
+
+	// N1 and N2 are the number of values in each stream.
+	N1, N2 int
+}
+
+// NewSequential returns a new Sequential test with false-positive rate
+// alpha.
+//
+// effect is the relative difference the test is most sensitive to,
+// such as 0.05 for 5%. The test detects any difference eventually,
+// but detects differences near effect with the fewest runs. If effect
+// is 0, it defaults to 0.05.
+//
+// If margin is greater than 0, the test also stops when it concludes
+// the geometric means are within a relative margin of each other,
+// such as 0.02 for ±2%. Otherwise, it only stops when the streams
+// differ.
+func NewSequential(alpha, effect, margin float64) *Sequential {
+	if effect <= 0 {
+		effect = defaultSequentialEffect
+	}
+	return &Sequential{
+		alpha:  alpha,
+		tau:    math.Log(1 + effect),
+		margin: margin,
+		p:      1,
+		lo:     math.Inf(-1),
+		hi:     math.Inf(1),
+	}
+}
+
+// Add adds a measurement x to stream i, which must be 0 or 1, and
+// updates the test. x must be positive.
+func (q *Sequential) Add(i int, x float64) error {
+	if i != 0 && i != 1 {
+		panic(fmt.Sprintf("stream must be 0 or 1, got %d", i))
+	}
+	if !(x > 0) {
+		return fmt.Errorf("sequential test requires positive values, but got %v", x)
+	}
+	q.logs[i] = append(q.logs[i], math.Log(x))
+
+	n1, n2 := float64(len(q.logs[0])), float64(len(q.logs[1]))
+	if n1 < minSequentialSamples || n2 < minSequentialSamples {
+		return nil
+	}
+	m1, v1 := meanVar(q.logs[0])
+	m2, v2 := meanVar(q.logs[1])
+	d := m2 - m1
+	v := v1/n1 + v2/n2
+	if v == 0 {
+		// Both streams are constant so far.
+		if d != 0 {
+			q.p, q.lo, q.hi = 0, d, d
+		}
+		return nil
+	}
+
+	// The mixture likelihood ratio of d with a normal mixing
+	// distribution with standard deviation tau.
+	t2 := q.tau * q.tau
+	logLR := 0.5*math.Log(v/(v+t2)) + t2*d*d/(2*v*(v+t2))
+	q.p = math.Min(q.p, math.Exp(-logLR))
+
+	// The confidence sequence is the set of differences the test
+	// wouldn't reject. Intersecting it over time keeps it valid.
+	w := math.Sqrt(v * (v + t2) / t2 * (math.Log((v+t2)/v) - 2*math.Log(q.alpha)))
+	q.lo, q.hi = math.Max(q.lo, d-w), math.Min(q.hi, d+w)
+	return nil
+}
+
+// Result returns the current state of the test.
+func (q *Sequential) Result() SequentialResult {
+	r := SequentialResult{
+		P:     q.p,
+		Ratio: Summary{Center: math.NaN(), Lo: math.Exp(q.lo), Hi: math.Exp(q.hi), Confidence: 1 - q.alpha},
+		N1:    len(q.logs[0]),
+		N2:    len(q.logs[1]),
+	}
+	if r.N1 > 0 && r.N2 > 0 {
+		r.Ratio.Center = math.Exp(stats.Mean(q.logs[1]) - stats.Mean(q.logs[0]))
+	}
+	if q.p <= q.alpha {
+		r.Decision = SequentialDifferent
+	} else if q.margin > 0 && r.Ratio.Lo >= 1-q.margin && r.Ratio.Hi <= 1+q.margin {
+		r.Decision = SequentialEquivalent
+	}
+	return r
+}
@@ -0,0 +1,89 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSequential(t *testing.T) {
+	// run alternates runs of two streams, where the second is
+	// scaled by ratio, until the test decides or max runs.
+	run := func(ratio float64, margin float64, max int) (SequentialResult, int) {
+		q := NewSequential(0.05, 0.05, margin)
+		r := rand.New(rand.NewSource(1))
+		lastP := 1.0
+		for i := 0; i < max; i++ {
+			q.Add(0, 100*(1+0.02*r.NormFloat64()))
+			q.Add(1, ratio*100*(1+0.02*r.NormFloat64()))
+			res := q.Result()
+			if res.P > lastP {
+				t.Fatalf("P increased from %v to %v", lastP, res.P)
+			}
+			lastP = res.P
+			if res.Decision == SequentialDifferent && res.Ratio.Lo <= 1 && 1 <= res.Ratio.Hi {
+				t.Fatalf("decided different, but ratio interval %v contains 1", res.Ratio)
+			}
+			if res.Decision != SequentialContinue {
+				return res, i + 1
+			}
+		}
+		return q.Result(), max
+	}
+
+	// A 10% difference is detected quickly.
+	res, n := run(1.1, 0, 100)
+	if res.Decision != SequentialDifferent || n > 10 {
+		t.Errorf("10%% difference: got %v after %d runs, want different within 10 runs", res.Decision, n)
+	}
+	if !(res.Ratio.Lo > 1 && res.Ratio.Center > 1.05) {
+		t.Errorf("10%% difference: got ratio %v", res.Ratio)
+	}
+
+	// With no difference, peeking after every run doesn't produce
+	// a false positive, and the test eventually concludes
+	// equivalence.
+	res, _ = run(1, 0, 200)
+	if res.Decision != SequentialContinue || res.N1 != 200 {
+		t.Errorf("no difference: got %v after %d runs, want continue", res.Decision, res.N1)
+	}
+	res, _ = run(1, 0.02, 200)
+	if res.Decision != SequentialEquivalent {
+		t.Errorf("no difference with margin: got %v, want equivalent", res.Decision)
+	}
+}
+
+func TestSequentialFalsePositives(t *testing.T) {
+	// Peeking after every run should keep the false-positive rate
+	// near alpha.
+	const trials, runs = 200, 50
+	r := rand.New(rand.NewSource(1))
+	fp := 0
+	for i := 0; i < trials; i++ {
+		q := NewSequential(0.05, 0.05, 0)
+		for j := 0; j < runs; j++ {
+			q.Add(0, 100*(1+0.02*r.NormFloat64()))
+			q.Add(1, 100*(1+0.02*r.NormFloat64()))
+			if q.Result().Decision == SequentialDifferent {
+				fp++
+				break
+			}
+		}
+	}
+	if fp > trials/10 {
+		t.Errorf("got %d false positives in %d trials, want about %d", fp, trials, trials/20)
+	}
+}
+
+func TestSequentialErrors(t *testing.T) {
+	q := NewSequential(0.05, 0, 0)
+	if err := q.Add(0, 0); err == nil {
+		t.Errorf("Add(0, 0): want error")
+	}
+	if res := q.Result(); res.N1 != 0 || res.P != 1 || res.Decision != SequentialContinue {
+		t.Errorf("after error: got %+v", res)
+	}
+}
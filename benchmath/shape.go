@@ -0,0 +1,98 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"fmt"
+	"math"
+)
+
+// CompareShape tests whether s1 and s2 come from the same
+// distribution using the two-sample Kolmogorov–Smirnov test.
+//
+// Unlike Assumption.Compare, which tests for a difference in the
+// centers of two samples, this is sensitive to any difference in the
+// distributions, including changes in variance, tails, or modality
+// that leave the center unchanged. It is less powerful than
+// Assumption.Compare at detecting changes in center.
+//
+// The resulting Comparison uses s1.Thresholds.ShapeAlpha as its
+// Alpha.
+func CompareShape(s1, s2 *Sample) Comparison {
+	d, p := ksTest(s1.Values, s2.Values)
+	return Comparison{P: p, N1: len(s1.Values), N2: len(s2.Values), Alpha: s1.Thresholds.ShapeAlpha, Test: KSTest, Statistic: d}
+}
+
+// addCompareWarnings adds warnings to cmp about the design of the
+// comparison of s1 and s2 and about differences its test can't see.
+func addCompareWarnings(cmp *Comparison, s1, s2 *Sample) {
+	if err := interleaved(s1, s2); err != nil {
+		cmp.Warnings = append(cmp.Warnings, err)
+	}
+	addShapeWarning(cmp, s1, s2)
+}
+
+// addShapeWarning adds a warning to cmp if it found no difference
+// between s1 and s2, but their distributions differ according to
+// CompareShape. This is a no-op if shape comparison is disabled.
+func addShapeWarning(cmp *Comparison, s1, s2 *Sample) {
+	if s1.Thresholds.ShapeAlpha <= 0 || cmp.P <= cmp.Alpha {
+		return
+	}
+	shape := CompareShape(s1, s2)
+	if shape.P < shape.Alpha {
+		cmp.Warnings = append(cmp.Warnings, fmt.Errorf("distributions differ in shape (KS test p=%0.3f), although centers do not", shape.P))
+	}
+}
+
+// ksTest performs a two-sample Kolmogorov–Smirnov test on sorted
+// samples xs and ys. It returns the test statistic D, the maximum
+// distance between the empirical CDFs of the two samples, and the
+// p-value of the null hypothesis that the samples come from the same
+// distribution.
+func ksTest(xs, ys []float64) (d, p float64) {
+	if len(xs) == 0 || len(ys) == 0 {
+		return 0, 1
+	}
+	n1, n2 := float64(len(xs)), float64(len(ys))
+	var i, j int
+	for i < len(xs) && j < len(ys) {
+		// Step past all values equal to the smallest
+		// remaining value in both samples, so ties don't
+		// create spurious gaps.
+		v := math.Min(xs[i], ys[j])
+		for i < len(xs) && xs[i] == v {
+			i++
+		}
+		for j < len(ys) && ys[j] == v {
+			j++
+		}
+		d = math.Max(d, math.Abs(float64(i)/n1-float64(j)/n2))
+	}
+
+	// Use the asymptotic Kolmogorov distribution with Stephens'
+	// small-sample correction.
+	ne := math.Sqrt(n1 * n2 / (n1 + n2))
+	return d, ksProb((ne + 0.12 + 0.11/ne) * d)
+}
+
+// ksProb returns the complementary CDF of the Kolmogorov distribution
+// at lambda.
+func ksProb(lambda float64) float64 {
+	a2 := -2 * lambda * lambda
+	fac, sum, prev := 2.0, 0.0, 0.0
+	for j := 1; j <= 100; j++ {
+		term := fac * math.Exp(a2*float64(j*j))
+		sum += term
+		if math.Abs(term) <= 0.001*prev || math.Abs(term) <= 1e-8*sum {
+			return math.Max(0, math.Min(1, sum))
+		}
+		fac = -fac
+		prev = math.Abs(term)
+	}
+	// The series failed to converge, which happens for small
+	// lambda, where the probability approaches 1.
+	return 1
+}
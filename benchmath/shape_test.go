@@ -0,0 +1,74 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"math"
+	"testing"
+)
+
+func TestKSTest(t *testing.T) {
+	check := func(xs, ys []float64, wantD, wantP float64) {
+		t.Helper()
+		d, p := ksTest(xs, ys)
+		if math.Abs(d-wantD) > 1e-9 || math.Abs(p-wantP) > 1e-3 {
+			t.Errorf("ksTest(%v, %v) = %v, %v; want %v, %v", xs, ys, d, p, wantD, wantP)
+		}
+	}
+	check([]float64{1, 2, 3}, []float64{1, 2, 3}, 0, 1)
+	// Completely separated samples.
+	check([]float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, []float64{11, 12, 13, 14, 15, 16, 17, 18, 19, 20}, 1, ksProb((math.Sqrt(5)+0.12+0.11/math.Sqrt(5))*1))
+	// Ties across samples don't count as gaps.
+	check([]float64{1, 1, 2, 2}, []float64{1, 2}, 0, 1)
+	check([]float64{1, 2, 3, 4}, []float64{3, 4, 5, 6}, 0.5, ksProb((math.Sqrt(2)+0.12+0.11/math.Sqrt(2))*0.5))
+}
+
+func TestKSProb(t *testing.T) {
+	// Known values of the Kolmogorov distribution.
+	check := func(lambda, want float64) {
+		t.Helper()
+		if got := ksProb(lambda); math.Abs(got-want) > 1e-4 {
+			t.Errorf("ksProb(%v) = %v, want %v", lambda, got, want)
+		}
+	}
+	check(0.1, 1)
+	check(1.0, 0.2700)
+	check(1.36, 0.0494)
+	check(1.63, 0.0098)
+}
+
+func TestShapeWarning(t *testing.T) {
+	// Same median, but s2 has much wider spread.
+	thr := DefaultThresholds
+	thr.ShapeAlpha = 0.05
+	var v1, v2 []float64
+	for i := 0; i < 20; i++ {
+		v1 = append(v1, 100+float64(i%2))
+		v2 = append(v2, 100+(float64(i%10)-4.5)*4)
+	}
+	s1, s2 := NewSample(v1, &thr), NewSample(v2, &thr)
+
+	for _, a := range []Assumption{AssumeNothing, AssumeNormal, AssumeLogNormal, AssumeBootstrap} {
+		cmp := a.Compare(s1, s2)
+		if cmp.P <= cmp.Alpha {
+			t.Errorf("%T: want no difference in centers, got %v", a, cmp)
+			continue
+		}
+		if len(cmp.Warnings) != 1 {
+			t.Errorf("%T: want shape warning, got %v", a, cmp.Warnings)
+		}
+	}
+
+	// Disabled by default.
+	s1, s2 = NewSample(v1, &DefaultThresholds), NewSample(v2, &DefaultThresholds)
+	if cmp := AssumeNothing.Compare(s1, s2); len(cmp.Warnings) != 0 {
+		t.Errorf("want no shape warning by default, got %v", cmp.Warnings)
+	}
+
+	cmp := CompareShape(s1, s2)
+	if cmp.N1 != 20 || cmp.N2 != 20 || !(cmp.P < 0.05) {
+		t.Errorf("want significant shape difference, got %v", cmp)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"fmt"
+
+	"github.com/aclements/go-moremath/stats"
+)
+
+// A SpreadComparison is the result of comparing the spread, or noise,
+// of two samples.
+type SpreadComparison struct {
+	// Comparison is the result of testing the null hypothesis
+	// that the two samples have the same spread.
+	Comparison
+
+	// Spread1 and Spread2 are the spreads of the two samples,
+	// measured as the mean absolute deviation from the median.
+	Spread1, Spread2 float64
+}
+
+// CompareSpread tests whether s1 and s2 have the same spread using the
+// Brown–Forsythe test. This is robust to non-normal distributions,
+// so it's appropriate regardless of the Assumption used to compare
+// the centers of the samples.
+//
+// The Brown–Forsythe test is an analysis of variance of the absolute
+// deviations of each sample from its median. With two samples, this
+// is equivalent to a pooled two-sample t-test on the absolute
+// deviations.
+func CompareSpread(s1, s2 *Sample) SpreadComparison {
+	if len(s1.Values) < 2 || len(s2.Values) < 2 {
+		return SpreadComparison{Comparison: Comparison{
+			P: 1, N1: len(s1.Values), N2: len(s2.Values), Alpha: s1.Thresholds.CompareAlpha,
+			Warnings: []error{fmt.Errorf("need >= 2 samples to compare spread")},
+		}}
+	}
+	dev1, dev2 := absDeviations(s1), absDeviations(s2)
+	cmp := SpreadComparison{
+		Comparison: Comparison{N1: len(s1.Values), N2: len(s2.Values), Alpha: s1.Thresholds.CompareAlpha},
+		Spread1:    stats.Mean(dev1),
+		Spread2:    stats.Mean(dev2),
+	}
+	if cmp.Spread1 == 0 && cmp.Spread2 == 0 {
+		// Neither sample has any spread, so there's no
+		// difference. This is common for exact measurements.
+		cmp.P = 1
+		return cmp
+	}
+	t, err := stats.TwoSampleTTest(stats.Sample{Xs: dev1}, stats.Sample{Xs: dev2}, stats.LocationDiffers)
+	if err != nil {
+		// The t-test failed. Report as if there's no
+		// significant difference, along with the error.
+		cmp.P = 1
+		cmp.Warnings = []error{err}
+		return cmp
+	}
+	cmp.P = t.P
+	cmp.Test, cmp.Statistic, cmp.DF = BrownForsytheTest, t.T, t.DoF
+	return cmp
+}
+
+// absDeviations returns the absolute deviations of the values in s
+// from its median.
+func absDeviations(s *Sample) []float64 {
+	med := s.sample().Quantile(0.5)
+	devs := make([]float64, len(s.Values))
+	for i, v := range s.Values {
+		if v > med {
+			devs[i] = v - med
+		} else {
+			devs[i] = med - v
+		}
+	}
+	return devs
+}
+
+// FormatDelta formats the difference in spread between the two
+// samples. If the comparison accepts the null hypothesis that the
+// samples have the same spread, it returns "~". Otherwise, it returns
+// "noisier" or "less noisy" followed by the ratio of the spread of the
+// second sample to the first, such as "noisier ×2.10".
+func (c SpreadComparison) FormatDelta() string {
+	if c.P > c.Alpha {
+		return "~"
+	}
+	if c.Spread1 == 0 {
+		return "noisier ×∞"
+	}
+	ratio := c.Spread2 / c.Spread1
+	if ratio > 1 {
+		return fmt.Sprintf("noisier ×%.2f", ratio)
+	}
+	return fmt.Sprintf("less noisy ×%.2f", ratio)
+}
@@ -0,0 +1,49 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCompareSpread(t *testing.T) {
+	thr := DefaultThresholds
+	quiet := NewSample([]float64{99, 100, 100, 101, 99, 100, 101, 100}, &thr)
+	noisy := NewSample([]float64{90, 100, 110, 95, 105, 85, 115, 100}, &thr)
+
+	cmp := CompareSpread(quiet, noisy)
+	if !(cmp.P < thr.CompareAlpha) || cmp.N1 != 8 || cmp.N2 != 8 {
+		t.Errorf("want significant difference, got %+v", cmp)
+	}
+	if cmp.Spread1 != 0.5 || cmp.Spread2 != 7.5 {
+		t.Errorf("want spreads 0.5 and 7.5, got %v and %v", cmp.Spread1, cmp.Spread2)
+	}
+	if got, want := cmp.FormatDelta(), "noisier ×15.00"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	if got, want := CompareSpread(noisy, quiet).FormatDelta(), "less noisy ×0.07"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+
+	// The spread test ignores differences in center.
+	shifted := NewSample([]float64{199, 200, 200, 201, 199, 200, 201, 200}, &thr)
+	if cmp := CompareSpread(quiet, shifted); cmp.P != 1 || cmp.FormatDelta() != "~" {
+		t.Errorf("want no difference, got %+v", cmp)
+	}
+
+	// Exact samples have no spread.
+	exact1 := NewSample([]float64{1, 1, 1}, &thr)
+	exact2 := NewSample([]float64{2, 2, 2}, &thr)
+	checkComparison(t, CompareSpread(exact1, exact2).Comparison, Comparison{P: 1, N1: 3, N2: 3, Alpha: 0.05})
+
+	one := NewSample([]float64{1}, &thr)
+	checkComparison(t, CompareSpread(one, noisy).Comparison, Comparison{P: 1, N1: 1, N2: 8, Alpha: 0.05},
+		"need >= 2 samples to compare spread")
+	exact8 := NewSample([]float64{100, 100, 100, 100, 100, 100, 100, 100}, &thr)
+	if got := CompareSpread(exact8, noisy); !math.IsInf(got.Spread2/got.Spread1, 1) || got.FormatDelta() != "noisier ×∞" {
+		t.Errorf("want infinite spread ratio, got %+v (%s)", got, got.FormatDelta())
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import "github.com/aclements/go-moremath/stats"
+
+// Names of statistical tests, as reported in Comparison.Test and
+// KComparison.Test.
+const (
+	// UTest is the Mann-Whitney U test. The statistic is U for
+	// the first sample, counting ties as 0.5.
+	UTest = "Mann-Whitney U"
+
+	// WelchTTest is Welch's unequal variances t-test. The
+	// statistic is t and DF is the Welch–Satterthwaite degrees of
+	// freedom.
+	WelchTTest = "Welch's t"
+
+	// PairedTTest is the paired t-test. The statistic is t.
+	PairedTTest = "paired t"
+
+	// SignedRankTest is the Wilcoxon signed-rank test. The
+	// statistic is W+, the sum of the ranks of the positive
+	// differences.
+	SignedRankTest = "Wilcoxon signed-rank"
+
+	// YuenTTest is Yuen's trimmed-means t-test. The statistic is
+	// t.
+	YuenTTest = "Yuen's t"
+
+	// BootstrapTest is a bootstrap test. The statistic is the
+	// difference between the summary statistics of the two
+	// samples.
+	BootstrapTest = "bootstrap"
+
+	// KSTest is the two-sample Kolmogorov–Smirnov test. The
+	// statistic is D, the largest difference between the
+	// empirical CDFs.
+	KSTest = "Kolmogorov-Smirnov"
+
+	// BrownForsytheTest is the Brown–Forsythe test. The statistic
+	// is the pooled t statistic of the absolute deviations from
+	// the medians.
+	BrownForsytheTest = "Brown-Forsythe"
+
+	// GehanTest is Gehan's generalized Wilcoxon test for samples
+	// with censored values. The statistic is the number of pairs
+	// in which the value from the second sample is certainly
+	// larger, less the number in which it's certainly smaller.
+	GehanTest = "Gehan-Wilcoxon"
+
+	// PoissonRateTest is the conditional test of whether two
+	// samples of counts have the same Poisson rate. The statistic
+	// is the total count of the second sample.
+	PoissonRateTest = "Poisson rate"
+
+	// KruskalWallisTest is the Kruskal-Wallis test of whether
+	// several samples come from the same distribution. The
+	// statistic is H, corrected for ties, which approximately
+	// follows a chi-squared distribution with DF degrees of
+	// freedom.
+	KruskalWallisTest = "Kruskal-Wallis"
+
+	// ANOVATest is a one-way analysis of variance of several
+	// samples. The statistic is F, with DF and DF2 degrees of
+	// freedom.
+	ANOVATest = "one-way ANOVA"
+)
+
+// uTestExact reports whether stats.MannWhitneyUTest uses the exact U
+// distribution for sorted samples xs and ys.
+func uTestExact(xs, ys []float64) bool {
+	limit := stats.MannWhitneyExactLimit
+	if hasTies(xs, ys) {
+		limit = stats.MannWhitneyTiesExactLimit
+	}
+	return len(xs) <= limit && len(ys) <= limit
+}
+
+// hasTies reports whether sorted samples xs and ys contain any
+// repeated value, within or between the samples.
+func hasTies(xs, ys []float64) bool {
+	var prev float64
+	first := true
+	for i, j := 0, 0; i < len(xs) || j < len(ys); {
+		var x float64
+		if j == len(ys) || (i < len(xs) && xs[i] <= ys[j]) {
+			x = xs[i]
+			i++
+		} else {
+			x = ys[j]
+			j++
+		}
+		if !first && x == prev {
+			return true
+		}
+		prev, first = x, false
+	}
+	return false
+}
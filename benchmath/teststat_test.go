@@ -0,0 +1,47 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import "testing"
+
+func TestComparisonStatistics(t *testing.T) {
+	s1 := NewSample([]float64{1, 2, 3, 4}, &DefaultThresholds)
+	s2 := NewSample([]float64{5, 6, 7, 8}, &DefaultThresholds)
+	tied := NewSample([]float64{4, 5, 6, 7}, &DefaultThresholds)
+
+	check := func(name string, cmp Comparison, test string, stat, df float64, exact bool) {
+		t.Helper()
+		if cmp.Test != test || !aeq(cmp.Statistic, stat) || !aeq(cmp.DF, df) || cmp.Exact != exact {
+			t.Errorf("%s: got %s %v df=%v exact=%v, want %s %v df=%v exact=%v", name, cmp.Test, cmp.Statistic, cmp.DF, cmp.Exact, test, stat, df, exact)
+		}
+	}
+
+	check("nothing", AssumeNothing.Compare(s1, s2), UTest, 0, 0, true)
+	check("nothing tied", AssumeNothing.Compare(s1, tied), UTest, 0.5, 0, true)
+	check("normal", AssumeNormal.Compare(s1, s2), WelchTTest, -4.381780460041329, 6, false)
+	check("trimmed", AssumeTrimmed.Compare(s1, s2), YuenTTest, 4.381780460041329, 6, false)
+	check("bootstrap", AssumeBootstrap.Compare(s1, s2), BootstrapTest, 4, 0, false)
+	check("paired t", ComparePaired(AssumeNormal, s1.Values, []float64{2, 4, 5, 7}, &DefaultThresholds), PairedTTest, -4.898979485566356, 3, true)
+	check("signed-rank", ComparePaired(AssumeNothing, s1.Values, []float64{2, 4, 6, 8}, &DefaultThresholds), SignedRankTest, 10, 0, true)
+	check("shape", CompareShape(s1, s2), KSTest, 1, 0, false)
+}
+
+func TestHasTies(t *testing.T) {
+	for _, test := range []struct {
+		xs, ys []float64
+		want   bool
+	}{
+		{[]float64{1, 2}, []float64{3, 4}, false},
+		{[]float64{1, 3}, []float64{2, 4}, false},
+		{[]float64{1, 1}, []float64{3, 4}, true},
+		{[]float64{1, 3}, []float64{3, 4}, true},
+		{nil, []float64{3, 3}, true},
+		{nil, nil, false},
+	} {
+		if got := hasTies(test.xs, test.ys); got != test.want {
+			t.Errorf("hasTies(%v, %v) = %v, want %v", test.xs, test.ys, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/aclements/go-moremath/stats"
+)
+
+// NewWeightedSample is like NewSample, but gives each value a weight.
+// weights[i] is the weight of values[i], and must be non-negative.
+// For example, weighting each value by the iteration count or
+// duration of the run that measured it means values from longer runs
+// count for more. NewWeightedSample takes ownership of both values
+// and weights and sorts them together in place, so callers must not
+// reuse either slice.
+//
+// AssumeNothing, AssumeNormal, and AssumeLogNormal take weights into
+// account in both summaries and comparisons, and AssumeExact is
+// unaffected by them. Other assumptions ignore weights and add a
+// warning to their results. Analyses other than
+// Assumption.Summary and Assumption.Compare ignore weights.
+func NewWeightedSample(values, weights []float64, t *Thresholds) *Sample {
+	if len(values) != len(weights) {
+		panic(fmt.Sprintf("%d values, but %d weights", len(values), len(weights)))
+	}
+	drift := driftWarnings(values, t)
+	sort.Sort(&weightSorter{values, weights})
+	s := newSample(values, weights, t)
+	s.Warnings = append(drift, s.Warnings...)
+	return s
+}
+
+type weightSorter struct {
+	xs, ws []float64
+}
+
+func (s *weightSorter) Len() int {
+	return len(s.xs)
+}
+
+func (s *weightSorter) Less(i, j int) bool {
+	return s.xs[i] < s.xs[j]
+}
+
+func (s *weightSorter) Swap(i, j int) {
+	s.xs[i], s.xs[j] = s.xs[j], s.xs[i]
+	s.ws[i], s.ws[j] = s.ws[j], s.ws[i]
+}
+
+// weightedSample is like sample, but includes s's weights, if any.
+func (s *Sample) weightedSample() stats.Sample {
+	return stats.Sample{Xs: s.Values, Weights: s.Weights, Sorted: true}
+}
+
+// tMoments are the moments of a possibly weighted sample. It
+// implements stats.TTestSample.
+type tMoments struct {
+	// n is the sample size. For a weighted sample, this is Kish's
+	// effective sample size, (Σw)²/Σw².
+	n        float64
+	mean     float64
+	variance float64
+}
+
+func (m tMoments) Weight() float64   { return m.n }
+func (m tMoments) Mean() float64     { return m.mean }
+func (m tMoments) Variance() float64 { return m.variance }
+
+// moments returns the moments of xs. For weighted samples, the
+// variance treats the weights as reliability weights.
+func moments(xs stats.Sample) tMoments {
+	if xs.Weights == nil {
+		m := tMoments{n: float64(len(xs.Xs)), mean: stats.Mean(xs.Xs)}
+		if len(xs.Xs) > 1 {
+			m.variance = stats.Variance(xs.Xs)
+		}
+		return m
+	}
+	var v1, v2, sum float64
+	for i, x := range xs.Xs {
+		w := xs.Weights[i]
+		v1 += w
+		v2 += w * w
+		sum += w * x
+	}
+	if v1 == 0 {
+		return tMoments{mean: math.NaN()}
+	}
+	m := tMoments{n: v1 * v1 / v2, mean: sum / v1}
+	var ss float64
+	for i, x := range xs.Xs {
+		d := x - m.mean
+		ss += xs.Weights[i] * d * d
+	}
+	if denom := v1 - v2/v1; denom > 0 {
+		m.variance = ss / denom
+	}
+	return m
+}
+
+// meanCI returns the mean of xs and its confidence interval. Unlike
+// stats.Sample.MeanCI, this supports weighted samples.
+func meanCI(xs stats.Sample, confidence float64) (mean, lo, hi float64) {
+	if xs.Weights == nil {
+		return xs.MeanCI(confidence)
+	}
+	m := moments(xs)
+	var w float64
+	if confidence <= 0 {
+		w = 0
+	} else if confidence >= 1 || m.n <= 1 {
+		w = math.Inf(1)
+	} else {
+		tdist := stats.TDist{V: m.n - 1}
+		t := -stats.InvCDF(tdist)((1 - confidence) / 2)
+		w = t * math.Sqrt(m.variance/m.n)
+	}
+	return m.mean, m.mean - w, m.mean + w
+}
+
+// welchTTest performs Welch's t-test on possibly weighted samples.
+func welchTTest(xs1, xs2 stats.Sample) (*stats.TTestResult, error) {
+	if xs1.Weights == nil && xs2.Weights == nil {
+		return stats.TwoSampleWelchTTest(xs1, xs2, stats.LocationDiffers)
+	}
+	return stats.TwoSampleWelchTTest(moments(xs1), moments(xs2), stats.LocationDiffers)
+}
+
+// effectiveN returns the effective size of s. For an unweighted sample,
+// this is simply the number of values.
+func (s *Sample) effectiveN() float64 {
+	return moments(stats.Sample{Xs: s.Values, Weights: s.Weights}).n
+}
+
+// weightedMedianCI returns the median of weighted sample s and its
+// confidence interval. The interval uses the order statistics of the
+// median confidence interval for a sample of s's effective size,
+// mapped to quantiles of s.
+func weightedMedianCI(s *Sample, confidence float64) (ci stats.QuantileCIResult, median, lo, hi float64) {
+	xs := s.weightedSample()
+	neff := s.effectiveN()
+	ci = medianCI(int(math.Round(neff)), confidence)
+	median = xs.Quantile(0.5)
+	lo, hi = math.Inf(-1), math.Inf(1)
+	if ci.LoOrder >= 1 {
+		lo = xs.Quantile(float64(ci.LoOrder) / float64(ci.N+1))
+	}
+	if ci.HiOrder <= ci.N {
+		hi = xs.Quantile(float64(ci.HiOrder) / float64(ci.N+1))
+	}
+	return
+}
+
+// weightedUTest performs a weighted Mann-Whitney U test on s1 and s2
+// using the normal approximation. It estimates the probability that a
+// value from s2 exceeds a value from s1, counting ties as half, with
+// each pair of values weighted by the product of their weights. The
+// variance of this estimate under the null hypothesis uses the
+// effective sizes of s1 and s2.
+func weightedUTest(s1, s2 *Sample) Comparison {
+	cmp := Comparison{P: 1, N1: len(s1.Values), N2: len(s2.Values), Alpha: s1.Thresholds.CompareAlpha}
+	ws := func(s *Sample, i int) float64 {
+		if s.Weights == nil {
+			return 1
+		}
+		return s.Weights[i]
+	}
+	var w1, w2, gt float64
+	for i := range s1.Values {
+		w1 += ws(s1, i)
+	}
+	// Both samples are sorted, so walk s2 in order, tracking the
+	// weight of s1 below and equal to each value.
+	var below, atOrBelow float64
+	var lt, le int
+	for j, y := range s2.Values {
+		w := ws(s2, j)
+		w2 += w
+		for lt < len(s1.Values) && s1.Values[lt] < y {
+			below += ws(s1, lt)
+			lt++
+		}
+		if le < lt {
+			le, atOrBelow = lt, below
+		}
+		for le < len(s1.Values) && s1.Values[le] <= y {
+			atOrBelow += ws(s1, le)
+			le++
+		}
+		gt += w * (below + (atOrBelow-below)/2)
+	}
+	n1, n2 := s1.effectiveN(), s2.effectiveN()
+	if w1 == 0 || w2 == 0 || n1 == 0 || n2 == 0 {
+		cmp.Warnings = append(cmp.Warnings, stats.ErrSampleSize)
+		return cmp
+	}
+	theta := gt / (w1 * w2)
+	sigma := math.Sqrt((n1 + n2 + 1) / (12 * n1 * n2))
+	z := math.Abs(theta-0.5) / sigma
+	cmp.P = 2 * (1 - stats.NormalDist{Mu: 0, Sigma: 1}.CDF(z))
+	// Report the weighted U for s1, scaled to the actual sample
+	// sizes.
+	cmp.Test, cmp.Statistic = UTest, (1-theta)*float64(cmp.N1*cmp.N2)
+	cmp.Effect, cmp.EffectMeasure = 2*theta-1, EffectCliffsDelta
+	return cmp
+}
+
+// weightsIgnored returns a warning if any of samples is weighted, for
+// use by assumptions that don't support weights.
+func weightsIgnored(a Assumption, samples ...*Sample) []error {
+	for _, s := range samples {
+		if s.Weights != nil {
+			return []error{fmt.Errorf("%s ignores sample weights", a.SummaryLabel())}
+		}
+	}
+	return nil
+}
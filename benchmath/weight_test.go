@@ -0,0 +1,90 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmath
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestNewWeightedSample(t *testing.T) {
+	s := NewWeightedSample([]float64{3, 1, 2}, []float64{30, 10, 20}, &DefaultThresholds)
+	if want := []float64{1, 2, 3}; !reflect.DeepEqual(s.Values, want) {
+		t.Errorf("got values %v, want %v", s.Values, want)
+	}
+	if want := []float64{10, 20, 30}; !reflect.DeepEqual(s.Weights, want) {
+		t.Errorf("got weights %v, want %v", s.Weights, want)
+	}
+
+	// Trimming outliers keeps the weights of the inliers.
+	thr := DefaultThresholds
+	thr.Outliers, thr.TrimOutliers = OutliersTukey, true
+	s = NewWeightedSample([]float64{10, 11, 12, 13, 14, 100}, []float64{1, 2, 3, 4, 5, 6}, &thr)
+	if want := []float64{10, 11, 12, 13, 14}; !reflect.DeepEqual(s.Values, want) {
+		t.Errorf("trimmed: got values %v, want %v", s.Values, want)
+	}
+	if want := []float64{1, 2, 3, 4, 5}; !reflect.DeepEqual(s.Weights, want) {
+		t.Errorf("trimmed: got weights %v, want %v", s.Weights, want)
+	}
+}
+
+func TestWeightedEqual(t *testing.T) {
+	// Equal weights should give the same results as no weights.
+	xs := []float64{10, 11, 12, 13, 14, 15}
+	ys := []float64{12, 13, 14, 15, 16, 17}
+	ws := []float64{2, 2, 2, 2, 2, 2}
+	s1, s2 := NewSample(xs, &DefaultThresholds), NewSample(ys, &DefaultThresholds)
+	w1 := NewWeightedSample(append([]float64(nil), xs...), ws, &DefaultThresholds)
+	w2 := NewWeightedSample(append([]float64(nil), ys...), ws, &DefaultThresholds)
+
+	for _, a := range []Assumption{AssumeNormal, AssumeLogNormal} {
+		got, want := a.Summary(w1, 0.95), a.Summary(s1, 0.95)
+		if !aeq(got.Center, want.Center) || !aeq(got.Lo, want.Lo) || !aeq(got.Hi, want.Hi) {
+			t.Errorf("%s: got %+v, want %+v", a.SummaryLabel(), got, want)
+		}
+		gotC, wantC := a.Compare(w1, w2), a.Compare(s1, s2)
+		if !aeq(gotC.P, wantC.P) || !aeq(gotC.Effect, wantC.Effect) {
+			t.Errorf("%s: got %+v, want %+v", a.SummaryLabel(), gotC, wantC)
+		}
+	}
+
+	// The weighted U-test uses the normal approximation, but the
+	// effect size is exact.
+	gotC, wantC := AssumeNothing.Compare(w1, w2), AssumeNothing.Compare(s1, s2)
+	if !aeq(gotC.Effect, wantC.Effect) {
+		t.Errorf("nothing: got effect %v, want %v", gotC.Effect, wantC.Effect)
+	}
+	if math.Abs(gotC.P-wantC.P) > 0.05 {
+		t.Errorf("nothing: got P %v, want about %v", gotC.P, wantC.P)
+	}
+}
+
+func TestWeightedSummary(t *testing.T) {
+	s := NewWeightedSample([]float64{1, 2, 3, 10}, []float64{1, 1, 1, 10}, &DefaultThresholds)
+	if got := AssumeNothing.Summary(s, 0.95).Center; got != 10 {
+		t.Errorf("median: got %v, want 10", got)
+	}
+	if got, want := AssumeNormal.Summary(s, 0.95).Center, 106.0/13; !aeq(got, want) {
+		t.Errorf("mean: got %v, want %v", got, want)
+	}
+	// The effective sample size is (Σw)²/Σw² = 169/103, so the
+	// median has no finite interval.
+	checkSummary(t, AssumeNothing.Summary(s, 0.95), Summary{Center: 10, Lo: math.Inf(-1), Hi: math.Inf(1), Confidence: 1}, "need >= 6 samples for confidence interval at level 0.95")
+}
+
+func TestWeightsIgnored(t *testing.T) {
+	s := NewWeightedSample([]float64{1, 2, 3, 4}, []float64{1, 1, 1, 10}, &DefaultThresholds)
+	for _, a := range []Assumption{AssumeBootstrap, AssumeTrimmed, Quantile{0.9}} {
+		summary := a.Summary(s, 0.95)
+		if len(summary.Warnings) == 0 || summary.Warnings[0].Error() != a.SummaryLabel()+" ignores sample weights" {
+			t.Errorf("%s: got summary warnings %v", a.SummaryLabel(), summary.Warnings)
+		}
+		cmp := a.Compare(s, s)
+		if len(cmp.Warnings) == 0 || cmp.Warnings[0].Error() != a.SummaryLabel()+" ignores sample weights" {
+			t.Errorf("%s: got comparison warnings %v", a.SummaryLabel(), cmp.Warnings)
+		}
+	}
+}
@@ -7,6 +7,7 @@ package benchproc
 import (
 	"bytes"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"golang.org/x/perf/benchfmt"
@@ -31,6 +32,11 @@ type extractor func(*benchfmt.Result) []byte
 // - "/{key}" for a benchmark sub-name key. This may be "/gomaxprocs"
 // and the extractor will normalize the name as needed.
 //
+// - ".iters" for the iteration count.
+//
+// - ".file-index" for the index of the input file the result was read
+// from.
+//
 // - Any other string is a file configuration key.
 func newExtractor(key string) (extractor, error) {
 	if len(key) == 0 {
@@ -44,6 +50,12 @@ func newExtractor(key string) (extractor, error) {
 	case key == ".fullname":
 		return extractFull, nil
 
+	case key == ".iters":
+		return extractIters, nil
+
+	case key == ".file-index":
+		return extractFileIndex, nil
+
 	case strings.HasPrefix(key, "/"):
 		// Construct the byte prefix to search for.
 		prefix := make([]byte, len(key)+1)
@@ -66,8 +78,9 @@ func newExtractor(key string) (extractor, error) {
 // normalized to "/key=*" (or "-*" for gomaxprocs). If ".name" is
 // excluded, the name will be normalized to "*". This will ignore
 // anything in the exclude list that isn't in the form of a /-prefixed
-// sub-name key or ".name".
-func newExtractorFullName(exclude []string) extractor {
+// sub-name key or ".name". If allKeys is true, all sub-name keys,
+// including gomaxprocs, are excluded.
+func newExtractorFullName(exclude []string, allKeys bool) extractor {
 	// Extract the sub-name keys, turn them into substrings and
 	// construct their normalized replacement.
 	var replace [][]byte
@@ -85,11 +98,14 @@ func newExtractorFullName(exclude []string) extractor {
 			excGomaxprocs = true
 		}
 	}
+	if allKeys {
+		excGomaxprocs = true
+	}
 	if len(replace) == 0 && !excName && !excGomaxprocs {
 		return extractFull
 	}
 	return func(res *benchfmt.Result) []byte {
-		return extractFullExcluded(res, replace, excName, excGomaxprocs)
+		return extractFullExcluded(res, replace, excName, excGomaxprocs, allKeys)
 	}
 }
 
@@ -101,7 +117,15 @@ func extractFull(res *benchfmt.Result) []byte {
 	return res.Name.Full()
 }
 
-func extractFullExcluded(res *benchfmt.Result, replace [][]byte, excName, excGomaxprocs bool) []byte {
+func extractIters(res *benchfmt.Result) []byte {
+	return strconv.AppendInt(nil, int64(res.Iters), 10)
+}
+
+func extractFileIndex(res *benchfmt.Result) []byte {
+	return strconv.AppendInt(nil, int64(res.FileIndex), 10)
+}
+
+func extractFullExcluded(res *benchfmt.Result, replace [][]byte, excName, excGomaxprocs, excAllKeys bool) []byte {
 	name := res.Name.Full()
 	found := false
 	if excName {
@@ -118,6 +142,9 @@ func extractFullExcluded(res *benchfmt.Result, replace [][]byte, excName, excGom
 	if !found && excGomaxprocs && bytes.IndexByte(name, '-') >= 0 {
 		found = true
 	}
+	if !found && excAllKeys && bytes.IndexByte(name, '=') >= 0 {
+		found = true
+	}
 	if !found {
 		// No need to transform name.
 		return name
@@ -143,6 +170,12 @@ outer:
 			newName = append(newName, "-*"...)
 			continue outer
 		}
+		if excAllKeys {
+			if eq := bytes.IndexByte(part, '='); eq >= 0 {
+				newName = append(append(newName, part[:eq+1]...), '*')
+				continue outer
+			}
+		}
 		newName = append(newName, part...)
 	}
 	return newName
@@ -46,7 +46,7 @@ func TestExtractFullName(t *testing.T) {
 	})
 
 	t.Run("excludeA", func(t *testing.T) {
-		x := newExtractorFullName([]string{"/a"})
+		x := newExtractorFullName([]string{"/a"}, false)
 		check(t, x, "Test", "Test")
 		check(t, x, "Test/a=123", "Test/a=*")
 		check(t, x, "Test/b=123/a=123", "Test/b=123/a=*")
@@ -56,22 +56,32 @@ func TestExtractFullName(t *testing.T) {
 	})
 
 	t.Run("excludeName", func(t *testing.T) {
-		x := newExtractorFullName([]string{".name"})
+		x := newExtractorFullName([]string{".name"}, false)
 		check(t, x, "Test", "*")
 		check(t, x, "Test/a=123", "*/a=123")
-		x = newExtractorFullName([]string{".name", "/a"})
+		x = newExtractorFullName([]string{".name", "/a"}, false)
 		check(t, x, "Test", "*")
 		check(t, x, "Test/a=123", "*/a=*")
 		check(t, x, "Test/a=123/b=123", "*/a=*/b=123")
 	})
 
 	t.Run("excludeGomaxprocs", func(t *testing.T) {
-		x := newExtractorFullName([]string{"/gomaxprocs"})
+		x := newExtractorFullName([]string{"/gomaxprocs"}, false)
 		check(t, x, "Test", "Test")
 		check(t, x, "Test/a=123", "Test/a=123")
 		check(t, x, "Test/a=123-2", "Test/a=123-*")
 		check(t, x, "Test/gomaxprocs=123", "Test/gomaxprocs=*")
 	})
+
+	t.Run("excludeAllKeys", func(t *testing.T) {
+		x := newExtractorFullName(nil, true)
+		check(t, x, "Test", "Test")
+		check(t, x, "Test/a=123/gob", "Test/a=*/gob")
+		check(t, x, "Test/a=123/b=4-2", "Test/a=*/b=*-*")
+		check(t, x, "Test/gomaxprocs=123", "Test/gomaxprocs=*")
+		x = newExtractorFullName([]string{".name"}, true)
+		check(t, x, "Test/a=1", "*/a=*")
+	})
 }
 
 func TestExtractNameKey(t *testing.T) {
@@ -123,6 +133,30 @@ func TestExtractFileKey(t *testing.T) {
 	}
 }
 
+func TestExtractIters(t *testing.T) {
+	x, err := newExtractor(".iters")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := r(t, "Name")
+	res.Iters = 1000
+	if got, want := string(x(res)), "1000"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestExtractFileIndex(t *testing.T) {
+	x, err := newExtractor(".file-index")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := r(t, "Name")
+	res.FileIndex = 3
+	if got, want := string(x(res)), "3"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
 func TestExtractBadKey(t *testing.T) {
 	check := func(t *testing.T, got error, want string) {
 		t.Helper()
@@ -6,6 +6,7 @@ package benchproc
 
 import (
 	"fmt"
+	"math/bits"
 
 	"golang.org/x/perf/benchfmt"
 	"golang.org/x/perf/benchproc/internal/parse"
@@ -27,12 +28,47 @@ type filterFn func(res *benchfmt.Result) (mask, bool)
 // expression, such as ".name:Copy /size:4k". See "go doc
 // golang.org/x/perf/benchproc/syntax" for a description of filter
 // syntax.
+//
+// NewFilter is equivalent to parsing query with a zero FilterParser,
+// which defines no predicate functions.
 func NewFilter(query string) (*Filter, error) {
+	return (&FilterParser{}).Parse(query)
+}
+
+// A FilterParser parses filter expressions that may call
+// user-defined predicate functions.
+//
+// The zero value of FilterParser is ready to use.
+type FilterParser struct {
+	preds map[string]func(vals []string) bool
+}
+
+// AddPredicate defines a predicate function that filter expressions
+// can call as "name(key1 key2 ...)". When the filter is applied to a
+// result, pred is called with the values of the argument keys in that
+// result and the filter matches the result if pred returns true.
+// Later definitions of name replace earlier definitions.
+func (p *FilterParser) AddPredicate(name string, pred func(vals []string) bool) {
+	if p.preds == nil {
+		p.preds = make(map[string]func(vals []string) bool)
+	}
+	p.preds[name] = pred
+}
+
+// Parse constructs a result filter from a boolean filter expression,
+// like NewFilter, but also permits calls to the predicates defined on
+// p.
+func (p *FilterParser) Parse(query string) (*Filter, error) {
 	q, err := parse.ParseFilter(query)
 	if err != nil {
 		return nil, err
 	}
+	return p.newFilter(query, q)
+}
 
+// newFilter compiles filter expression q, which was parsed from
+// query.
+func (p *FilterParser) newFilter(query string, q parse.Filter) (*Filter, error) {
 	// Recursively walk the filter expression, "compiling" it into
 	// a filterFn.
 	//
@@ -81,6 +117,32 @@ func NewFilter(query string) (*Filter, error) {
 			return func(res *benchfmt.Result) (mask, bool) {
 				return nil, q.Match(ext(res))
 			}, nil
+
+		case *parse.FilterCall:
+			pred, ok := p.preds[q.Name]
+			if !ok {
+				return nil, &parse.SyntaxError{query, q.Off, fmt.Sprintf("unknown predicate %q", q.Name)}
+			}
+			exts := make([]extractor, len(q.Keys))
+			for i, key := range q.Keys {
+				ext := extractors[key]
+				if ext == nil {
+					ext, err = newExtractor(key)
+					if err != nil {
+						return nil, &parse.SyntaxError{query, q.Off, err.Error()}
+					}
+					extractors[key] = ext
+				}
+				exts[i] = ext
+			}
+
+			return func(res *benchfmt.Result) (mask, bool) {
+				vals := make([]string, len(exts))
+				for i, ext := range exts {
+					vals[i] = string(ext(res))
+				}
+				return nil, pred(vals)
+			}, nil
 		}
 		panic(fmt.Sprintf("unknown query node type %T", q))
 	}
@@ -159,6 +221,9 @@ func (f *Filter) Apply(res *benchfmt.Result) bool {
 // Match returns the set of res.Values that match f.
 //
 // In contrast with the Apply method, this does not modify the Result.
+// The Match's Test, Indices, and Values methods can be used to
+// inspect the matched values of a Result that must not be modified,
+// for example because it's shared by concurrent consumers.
 func (f *Filter) Match(res *benchfmt.Result) Match {
 	m, x := f.match(res)
 	return Match{len(res.Values), m, x}
@@ -242,6 +307,58 @@ func (m *Match) Test(i int) bool {
 	return m.m[i/32]&(1<<(i%32)) != 0
 }
 
+// Count returns the number of values in a result that matched the
+// query.
+func (m *Match) Count() int {
+	if m.m == nil {
+		if m.x {
+			return m.n
+		}
+		return 0
+	}
+	count := 0
+	for i, x := range m.m {
+		if rem := m.n - i*32; rem < 32 {
+			// Zero all bits above m.n.
+			x &^= 0xffffffff << rem
+		}
+		count += bits.OnesCount32(x)
+	}
+	return count
+}
+
+// Indices returns the indexes of the values in a result that matched
+// the query, in increasing order.
+func (m *Match) Indices() []int {
+	idxs := make([]int, 0, m.Count())
+	for i := 0; i < m.n; i++ {
+		if m.Test(i) {
+			idxs = append(idxs, i)
+		}
+	}
+	return idxs
+}
+
+// Values returns the Values of res that match m, without modifying
+// res. res must be the Result that m was computed from. If all values
+// match, the result is res.Values itself; otherwise it is a new slice.
+// Either way, callers should not modify it.
+//
+// Unlike Apply, this is safe to use on a Result shared with other
+// readers.
+func (m *Match) Values(res *benchfmt.Result) []benchfmt.Value {
+	if m.All() {
+		return res.Values
+	}
+	vals := make([]benchfmt.Value, 0, m.Count())
+	for i, val := range res.Values {
+		if m.Test(i) {
+			vals = append(vals, val)
+		}
+	}
+	return vals
+}
+
 // Apply rewrites res to keep only the Values that match m.
 // It reports whether any Values remain.
 func (m *Match) Apply(res *benchfmt.Result) bool {
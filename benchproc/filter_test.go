@@ -6,9 +6,11 @@ package benchproc
 
 import (
 	"fmt"
+	"strconv"
 	"testing"
 
 	"golang.org/x/perf/benchfmt"
+	"golang.org/x/perf/benchproc/internal/parse"
 )
 
 func TestFilter(t *testing.T) {
@@ -51,6 +53,9 @@ func TestFilter(t *testing.T) {
 		// Special keys
 		check(t, ".name:Name", ALL)
 		check(t, ".fullname:Name/n1=v3", ALL)
+		check(t, ".iters:1", ALL)
+		check(t, ".iters:/^[0-9]$/", ALL)
+		check(t, ".file-index:0", ALL)
 	})
 
 	t.Run("units", func(t *testing.T) {
@@ -93,6 +98,56 @@ func TestFilter(t *testing.T) {
 	})
 }
 
+func TestFilterPredicate(t *testing.T) {
+	var fp FilterParser
+	fp.AddPredicate("isPrime", func(vals []string) bool {
+		n, err := strconv.Atoi(vals[0])
+		if err != nil || n < 2 {
+			return false
+		}
+		for d := 2; d*d <= n; d++ {
+			if n%d == 0 {
+				return false
+			}
+		}
+		return true
+	})
+	fp.AddPredicate("same", func(vals []string) bool {
+		return vals[0] == vals[1]
+	})
+
+	check := func(query string, res *benchfmt.Result, want bool) {
+		t.Helper()
+		f, err := fp.Parse(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := f.Apply(res); got != want {
+			t.Errorf("%s on %s: got %v, want %v", query, res.Name, got, want)
+		}
+	}
+	check("isPrime(/size)", r(t, "Name/size=7"), true)
+	check("isPrime(/size)", r(t, "Name/size=8"), false)
+	check("-isPrime(/size) f1:v1", r(t, "Name/size=8", "f1", "v1"), true)
+	check("same(/a f1)", r(t, "Name/a=x", "f1", "x"), true)
+	check("same(/a f1)", r(t, "Name/a=x", "f1", "y"), false)
+
+	checkErr := func(query, error string, pos int) {
+		t.Helper()
+		_, err := fp.Parse(query)
+		if se, _ := err.(*parse.SyntaxError); se == nil || se.Msg != error || se.Off != pos {
+			t.Errorf("%s: want error %s at %d; got %s", query, error, pos, err)
+		}
+	}
+	checkErr("f1:v1 isComposite(/size)", "unknown predicate \"isComposite\"", 6)
+	checkErr(`isPrime("")`, "key must not be empty", 0)
+
+	// NewFilter doesn't know any predicates.
+	if _, err := NewFilter("isPrime(/size)"); err == nil {
+		t.Errorf("NewFilter: want error for unknown predicate")
+	}
+}
+
 func TestMatch(t *testing.T) {
 	check := func(m Match, all, any bool) {
 		t.Helper()
@@ -130,3 +185,58 @@ func TestMatch(t *testing.T) {
 	m = Match{n: 4, m: []uint32{0xfffffff0}}
 	check(m, false, false)
 }
+
+func TestMatchIndices(t *testing.T) {
+	check := func(m Match, want ...int) {
+		t.Helper()
+		if got := m.Count(); got != len(want) {
+			t.Errorf("match %+v: Count should be %d, got %d", m, len(want), got)
+		}
+		got := m.Indices()
+		if len(got) != len(want) {
+			t.Errorf("match %+v: Indices should be %v, got %v", m, want, got)
+			return
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Errorf("match %+v: Indices should be %v, got %v", m, want, got)
+				return
+			}
+		}
+	}
+	check(Match{n: 3, x: false})
+	check(Match{n: 3, x: true}, 0, 1, 2)
+	check(Match{n: 4, m: []uint32{0xfffffff5}}, 0, 2)
+	check(Match{n: 40, m: []uint32{0x80000001, 0xffffff01}}, 0, 31, 32)
+	check(Match{n: 64, m: []uint32{0, 0x80000000}}, 63)
+}
+
+func TestMatchValues(t *testing.T) {
+	res := r(t, "Name")
+	res.Values = []benchfmt.Value{
+		{100, "ns/op", 100e-9, "sec/op"},
+		{100, "B/op", 0, ""},
+		{1, "allocs/op", 0, ""},
+	}
+	f, err := NewFilter(".unit:(B/op allocs/op)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := f.Match(res)
+	vals := m.Values(res)
+	if len(vals) != 2 || vals[0].Unit != "B/op" || vals[1].Unit != "allocs/op" {
+		t.Errorf("got values %v, want B/op and allocs/op", vals)
+	}
+	if len(res.Values) != 3 {
+		t.Errorf("Values modified the Result")
+	}
+
+	f, err = NewFilter("*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m = f.Match(res)
+	if vals := m.Values(res); len(vals) != 3 {
+		t.Errorf("got values %v, want all values", vals)
+	}
+}
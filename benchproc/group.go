@@ -0,0 +1,82 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchproc
+
+import "golang.org/x/perf/benchfmt"
+
+// A Grouper accumulates values into groups keyed by the Configs of a
+// Schema and returns the groups in sorted order.
+//
+// This captures the common pattern of projecting each Result,
+// appending some value derived from it to a map keyed by Config, and
+// finally sorting the map's keys.
+type Grouper struct {
+	schema *Schema
+	groups map[Config]*Group
+}
+
+// A Group is a set of values with the same Config.
+type Group struct {
+	Config Config
+	// Values are the values added to this group, in the order
+	// they were added.
+	Values []interface{}
+}
+
+// NewGrouper returns a new Grouper that groups values by the Configs
+// of Schema s.
+func NewGrouper(s *Schema) *Grouper {
+	return &Grouper{s, make(map[Config]*Group)}
+}
+
+// Add projects res using the Grouper's Schema and adds val to the
+// resulting group. It returns the group's Config.
+func (g *Grouper) Add(res *benchfmt.Result, val interface{}) Config {
+	cfg := g.schema.Project(res)
+	g.AddConfig(cfg, val)
+	return cfg
+}
+
+// AddConfig adds val to the group for Config cfg, which must come from
+// the Grouper's Schema.
+func (g *Grouper) AddConfig(cfg Config, val interface{}) {
+	if cfg.Schema() != g.schema {
+		panic("Config does not come from the Grouper's Schema")
+	}
+	group := g.groups[cfg]
+	if group == nil {
+		group = &Group{Config: cfg}
+		g.groups[cfg] = group
+	}
+	group.Values = append(group.Values, val)
+}
+
+// Len returns the number of groups.
+func (g *Grouper) Len() int {
+	return len(g.groups)
+}
+
+// Get returns the values in the group for cfg, or nil if there is no
+// such group.
+func (g *Grouper) Get(cfg Config) []interface{} {
+	if group := g.groups[cfg]; group != nil {
+		return group.Values
+	}
+	return nil
+}
+
+// Groups returns all groups, sorted by Config.
+func (g *Grouper) Groups() []*Group {
+	cfgs := make([]Config, 0, len(g.groups))
+	for cfg := range g.groups {
+		cfgs = append(cfgs, cfg)
+	}
+	SortConfigs(cfgs)
+	out := make([]*Group, len(cfgs))
+	for i, cfg := range cfgs {
+		out[i] = g.groups[cfg]
+	}
+	return out
+}
@@ -0,0 +1,48 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchproc
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestGrouper(t *testing.T) {
+	s, _ := mustParse(t, "/size@num")
+	g := NewGrouper(s)
+
+	g.Add(r(t, "Name/size=10"), 1)
+	g.Add(r(t, "Name/size=2"), 2)
+	cfg := g.Add(r(t, "Name/size=10"), 3)
+	g.Add(r(t, "Name/size=1k"), 4)
+
+	if g.Len() != 3 {
+		t.Errorf("want 3 groups, got %d", g.Len())
+	}
+	if got, want := g.Get(cfg), []interface{}{1, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Get(%s): got %v, want %v", cfg, got, want)
+	}
+
+	var got []string
+	for _, group := range g.Groups() {
+		got = append(got, fmt.Sprint(group.Config, group.Values))
+	}
+	want := []string{"/size:2 [2]", "/size:10 [1 3]", "/size:1k [4]"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// Configs from other Schemas are rejected.
+	s2, _ := mustParse(t, "/size")
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("want panic adding Config from another Schema")
+			}
+		}()
+		g.AddConfig(p(t, s2, "Name/size=1"), 5)
+	}()
+}
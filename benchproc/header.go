@@ -4,13 +4,15 @@
 
 package benchproc
 
+import "strings"
+
 // A ConfigHeader is a node in a Config header tree. It represents a
 // subslice of a slice of Configs that are all equal up to some
 // prefix.
 //
 // Specifically, given a Config slice configs and ConfigHeader node n,
 // configs[n.Start:n.Start+n.Len] are equal for all fields from 0 to
-// n.Field.
+// n.Field (excluding any fields hoisted by NewConfigHeaderOpts).
 type ConfigHeader struct {
 	// Field is the index of the Schema field represented by this
 	// node.
@@ -59,30 +61,145 @@ type ConfigHeader struct {
 // each level is a stricter partitioning than the previous level, so
 // the ConfigHeaders logically form a tree.
 func NewConfigHeader(configs []Config) (levels [][]*ConfigHeader) {
+	levels, _ = NewConfigHeaderOpts(configs, ConfigHeaderOpts{})
+	return levels
+}
+
+// ConfigHeaderOpts are options for NewConfigHeaderOpts.
+type ConfigHeaderOpts struct {
+	// HoistCommon, if true, removes fields that have the same
+	// value in every Config from the header levels. These fields
+	// are instead returned separately so they can be presented
+	// once, for example above a table.
+	HoistCommon bool
+
+	// MaxLevels, if positive, limits the number of header levels.
+	// If there would be more levels than this, the last level
+	// merges all of the remaining fields.
+	MaxLevels int
+
+	// Merge is a set of fields to combine into header levels.
+	// Fields in Merge that would form adjacent levels are combined
+	// into a single level. Fields separated by a level that isn't
+	// merged stay separate, since combining them would reorder
+	// the levels and break the tree structure of the header.
+	Merge []Field
+}
+
+// NewConfigHeaderOpts is like NewConfigHeader, but accepts options
+// controlling the shape of the header.
+//
+// If opts.HoistCommon is set, fields whose value is identical across
+// all configs are omitted from levels and returned in common, in
+// Schema order. Fields that are empty in every Config are omitted
+// from both. In this case, levels[i] no longer necessarily
+// corresponds to field i of the Schema; use ConfigHeader.Field to
+// find the field represented by each level.
+//
+// If several fields are merged into one level by opts.MaxLevels or
+// opts.Merge, the Value of each node in that level has the form
+// "k1=v1 k2=v2", omitting fields with empty values, and Field is the
+// index of the first merged field.
+func NewConfigHeaderOpts(configs []Config, opts ConfigHeaderOpts) (levels [][]*ConfigHeader, common []FieldValue) {
 	if len(configs) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	fields := commonSchema(configs).Fields()
 
-	levels = make([][]*ConfigHeader, len(fields))
+	// Select the fields that will form levels.
+	type levelField struct {
+		idx   int
+		field Field
+	}
+	var lfields []levelField
+	for i, field := range fields {
+		if opts.HoistCommon {
+			val := configs[0].Get(field)
+			same := true
+			for _, config := range configs[1:] {
+				if config.Get(field) != val {
+					same = false
+					break
+				}
+			}
+			if same {
+				if val != "" {
+					common = append(common, FieldValue{field, val})
+				}
+				continue
+			}
+		}
+		lfields = append(lfields, levelField{i, field})
+	}
+
+	// Group fields into levels.
+	isMerge := func(f Field) bool {
+		for _, m := range opts.Merge {
+			if m.fieldInternal == f.fieldInternal {
+				return true
+			}
+		}
+		return false
+	}
+	var groups [][]levelField
+	prevMerge := false
+	for _, lf := range lfields {
+		merge := isMerge(lf.field)
+		if merge && prevMerge {
+			groups[len(groups)-1] = append(groups[len(groups)-1], lf)
+			continue
+		}
+		prevMerge = merge
+		groups = append(groups, []levelField{lf})
+	}
+	// Only ever merge trailing levels, so each level remains a
+	// refinement of the previous level.
+	if opts.MaxLevels > 0 && len(groups) > opts.MaxLevels {
+		last := groups[opts.MaxLevels-1]
+		for _, g := range groups[opts.MaxLevels:] {
+			last = append(last, g...)
+		}
+		groups = append(groups[:opts.MaxLevels-1], last)
+	}
+	value := func(config Config, group []levelField) string {
+		if len(group) == 1 {
+			return config.Get(group[0].field)
+		}
+		var buf strings.Builder
+		for _, lf := range group {
+			val := config.Get(lf.field)
+			if val == "" {
+				continue
+			}
+			if buf.Len() > 0 {
+				buf.WriteByte(' ')
+			}
+			buf.WriteString(lf.field.Name)
+			buf.WriteByte('=')
+			buf.WriteString(val)
+		}
+		return buf.String()
+	}
+
+	levels = make([][]*ConfigHeader, len(groups))
 	prevLevel := []*ConfigHeader{&ConfigHeader{-1, 0, len(configs), ""}}
 	// Walk through the levels of the tree, subdividing the nodes
 	// from the previous level.
-	for i, field := range fields {
+	for i, group := range groups {
 		for _, parent := range prevLevel {
 			var node *ConfigHeader
 			for j, config := range configs[parent.Start : parent.Start+parent.Len] {
-				val := config.Get(field)
+				val := value(config, group)
 				if node != nil && val == node.Value {
 					node.Len++
 				} else {
-					node = &ConfigHeader{i, parent.Start + j, 1, val}
+					node = &ConfigHeader{group[0].idx, parent.Start + j, 1, val}
 					levels[i] = append(levels[i], node)
 				}
 			}
 		}
 		prevLevel = levels[i]
 	}
-	return levels
+	return levels, common
 }
@@ -5,6 +5,7 @@
 package benchproc
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 )
@@ -107,4 +108,141 @@ a1 -- --
 		hdr := NewConfigHeader([]Config{c1, c2})
 		checkHeader(t, hdr, "")
 	})
+
+	// Test hoisting common fields.
+	t.Run("hoist", func(t *testing.T) {
+		s, _ := mustParse(t, ".config")
+		c1 := p(t, s, "", "a", "a1", "b", "b1", "c", "c1")
+		c2 := p(t, s, "", "a", "a1", "b", "b2", "c", "c1", "d", "")
+		hdr, common := NewConfigHeaderOpts([]Config{c1, c2}, ConfigHeaderOpts{HoistCommon: true})
+		if got, want := renderHeader(hdr), `
+b1 b2`; got != want {
+			t.Errorf("want %s, got %s", want, got)
+		}
+		if len(hdr) != 1 || hdr[0][0].Field != 1 {
+			t.Errorf("want single level for field 1, got %v", hdr)
+		}
+		var got []string
+		for _, fv := range common {
+			got = append(got, fv.Field.Name+"="+fv.Value)
+		}
+		if want := "a=a1 c=c1"; strings.Join(got, " ") != want {
+			t.Errorf("want common %s, got %s", want, strings.Join(got, " "))
+		}
+	})
+
+	// Test limiting the number of levels.
+	t.Run("maxLevels", func(t *testing.T) {
+		s, _ := mustParse(t, ".config")
+		c1 := p(t, s, "", "a", "a1", "b", "b1", "c", "c1")
+		c2 := p(t, s, "", "a", "a1", "b", "b1", "c", "c2")
+		c3 := p(t, s, "", "a", "a2", "b", "b2")
+		hdr, _ := NewConfigHeaderOpts([]Config{c1, c2, c3}, ConfigHeaderOpts{MaxLevels: 2})
+		checkHeader(t, hdr, `
+a1 -- a2
+b=b1 c=c1 b=b1 c=c2 b=b2`)
+	})
+
+	// Test merging selected fields.
+	t.Run("merge", func(t *testing.T) {
+		s, _ := mustParse(t, ".config")
+		c1 := p(t, s, "", "a", "a1", "b", "b1", "c", "c1")
+		c2 := p(t, s, "", "a", "a2", "b", "b1", "c", "c1")
+		c3 := p(t, s, "", "a", "a2", "b", "b1", "c", "c2")
+		fields := s.Fields()
+		hdr, _ := NewConfigHeaderOpts([]Config{c1, c2, c3}, ConfigHeaderOpts{Merge: []Field{fields[1], fields[2]}})
+		if got, want := renderHeader(hdr), `
+a1 a2 --
+b=b1 c=c1 b=b1 c=c1 b=b1 c=c2`; got != want {
+			t.Errorf("want %s, got %s", want, got)
+		}
+		if hdr[0][0].Field != 0 || hdr[1][0].Field != 1 {
+			t.Errorf("want fields 0, 1, got %d, %d", hdr[0][0].Field, hdr[1][0].Field)
+		}
+
+		// Fields that aren't adjacent stay separate.
+		hdr, _ = NewConfigHeaderOpts([]Config{c1, c2, c3}, ConfigHeaderOpts{Merge: []Field{fields[0], fields[2]}})
+		checkHeader(t, hdr, `
+a1 a2 --
+b1 b1 --
+c1 c1 c2`)
+	})
+}
+
+func TestConfigHeaderTree(t *testing.T) {
+	// Each level of a header must refine the previous level, with
+	// every node equal in all of the fields of its level and the
+	// levels above it, for any combination of options.
+	s, _ := mustParse(t, ".config")
+	var configs []Config
+	for i := 0; i < 24; i++ {
+		configs = append(configs, p(t, s, "", "a", fmt.Sprint(i/12), "b", fmt.Sprint(i/4%3), "c", fmt.Sprint(i/2%2), "d", fmt.Sprint(i%2)))
+	}
+	fields := s.Fields()
+
+	for max := 0; max <= len(fields); max++ {
+		for mask := 0; mask < 1<<len(fields); mask++ {
+			opts := ConfigHeaderOpts{MaxLevels: max}
+			for i, f := range fields {
+				if mask&(1<<i) != 0 {
+					opts.Merge = append(opts.Merge, f)
+				}
+			}
+			hdr, _ := NewConfigHeaderOpts(configs, opts)
+			name := fmt.Sprintf("MaxLevels=%d Merge=%v", max, opts.Merge)
+
+			// shown is the set of fields shown by the
+			// levels so far. Each level must show fields
+			// that come after these in Schema order, so
+			// that it's a refinement of the level above
+			// it rather than repeating its values.
+			shown := make(map[int]bool)
+			for i, level := range hdr {
+				var levelFields []Field
+				for _, f := range fields {
+					if level[0].Field == f.idx || strings.Contains(level[0].Value, f.Name+"=") {
+						levelFields = append(levelFields, f)
+					}
+				}
+				for _, f := range levelFields {
+					for _, later := range fields[f.idx:] {
+						if shown[later.idx] {
+							t.Errorf("%s: level %d shows %s after %s", name, i, f, later)
+						}
+					}
+				}
+				for _, f := range levelFields {
+					shown[f.idx] = true
+				}
+				for j, node := range level {
+					// The configs in node are equal in
+					// every field shown so far.
+					for _, c := range configs[node.Start+1 : node.Start+node.Len] {
+						for _, f := range fields {
+							if shown[f.idx] && c.Get(f) != configs[node.Start].Get(f) {
+								t.Errorf("%s: level %d node %d differs in %s", name, i, j, f)
+							}
+						}
+					}
+					// node starts where some field shown
+					// so far changes, so the level doesn't
+					// repeat nodes.
+					if j > 0 {
+						differ := false
+						for _, f := range fields {
+							if shown[f.idx] && configs[node.Start-1].Get(f) != configs[node.Start].Get(f) {
+								differ = true
+							}
+						}
+						if !differ {
+							t.Errorf("%s: level %d node %d repeats %q", name, i, j, node.Value)
+						}
+					}
+				}
+			}
+			if len(shown) != len(fields) {
+				t.Errorf("%s: header shows %d fields, want %d", name, len(shown), len(fields))
+			}
+		}
+	}
 }
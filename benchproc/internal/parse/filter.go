@@ -59,7 +59,7 @@ loop:
 		case '(', '-', '*', 'w', 'q':
 			q, toks = p.match(toks)
 			terms = append(terms, q)
-		case ')', 'O', 0:
+		case ')', 'O', 'K', 0:
 			break loop
 		default:
 			return nil, p.error(toks, "unexpected "+strconv.Quote(op.Tok))
@@ -92,6 +92,11 @@ func (p *parser) match(start tokenizer) (Filter, tokenizer) {
 		off := tok.Off
 		key := tok.Tok
 		op, toks2 := rest.key()
+		if op.Kind == '(' {
+			// Predicate call.
+			keys, rest := parseWords(toks2, "missing arguments")
+			return &FilterCall{key, keys, off}, rest
+		}
 		if op.Kind != ':' {
 			// TODO: Support other operators
 			return nil, p.error(start, "expected key:value")
@@ -67,4 +67,18 @@ func TestParseFilter(t *testing.T) {
 	check(`a:(b "c " /d/)`, `(a:b OR a:"c " OR a:/d/)`)
 	checkErr(`a:(b AND c)`, "expected value", 5)
 	checkErr(`a:()`, "nothing to match", 3)
+
+	// Comments and newlines
+	check("# Only linux.\ngoos:linux # and amd64\n\tgoarch:amd64\n", `(goos:linux AND goarch:amd64)`)
+	check("a:b#c", `a:b#c`)
+	check(`a:"#b"`, `a:"#b"`)
+	checkErr("a:b\n# c:d\ne", "expected key:value", 10)
+
+	// Predicate calls
+	check(`f(/size)`, `f(/size)`)
+	check(`f (a "b c") -g(d) h:i`, `(f(a "b c") AND -g(d) AND h:i)`)
+	check(`a:b OR f(a)`, `(a:b OR f(a))`)
+	checkErr(`f()`, "missing arguments", 2)
+	checkErr(`f(a`, "missing )", 3)
+	checkErr(`f(a:b)`, "missing )", 3)
 }
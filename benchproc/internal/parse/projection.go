@@ -27,6 +27,13 @@ type Projection struct {
 	// according to their order in this list.
 	Fixed []string
 
+	// Args gives the arguments to a parameterized named order,
+	// such as the bucket boundaries of "bin(1k 32k 1M)".
+	Args []string
+
+	// Desc indicates that Order should be reversed.
+	Desc bool
+
 	// KeyOff and OrderOff give the byte offsets of the key and
 	// order, for error reporting.
 	KeyOff, OrderOff int
@@ -34,29 +41,53 @@ type Projection struct {
 
 // String returns Projection as a valid projection expression.
 func (p Projection) String() string {
+	desc := ""
+	if p.Desc {
+		desc = "-"
+	}
 	switch p.Order {
 	case "first":
-		return quoteWord(p.Key)
-	case "fixed":
-		words := make([]string, 0, len(p.Fixed))
-		for _, word := range p.Fixed {
-			words = append(words, quoteWord(word))
+		if !p.Desc {
+			return quoteWord(p.Key)
 		}
-		return fmt.Sprintf("%s@(%s)", quoteWord(p.Key), strings.Join(words, " "))
+	case "fixed":
+		return fmt.Sprintf("%s@%s(%s)", quoteWord(p.Key), desc, quoteWords(p.Fixed))
+	}
+	if len(p.Args) > 0 {
+		return fmt.Sprintf("%s@%s%s(%s)", quoteWord(p.Key), desc, quoteWord(p.Order), quoteWords(p.Args))
 	}
-	return fmt.Sprintf("%s@%s", quoteWord(p.Key), quoteWord(p.Order))
+	return fmt.Sprintf("%s@%s%s", quoteWord(p.Key), desc, quoteWord(p.Order))
+}
+
+// quoteWords quotes each of words and joins them with spaces.
+func quoteWords(words []string) string {
+	quoted := make([]string, 0, len(words))
+	for _, word := range words {
+		quoted = append(quoted, quoteWord(word))
+	}
+	return strings.Join(quoted, " ")
 }
 
 // ParseProjection parses a projection expression into a tuple of
 // Projections.
 func ParseProjection(q string) ([]Projection, error) {
+	projs, toks := parseProjections(newTokenizer(q))
+	toks.end()
+	if toks.errt.err != nil {
+		return nil, toks.errt.err
+	}
+	return projs, nil
+}
+
+// parseProjections consumes a projection expression up to the end
+// of the string or a query keyword.
+func parseProjections(toks tokenizer) ([]Projection, tokenizer) {
 	// Parse each projection.
 	var projs []Projection
-	toks := newTokenizer(q)
 	for {
 		// Peek at the next token.
 		tok, toks2 := toks.key()
-		if tok.Kind == 0 {
+		if tok.Kind == 0 || tok.Kind == 'K' {
 			// No more projections.
 			break
 		} else if tok.Kind == ',' && len(projs) > 0 {
@@ -68,11 +99,7 @@ func ParseProjection(q string) ([]Projection, error) {
 		proj, toks = parseProjection1(toks)
 		projs = append(projs, proj)
 	}
-	toks.end()
-	if toks.errt.err != nil {
-		return nil, toks.errt.err
-	}
-	return projs, nil
+	return projs, toks
 }
 
 func parseProjection1(toks tokenizer) (Projection, tokenizer) {
@@ -98,37 +125,75 @@ func parseProjection1(toks tokenizer) (Projection, tokenizer) {
 	}
 	toks = toks2
 
+	// Consume optional "-" for a descending order.
+	if minus, toks2 := toks.key(); minus.Kind == '-' {
+		p.Desc = true
+		toks = toks2
+	}
+
 	// Is it a named sort order?
 	order, toks2 := toks.key()
 	p.OrderOff = order.Off
 	if order.Kind == 'w' || order.Kind == 'q' {
 		p.Order = order.Tok
-		return p, toks2
+		toks = toks2
+		// Consume optional order arguments.
+		if open, toks2 := toks.key(); open.Kind == '(' {
+			p.Args, toks = parseWords(toks2, "missing arguments")
+		}
+		return parseDirection(p, toks)
 	}
 	// Or a fixed sort order?
 	if order.Kind == '(' {
 		p.Order = "fixed"
-		toks = toks2
-		for {
-			t, toks2 := toks.key()
-			if t.Kind == 'w' || t.Kind == 'q' {
-				toks = toks2
-				p.Fixed = append(p.Fixed, t.Tok)
-			} else if t.Kind == ')' {
-				if len(p.Fixed) == 0 {
-					_, toks = toks.error("nothing to match")
-				} else {
-					toks = toks2
-				}
-				break
+		p.Fixed, toks = parseWords(toks2, "nothing to match")
+		return parseDirection(p, toks)
+	}
+	// Bad sort order syntax.
+	_, toks = toks.error("expected named sort order or parenthesized list")
+	return p, toks
+}
+
+// parseWords consumes a list of words up to and including a closing
+// ")". If the list is empty, it reports error emptyMsg.
+func parseWords(toks tokenizer, emptyMsg string) ([]string, tokenizer) {
+	var words []string
+	for {
+		t, toks2 := toks.key()
+		if t.Kind == 'w' || t.Kind == 'q' {
+			toks = toks2
+			words = append(words, t.Tok)
+		} else if t.Kind == ')' {
+			if len(words) == 0 {
+				_, toks = toks.error(emptyMsg)
 			} else {
-				_, toks = toks.error("missing )")
-				break
+				toks = toks2
 			}
+			return words, toks
+		} else {
+			_, toks = toks.error("missing )")
+			return words, toks
 		}
+	}
+}
+
+// parseDirection consumes an optional ":asc" or ":desc" suffix
+// following a sort order.
+func parseDirection(p Projection, toks tokenizer) (Projection, tokenizer) {
+	sep, toks2 := toks.key()
+	if sep.Kind != ':' {
 		return p, toks
 	}
-	// Bad sort order syntax.
-	_, toks = toks.error("expected named sort order or parenthesized list")
-	return p, toks
+	toks = toks2
+	dir, toks2 := toks.key()
+	if !(dir.Kind == 'w' || dir.Kind == 'q') || (dir.Tok != "asc" && dir.Tok != "desc") {
+		_, toks = toks.error("expected asc or desc")
+		return p, toks
+	}
+	if p.Desc {
+		_, toks = toks.error("sort direction specified twice")
+		return p, toks
+	}
+	p.Desc = dir.Tok == "desc"
+	return p, toks2
 }
@@ -49,4 +49,20 @@ func TestParseProjection(t *testing.T) {
 	checkErr("a@(", "missing )", 3)
 	checkErr("a@(,", "missing )", 3)
 	checkErr("a@()", "nothing to match", 3)
+
+	check("a@-num, b@num:desc, c@num:asc", "a@-num", "b@-num", "c@num")
+	check("a@-(1 2), b@(3 4):desc", "a@-(1 2)", "b@-(3 4)")
+	check("a@-first, a@first", "a@-first", "a")
+	checkErr("a@-", "expected named sort order or parenthesized list", 3)
+	checkErr("a@num:", "expected asc or desc", 6)
+	checkErr("a@num:up", "expected asc or desc", 6)
+	checkErr("a@-num:desc", "sort direction specified twice", 7)
+
+	check("# Rows\n.name,\n/size@num # biggest last\n", ".name", "/size@num")
+	check("a@(x #y\nz)", "a@(x z)")
+
+	check("a@bin(1k 32k 1M), b@bin(1):desc", "a@bin(1k 32k 1M)", "b@-bin(1)")
+	check("a@bin (1 2) b", "a@bin(1 2)", "b")
+	checkErr("a@bin(", "missing )", 6)
+	checkErr("a@bin()", "missing arguments", 6)
 }
@@ -0,0 +1,66 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package parse
+
+import "strconv"
+
+// A Clause is a single clause of a query.
+type Clause struct {
+	Keyword string // The keyword that introduced this clause
+	Off     int    // Byte offset of Keyword in the query
+
+	// Filter is the filter expression of a filter clause, or nil
+	// for a projection clause.
+	Filter Filter
+	// Projection is the projection expression of a projection
+	// clause.
+	Projection []Projection
+}
+
+// ParseQuery parses a query consisting of a sequence of clauses. Each
+// clause begins with a keyword. filterKeyword introduces a filter
+// expression and each of projKeywords introduces a projection
+// expression. Within a query, keywords are reserved words, much like
+// "AND" and "OR", and must be quoted to be used as keys or values.
+// Each keyword may appear at most once.
+func ParseQuery(q string, filterKeyword string, projKeywords []string) ([]Clause, error) {
+	keywords := map[string]bool{filterKeyword: true}
+	for _, kw := range projKeywords {
+		keywords[kw] = true
+	}
+	toks := newTokenizer(q)
+	toks.keywords = keywords
+
+	var clauses []Clause
+	seen := make(map[string]bool)
+	p := parser{}
+	for {
+		kw, toks2 := toks.key()
+		if kw.Kind == 0 {
+			break
+		} else if kw.Kind != 'K' {
+			toks = p.error(toks, "expected clause keyword")
+			break
+		} else if seen[kw.Tok] {
+			toks = p.error(toks, "duplicate "+strconv.Quote(kw.Tok)+" clause")
+			break
+		}
+		seen[kw.Tok] = true
+		toks = toks2
+
+		clause := Clause{Keyword: kw.Tok, Off: kw.Off}
+		if kw.Tok == filterKeyword {
+			clause.Filter, toks = p.expr(toks)
+		} else {
+			clause.Projection, toks = parseProjections(toks)
+		}
+		clauses = append(clauses, clause)
+	}
+	toks.end()
+	if toks.errt.err != nil {
+		return nil, toks.errt.err
+	}
+	return clauses, nil
+}
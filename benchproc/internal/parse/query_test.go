@@ -0,0 +1,62 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package parse
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	projKeywords := []string{"ROW", "COL"}
+	check := func(query string, want ...string) {
+		t.Helper()
+		clauses, err := ParseQuery(query, "FILTER", projKeywords)
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", query, err)
+			return
+		}
+		var got []string
+		for _, c := range clauses {
+			if c.Filter != nil {
+				got = append(got, c.Keyword+" "+c.Filter.String())
+				continue
+			}
+			var projs []string
+			for _, p := range c.Projection {
+				projs = append(projs, p.String())
+			}
+			got = append(got, c.Keyword+" "+strings.Join(projs, ","))
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %v, want %v", query, got, want)
+		}
+	}
+	checkErr := func(query, error string, pos int) {
+		t.Helper()
+		_, err := ParseQuery(query, "FILTER", projKeywords)
+		if se, _ := err.(*SyntaxError); se == nil || se.Msg != error || se.Off != pos {
+			t.Errorf("%s: want error %s at %d; got %s", query, error, pos, err)
+		}
+	}
+
+	check("")
+	check("FILTER a:b", "FILTER a:b")
+	check("FILTER a:b c:d ROW .name COL /format",
+		"FILTER (a:b AND c:d)", "ROW .name", "COL /format")
+	check("COL x@num ROW a, b", "COL x@num", "ROW a,b")
+	check("FILTER (a:b OR c:d) -e:f ROW", "FILTER ((a:b OR c:d) AND -e:f)", "ROW ")
+	check(`FILTER "ROW":/x/ ROW "COL"`, "FILTER ROW:/x/", "ROW COL")
+	check("FILTER a:/ROW b/", "FILTER a:/ROW b/")
+
+	checkErr("a:b", "expected clause keyword", 0)
+	checkErr("ROW a ROW b", "duplicate \"ROW\" clause", 6)
+	checkErr("FILTER ROW a", "expected key:value or subexpression", 7)
+	checkErr("FILTER a:ROW", "expected key:value", 7)
+	checkErr("FILTER (a:b ROW a)", "missing \")\"", 12)
+	checkErr("ROW a, COL b", "expected key", 7)
+	checkErr("ROW a@(x COL)", "missing )", 9)
+}
@@ -22,17 +22,37 @@ type SyntaxError struct {
 }
 
 func (e *SyntaxError) Error() string {
+	// If the query spans multiple lines, show just the line
+	// containing the error.
+	q, off := e.Query, e.Off
+	if off > len(q) {
+		off = len(q)
+	}
+	line := 0
+	if strings.Contains(q, "\n") {
+		line = 1 + strings.Count(q[:off], "\n")
+		start := strings.LastIndexByte(q[:off], '\n') + 1
+		end := len(q)
+		if i := strings.IndexByte(q[off:], '\n'); i >= 0 {
+			end = off + i
+		}
+		q, off = q[start:end], off-start
+	}
+
 	// Translate byte offset to a rune offset.
 	pos := 0
-	for i, r := range e.Query {
-		if i >= e.Off {
+	for i, r := range q {
+		if i >= off {
 			break
 		}
 		if unicode.IsGraphic(r) {
 			pos++
 		}
 	}
-	return fmt.Sprintf("syntax error: %s\n\t%s\n\t%*s^", e.Msg, e.Query, pos, "")
+	if line > 0 {
+		return fmt.Sprintf("syntax error: line %d: %s\n\t%s\n\t%*s^", line, e.Msg, q, pos, "")
+	}
+	return fmt.Sprintf("syntax error: %s\n\t%s\n\t%*s^", e.Msg, q, pos, "")
 }
 
 type errorTracker struct {
@@ -51,8 +71,8 @@ func (t *errorTracker) error(q string, msg string) {
 type tok struct {
 	// Kind specifies the category of this token. It is either 'w'
 	// or 'q' for an unquoted or quoted word, respectively, 'r'
-	// for a regexp, an operator character, or 0 for the
-	// end-of-string token.
+	// for a regexp, 'K' for a query clause keyword, an operator
+	// character, or 0 for the end-of-string token.
 	Kind   byte
 	Off    int    // Byte offset of the beginning of this token
 	Tok    string // Literal token contents; quoted words are unescaped
@@ -62,10 +82,14 @@ type tok struct {
 type tokenizer struct {
 	q    string
 	errt *errorTracker
+
+	// keywords, if non-nil, is the set of bare words that
+	// tokenize as 'K' keyword tokens.
+	keywords map[string]bool
 }
 
 func newTokenizer(q string) tokenizer {
-	return tokenizer{q, &errorTracker{q, nil}}
+	return tokenizer{q, &errorTracker{q, nil}, nil}
 }
 
 func isOp(ch rune) bool {
@@ -117,6 +141,13 @@ func (t *tokenizer) next(allowRegexp bool) (tok, tokenizer) {
 			return t.tok(t.q[0], t.q[:1], t.q[1:])
 		} else if n := isSpace(t.q); n > 0 {
 			t.q = t.q[n:]
+		} else if t.q[0] == '#' {
+			// Skip comment to the end of the line.
+			if i := strings.IndexByte(t.q, '\n'); i >= 0 {
+				t.q = t.q[i:]
+			} else {
+				t.q = ""
+			}
 		} else if allowRegexp && t.q[0] == '/' {
 			return t.regexp()
 		} else if t.q[0] == '"' {
@@ -132,7 +163,7 @@ func (t *tokenizer) next(allowRegexp bool) (tok, tokenizer) {
 
 func (t *tokenizer) tok(kind byte, token string, rest string) (tok, tokenizer) {
 	off := len(t.errt.qOrig) - len(t.q)
-	return tok{kind, off, token, nil}, tokenizer{rest, t.errt}
+	return tok{kind, off, token, nil}, tokenizer{rest, t.errt, t.keywords}
 }
 
 func (t *tokenizer) error(msg string) (tok, tokenizer) {
@@ -174,6 +205,8 @@ func (t *tokenizer) bareWord() (tok, tokenizer) {
 		return t.tok('A', word, t.q[end:])
 	} else if word == "OR" {
 		return t.tok('O', word, t.q[end:])
+	} else if t.keywords[word] {
+		return t.tok('K', word, t.q[end:])
 	}
 	return t.tok('w', word, t.q[end:])
 }
@@ -188,7 +221,7 @@ func quoteWord(s string) string {
 		case '"', ' ', '\a', '\b':
 			return strconv.Quote(s)
 		}
-		if isOp(r) || unicode.IsSpace(r) || (i == 0 && r == '-' || r == '*') {
+		if isOp(r) || unicode.IsSpace(r) || (i == 0 && r == '-' || r == '*') || (i == 0 && r == '#') {
 			return strconv.Quote(s)
 		}
 	}
@@ -0,0 +1,32 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package parse
+
+import "testing"
+
+func TestSyntaxError(t *testing.T) {
+	check := func(q string, off int, want string) {
+		t.Helper()
+		err := &SyntaxError{q, off, "bad"}
+		if got := err.Error(); got != want {
+			t.Errorf("%q at %d: got %q, want %q", q, off, got, want)
+		}
+	}
+	check("a b", 2, "syntax error: bad\n\ta b\n\t  ^")
+	check("a b", 3, "syntax error: bad\n\ta b\n\t   ^")
+	check("a\nb c\nd", 4, "syntax error: line 2: bad\n\tb c\n\t  ^")
+	check("a\nb c\n", 6, "syntax error: line 3: bad\n\t\n\t^")
+	check("a\nb", 0, "syntax error: line 1: bad\n\ta\n\t^")
+}
+
+func TestQuoteWord(t *testing.T) {
+	for _, word := range []string{"a", "a#b", "#a", "-a", "a b", ""} {
+		toks := newTokenizer(quoteWord(word))
+		tok, _ := toks.key()
+		if (tok.Kind != 'w' && tok.Kind != 'q') || tok.Tok != word {
+			t.Errorf("quoteWord(%q) = %s tokenizes as %q", word, quoteWord(word), tok.Tok)
+		}
+	}
+}
@@ -10,8 +10,8 @@ import (
 	"strings"
 )
 
-// A Filter is a node in the boolean filter. It can either be a
-// FilterOp or a FilterMatch.
+// A Filter is a node in the boolean filter. It can be a FilterOp,
+// a FilterMatch, or a FilterCall.
 type Filter interface {
 	isFilter()
 	String() string
@@ -59,6 +59,22 @@ func (q *FilterMatch) MatchString(value string) bool {
 	return q.Lit == value
 }
 
+// A FilterCall is a leaf in a Filter tree that calls a named
+// predicate function with the values of one or more keys.
+type FilterCall struct {
+	Name string
+	Keys []string
+
+	// Off is the byte offset of the name in the original query,
+	// for error reporting.
+	Off int
+}
+
+func (q *FilterCall) isFilter() {}
+func (q *FilterCall) String() string {
+	return quoteWord(q.Name) + "(" + quoteWords(q.Keys) + ")"
+}
+
 // A FilterOp is a boolean operator in the Filter tree. OpNot must have
 // exactly one child node. OpAnd and OpOr may have zero or more child nodes.
 type FilterOp struct {
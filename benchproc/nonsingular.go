@@ -4,6 +4,12 @@
 
 package benchproc
 
+import (
+	"bytes"
+
+	"golang.org/x/perf/benchfmt"
+)
+
 // NonSingularFields returns the subset of Schema fields for which at
 // least two of configs have different values.
 //
@@ -12,15 +18,12 @@ package benchproc
 // differences. Typically these configurations are "residue"
 // configurations produced by ProjectionParser.Residue.
 func NonSingularFields(configs []Config) []Field {
-	// TODO: This is generally used on residue configs, but those
-	// might just have ".fullname" (generally with implicit
+	// Note that this is generally used on residue configs, but
+	// those might just have ".fullname" (generally with implicit
 	// exclusions). Telling the user that a set of benchmarks
 	// varies in ".fullname" isn't nearly as useful as listing out
-	// the specific subfields. This API can't do that (right now)
-	// because the subfields of .fullname don't even have Fields.
-	// But maybe the residue schema should dynamically break out
-	// .fullname into its parts the way we do with .config (in
-	// which case NonSingularFields would just work).
+	// the specific subfields. The subfields of .fullname don't
+	// have Fields, so NonSingularKeys handles this.
 
 	if len(configs) <= 1 {
 		// There can't be any differences.
@@ -39,3 +42,74 @@ func NonSingularFields(configs []Config) []Field {
 	}
 	return out
 }
+
+// NonSingularKeys is like NonSingularFields, but returns the names of
+// the keys that vary. Where NonSingularFields reports that a
+// ".fullname" field varies, NonSingularKeys instead reports the
+// specific parts of the benchmark name that vary: ".name" for the
+// base name, "/{key}" for a sub-name key, or "/gomaxprocs". If the
+// names differ in a way that can't be attributed to a key, such as in
+// a positional sub-name part, it reports ".fullname".
+func NonSingularKeys(configs []Config) []string {
+	var out []string
+	for _, f := range NonSingularFields(configs) {
+		if f.Name != ".fullname" {
+			out = append(out, f.Name)
+			continue
+		}
+		out = append(out, nonSingularNameKeys(configs, f)...)
+	}
+	return out
+}
+
+// nonSingularNameKeys returns the name keys that vary between the
+// values of full name field f in configs.
+func nonSingularNameKeys(configs []Config, f Field) []string {
+	// Split each name into key/value pairs, tracking the order
+	// in which we first see each key.
+	var keys []string
+	seen := make(map[string]bool)
+	names := make([]map[string]string, len(configs))
+	for i, c := range configs {
+		names[i] = make(map[string]string)
+		nameKeys(benchfmt.Name(c.Get(f)), func(key, val string) {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+			names[i][key] += val
+		})
+	}
+
+	var out []string
+	for _, key := range keys {
+		base := names[0][key]
+		for _, vals := range names[1:] {
+			if vals[key] != base {
+				out = append(out, key)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// nameKeys splits a full benchmark name into keys and calls fn for
+// each key and value. The base name has key ".name". Positional
+// sub-name parts have key ".fullname", and fn may be called more
+// than once for this key.
+func nameKeys(name benchfmt.Name, fn func(key, val string)) {
+	base, parts := name.Parts()
+	fn(".name", string(base))
+	for _, part := range parts {
+		switch {
+		case part[0] == '-':
+			fn("/gomaxprocs", string(part[1:]))
+		case bytes.IndexByte(part, '=') >= 0:
+			eq := bytes.IndexByte(part, '=')
+			fn(string(part[:eq]), string(part[eq+1:]))
+		default:
+			fn(".fullname", string(part))
+		}
+	}
+}
@@ -53,3 +53,43 @@ func TestNonSingularFields(t *testing.T) {
 	}
 	check("a", "b")
 }
+
+func TestNonSingularKeys(t *testing.T) {
+	check := func(cfgs []Config, want ...string) {
+		t.Helper()
+		got := NonSingularKeys(cfgs)
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("want %v, got %v", want, got)
+		}
+	}
+
+	s, _ := mustParse(t, ".config,.fullname")
+	check([]Config{
+		p(t, s, "Name/a=1/b=2-4", "x", "1"),
+		p(t, s, "Name/a=1/b=2-4", "x", "1"),
+	})
+	check([]Config{
+		p(t, s, "Name/a=1/b=2-4", "x", "1"),
+		p(t, s, "Name/a=1/b=3-4", "x", "2"),
+	}, "x", "/b")
+	check([]Config{
+		p(t, s, "Name/a=1/b=2-4"),
+		p(t, s, "Other/a=1/b=2-8"),
+		p(t, s, "Name/a=1/b=2/c=1-4"),
+	}, ".name", "/gomaxprocs", "/c")
+	// Positional parts.
+	check([]Config{
+		p(t, s, "Name/gob/a=1"),
+		p(t, s, "Name/json/a=2"),
+	}, ".fullname", "/a")
+
+	// Excluded keys are normalized, so they don't vary.
+	pp := ProjectionParser{}
+	f, _ := NewFilter("*")
+	pp.Parse(".name", f)
+	s = pp.Residue()
+	check([]Config{
+		p(t, s, "Name/format=json-48"),
+		p(t, s, "Other/format=gob-48"),
+	}, "/format")
+}
@@ -0,0 +1,100 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchproc
+
+import "golang.org/x/perf/benchproc/internal/parse"
+
+// FilterKeyword is the keyword that introduces the filter clause of
+// a query.
+const FilterKeyword = "FILTER"
+
+// A QueryClause describes a projection clause accepted by
+// ProjectionParser.ParseQuery.
+type QueryClause struct {
+	// Keyword is the keyword that introduces this clause in a
+	// query, such as "ROW". By convention, keywords are upper
+	// case.
+	Keyword string
+
+	// Default is the projection expression to use if a query
+	// omits this clause.
+	Default string
+}
+
+// A Query is a filter and a set of projections parsed from a single
+// query string.
+type Query struct {
+	// Filter is the filter from the query's FILTER clause.
+	Filter *Filter
+
+	// Schemas is the projection Schema for each QueryClause,
+	// in the order the clauses were passed to ParseQuery.
+	Schemas []*Schema
+}
+
+// ParseQuery parses a query that combines a filter and several
+// projections in one string, such as
+//
+//	FILTER goos:linux .unit:sec/op ROW .name COL /format
+//
+// A query is a sequence of clauses, each introduced by a keyword.
+// The FILTER clause gives a filter expression and each of clauses
+// describes a keyword that introduces a projection expression. Each
+// clause may appear at most once and clauses may appear in any
+// order. Within a query, keywords are reserved words and must be
+// quoted to be used as keys or values. See "go doc
+// golang.org/x/perf/benchproc/syntax" for the syntax of filters and
+// projections.
+//
+// If the query omits the FILTER clause, ParseQuery uses filter
+// expression defaultFilter. If it omits any projection clause, it
+// uses that clause's Default. Regardless of their order in q, the
+// projections are parsed in the order of clauses, as if by
+// successive calls to Parse.
+func (p *ProjectionParser) ParseQuery(q, defaultFilter string, clauses []QueryClause) (*Query, error) {
+	keywords := make([]string, len(clauses))
+	for i, c := range clauses {
+		keywords[i] = c.Keyword
+	}
+	parsed, err := parse.ParseQuery(q, FilterKeyword, keywords)
+	if err != nil {
+		return nil, err
+	}
+	byKeyword := make(map[string]parse.Clause)
+	for _, c := range parsed {
+		byKeyword[c.Keyword] = c
+	}
+
+	// Construct the filter first because projections may add to
+	// it.
+	fp := p.FilterParser
+	if fp == nil {
+		fp = &FilterParser{}
+	}
+	var filter *Filter
+	if c, ok := byKeyword[FilterKeyword]; ok {
+		filter, err = fp.newFilter(q, c.Filter)
+	} else {
+		filter, err = fp.Parse(defaultFilter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	query := &Query{Filter: filter, Schemas: make([]*Schema, len(clauses))}
+	for i, clause := range clauses {
+		var s *Schema
+		if c, ok := byKeyword[clause.Keyword]; ok {
+			s, err = p.parse(q, c.Projection, filter)
+		} else {
+			s, err = p.Parse(clause.Default, filter)
+		}
+		if err != nil {
+			return nil, err
+		}
+		query.Schemas[i] = s
+	}
+	return query, nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchproc
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/perf/benchproc/internal/parse"
+)
+
+func TestParseQuery(t *testing.T) {
+	clauses := []QueryClause{
+		{"ROW", ".fullname"},
+		{"COL", ".label"},
+	}
+
+	t.Run("basic", func(t *testing.T) {
+		var pp ProjectionParser
+		q, err := pp.ParseQuery("COL /format FILTER goos:linux ROW .name", "*", clauses)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := fieldNames(q.Schemas[0]), []string{".name"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("ROW: got %v, want %v", got, want)
+		}
+		if got, want := fieldNames(q.Schemas[1]), []string{"/format"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("COL: got %v, want %v", got, want)
+		}
+		if !q.Filter.Apply(r(t, "Name", "goos", "linux")) {
+			t.Errorf("filter rejected goos:linux")
+		}
+		if q.Filter.Apply(r(t, "Name", "goos", "darwin")) {
+			t.Errorf("filter accepted goos:darwin")
+		}
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		var pp ProjectionParser
+		q, err := pp.ParseQuery("ROW .name", "goos:darwin", clauses)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := fieldNames(q.Schemas[1]), []string{".label"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("COL: got %v, want %v", got, want)
+		}
+		if !q.Filter.Apply(r(t, "Name", "goos", "darwin")) {
+			t.Errorf("filter rejected goos:darwin")
+		}
+	})
+
+	t.Run("fixed", func(t *testing.T) {
+		// Fixed orders in projections add to the query filter.
+		var pp ProjectionParser
+		q, err := pp.ParseQuery("ROW .name COL goos@(linux)", "*", clauses)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if q.Filter.Apply(r(t, "Name", "goos", "darwin")) {
+			t.Errorf("filter accepted goos:darwin")
+		}
+	})
+
+	t.Run("predicate", func(t *testing.T) {
+		fp := &FilterParser{}
+		fp.AddPredicate("small", func(vals []string) bool { return len(vals[0]) < 3 })
+		pp := ProjectionParser{FilterParser: fp}
+		q, err := pp.ParseQuery("FILTER small(/size)", "*", clauses)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !q.Filter.Apply(r(t, "Name/size=1k")) || q.Filter.Apply(r(t, "Name/size=1024")) {
+			t.Errorf("predicate not applied")
+		}
+	})
+
+	checkErr := func(query, error string, pos int) {
+		t.Helper()
+		var pp ProjectionParser
+		_, err := pp.ParseQuery(query, "*", clauses)
+		if se, _ := err.(*parse.SyntaxError); se == nil || se.Msg != error || se.Off != pos || se.Query != query {
+			t.Errorf("%s: want error %s at %d; got %s", query, error, pos, err)
+		}
+	}
+	checkErr("ROW .name COL x@foo", "unknown order \"foo\"", 16)
+	checkErr(`ROW .name FILTER "":x`, "key must not be empty", 17)
+	checkErr("TABLE .config", "expected clause keyword", 0)
+}
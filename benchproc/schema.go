@@ -5,9 +5,15 @@
 package benchproc
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"hash/fnv"
 	"hash/maphash"
+	"io"
+	"sort"
 	"strings"
+	"sync"
 
 	"golang.org/x/perf/benchfmt"
 	"golang.org/x/perf/benchproc/internal/parse"
@@ -33,17 +39,44 @@ import (
 // configuration keys "commit" and "date" are excluded from the group
 // key ".config".
 type ProjectionParser struct {
+	// FilterParser, if non-nil, parses the FILTER clauses of
+	// queries passed to ParseQuery. This allows queries to call
+	// its predicate functions.
+	FilterParser *FilterParser
+
 	configKeys   map[string]bool // Specific .config keys (excluded from .config)
 	fullnameKeys []string        // Specific sub-name keys (excluded from .fullname)
 	haveConfig   bool            // .config was projected
 	haveFullname bool            // .fullname was projected
+	haveNameKeys bool            // .namekeys was projected
+
+	orders map[string]func(a, b string) int // Custom named orders
 
 	// Fields below here are constructed when the first Result is
 	// processed.
 
+	fullOnce      sync.Once
 	fullExtractor extractor
 }
 
+// AddOrder registers a custom named sort order that can be used in
+// projection expressions subsequently parsed by p as "key@name".
+// cmp must return a negative number if a sorts before b, a positive
+// number if a sorts after b, or 0 if a and b are unordered. Custom
+// orders take precedence over built-in named orders. AddOrder panics
+// if name is "first", "fixed", "bin", or "window", which have special
+// meaning.
+func (p *ProjectionParser) AddOrder(name string, cmp func(a, b string) int) {
+	switch name {
+	case "first", "fixed", "bin", "window":
+		panic(fmt.Sprintf("cannot redefine order %q", name))
+	}
+	if p.orders == nil {
+		p.orders = make(map[string]func(a, b string) int)
+	}
+	p.orders[name] = cmp
+}
+
 // Parse parses a single projection expression, such as ".name,/size".
 // See "go doc golang.org/x/perf/benchproc/syntax" for a description
 // of projection syntax.
@@ -51,20 +84,25 @@ type ProjectionParser struct {
 // If the projection expression contains any fixed orders that imply a
 // filter, Parse will add these filters to "filter".
 func (p *ProjectionParser) Parse(proj string, filter *Filter) (*Schema, error) {
-	if p.configKeys == nil {
-		p.configKeys = make(map[string]bool)
-	}
-
-	s := newSchema()
-
 	// Parse the projection.
 	parts, err := parse.ParseProjection(proj)
 	if err != nil {
 		return nil, err
 	}
+	return p.parse(proj, parts, filter)
+}
+
+// parse constructs a Schema from parts, which were parsed from query
+// string q.
+func (p *ProjectionParser) parse(q string, parts []parse.Projection, filter *Filter) (*Schema, error) {
+	if p.configKeys == nil {
+		p.configKeys = make(map[string]bool)
+	}
+
+	s := newSchema()
 	var filterParts []filterFn
 	for _, part := range parts {
-		f, err := p.makeProjection(s, proj, part)
+		f, err := p.makeProjection(s, q, part)
 		if err != nil {
 			return nil, err
 		}
@@ -107,6 +145,16 @@ func (p *ProjectionParser) makeProjection(s *Schema, q string, proj parse.Projec
 	var initField func(field Field)
 	var filter filterFn
 	makeFilter := func(ext extractor) {}
+	// mapVal, if non-nil, maps each extracted value before it is
+	// stored in the Config.
+	var mapVal func(val []byte) []byte
+	mapping := proj.Order == "bin" || proj.Order == "window"
+	if len(proj.Args) > 0 && !mapping {
+		return nil, &parse.SyntaxError{q, proj.OrderOff, fmt.Sprintf("order %q does not take arguments", proj.Order)}
+	}
+	if mapping && (proj.Key == ".config" || proj.Key == ".fullname" || proj.Key == ".namekeys") {
+		return nil, &parse.SyntaxError{q, proj.OrderOff, fmt.Sprintf("%s order not allowed for %s", proj.Order, proj.Key)}
+	}
 	if proj.Order == "fixed" {
 		fixedMap := make(map[string]int, len(proj.Fixed))
 		for i, s := range proj.Fixed {
@@ -130,6 +178,28 @@ func (p *ProjectionParser) makeProjection(s *Schema, q string, proj parse.Projec
 				return field.order[a] - field.order[b]
 			}
 		}
+	} else if proj.Order == "bin" {
+		b, err := newBinner(proj.Args)
+		if err != nil {
+			return nil, &parse.SyntaxError{q, proj.OrderOff, err.Error()}
+		}
+		initField = func(field Field) {
+			field.cmp = b.cmp
+		}
+		mapVal = b.bin
+	} else if proj.Order == "window" {
+		w, err := newWindower(proj.Args)
+		if err != nil {
+			return nil, &parse.SyntaxError{q, proj.OrderOff, err.Error()}
+		}
+		initField = func(field Field) {
+			field.cmp = builtinOrders["date"]
+		}
+		mapVal = w.window
+	} else if cmp, ok := p.orders[proj.Order]; ok {
+		initField = func(field Field) {
+			field.cmp = cmp
+		}
 	} else if cmp, ok := builtinOrders[proj.Order]; ok {
 		initField = func(field Field) {
 			field.cmp = cmp
@@ -137,8 +207,19 @@ func (p *ProjectionParser) makeProjection(s *Schema, q string, proj parse.Projec
 	} else {
 		return nil, &parse.SyntaxError{q, proj.OrderOff, fmt.Sprintf("unknown order %q", proj.Order)}
 	}
+	if proj.Desc {
+		// Reverse the order.
+		ascField := initField
+		initField = func(field Field) {
+			ascField(field)
+			cmp := field.cmp
+			field.cmp = func(a, b string) int {
+				return cmp(b, a)
+			}
+		}
+	}
 
-	var project func(*benchfmt.Result, *[]string)
+	var project func(*benchfmt.Result, *Projector)
 	switch proj.Key {
 	case ".config":
 		// File configuration, excluding any more
@@ -151,20 +232,78 @@ func (p *ProjectionParser) makeProjection(s *Schema, q string, proj parse.Projec
 		p.haveConfig = true
 		group := s.addGroup(s.root, ".config")
 		seen := make(map[string]Field)
-		project = func(r *benchfmt.Result, row *[]string) {
+		s.configField = func(key string) (Field, bool) {
+			s.mu.RLock()
+			field, ok := seen[key]
+			s.mu.RUnlock()
+			if !ok {
+				if p.configKeys[key] {
+					return Field{}, false
+				}
+				s.mu.Lock()
+				defer s.mu.Unlock()
+				if field, ok = seen[key]; !ok {
+					field = s.addField(group, key)
+					initField(field)
+					seen[key] = field
+				}
+			}
+			return field, true
+		}
+		project = func(r *benchfmt.Result, pr *Projector) {
 			for _, cfg := range r.FileConfig {
-				field, ok := seen[cfg.Key]
+				field, ok := s.configField(cfg.Key)
 				if !ok {
-					if p.configKeys[cfg.Key] {
-						continue
+					continue
+				}
+				pr.set(field, pr.intern(cfg.Value))
+			}
+		}
+
+	case ".namekeys":
+		// Sub-name configuration keys, excluding any more
+		// specific sub-name keys. Like .config, this expands
+		// into a field for each key as we observe them.
+		if proj.Order == "fixed" {
+			// Fixed orders don't make sense for a whole tuple.
+			return nil, &parse.SyntaxError{q, proj.OrderOff, fmt.Sprintf("fixed order not allowed for .namekeys")}
+		}
+
+		p.haveNameKeys = true
+		group := s.addGroup(s.root, ".namekeys")
+		seen := make(map[string]Field)
+		s.nameKeyField = func(key string) (Field, bool) {
+			s.mu.RLock()
+			field, ok := seen[key]
+			s.mu.RUnlock()
+			if !ok {
+				for _, k := range p.fullnameKeys {
+					if k == key {
+						return Field{}, false
 					}
-					field = s.addField(group, cfg.Key)
+				}
+				s.mu.Lock()
+				defer s.mu.Unlock()
+				if field, ok = seen[key]; !ok {
+					field = s.addField(group, key)
 					initField(field)
-					seen[cfg.Key] = field
+					seen[key] = field
 				}
-
-				(*row)[field.idx] = s.intern(cfg.Value)
 			}
+			return field, true
+		}
+		project = func(r *benchfmt.Result, pr *Projector) {
+			nameKeys(r.Name, func(key, val string) {
+				if !strings.HasPrefix(key, "/") {
+					// Base name or positional part.
+					return
+				}
+				field, ok := s.nameKeyField(key)
+				if !ok {
+					return
+				}
+				pr.set(field, pr.intern([]byte(val)))
+			})
 		}
 
 	case ".fullname":
@@ -183,12 +322,12 @@ func (p *ProjectionParser) makeProjection(s *Schema, q string, proj parse.Projec
 		initField(field)
 		makeFilter(extractFull)
 
-		project = func(r *benchfmt.Result, row *[]string) {
-			if p.fullExtractor == nil {
-				p.fullExtractor = newExtractorFullName(p.fullnameKeys)
-			}
+		project = func(r *benchfmt.Result, pr *Projector) {
+			p.fullOnce.Do(func() {
+				p.fullExtractor = newExtractorFullName(p.fullnameKeys, p.haveNameKeys)
+			})
 			val := p.fullExtractor(r)
-			(*row)[field.idx] = s.intern(val)
+			pr.set(field, pr.intern(val))
 		}
 
 	default:
@@ -196,6 +335,11 @@ func (p *ProjectionParser) makeProjection(s *Schema, q string, proj parse.Projec
 		// to the excludes.
 		if proj.Key == ".name" || strings.HasPrefix(proj.Key, "/") {
 			p.fullnameKeys = append(p.fullnameKeys, proj.Key)
+		} else if proj.Key == ".iters" || proj.Key == ".file-index" {
+			// These don't come from the name or file
+			// configuration, so the projection cache
+			// can't be used.
+			s.uncacheable = true
 		} else {
 			p.configKeys[proj.Key] = true
 		}
@@ -206,12 +350,16 @@ func (p *ProjectionParser) makeProjection(s *Schema, q string, proj parse.Projec
 		field := s.addField(s.root, proj.Key)
 		initField(field)
 		makeFilter(ext)
-		project = func(r *benchfmt.Result, row *[]string) {
+		project = func(r *benchfmt.Result, pr *Projector) {
 			val := ext(r)
-			(*row)[field.idx] = s.intern(val)
+			if mapVal != nil {
+				val = mapVal(val)
+			}
+			pr.set(field, pr.intern(val))
 		}
 	}
 	s.project = append(s.project, project)
+	s.parts = append(s.parts, proj)
 	return filter, nil
 }
 
@@ -222,7 +370,17 @@ func (p *ProjectionParser) makeProjection(s *Schema, q string, proj parse.Projec
 // as map keys). A Schema also implies a sort order, which is
 // lexicographic based on the order of fields in the Schema, with the
 // order of each individual field determined by the projection.
+//
+// A Schema is safe for concurrent use by multiple goroutines, but
+// its Project and ProjectValues methods project one Result at a time.
+// To project Results in parallel, use a Projector in each goroutine.
 type Schema struct {
+	// mu protects the structure of the Schema, which grows as
+	// Results are projected: the fields, the fields of the
+	// .config and .namekeys groups, and the observation orders of
+	// fields.
+	mu sync.RWMutex
+
 	root    Field
 	nFields int
 
@@ -230,34 +388,75 @@ type Schema struct {
 	// the values of a benchmark result.
 	unitField Field
 
-	// flatCache, if non-nil, contains the flattened sequence of
-	// fields.
+	// flatCache is the flattened sequence of fields. It is
+	// rebuilt whenever a field is added.
 	flatCache []Field
 
 	// project is a set of functions that project a Result into
-	// row.
-	//
-	// These take a pointer to row because these functions may
-	// grow the schema, so the row slice may grow.
-	project []func(r *benchfmt.Result, row *[]string)
+	// a Projector's row.
+	project []func(r *benchfmt.Result, pr *Projector)
 
-	// row is the buffer used to construct a projection.
-	row []string
+	// configs are the interned Configs of this Schema, sharded by
+	// hash so that Projectors can intern Configs concurrently.
+	configs [configShards]configShard
 
-	// interns is used to intern []byte to string. These are
-	// always referenced in Configs, so this doesn't cause any
-	// over-retention.
-	interns map[string]string
+	// parts are the parsed projection components that built this
+	// Schema, in order.
+	parts []parse.Projection
+
+	// projMu protects proj and cacheEnabled.
+	projMu sync.Mutex
+	// proj is the Projector used by Project, ProjectValues, and
+	// Convert.
+	proj *Projector
+	// cacheEnabled indicates that new Projectors should use a
+	// projection cache. See SetCache.
+	cacheEnabled bool
+	// uncacheable indicates that this Schema projects components
+	// of Results other than their name and file configuration,
+	// so the projection cache must not be used.
+	uncacheable bool
+
+	// configField, if non-nil, returns the field in the .config
+	// group for file configuration key, adding it if necessary.
+	// It returns false if key is excluded from the group.
+	configField func(key string) (Field, bool)
+
+	// nameKeyField is like configField, but for sub-name keys in
+	// the .namekeys group.
+	nameKeyField func(key string) (Field, bool)
+}
+
+// configShards is the number of shards of a Schema's Config table.
+const configShards = 64
+
+// A configShard is one shard of a Schema's Config table, mapping
+// from the hash of a Config's values to Configs with that hash.
+type configShard struct {
+	mu sync.Mutex
+	m  map[uint64][]*configNode
+}
 
-	// configs are the interned Configs of this Schema.
-	configs map[uint64][]*configNode
+// A projectCacheEntry is a memoized projection of a Result.
+type projectCacheEntry struct {
+	// row is the projected row, not including any .unit field,
+	// or nil if it hasn't been projected yet.
+	row []string
+	// cfg is the interned Config of row, or the zero Config if it
+	// hasn't been interned yet.
+	cfg Config
+	// units maps from a .unit value to the interned Config of row
+	// with that unit.
+	units map[string]Config
 }
 
 func newSchema() *Schema {
 	var s Schema
 	s.root.fieldInternal = &fieldInternal{idx: -1}
-	s.interns = make(map[string]string)
-	s.configs = make(map[uint64][]*configNode)
+	for i := range s.configs {
+		s.configs[i].m = make(map[uint64][]*configNode)
+	}
+	s.proj = s.NewProjector()
 	return &s
 }
 
@@ -270,10 +469,21 @@ func (s *Schema) addField(group Field, name string) Field {
 	field := Field{name, &fieldInternal{schema: s, idx: s.nFields}}
 	s.nFields++
 	group.sub = append(group.sub, field)
-	// Add to the row buffer.
-	s.row = append(s.row, "")
-	// Clear the current flattening.
-	s.flatCache = nil
+	// Rebuild the flattening. This is a new slice, so slices
+	// returned by Fields remain valid.
+	flat := make([]Field, 0, s.nFields)
+	var walk func(f Field)
+	walk = func(f Field) {
+		if f.idx != -1 {
+			flat = append(flat, f)
+		} else {
+			for _, sub := range f.sub {
+				walk(sub)
+			}
+		}
+	}
+	walk(s.root)
+	s.flatCache = flat
 	return field
 }
 
@@ -291,8 +501,10 @@ func (s *Schema) addGroup(group Field, name string) Field {
 //
 // Typically, callers need to break out individual benchmark values on
 // some dimension of a set of Schemas. Adding a .unit field makes this
-// easy.
+// easy. AddValues must be called before projecting any Results.
 func (s *Schema) AddValues() Field {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	if s.unitField.fieldInternal != nil {
 		panic("Schema already has a .unit field")
 	}
@@ -313,25 +525,28 @@ func (s *Schema) AddValues() Field {
 //
 // The caller must not modify the returned slice.
 func (s *Schema) Fields() []Field {
-	if s.flatCache != nil {
-		return s.flatCache
-	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.fields()
+}
 
-	s.flatCache = make([]Field, 0, s.nFields)
-	var walk func(f Field)
-	walk = func(f Field) {
-		if f.idx != -1 {
-			s.flatCache = append(s.flatCache, f)
-		} else {
-			for _, sub := range f.sub {
-				walk(sub)
-			}
-		}
-	}
-	walk(s.root)
+// fields is like Fields, but s.mu must be held.
+func (s *Schema) fields() []Field {
 	return s.flatCache
 }
 
+// String returns s as a projection expression. Parsing the result
+// with a ProjectionParser produces an equivalent Schema, including
+// sort orders and fixed value lists. The ".unit" field added by
+// AddValues is not part of the projection expression and is omitted.
+func (s *Schema) String() string {
+	parts := make([]string, len(s.parts))
+	for i, part := range s.parts {
+		parts[i] = part.String()
+	}
+	return strings.Join(parts, ",")
+}
+
 // A Field is a single dimension of a Schema.
 type Field struct {
 	Name string
@@ -376,8 +591,9 @@ var configSeed = maphash.MakeSeed()
 // If this Schema includes a .units field, it will be left as "" in
 // the resulting Config. The caller should use ProjectValues instead.
 func (s *Schema) Project(r *benchfmt.Result) Config {
-	s.populateRow(r)
-	return s.internRow()
+	s.projMu.Lock()
+	defer s.projMu.Unlock()
+	return s.proj.Project(r)
 }
 
 // ProjectValues is like Project, but for each benchmark value of
@@ -388,44 +604,267 @@ func (s *Schema) Project(r *benchfmt.Result) Config {
 // these Configs. If not, then all of the Configs will be identical
 // because the benchmark values vary only on .unit.
 func (s *Schema) ProjectValues(r *benchfmt.Result) []Config {
-	s.populateRow(r)
+	s.projMu.Lock()
+	defer s.projMu.Unlock()
+	return s.proj.ProjectValues(r)
+}
+
+// A Projector projects Results into Configs of a Schema, like the
+// Schema's Project and ProjectValues methods. Each Projector has its
+// own row buffer, interning table, and projection cache, so
+// goroutines that each use their own Projector can project Results
+// in parallel. Configs from all of the Projectors of a Schema are ==
+// if they have the same values, just like Configs from the Schema.
+//
+// A Projector must not be used by multiple goroutines at once.
+type Projector struct {
+	s *Schema
+
+	// row is the buffer used to construct a projection, indexed
+	// by field index. It may be shorter than the number of
+	// fields, in which case the missing values are "".
+	row []string
+
+	// interns is used to intern []byte to string. These are
+	// always referenced in Configs, so this doesn't cause any
+	// over-retention.
+	interns map[string]string
+
+	// cache, if non-nil, memoizes projections of Results with
+	// the same name and file configuration. It maps from an
+	// encoded file configuration to a full benchmark name to a
+	// projection.
+	cache map[string]map[string]*projectCacheEntry
+	// cacheConfig is the file configuration of the last Result
+	// looked up in the cache, and cacheNames is the sub-map of
+	// cache for that file configuration. Consecutive Results
+	// usually have the same file configuration, so this avoids
+	// encoding the file configuration of most Results.
+	cacheConfig []benchfmt.Config
+	cacheNames  map[string]*projectCacheEntry
+}
+
+// NewProjector returns a new Projector for s. The Projector uses a
+// projection cache if one is enabled for s by SetCache.
+//
+// When Results are projected concurrently, the observation order of
+// fields sorted by first observation depends on which Projector
+// first interns each Config.
+func (s *Schema) NewProjector() *Projector {
+	pr := &Projector{s: s, interns: make(map[string]string)}
+	if s.cacheEnabled {
+		pr.cache = make(map[string]map[string]*projectCacheEntry)
+	}
+	return pr
+}
+
+// Project is like Schema.Project, but uses pr's buffers.
+func (pr *Projector) Project(r *benchfmt.Result) Config {
+	e := pr.lookupCache(r)
+	if e != nil && !e.cfg.IsZero() {
+		return e.cfg
+	}
+	pr.loadRow(r, e)
+	cfg := pr.s.internRow(pr.row)
+	if e != nil {
+		e.cfg = cfg
+	}
+	return cfg
+}
+
+// ProjectValues is like Schema.ProjectValues, but uses pr's buffers.
+func (pr *Projector) ProjectValues(r *benchfmt.Result) []Config {
+	s := pr.s
+	e := pr.lookupCache(r)
 	out := make([]Config, len(r.Values))
 	if s.unitField.fieldInternal == nil {
 		// There's no .unit, so the Configs will all be the same.
-		cfg := s.internRow()
+		var cfg Config
+		if e != nil && !e.cfg.IsZero() {
+			cfg = e.cfg
+		} else {
+			pr.loadRow(r, e)
+			cfg = s.internRow(pr.row)
+			if e != nil {
+				e.cfg = cfg
+			}
+		}
 		for i := range out {
 			out[i] = cfg
 		}
 		return out
 	}
 	// Vary the .unit field.
+	loaded := false
 	for i, val := range r.Values {
-		s.row[s.unitField.idx] = val.Unit
-		out[i] = s.internRow()
+		if e != nil {
+			if cfg, ok := e.units[val.Unit]; ok {
+				out[i] = cfg
+				continue
+			}
+		}
+		if !loaded {
+			pr.loadRow(r, e)
+			loaded = true
+		}
+		pr.set(s.unitField, val.Unit)
+		out[i] = s.internRow(pr.row)
+		if e != nil {
+			if e.units == nil {
+				e.units = make(map[string]Config)
+			}
+			e.units[val.Unit] = out[i]
+		}
 	}
 	return out
 }
 
-func (s *Schema) populateRow(r *benchfmt.Result) {
-	// Clear the row buffer.
-	for i := range s.row {
-		s.row[i] = ""
+// Reset discards the Configs interned by s and resets the
+// observation order of fields that are sorted by first observation.
+// This allows a long-running process to project an unbounded stream
+// of Results in separate batches without accumulating memory, and
+// allows logically separate data sets to be ordered independently.
+//
+// The fields of s are retained. Configs returned before Reset remain
+// valid, but they will not be == to Configs returned after Reset and
+// they must not be compared or sorted with them. Likewise, Projectors
+// created before Reset must not be used after it.
+func (s *Schema) Reset() {
+	s.projMu.Lock()
+	defer s.projMu.Unlock()
+	for i := range s.configs {
+		s.configs[i].mu.Lock()
+		defer s.configs[i].mu.Unlock()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.proj = s.NewProjector()
+	for i := range s.configs {
+		s.configs[i].m = make(map[uint64][]*configNode)
+	}
+	for _, field := range s.fields() {
+		if field.order != nil {
+			field.order = make(map[string]int)
+		}
+	}
+}
+
+// SetCache enables or disables memoization of projections in s.
+// When enabled, projecting a Result with the same full name and file
+// configuration as an earlier Result reuses the earlier projection
+// rather than extracting and interning its components again. Since
+// benchmarks are typically run many times, this can significantly
+// speed up projection. The cache is keyed by file configuration and
+// is periodically discarded if there are many distinct file
+// configurations. Each Projector has its own cache.
+//
+// The cache assumes the projection of a Result depends only on its
+// name and file configuration, which is true of all projections
+// except those involving ".iters" or ".file-index". The cache is not
+// used for these projections even if it is enabled.
+func (s *Schema) SetCache(enabled bool) {
+	s.projMu.Lock()
+	defer s.projMu.Unlock()
+	s.cacheEnabled = enabled
+	s.proj.cache, s.proj.cacheConfig, s.proj.cacheNames = nil, nil, nil
+	if enabled {
+		s.proj.cache = make(map[string]map[string]*projectCacheEntry)
+	}
+}
+
+// lookupCache returns the projection cache entry for r. If there is
+// no entry, it adds an empty entry. If the cache is disabled, it
+// returns nil.
+func (pr *Projector) lookupCache(r *benchfmt.Result) *projectCacheEntry {
+	if pr.cache == nil || pr.s.uncacheable {
+		return nil
+	}
+	if pr.cacheNames == nil || !equalFileConfig(r.FileConfig, pr.cacheConfig) {
+		// Switch to the cache for this file configuration.
+		pr.cacheConfig = pr.cacheConfig[:0]
+		var key []byte
+		for _, c := range r.FileConfig {
+			pr.cacheConfig = append(pr.cacheConfig, benchfmt.Config{Key: c.Key, Value: append([]byte(nil), c.Value...)})
+			key = append(key, c.Key...)
+			key = append(key, 0)
+			key = append(key, c.Value...)
+			key = append(key, 0)
+		}
+		pr.cacheNames = pr.cache[string(key)]
+		if pr.cacheNames == nil {
+			if len(pr.cache) >= maxCacheConfigs {
+				// Bound the size of the cache.
+				pr.cache = make(map[string]map[string]*projectCacheEntry)
+			}
+			pr.cacheNames = make(map[string]*projectCacheEntry)
+			pr.cache[string(key)] = pr.cacheNames
+		}
+	}
+	e := pr.cacheNames[string(r.Name)]
+	if e == nil {
+		e = new(projectCacheEntry)
+		pr.cacheNames[string(r.Name)] = e
+	}
+	return e
+}
+
+// maxCacheConfigs is the maximum number of file configurations to
+// retain in a projection cache.
+const maxCacheConfigs = 1024
+
+// loadRow fills pr.row with the projection of r. If cache entry e is
+// non-nil, it uses e's cached row if there is one, or else records
+// the projected row in e.
+func (pr *Projector) loadRow(r *benchfmt.Result, e *projectCacheEntry) {
+	if e == nil || e.row == nil {
+		pr.populateRow(r)
+		if e != nil {
+			e.row = append([]string{}, pr.row...)
+		}
+		return
 	}
+	pr.row = append(pr.row[:0], e.row...)
+}
+
+func equalFileConfig(a, b []benchfmt.Config) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Key != b[i].Key || !bytes.Equal(a[i].Value, b[i].Value) {
+			return false
+		}
+	}
+	return true
+}
+
+func (pr *Projector) populateRow(r *benchfmt.Result) {
+	// Clear the row buffer.
+	pr.row = pr.row[:0]
 
 	// Run the projection functions to fill in row.
-	for _, proj := range s.project {
+	for _, proj := range pr.s.project {
 		// proj may add fields and grow row.
-		proj(r, &s.row)
+		proj(r, pr)
+	}
+}
+
+// set sets the value of field in pr.row, growing pr.row if necessary.
+func (pr *Projector) set(field Field, val string) {
+	for len(pr.row) <= field.idx {
+		pr.row = append(pr.row, "")
 	}
+	pr.row[field.idx] = val
 }
 
-func (s *Schema) internRow() Config {
+// internRow returns the Config of s with the values in row, indexed
+// by field index.
+func (s *Schema) internRow(row []string) Config {
 	// Hash the configuration. This must be invariant to unused
 	// trailing fields: the schema can grow, and if those new
 	// fields are later cleared, we want configurations from
 	// before the growth to equal configurations from after the
 	// growth.
-	row := s.row
 	for len(row) > 0 && row[len(row)-1] == "" {
 		row = row[:len(row)-1]
 	}
@@ -437,7 +876,10 @@ func (s *Schema) internRow() Config {
 	hash := h.Sum64()
 
 	// Check if we already have this configuration.
-	configs := s.configs[hash]
+	shard := &s.configs[hash%configShards]
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	configs := shard.m[hash]
 	for _, config := range configs {
 		if config.equalRow(row) {
 			return Config{config}
@@ -445,7 +887,8 @@ func (s *Schema) internRow() Config {
 	}
 
 	// Update observation orders.
-	for _, field := range s.Fields() {
+	s.mu.Lock()
+	for _, field := range s.fields() {
 		if field.order == nil {
 			// Not tracking observation order for this field.
 			continue
@@ -458,19 +901,20 @@ func (s *Schema) internRow() Config {
 			field.order[val] = len(field.order)
 		}
 	}
+	s.mu.Unlock()
 
 	// Save the config.
 	config := &configNode{s, append([]string(nil), row...)}
-	s.configs[hash] = append(s.configs[hash], config)
+	shard.m[hash] = append(shard.m[hash], config)
 	return Config{config}
 }
 
-func (s *Schema) intern(b []byte) string {
-	if str, ok := s.interns[string(b)]; ok {
+func (pr *Projector) intern(b []byte) string {
+	if str, ok := pr.interns[string(b)]; ok {
 		return str
 	}
 	str := string(b)
-	s.interns[str] = str
+	pr.interns[str] = str
 	return str
 }
 
@@ -512,6 +956,40 @@ func (c Config) Schema() *Schema {
 	return c.c.schema
 }
 
+// A FieldValue is a Field and its value in some Config.
+type FieldValue struct {
+	Field Field
+	Value string
+}
+
+// Fields returns the fields of Config c and their values, in schema
+// order. This includes fields with empty values.
+func (c Config) Fields() []FieldValue {
+	if c.IsZero() {
+		return nil
+	}
+	fields := c.c.schema.Fields()
+	out := make([]FieldValue, len(fields))
+	for i, field := range fields {
+		out[i] = FieldValue{field, c.Get(field)}
+	}
+	return out
+}
+
+// Map returns Config c as a map from field names to values. This
+// includes fields with empty values.
+func (c Config) Map() map[string]string {
+	if c.IsZero() {
+		return nil
+	}
+	fields := c.c.schema.Fields()
+	m := make(map[string]string, len(fields))
+	for _, field := range fields {
+		m[field.Name] = c.Get(field)
+	}
+	return m
+}
+
 // String returns Config as a space-separated sequence of key:value
 // pairs in schema order.
 func (c Config) String() string {
@@ -549,6 +1027,165 @@ func (c Config) string(keys bool) string {
 	return buf.String()
 }
 
+// Hash returns a 64-bit hash of the field names and non-empty values
+// of c. Unlike the internal hashing used to intern Configs, this hash
+// is stable across processes and program runs, so it's suitable for
+// keying external caches and on-disk indexes. Configs with the same
+// field values have the same hash, even if they come from different
+// Schemas or their Schemas order fields differently.
+func (c Config) Hash() uint64 {
+	if c.IsZero() {
+		return fnv.New64a().Sum64()
+	}
+	type kv struct{ k, v string }
+	var kvs []kv
+	for _, field := range c.c.schema.Fields() {
+		if val := c.Get(field); val != "" {
+			kvs = append(kvs, kv{field.Name, val})
+		}
+	}
+	sort.Slice(kvs, func(i, j int) bool {
+		return kvs[i].k < kvs[j].k
+	})
+	h := fnv.New64a()
+	for _, kv := range kvs {
+		// Terminate each string so adjacent strings can't
+		// run together.
+		io.WriteString(h, kv.k)
+		h.Write([]byte{0})
+		io.WriteString(h, kv.v)
+		h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
+
+// MarshalJSON returns Config c as a JSON object mapping field names
+// to values, in schema order. Fields with empty values are omitted.
+// A zero Config is marshaled as null. Schema.UnmarshalConfig reverses
+// this.
+func (c Config) MarshalJSON() ([]byte, error) {
+	if c.IsZero() {
+		return []byte("null"), nil
+	}
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	first := true
+	for _, field := range c.c.schema.Fields() {
+		if field.idx >= len(c.c.vals) || c.c.vals[field.idx] == "" {
+			continue
+		}
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		key, err := json.Marshal(field.Name)
+		if err != nil {
+			return nil, err
+		}
+		val, err := json.Marshal(c.c.vals[field.idx])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// MarshalText returns Config c in the same form as String. This
+// allows Configs to be used as keys in JSON-encoded maps.
+func (c Config) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalConfig reconstructs a Config of Schema s from its JSON
+// encoding, as produced by Config.MarshalJSON. The result is
+// interned like any other Config of s, so it will be == to a Config
+// with the same values produced by Project.
+//
+// Every field named in data must be in s, with the exception of
+// file configuration keys in a .config group and sub-name keys in a
+// .namekeys group, which are added to s as they would be by Project.
+func (s *Schema) UnmarshalConfig(data []byte) (Config, error) {
+	var vals map[string]string
+	if err := json.Unmarshal(data, &vals); err != nil {
+		return Config{}, err
+	}
+	if vals == nil {
+		return Config{}, nil
+	}
+	return s.internValues(vals)
+}
+
+// Convert returns a Config of Schema s with the same values as c,
+// which may come from a different Schema. This can be used to join
+// Configs produced by separate ProjectionParsers, or to compare them.
+// Fields are matched by name. Fields of s that aren't in c's Schema
+// have empty values. Like UnmarshalConfig, it is an error if c has a
+// non-empty field that s doesn't have, except that new file
+// configuration keys are added to a .config group in s.
+func (s *Schema) Convert(c Config) (Config, error) {
+	if c.IsZero() {
+		return Config{}, nil
+	}
+	if c.c.schema == s {
+		return c, nil
+	}
+	vals := make(map[string]string)
+	for _, field := range c.c.schema.Fields() {
+		if val := c.Get(field); val != "" {
+			vals[field.Name] = val
+		}
+	}
+	return s.internValues(vals)
+}
+
+// internValues returns the Config of s with the given field values,
+// which are keyed by field name.
+func (s *Schema) internValues(vals map[string]string) (Config, error) {
+	s.projMu.Lock()
+	defer s.projMu.Unlock()
+	fields := make(map[string]Field)
+	for _, field := range s.Fields() {
+		fields[field.Name] = field
+	}
+	// Sort the keys so any new .config fields are added in a
+	// deterministic order.
+	keys := make([]string, 0, len(vals))
+	for key := range vals {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, ok := fields[key]; ok {
+			continue
+		}
+		// Sub-name keys are never file configuration keys.
+		if s.configField != nil && !strings.HasPrefix(key, "/") {
+			if field, ok := s.configField(key); ok {
+				fields[key] = field
+				continue
+			}
+		}
+		if s.nameKeyField != nil && strings.HasPrefix(key, "/") {
+			if field, ok := s.nameKeyField(key); ok {
+				fields[key] = field
+				continue
+			}
+		}
+		return Config{}, fmt.Errorf("unknown field %q", key)
+	}
+
+	pr := s.proj
+	pr.row = pr.row[:0]
+	for _, key := range keys {
+		pr.set(fields[key], pr.intern([]byte(vals[key])))
+	}
+	return s.internRow(pr.row), nil
+}
+
 // commonSchema returns the Schema that all configs have, or panics if
 // any Config has a different Schema. It returns nil if len(configs)
 // == 0.
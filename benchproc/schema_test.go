@@ -5,8 +5,14 @@
 package benchproc
 
 import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
 	"reflect"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"golang.org/x/perf/benchfmt"
 	"golang.org/x/perf/benchproc/internal/parse"
@@ -139,6 +145,15 @@ func TestProjectionParsing(t *testing.T) {
 	checkErr("a@foo", "unknown order \"foo\"", 2)
 
 	checkErr(".config@(1 2)", "fixed order not allowed for .config", 8)
+
+	checkErr("a@num(1 2)", "order \"num\" does not take arguments", 2)
+	checkErr("a@bin", "bin order requires bucket boundaries", 2)
+	checkErr("a@bin(2 1)", "bucket boundaries must be increasing", 2)
+	checkErr("a@bin(x)", "bad bucket boundary \"x\"", 2)
+	checkErr(".fullname@bin(1)", "bin order not allowed for .fullname", 10)
+	checkErr("a@window", "window order requires a window size and optional anchor time", 2)
+	checkErr(".config@window(day)", "window order not allowed for .config", 8)
+	checkErr(".namekeys@(1 2)", "fixed order not allowed for .namekeys", 10)
 }
 
 func TestProjectionFiltering(t *testing.T) {
@@ -251,3 +266,483 @@ func TestProjectionValues(t *testing.T) {
 	check(cfgs[0], "x:1 .unit:ns/op", "ns/op")
 	check(cfgs[1], "x:1 .unit:gigawatts", "gigawatts")
 }
+
+func TestProjectionString(t *testing.T) {
+	check := func(proj, want string) {
+		t.Helper()
+		s, _ := mustParse(t, proj)
+		if got := s.String(); got != want {
+			t.Errorf("%s: got %s, want %s", proj, got, want)
+		}
+		// The result must parse back to the same expression.
+		s2, _ := mustParse(t, want)
+		if got := s2.String(); got != want {
+			t.Errorf("%s: round trip got %s, want %s", want, got, want)
+		}
+	}
+
+	check("", "")
+	check("a", "a")
+	check("a b", "a,b")
+	check(".config,.fullname", ".config,.fullname")
+	check("/size@num, .name@alpha", "/size@num,.name@alpha")
+	check(`a@(x "y z" w)`, `a@(x "y z" w)`)
+	check(`"a b"@num`, `"a b"@num`)
+	check("a@num:desc,b@-(x y)", "a@-num,b@-(x y)")
+	check("a@bin(1k 1M)", "a@bin(1k 1M)")
+	check("a@window(6h)", "a@window(6h)")
+	check(`a@window(day "2021-03-01T09:00:00Z")`, `a@window(day "2021-03-01T09:00:00Z")`)
+
+	// The .unit field isn't part of the projection.
+	s, _ := mustParse(t, "a")
+	s.AddValues()
+	if got := s.String(); got != "a" {
+		t.Errorf("with .unit: got %s, want a", got)
+	}
+}
+
+func TestConfigJSON(t *testing.T) {
+	s, _ := mustParse(t, "commit,.config,.name")
+	c := p(t, s, "Name/a=1", "commit", "abc", "goos", "linux", "x", "")
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"commit":"abc","goos":"linux",".name":"Name"}`; string(data) != want {
+		t.Errorf("got %s, want %s", data, want)
+	}
+
+	// Unmarshaling into the same Schema produces the same Config.
+	c2, err := s.UnmarshalConfig(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c2 != c {
+		t.Errorf("round trip got %s, want %s", c2, c)
+	}
+
+	// Unmarshaling into a new Schema adds .config fields.
+	s2, _ := mustParse(t, "commit,.config,.name")
+	c3, err := s2.UnmarshalConfig(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c3.String() != c.String() {
+		t.Errorf("new Schema got %s, want %s", c3, c)
+	}
+	// And later projections agree with it.
+	if c4 := p(t, s2, "Name/a=2", "commit", "abc", "goos", "linux"); c4 != c3 {
+		t.Errorf("projection after unmarshal got %s, want %s", c4, c3)
+	}
+
+	// Keys excluded from the .config group and unknown keys are
+	// errors.
+	if _, err := s2.UnmarshalConfig([]byte(`{"/a":"1"}`)); err == nil {
+		t.Errorf("want error for unknown field")
+	}
+	s3, _ := mustParse(t, ".name")
+	if _, err := s3.UnmarshalConfig([]byte(`{"goos":"linux"}`)); err == nil {
+		t.Errorf("want error for unknown field")
+	}
+
+	// Zero configs.
+	data, err = json.Marshal(Config{})
+	if err != nil || string(data) != "null" {
+		t.Errorf("zero Config: got %s, %v, want null", data, err)
+	}
+	if c, err := s.UnmarshalConfig(data); err != nil || !c.IsZero() {
+		t.Errorf("unmarshal null: got %s, %v, want zero Config", c, err)
+	}
+
+	// Configs can be map keys.
+	data, err = json.Marshal(map[Config]int{c: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"commit:abc goos:linux .name:Name":1}`; string(data) != want {
+		t.Errorf("map: got %s, want %s", data, want)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	s1, _ := mustParse(t, ".config,.name")
+	s2, _ := mustParse(t, ".name,.config,/a")
+	c1 := p(t, s1, "Name/a=1", "goos", "linux")
+	c2 := p(t, s2, "Name/a=1", "goos", "linux")
+
+	got, err := s2.Convert(c1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Schema() != s2 {
+		t.Fatalf("converted Config has wrong Schema")
+	}
+	if want := p(t, s2, "Name", "goos", "linux"); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	// s1 doesn't have /a.
+	if _, err := s1.Convert(c2); err == nil {
+		t.Errorf("want error converting Config with /a")
+	}
+	if got, err := s1.Convert(p(t, s2, "Name", "goos", "linux")); err != nil || got != c1 {
+		t.Errorf("got %s, %v, want %s", got, err, c1)
+	}
+
+	// Converting to the same Schema is the identity.
+	if got, err := s1.Convert(c1); err != nil || got != c1 {
+		t.Errorf("got %s, %v, want %s", got, err, c1)
+	}
+}
+
+func TestProjectConcurrent(t *testing.T) {
+	// Project from many goroutines using Schemas from a shared
+	// ProjectionParser. This is mostly useful with the race
+	// detector.
+	var pp ProjectionParser
+	f, _ := NewFilter("*")
+	s1, err := pp.Parse(".config,/a", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s2, err := pp.Parse(".fullname", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s2.AddValues()
+
+	const nG, nR = 8, 100
+	var wg sync.WaitGroup
+	for g := 0; g < nG; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			var cfgs []Config
+			for i := 0; i < nR; i++ {
+				res := r(t, fmt.Sprintf("Name/a=%d/b=%d", i%5, g), "goos", "linux", fmt.Sprintf("k%d", g), "v")
+				res.Values = []benchfmt.Value{{Value: 1, Unit: "ns/op"}, {Value: 2, Unit: "B/op"}}
+				cfgs = append(cfgs, s1.Project(res))
+				s2.ProjectValues(res)
+			}
+			SortConfigs(cfgs)
+			for _, c := range cfgs {
+				_ = c.String()
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	// Each goroutine's config key adds a field to .config.
+	if got, want := len(s1.Fields()), nG+2; got != want {
+		t.Errorf("got %d fields, want %d", got, want)
+	}
+	// Every projection of the same result gets the same Config.
+	res := r(t, "Name/a=1/b=0", "goos", "linux", "k0", "v")
+	if c1, c2 := s1.Project(res), s1.Project(res); c1 != c2 {
+		t.Errorf("Configs not equal: %s != %s", c1, c2)
+	}
+}
+
+func TestProjector(t *testing.T) {
+	// Projectors in separate goroutines produce the same Configs
+	// as the Schema, including for file configuration keys they
+	// add concurrently.
+	s, _ := mustParse(t, ".config,.fullname")
+	s.AddValues()
+	s.SetCache(true)
+	res := func(i, g int) *benchfmt.Result {
+		res := r(t, fmt.Sprintf("Name/a=%d", i%5), "goos", "linux", fmt.Sprintf("k%d", g), "v")
+		res.Values = []benchfmt.Value{{Value: 1, Unit: "ns/op"}, {Value: 2, Unit: "B/op"}}
+		return res
+	}
+
+	const nG, nR = 8, 100
+	got := make([][]Config, nG)
+	var wg sync.WaitGroup
+	for g := 0; g < nG; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			pr := s.NewProjector()
+			for i := 0; i < nR; i++ {
+				got[g] = append(got[g], pr.ProjectValues(res(i, g))...)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	for g := range got {
+		for i := 0; i < nR; i++ {
+			want := s.ProjectValues(res(i, g))
+			for j := range want {
+				if c := got[g][i*len(want)+j]; c != want[j] {
+					t.Fatalf("goroutine %d, result %d: got %s, want %s", g, i, c, want[j])
+				}
+			}
+		}
+	}
+}
+
+func TestConfigHash(t *testing.T) {
+	s1, _ := mustParse(t, ".config,.name")
+	s2, _ := mustParse(t, ".name,.config")
+	c1 := p(t, s1, "Name", "a", "1", "b", "2")
+	c2 := p(t, s2, "Name", "b", "2", "a", "1")
+	if c1.Hash() != c2.Hash() {
+		t.Errorf("equivalent Configs have different hashes")
+	}
+	// The hash must be stable across runs.
+	if got, want := c1.Hash(), uint64(0xb5ec358cf190486f); got != want {
+		t.Errorf("got hash %#x, want %#x", got, want)
+	}
+	if c3 := p(t, s1, "Name", "a", "12"); c3.Hash() == c1.Hash() {
+		t.Errorf("different Configs have the same hash")
+	}
+	if c4, c5 := p(t, s1, "", "a", "1\x00b"), p(t, s1, "", "a\x00b", "1"); c4.Hash() == c5.Hash() {
+		t.Errorf("ambiguous Configs have the same hash")
+	}
+}
+
+// numConfigs returns the number of Configs interned by s.
+func numConfigs(s *Schema) int {
+	n := 0
+	for i := range s.configs {
+		for _, configs := range s.configs[i].m {
+			n += len(configs)
+		}
+	}
+	return n
+}
+
+func TestReset(t *testing.T) {
+	s, _ := mustParse(t, "a")
+	c1 := p(t, s, "", "a", "1")
+	p(t, s, "", "a", "2")
+	if numConfigs(s) != 2 {
+		t.Fatalf("want 2 configs, got %d", numConfigs(s))
+	}
+
+	s.Reset()
+	if numConfigs(s) != 0 || len(s.proj.interns) != 0 {
+		t.Errorf("Reset didn't discard interned configs")
+	}
+	// Old Configs are still valid.
+	if got := c1.String(); got != "a:1" {
+		t.Errorf("old Config: got %s, want a:1", got)
+	}
+
+	// Observation order starts over.
+	p(t, s, "", "a", "1")
+	p(t, s, "", "a", "2")
+	s.Reset()
+	c := []Config{
+		p(t, s, "", "a", "3"),
+		p(t, s, "", "a", "1"),
+		p(t, s, "", "a", "2"),
+	}
+	SortConfigs(c)
+	var got []string
+	for _, cfg := range c {
+		got = append(got, cfg.String())
+	}
+	if want := []string{"a:3", "a:1", "a:2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got order %v, want %v", got, want)
+	}
+}
+
+// readBent reads the bent benchmark corpus.
+func readBent(t testing.TB) []*benchfmt.Result {
+	return readResults(t, "../benchfmt/testdata/bent/*")
+}
+
+// readResults reads all of the Results in the files matching glob.
+func readResults(t testing.TB, glob string) []*benchfmt.Result {
+	matches, err := filepath.Glob(glob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := benchfmt.Files{Paths: matches}
+	var results []*benchfmt.Result
+	for files.Scan() {
+		res, err := files.Result()
+		if err != nil {
+			continue
+		}
+		results = append(results, res.Clone())
+	}
+	if err := files.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return results
+}
+
+func TestProjectCache(t *testing.T) {
+	results := readBent(t)
+	const proj = ".config,.name,/gomaxprocs"
+	s1, _ := mustParse(t, proj)
+	s1.AddValues()
+	s2, _ := mustParse(t, proj)
+	s2.AddValues()
+	s2.SetCache(true)
+
+	for i, res := range results {
+		c1, c2 := s1.ProjectValues(res), s2.ProjectValues(res)
+		for j := range c1 {
+			if c1[j].String() != c2[j].String() {
+				t.Fatalf("result %d: without cache %s, with cache %s", i, c1[j], c2[j])
+			}
+		}
+		if c1, c2 := s1.Project(res), s2.Project(res); c1.String() != c2.String() {
+			t.Fatalf("result %d: without cache %s, with cache %s", i, c1, c2)
+		}
+	}
+	if numConfigs(s1) != numConfigs(s2) {
+		t.Errorf("got %d configs with cache, want %d", numConfigs(s2), numConfigs(s1))
+	}
+}
+
+func TestProjectIters(t *testing.T) {
+	// .iters varies between results with the same name and file
+	// configuration, so it must bypass the projection cache.
+	s, _ := mustParse(t, ".name,.iters@num,.file-index")
+	s.SetCache(true)
+	r1, r2 := r(t, "Name"), r(t, "Name")
+	r1.Iters, r2.Iters = 10, 1000
+	r2.FileIndex = 1
+	c1, c2 := s.Project(r1), s.Project(r2)
+	if got, want := c1.String(), ".name:Name .iters:10 .file-index:0"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if got, want := c2.String(), ".name:Name .iters:1000 .file-index:1"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if !c1.Less(c2) {
+		t.Errorf("want %s < %s", c1, c2)
+	}
+}
+
+func BenchmarkProject(b *testing.B) {
+	corpora := []struct {
+		name    string
+		results []*benchfmt.Result
+	}{
+		{"bent", readBent(b)},
+		{"suffixarray", readResults(b, "testdata/suffixarray.bench")},
+	}
+	for _, corpus := range corpora {
+		for _, cache := range []bool{false, true} {
+			b.Run(fmt.Sprintf("%s/cache=%v", corpus.name, cache), func(b *testing.B) {
+				start := time.Now()
+				for i := 0; i < b.N; i++ {
+					// Project like benchstat's default
+					// row projection, which must
+					// normalize the name.
+					f, _ := NewFilter("*")
+					var pp ProjectionParser
+					pp.Parse("/gomaxprocs", f)
+					s, _ := pp.Parse(".config,.fullname", f)
+					s.AddValues()
+					s.SetCache(cache)
+					for _, res := range corpus.results {
+						s.ProjectValues(res)
+					}
+				}
+				dur := time.Since(start)
+				b.ReportMetric(float64(len(corpus.results)*b.N)/dur.Seconds(), "results/sec")
+			})
+		}
+	}
+}
+
+func BenchmarkProjectParallel(b *testing.B) {
+	// Project the bent corpus like BenchmarkProject, but from
+	// GOMAXPROCS goroutines. Run with -cpu to see the scaling.
+	results := readBent(b)
+	for _, impl := range []string{"Schema", "Projector"} {
+		b.Run(impl, func(b *testing.B) {
+			f, _ := NewFilter("*")
+			var pp ProjectionParser
+			pp.Parse("/gomaxprocs", f)
+			s, _ := pp.Parse(".config,.fullname", f)
+			s.AddValues()
+			start := time.Now()
+			var n int64
+			b.RunParallel(func(pb *testing.PB) {
+				project := s.ProjectValues
+				if impl == "Projector" {
+					project = s.NewProjector().ProjectValues
+				}
+				var i int
+				for ; pb.Next(); i++ {
+					project(results[i%len(results)])
+				}
+				atomic.AddInt64(&n, int64(i))
+			})
+			dur := time.Since(start)
+			b.ReportMetric(float64(n)/dur.Seconds(), "results/sec")
+		})
+	}
+}
+
+func TestConfigFields(t *testing.T) {
+	s, _ := mustParse(t, "b,a,.config")
+	p(t, s, "", "x", "1")
+	c := p(t, s, "", "a", "2", "b", "3")
+
+	var got []string
+	for _, fv := range c.Fields() {
+		got = append(got, fv.Field.Name+"="+fv.Value)
+	}
+	if want := []string{"b=3", "a=2", "x="}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Fields: got %v, want %v", got, want)
+	}
+
+	if got, want := c.Map(), map[string]string{"a": "2", "b": "3", "x": ""}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Map: got %v, want %v", got, want)
+	}
+
+	if (Config{}).Fields() != nil || (Config{}).Map() != nil {
+		t.Errorf("zero Config: want nil")
+	}
+}
+
+func TestNameKeys(t *testing.T) {
+	var pp ProjectionParser
+	f, _ := NewFilter("*")
+	s, err := pp.Parse(".namekeys", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pp.Parse("/b", f); err != nil {
+		t.Fatal(err)
+	}
+	res := pp.Residue()
+
+	c := p(t, s, "Name/a=1/b=2/gob/c=3-4")
+	if got, want := c.String(), "/a:1 /c:3 /gomaxprocs:4"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	c = p(t, s, "Name/d=5")
+	if got, want := c.String(), "/d:5"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if got, want := fieldNames(s), []string{"/a", "/c", "/gomaxprocs", "/d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got fields %v, want %v", got, want)
+	}
+
+	// Sub-name keys are excluded from the residue's .fullname.
+	c = p(t, res, "Name/a=1/b=2/gob/c=3-4")
+	if got, want := c.String(), ".fullname:Name/a=*/b=*/gob/c=*-*"; got != want {
+		t.Errorf("residue: got %s, want %s", got, want)
+	}
+
+	// Unmarshaling adds .namekeys fields.
+	c, err = s.UnmarshalConfig([]byte(`{"/e":"6"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := c.String(), "/e:6"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
@@ -5,11 +5,15 @@
 package benchproc
 
 import (
+	"fmt"
 	"math"
 	"regexp"
+	"runtime"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 // Less reports whether c comes before o in the sort order implied by
@@ -18,7 +22,10 @@ func (c Config) Less(o Config) bool {
 	if c.c.schema != o.c.schema {
 		panic("cannot compare Configs from different Schemas")
 	}
-	return less(c.c.schema.Fields(), c.c.vals, o.c.vals)
+	s := c.c.schema
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return less(s.fields(), c.c.vals, o.c.vals)
 }
 
 func less(flat []Field, a, b []string) bool {
@@ -32,18 +39,7 @@ func less(flat []Field, a, b []string) bool {
 			bb = b[node.idx]
 		}
 		if aa != bb {
-			cmp := node.cmp(aa, bb)
-			if cmp != 0 {
-				return cmp < 0
-			}
-			// The values are equal/unordered according to
-			// the comparison function, but the strings
-			// differ. Because Configs are only == if
-			// their string representations are ==, this
-			// means we have to fall back to a secondary
-			// comparison that is only == if the strings
-			// are ==.
-			return aa < bb
+			return lessValue(node, aa, bb)
 		}
 	}
 
@@ -51,11 +47,34 @@ func less(flat []Field, a, b []string) bool {
 	return false
 }
 
+// lessValue reports whether value a of field node sorts before b.
+func lessValue(node Field, a, b string) bool {
+	if a == b {
+		return false
+	}
+	cmp := node.cmp(a, b)
+	if cmp != 0 {
+		return cmp < 0
+	}
+	// The values are equal/unordered according to the comparison
+	// function, but the strings differ. Because Configs are only
+	// == if their string representations are ==, this means we
+	// have to fall back to a secondary comparison that is only ==
+	// if the strings are ==.
+	return a < b
+}
+
+// parallelSortMin is the minimum number of Configs SortConfigs will
+// sort in parallel.
+var parallelSortMin = 1 << 14
+
 // SortConfigs sorts a slice of Configs using Config.Less.
 // All configs must have the same Schema.
 //
 // This is equivalent to using Config.Less with the sort package but
-// more efficient.
+// more efficient. Rather than comparing fields on every comparison,
+// SortConfigs ranks the distinct values of each field once and then
+// sorts by these ranks, in parallel for large slices.
 func SortConfigs(configs []Config) {
 	// Check all the schemas so we don't have to do this on every
 	// comparison.
@@ -63,11 +82,161 @@ func SortConfigs(configs []Config) {
 		return
 	}
 	s := commonSchema(configs)
-	flat := s.Fields()
+	// Hold the lock while ranking because projecting can update
+	// the observation orders used by comparison functions. Once
+	// we have the ranks, we no longer need the comparison
+	// functions.
+	s.mu.RLock()
+	flat := s.fields()
+	keys := rankConfigs(flat, configs)
+	s.mu.RUnlock()
 
-	sort.Slice(configs, func(i, j int) bool {
-		return less(flat, configs[i].c.vals, configs[j].c.vals)
-	})
+	nf := len(flat)
+	lessIdx := func(a, b int32) bool {
+		ka := keys[int(a)*nf : int(a+1)*nf]
+		kb := keys[int(b)*nf : int(b+1)*nf]
+		for i, k := range ka {
+			if k != kb[i] {
+				return k < kb[i]
+			}
+		}
+		return false
+	}
+	perm := make([]int32, len(configs))
+	for i := range perm {
+		perm[i] = int32(i)
+	}
+	sortPerm(perm, lessIdx)
+
+	sorted := make([]Config, len(configs))
+	for i, idx := range perm {
+		sorted[i] = configs[idx]
+	}
+	copy(configs, sorted)
+}
+
+// rankConfigs returns a len(configs)*len(flat) matrix of sort keys.
+// Row i gives the rank of each field value of configs[i] among all
+// values of that field in configs, such that comparing rows
+// lexically is equivalent to comparing Configs with less.
+func rankConfigs(flat []Field, configs []Config) []int32 {
+	nf := len(flat)
+	keys := make([]int32, len(configs)*nf)
+	var vals []string
+	ids := make(map[string]int32)
+	for fi, field := range flat {
+		// Assign an ID to each distinct value of this field.
+		vals = vals[:0]
+		for k := range ids {
+			delete(ids, k)
+		}
+		for ci, c := range configs {
+			var val string
+			if field.idx < len(c.c.vals) {
+				val = c.c.vals[field.idx]
+			}
+			id, ok := ids[val]
+			if !ok {
+				id = int32(len(vals))
+				ids[val] = id
+				vals = append(vals, val)
+			}
+			keys[ci*nf+fi] = id
+		}
+		if len(vals) == 1 {
+			// All IDs are 0, which is also the rank.
+			continue
+		}
+
+		// Sort the distinct values and map IDs to ranks.
+		order := make([]int32, len(vals))
+		for i := range order {
+			order[i] = int32(i)
+		}
+		sort.Slice(order, func(i, j int) bool {
+			return lessValue(field, vals[order[i]], vals[order[j]])
+		})
+		rank := make([]int32, len(vals))
+		for r, id := range order {
+			rank[id] = int32(r)
+		}
+		for ci := range configs {
+			keys[ci*nf+fi] = rank[keys[ci*nf+fi]]
+		}
+	}
+	return keys
+}
+
+// sortPerm sorts perm using less. Large slices are split into
+// chunks that are sorted concurrently and then merged.
+func sortPerm(perm []int32, less func(a, b int32) bool) {
+	sortChunk := func(chunk []int32) {
+		sort.Slice(chunk, func(i, j int) bool {
+			return less(chunk[i], chunk[j])
+		})
+	}
+
+	nChunks := runtime.GOMAXPROCS(-1)
+	if len(perm) < parallelSortMin || nChunks < 2 {
+		sortChunk(perm)
+		return
+	}
+
+	// Sort chunks in parallel.
+	var chunks [][]int32
+	chunkSize := (len(perm) + nChunks - 1) / nChunks
+	for start := 0; start < len(perm); start += chunkSize {
+		end := start + chunkSize
+		if end > len(perm) {
+			end = len(perm)
+		}
+		chunks = append(chunks, perm[start:end])
+	}
+	var wg sync.WaitGroup
+	for _, chunk := range chunks {
+		wg.Add(1)
+		go func(chunk []int32) {
+			defer wg.Done()
+			sortChunk(chunk)
+		}(chunk)
+	}
+	wg.Wait()
+
+	// Merge adjacent pairs of chunks in parallel until there's
+	// one chunk left. Because the chunks are adjacent in perm,
+	// each merge produces a contiguous subslice of perm.
+	buf := make([]int32, len(perm))
+	for len(chunks) > 1 {
+		var next [][]int32
+		for i := 0; i < len(chunks); i += 2 {
+			if i+1 == len(chunks) {
+				next = append(next, chunks[i])
+				continue
+			}
+			a, b := chunks[i], chunks[i+1]
+			merged := perm[len(perm)-cap(a) : len(perm)-cap(a)+len(a)+len(b)]
+			next = append(next, merged)
+			wg.Add(1)
+			go func(a, b, merged []int32) {
+				defer wg.Done()
+				off := len(perm) - cap(merged)
+				out := buf[off : off+len(merged)]
+				i, j := 0, 0
+				for k := range out {
+					if j == len(b) || (i < len(a) && !less(b[j], a[i])) {
+						out[k] = a[i]
+						i++
+					} else {
+						out[k] = b[j]
+						j++
+					}
+				}
+				copy(merged, out)
+			}(a, b, merged)
+		}
+		wg.Wait()
+		chunks = next
+	}
 }
 
 // builtinOrders is the built-in comparison functions.
@@ -90,16 +259,68 @@ var builtinOrders = map[string]func(a, b string) int{
 			// The values are unordered.
 			return 0
 		}
-		if erra != nil && errb != nil {
-			// The values are unordered.
+		return cmpErrs(erra, errb)
+	},
+	"date": func(a, b string) int {
+		aa, erra := parseDate(a)
+		bb, errb := parseDate(b)
+		if erra == nil && errb == nil {
+			if aa.Before(bb) {
+				return -1
+			}
+			if aa.After(bb) {
+				return 1
+			}
 			return 0
 		}
-		// Put floats before non-floats.
-		if erra == nil {
-			return -1
+		return cmpErrs(erra, errb)
+	},
+	"semver": func(a, b string) int {
+		aa, erra := parseSemver(a)
+		bb, errb := parseSemver(b)
+		if erra == nil && errb == nil {
+			return aa.cmp(bb)
 		}
-		return 1
+		return cmpErrs(erra, errb)
 	},
+	"natural": cmpNatural,
+	"duration": func(a, b string) int {
+		aa, erra := time.ParseDuration(a)
+		bb, errb := time.ParseDuration(b)
+		if erra == nil && errb == nil {
+			if aa < bb {
+				return -1
+			}
+			if aa > bb {
+				return 1
+			}
+			return 0
+		}
+		return cmpErrs(erra, errb)
+	},
+	"goversion": func(a, b string) int {
+		aa, erra := parseGoVersion(a)
+		bb, errb := parseGoVersion(b)
+		if erra == nil && errb == nil {
+			return aa.cmp(bb)
+		}
+		return cmpErrs(erra, errb)
+	},
+}
+
+// cmpErrs orders values that failed to parse after values that
+// parsed successfully. It is used by orders that parse their values
+// when at least one of the two values failed to parse.
+func cmpErrs(erra, errb error) int {
+	if erra != nil && errb != nil {
+		// The values are unordered.
+		return 0
+	}
+	// Put parsed values before unparseable values.
+	if erra == nil {
+		return -1
+	}
+	return 1
 }
 
 const numPrefixes = `KMGTPEZY`
@@ -107,7 +328,7 @@ const numPrefixes = `KMGTPEZY`
 var numRe = regexp.MustCompile(`([0-9.]+)([k` + numPrefixes + `]i?)?[bB]?`)
 
 // parseNum is a fuzzy number parser. It supports common patterns,
-// such as SI prefixes.
+// such as SI prefixes and Go durations, which are parsed as seconds.
 func parseNum(x string) (float64, error) {
 	// Try parsing as a regular float.
 	v, err := strconv.ParseFloat(x, 64)
@@ -115,6 +336,13 @@ func parseNum(x string) (float64, error) {
 		return v, nil
 	}
 
+	// Try a Go duration. This has to come before suffixed
+	// numbers because numRe would otherwise match just a prefix
+	// of something like "1m30s".
+	if d, err := time.ParseDuration(x); err == nil {
+		return d.Seconds(), nil
+	}
+
 	// Try a suffixed number.
 	subs := numRe.FindStringSubmatch(x)
 	if subs != nil {
@@ -138,3 +366,545 @@ func parseNum(x string) (float64, error) {
 
 	return 0, strconv.ErrSyntax
 }
+
+// dateLayouts are the time layouts recognized by parseDate, in
+// addition to Unix timestamps.
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"20060102",
+	time.UnixDate,
+	time.RFC1123Z,
+	time.RFC1123,
+}
+
+// parseDate parses a timestamp in one of several common formats,
+// including RFC 3339, "YYYY-MM-DD", "YYYYMMDD", and Unix seconds.
+func parseDate(x string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, x); err == nil {
+			return t, nil
+		}
+	}
+
+	// Try Unix seconds, possibly with a fractional part.
+	// "YYYYMMDD" was tried above, so an 8-digit value here is
+	// not a valid date and is treated as seconds.
+	if v, err := strconv.ParseFloat(x, 64); err == nil && !math.IsNaN(v) && !math.IsInf(v, 0) {
+		sec, frac := math.Modf(v)
+		return time.Unix(int64(sec), int64(frac*1e9)).UTC(), nil
+	}
+
+	return time.Time{}, strconv.ErrSyntax
+}
+
+// A semver is a parsed semantic version.
+type semver struct {
+	// release is the major, minor, and patch version numbers.
+	// Missing components are 0.
+	release [3]uint64
+	// pre is the dot-separated pre-release identifiers, or nil
+	// for a release version.
+	pre []string
+}
+
+// parseSemver parses a semantic version, such as "1.2.3",
+// "v1.2.3-rc.1", or "1.2.3+build". It accepts an optional "v" prefix
+// and allows the minor and patch versions to be omitted. Build
+// metadata is ignored.
+func parseSemver(x string) (semver, error) {
+	var v semver
+	x = strings.TrimPrefix(x, "v")
+	if i := strings.IndexByte(x, '+'); i >= 0 {
+		x = x[:i]
+	}
+	if i := strings.IndexByte(x, '-'); i >= 0 {
+		if i == len(x)-1 {
+			return v, strconv.ErrSyntax
+		}
+		v.pre = strings.Split(x[i+1:], ".")
+		for _, id := range v.pre {
+			if id == "" {
+				return v, strconv.ErrSyntax
+			}
+		}
+		x = x[:i]
+	}
+	parts := strings.Split(x, ".")
+	if len(parts) > len(v.release) {
+		return v, strconv.ErrSyntax
+	}
+	for i, part := range parts {
+		n, err := parseDigits(part)
+		if err != nil {
+			return v, err
+		}
+		v.release[i] = n
+	}
+	return v, nil
+}
+
+// cmp compares semantic versions v and o according to semantic
+// versioning precedence rules.
+func (v semver) cmp(o semver) int {
+	for i := range v.release {
+		if v.release[i] != o.release[i] {
+			if v.release[i] < o.release[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	// A pre-release version has lower precedence than the
+	// release version.
+	if v.pre == nil || o.pre == nil {
+		if v.pre != nil {
+			return -1
+		} else if o.pre != nil {
+			return 1
+		}
+		return 0
+	}
+
+	// Compare pre-release identifiers. Numeric identifiers are
+	// compared numerically and have lower precedence than
+	// alphanumeric identifiers.
+	for i := 0; i < len(v.pre) && i < len(o.pre); i++ {
+		a, b := v.pre[i], o.pre[i]
+		an, aerr := strconv.ParseUint(a, 10, 64)
+		bn, berr := strconv.ParseUint(b, 10, 64)
+		switch {
+		case aerr == nil && berr == nil:
+			if an != bn {
+				if an < bn {
+					return -1
+				}
+				return 1
+			}
+		case aerr == nil:
+			return -1
+		case berr == nil:
+			return 1
+		default:
+			if c := strings.Compare(a, b); c != 0 {
+				return c
+			}
+		}
+	}
+	// A larger set of pre-release identifiers has higher
+	// precedence if all preceding identifiers are equal.
+	return len(v.pre) - len(o.pre)
+}
+
+// A goVersion is a parsed Go toolchain version.
+type goVersion struct {
+	// devel indicates a development toolchain build. Development
+	// builds sort after all releases.
+	devel bool
+	// date is the commit date of a development build, if known.
+	date time.Time
+
+	// release is the major, minor, and patch version numbers.
+	release [3]uint64
+	// pre is the pre-release kind: preBeta, preRC, or preNone
+	// for a full release.
+	pre int
+	// preNum is the beta or release candidate number.
+	preNum uint64
+}
+
+const (
+	preBeta = iota
+	preRC
+	preNone
+)
+
+// develDateLayout is the format of the commit date in a development
+// toolchain version, such as "devel +0a1b2c3 Tue Jan 2 15:04:05 2024 +0000".
+const develDateLayout = "Mon Jan 2 15:04:05 2006 -0700"
+
+// parseGoVersion parses a Go toolchain version as reported by
+// runtime.Version, such as "go1.21.3", "go1.22rc1", or
+// "devel +0a1b2c3 Tue Jan 2 15:04:05 2024 +0000".
+func parseGoVersion(x string) (goVersion, error) {
+	var v goVersion
+	if strings.HasPrefix(x, "devel") {
+		v.devel = true
+		// Look for a commit date following the revision.
+		fields := strings.Fields(x)
+		for i := 1; i < len(fields); i++ {
+			rest := strings.Join(fields[i:], " ")
+			if t, err := time.Parse(develDateLayout, rest); err == nil {
+				v.date = t
+				break
+			}
+			if t, err := parseDate(rest); err == nil {
+				v.date = t
+				break
+			}
+		}
+		return v, nil
+	}
+
+	if !strings.HasPrefix(x, "go") {
+		return v, strconv.ErrSyntax
+	}
+	x = x[len("go"):]
+	// Strip any trailing fields, such as " X:boringcrypto".
+	if i := strings.IndexAny(x, " \t"); i >= 0 {
+		x = x[:i]
+	}
+
+	// Split off the pre-release suffix.
+	v.pre = preNone
+	for _, pre := range []struct {
+		s    string
+		kind int
+	}{{"beta", preBeta}, {"rc", preRC}} {
+		if i := strings.Index(x, pre.s); i >= 0 {
+			n, err := parseDigits(x[i+len(pre.s):])
+			if err != nil {
+				return v, err
+			}
+			v.pre, v.preNum = pre.kind, n
+			x = x[:i]
+			break
+		}
+	}
+
+	parts := strings.Split(x, ".")
+	if len(parts) > len(v.release) {
+		return v, strconv.ErrSyntax
+	}
+	for i, part := range parts {
+		n, err := parseDigits(part)
+		if err != nil {
+			return v, err
+		}
+		v.release[i] = n
+	}
+	return v, nil
+}
+
+// parseDigits parses a non-empty string of decimal digits.
+func parseDigits(x string) (uint64, error) {
+	// ParseUint accepts things like "0x1", so require plain
+	// digits.
+	if x == "" || strings.Trim(x, "0123456789") != "" {
+		return 0, strconv.ErrSyntax
+	}
+	return strconv.ParseUint(x, 10, 64)
+}
+
+// cmp compares Go versions v and o. Releases are ordered
+// numerically, with betas before release candidates before the
+// final release. Development builds sort after all releases and are
+// ordered by their commit date, if known. Development builds without
+// a date sort last.
+func (v goVersion) cmp(o goVersion) int {
+	if v.devel || o.devel {
+		switch {
+		case !v.devel:
+			return -1
+		case !o.devel:
+			return 1
+		case v.date.IsZero() || o.date.IsZero():
+			if !v.date.IsZero() {
+				return -1
+			} else if !o.date.IsZero() {
+				return 1
+			}
+			return 0
+		case v.date.Before(o.date):
+			return -1
+		case v.date.After(o.date):
+			return 1
+		}
+		return 0
+	}
+
+	// Compare the version numbers. A pre-release of a new major
+	// version, such as go1.21rc1, has an implicit patch version of
+	// 0, so it sorts before go1.21.0.
+	for i := range v.release {
+		if v.release[i] != o.release[i] {
+			if v.release[i] < o.release[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	if v.pre != o.pre {
+		return v.pre - o.pre
+	}
+	if v.preNum != o.preNum {
+		if v.preNum < o.preNum {
+			return -1
+		}
+		return 1
+	}
+	return 0
+}
+
+// cmpNatural compares a and b in "natural" order. Runs of decimal
+// digits are compared numerically and all other text is compared
+// byte-wise, so "run2" sorts before "run10". Numerically equal runs
+// with different numbers of leading zeros break ties, so "run1" sorts
+// before "run01" and distinct strings never compare equal.
+func cmpNatural(a, b string) int {
+	isDigit := func(c byte) bool { return '0' <= c && c <= '9' }
+	// tie is the difference in length of the first pair of
+	// numerically equal digit runs that differ in length.
+	tie := 0
+	for len(a) > 0 && len(b) > 0 {
+		if !isDigit(a[0]) || !isDigit(b[0]) {
+			if a[0] != b[0] {
+				if a[0] < b[0] {
+					return -1
+				}
+				return 1
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+
+		// Extract the digit runs.
+		i := 0
+		for i < len(a) && isDigit(a[i]) {
+			i++
+		}
+		j := 0
+		for j < len(b) && isDigit(b[j]) {
+			j++
+		}
+		da, db := a[:i], b[:j]
+		a, b = a[i:], b[j:]
+
+		// Compare the runs numerically without limiting their
+		// length: strip leading zeros, then a longer run is
+		// larger, and equal-length runs compare lexically.
+		za, zb := strings.TrimLeft(da, "0"), strings.TrimLeft(db, "0")
+		if len(za) != len(zb) {
+			return len(za) - len(zb)
+		}
+		if c := strings.Compare(za, zb); c != 0 {
+			return c
+		}
+		if tie == 0 {
+			tie = len(da) - len(db)
+		}
+	}
+	if len(a) != len(b) {
+		return len(a) - len(b)
+	}
+	return tie
+}
+
+// A binner maps numeric values into labeled buckets for the "bin"
+// order.
+type binner struct {
+	// bounds are the bucket boundaries, in increasing order.
+	bounds []float64
+	// labels are the bucket labels. Bucket i contains values v
+	// such that bounds[i-1] <= v < bounds[i]. Hence, there is
+	// one more label than there are bounds.
+	labels [][]byte
+	// index maps from a bucket label to its bucket index.
+	index map[string]int
+}
+
+// newBinner returns a binner for the bucket boundaries given by args.
+// Each argument is parsed by parseNum, and arguments must be strictly
+// increasing. The buckets are labeled using the arguments as
+// written, such as "<1k", "1k-32k", and ">=32k".
+func newBinner(args []string) (*binner, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("bin order requires bucket boundaries")
+	}
+	b := &binner{index: make(map[string]int)}
+	for i, arg := range args {
+		v, err := parseNum(arg)
+		if err != nil || math.IsNaN(v) {
+			return nil, fmt.Errorf("bad bucket boundary %q", arg)
+		}
+		if i > 0 && v <= b.bounds[i-1] {
+			return nil, fmt.Errorf("bucket boundaries must be increasing")
+		}
+		b.bounds = append(b.bounds, v)
+	}
+	addLabel := func(label string) {
+		b.index[label] = len(b.labels)
+		b.labels = append(b.labels, []byte(label))
+	}
+	addLabel("<" + args[0])
+	for i := 1; i < len(args); i++ {
+		addLabel(args[i-1] + "-" + args[i])
+	}
+	addLabel(">=" + args[len(args)-1])
+	return b, nil
+}
+
+// bin returns the label of the bucket containing val. If val is not a
+// number, it returns val unchanged.
+func (b *binner) bin(val []byte) []byte {
+	v, err := parseNum(string(val))
+	if err != nil || math.IsNaN(v) {
+		return val
+	}
+	i := sort.Search(len(b.bounds), func(i int) bool {
+		return v < b.bounds[i]
+	})
+	return b.labels[i]
+}
+
+// cmp orders bucket labels by bucket, followed by any values that
+// were not binned.
+func (b *binner) cmp(x, y string) int {
+	xi, xok := b.index[x]
+	yi, yok := b.index[y]
+	if xok && yok {
+		return xi - yi
+	}
+	if xok {
+		return -1
+	} else if yok {
+		return 1
+	}
+	return 0
+}
+
+// CommitOrder returns a comparison function that orders commit hashes
+// by their position in commits, which is typically a list of commits
+// in topological order, such as the output of "git rev-list
+// --topo-order --reverse". The result is intended to be used with
+// ProjectionParser.AddOrder.
+//
+// A value matches a commit if it is equal to the commit hash, is an
+// unambiguous prefix of it, or extends it, so abbreviated hashes are
+// ordered correctly on either side. Values that don't match any
+// commit sort after values that do.
+func CommitOrder(commits []string) func(a, b string) int {
+	type commit struct {
+		hash string
+		pos  int
+	}
+	sorted := make([]commit, 0, len(commits))
+	for i, hash := range commits {
+		sorted = append(sorted, commit{hash, i})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].hash < sorted[j].hash
+	})
+
+	// lookup returns the position of the commit matching x, or -1.
+	lookup := func(x string) int {
+		if x == "" {
+			return -1
+		}
+		i := sort.Search(len(sorted), func(i int) bool {
+			return sorted[i].hash >= x
+		})
+		if i > 0 && strings.HasPrefix(x, sorted[i-1].hash) {
+			// x is a longer form of an abbreviated commit.
+			return sorted[i-1].pos
+		}
+		if i == len(sorted) || !strings.HasPrefix(sorted[i].hash, x) {
+			return -1
+		}
+		if sorted[i].hash != x && i+1 < len(sorted) && strings.HasPrefix(sorted[i+1].hash, x) {
+			// Ambiguous prefix.
+			return -1
+		}
+		return sorted[i].pos
+	}
+
+	return func(a, b string) int {
+		pa, pb := lookup(a), lookup(b)
+		if pa >= 0 && pb >= 0 {
+			return pa - pb
+		}
+		if pa >= 0 {
+			return -1
+		} else if pb >= 0 {
+			return 1
+		}
+		return 0
+	}
+}
+
+// A windower maps timestamps to the start of the fixed time window
+// containing them for the "window" order.
+type windower struct {
+	// size is the window size.
+	size time.Duration
+	// anchor is the start of one window, in nanoseconds since
+	// the Unix epoch. Windows are aligned to this.
+	anchor int64
+	// layout is the time layout used to label windows.
+	layout string
+}
+
+// newWindower returns a windower for the window size and optional
+// anchor time given by args. The size must be "hour", "day", "week",
+// or a Go duration such as "6h". The anchor is a timestamp, parsed
+// like the "date" order, at which one window starts.
+func newWindower(args []string) (*windower, error) {
+	if len(args) != 1 && len(args) != 2 {
+		return nil, fmt.Errorf("window order requires a window size and optional anchor time")
+	}
+	const day = 24 * time.Hour
+	w := &windower{layout: time.RFC3339}
+	switch args[0] {
+	case "hour":
+		w.size = time.Hour
+	case "day":
+		w.size = day
+	case "week":
+		w.size = 7 * day
+		// By default, weeks start on Monday. The Unix
+		// epoch was a Thursday.
+		w.anchor = int64(4 * day)
+	default:
+		d, err := time.ParseDuration(args[0])
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("bad window size %q", args[0])
+		}
+		w.size = d
+	}
+	if len(args) == 2 {
+		t, err := parseDate(args[1])
+		if err != nil {
+			return nil, fmt.Errorf("bad window anchor %q", args[1])
+		}
+		w.anchor = t.UnixNano()
+	}
+	if w.size%day == 0 && w.anchor%int64(day) == 0 {
+		// Label whole-day windows with just the date.
+		w.layout = "2006-01-02"
+	} else if w.size%time.Second != 0 || w.anchor%int64(time.Second) != 0 {
+		w.layout = time.RFC3339Nano
+	}
+	return w, nil
+}
+
+// window returns the label of the window containing timestamp val,
+// which is the UTC start time of the window. Windows are aligned to
+// w.anchor. If val is not a timestamp, it returns val unchanged.
+func (w *windower) window(val []byte) []byte {
+	t, err := parseDate(string(val))
+	if err != nil {
+		return val
+	}
+	ns, size := t.UnixNano()-w.anchor, int64(w.size)
+	// Round toward negative infinity.
+	start := ns - ns%size
+	if ns%size < 0 {
+		start -= size
+	}
+	t = time.Unix(0, start+w.anchor).UTC()
+	return []byte(t.Format(w.layout))
+}
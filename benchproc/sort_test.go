@@ -5,9 +5,15 @@
 package benchproc
 
 import (
+	"fmt"
 	"math/rand"
 	"reflect"
+	"sort"
+	"strings"
 	"testing"
+	"time"
+
+	"golang.org/x/perf/benchfmt"
 )
 
 func TestSort(t *testing.T) {
@@ -72,6 +78,29 @@ func TestSort(t *testing.T) {
 	}
 	check(c, "a:20", "a:100", "a:a", "a:b")
 
+	// Descending.
+	s, _ = mustParse(t, "a@-num")
+	c = []Config{
+		p(t, s, "", "a", "3"),
+		p(t, s, "", "a", "100"),
+		p(t, s, "", "a", "20"),
+	}
+	check(c, "a:100", "a:20", "a:3")
+	s, _ = mustParse(t, "a@(x y z):desc")
+	c = []Config{
+		p(t, s, "", "a", "y"),
+		p(t, s, "", "a", "x"),
+		p(t, s, "", "a", "z"),
+	}
+	check(c, "a:z", "a:y", "a:x")
+	s, _ = mustParse(t, "a@-first")
+	c = []Config{
+		p(t, s, "", "a", "b"),
+		p(t, s, "", "a", "c"),
+		p(t, s, "", "a", "a"),
+	}
+	check(c, "a:a", "a:c", "a:b")
+
 	// Numeric with weird cases.
 	s, _ = mustParse(t, "a@num")
 	c = []Config{
@@ -93,6 +122,105 @@ func TestSort(t *testing.T) {
 		check(c, "a:-inf", "a:-infinity", "a:1", "a:1.0", "a:inf", "a:infinity", "a:NaN", "a:nan")
 	}
 
+	// Dates in mixed formats.
+	s, _ = mustParse(t, "a@date")
+	c = []Config{
+		p(t, s, "", "a", "bad"),
+		p(t, s, "", "a", "2021-02-01T00:00:00Z"),
+		p(t, s, "", "a", "20210115"),
+		p(t, s, "", "a", "1609459200"), // 2021-01-01
+		p(t, s, "", "a", "2020-12-31"),
+		p(t, s, "", "a", "2021-01-20T10:00:00-05:00"),
+	}
+	check(c, "a:2020-12-31", "a:1609459200", "a:20210115", "a:2021-01-20T10:00:00-05:00", "a:2021-02-01T00:00:00Z", "a:bad")
+
+	// Semantic versions.
+	s, _ = mustParse(t, "a@semver")
+	c = []Config{
+		p(t, s, "", "a", "1.2.10"),
+		p(t, s, "", "a", "x"),
+		p(t, s, "", "a", "v1.2.9"),
+		p(t, s, "", "a", "1.10"),
+		p(t, s, "", "a", "1.2.10-rc.1"),
+		p(t, s, "", "a", "1.2.10-beta.11"),
+		p(t, s, "", "a", "1.2.10-beta.2"),
+		p(t, s, "", "a", "1.2.10-beta"),
+		p(t, s, "", "a", "2"),
+	}
+	check(c, "a:v1.2.9", "a:1.2.10-beta", "a:1.2.10-beta.2", "a:1.2.10-beta.11", "a:1.2.10-rc.1", "a:1.2.10", "a:1.10", "a:2", "a:x")
+
+	// Durations.
+	s, _ = mustParse(t, "a@duration")
+	c = []Config{
+		p(t, s, "", "a", "1m30s"),
+		p(t, s, "", "a", "2s"),
+		p(t, s, "", "a", "x"),
+		p(t, s, "", "a", "100ms"),
+		p(t, s, "", "a", "1m"),
+		p(t, s, "", "a", "500µs"),
+	}
+	check(c, "a:500µs", "a:100ms", "a:2s", "a:1m", "a:1m30s", "a:x")
+
+	// Numeric with durations.
+	s, _ = mustParse(t, "a@num")
+	c = []Config{
+		p(t, s, "", "a", "1m30s"),
+		p(t, s, "", "a", "2s"),
+		p(t, s, "", "a", "100ms"),
+	}
+	check(c, "a:100ms", "a:2s", "a:1m30s")
+
+	// Binned.
+	s, _ = mustParse(t, "a@bin(1k 32k 1M)")
+	c = []Config{
+		p(t, s, "", "a", "2M"),
+		p(t, s, "", "a", "x"),
+		p(t, s, "", "a", "100"),
+		p(t, s, "", "a", "1k"),
+		p(t, s, "", "a", "4096"),
+		p(t, s, "", "a", "1M"),
+		p(t, s, "", "a", "32k"),
+	}
+	check(c, "a:<1k", "a:1k-32k", "a:1k-32k", "a:32k-1M", "a:>=1M", "a:>=1M", "a:x")
+
+	// Windowed.
+	s, _ = mustParse(t, "a@window(day)")
+	c = []Config{
+		p(t, s, "", "a", "2021-03-02T10:00:00Z"),
+		p(t, s, "", "a", "x"),
+		p(t, s, "", "a", "2021-03-01T23:59:59Z"),
+		p(t, s, "", "a", "2021-03-02T01:00:00+02:00"),
+		p(t, s, "", "a", "1614643200"),
+	}
+	check(c, "a:2021-03-01", "a:2021-03-01", "a:2021-03-02", "a:2021-03-02", "a:x")
+
+	// Natural.
+	s, _ = mustParse(t, "a@natural")
+	c = []Config{
+		p(t, s, "", "a", "run10"),
+		p(t, s, "", "a", "shard-7b"),
+		p(t, s, "", "a", "run02"),
+		p(t, s, "", "a", "run2"),
+		p(t, s, "", "a", "run"),
+		p(t, s, "", "a", "shard-7a"),
+		p(t, s, "", "a", "shard-12"),
+	}
+	check(c, "a:run", "a:run2", "a:run02", "a:run10", "a:shard-7a", "a:shard-7b", "a:shard-12")
+
+	// Go versions.
+	s, _ = mustParse(t, "a@goversion")
+	c = []Config{
+		p(t, s, "", "a", "devel +abc Tue Jan 2 15:04:05 2024 +0000"),
+		p(t, s, "", "a", "go1.21.3"),
+		p(t, s, "", "a", "x"),
+		p(t, s, "", "a", "go1.9"),
+		p(t, s, "", "a", "devel go1.22-def Mon Jan 1 15:04:05 2024 +0000"),
+		p(t, s, "", "a", "go1.22rc1"),
+		p(t, s, "", "a", "go1.22beta1"),
+		p(t, s, "", "a", "go1.22.0"),
+	}
+	check(c, "a:go1.9", "a:go1.21.3", "a:go1.22beta1", "a:go1.22rc1", "a:go1.22.0", "a:devel go1.22-def Mon Jan 1 15:04:05 2024 +0000", "a:devel +abc Tue Jan 2 15:04:05 2024 +0000", "a:x")
+
 	// Fixed.
 	s, _ = mustParse(t, "a@(c b a)")
 	c = []Config{
@@ -103,6 +231,187 @@ func TestSort(t *testing.T) {
 	check(c, "a:c", "a:b", "a:a")
 }
 
+// randConfigs projects n random results with a mix of sort orders.
+func randConfigs(n int, rng *rand.Rand) []Config {
+	f, _ := NewFilter("*")
+	s, err := (&ProjectionParser{}).Parse("a@num,b,c@-alpha,.name", f)
+	if err != nil {
+		panic(err)
+	}
+	configs := make([]Config, n)
+	for i := range configs {
+		res := &benchfmt.Result{
+			Name:  benchfmt.Name(fmt.Sprintf("B%d", rng.Intn(20))),
+			Iters: 1,
+		}
+		// Leave some values missing.
+		if v := rng.Intn(1001); v < 1000 {
+			res.FileConfig = append(res.FileConfig, benchfmt.Config{Key: "a", Value: []byte(fmt.Sprint(v))})
+		}
+		res.FileConfig = append(res.FileConfig,
+			benchfmt.Config{Key: "b", Value: []byte(fmt.Sprintf("b%d", rng.Intn(100)))},
+			benchfmt.Config{Key: "c", Value: []byte(fmt.Sprintf("c%d", rng.Intn(100)))})
+		configs[i] = s.Project(res)
+	}
+	return configs
+}
+
+// sortConfigsSlow sorts configs by walking the fields of each
+// compared pair. This is the original SortConfigs algorithm.
+func sortConfigsSlow(configs []Config) {
+	if len(configs) == 0 {
+		return
+	}
+	s := commonSchema(configs)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	flat := s.fields()
+	sort.Slice(configs, func(i, j int) bool {
+		return less(flat, configs[i].c.vals, configs[j].c.vals)
+	})
+}
+
+func TestSortConfigsLarge(t *testing.T) {
+	for _, parallel := range []bool{false, true} {
+		t.Run(fmt.Sprintf("parallel=%v", parallel), func(t *testing.T) {
+			if parallel {
+				defer func(old int) { parallelSortMin = old }(parallelSortMin)
+				parallelSortMin = 100
+			}
+			configs := randConfigs(5000, rand.New(rand.NewSource(1)))
+			want := append([]Config(nil), configs...)
+			sortConfigsSlow(want)
+			SortConfigs(configs)
+			for i := range want {
+				if configs[i] != want[i] {
+					t.Fatalf("index %d: got %s, want %s", i, configs[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkSortConfigs(b *testing.B) {
+	for _, n := range []int{1000, 100000} {
+		configs := randConfigs(n, rand.New(rand.NewSource(1)))
+		work := make([]Config, n)
+		for _, impl := range []struct {
+			name string
+			sort func([]Config)
+		}{
+			{"slow", sortConfigsSlow},
+			{"SortConfigs", SortConfigs},
+		} {
+			b.Run(fmt.Sprintf("n=%d/%s", n, impl.name), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					copy(work, configs)
+					impl.sort(work)
+				}
+			})
+		}
+	}
+}
+
+func TestAddOrder(t *testing.T) {
+	// Sort by string length.
+	var pp ProjectionParser
+	pp.AddOrder("len", func(a, b string) int {
+		return len(a) - len(b)
+	})
+	// Shadow a built-in order.
+	pp.AddOrder("num", func(a, b string) int {
+		return strings.Compare(b, a)
+	})
+	f, _ := NewFilter("*")
+	s, err := pp.Parse("a@len,b@num", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := []Config{
+		p(t, s, "", "a", "ccc", "b", "1"),
+		p(t, s, "", "a", "a", "b", "1"),
+		p(t, s, "", "a", "bb", "b", "1"),
+		p(t, s, "", "a", "bb", "b", "2"),
+	}
+	SortConfigs(c)
+	var got []string
+	for _, c := range c {
+		got = append(got, c.String())
+	}
+	want := []string{"a:a b:1", "a:bb b:2", "a:bb b:1", "a:ccc b:1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// Custom orders are local to a ProjectionParser.
+	if _, err := (&ProjectionParser{}).Parse("a@len", f); err == nil {
+		t.Errorf("want error for unknown order")
+	}
+}
+
+func TestCommitOrder(t *testing.T) {
+	cmp := CommitOrder([]string{"c0ffee12", "abc123", "abd456", "0a1b2c"})
+	check := func(a, b string, want int) {
+		t.Helper()
+		got := cmp(a, b)
+		if (got < 0) != (want < 0) || (got > 0) != (want > 0) {
+			t.Errorf("cmp(%s, %s) = %d, want %d", a, b, got, want)
+		}
+	}
+	check("c0ffee12", "abc123", -1)
+	check("0a1b2c", "abd456", 1)
+	check("abd456", "abd456", 0)
+	// Abbreviated hashes.
+	check("c0ff", "abc1", -1)
+	check("0a1", "abd", 1)
+	check("abd456ff", "abc123", 1)
+	// Unknown and ambiguous hashes sort last.
+	check("ffff", "0a1b2c", 1)
+	check("0a1b2c", "ab", -1)
+	check("ffff", "ab", 0)
+	check("", "abc123", 1)
+}
+
+func TestWindow(t *testing.T) {
+	check := func(size, x, want string) {
+		t.Helper()
+		w, err := newWindower(strings.Fields(size))
+		if err != nil {
+			t.Fatalf("%s: unexpected error %s", size, err)
+		}
+		if got := string(w.window([]byte(x))); got != want {
+			t.Errorf("window(%s) of %s: got %s, want %s", size, x, got, want)
+		}
+	}
+	check("hour", "2021-03-02T10:35:00Z", "2021-03-02T10:00:00Z")
+	check("hour", "2021-03-02T10:35:00+01:00", "2021-03-02T09:00:00Z")
+	check("day", "2021-03-02T10:35:00Z", "2021-03-02")
+	check("day", "20210302", "2021-03-02")
+	// 2021-03-03 is a Wednesday.
+	check("week", "2021-03-03T10:35:00Z", "2021-03-01")
+	check("week", "2021-03-01", "2021-03-01")
+	check("week", "2021-03-07T23:00:00Z", "2021-03-01")
+	check("6h", "2021-03-02T10:35:00Z", "2021-03-02T06:00:00Z")
+	check("48h", "2021-03-03T10:35:00Z", "2021-03-02")
+	check("500ms", "2021-03-02T10:35:00.7Z", "2021-03-02T10:35:00.5Z")
+	check("6h", "1969-12-31T23:00:00Z", "1969-12-31T18:00:00Z")
+	check("day", "not a date", "not a date")
+	// Windows anchored at a given time.
+	check("day 2021-03-01T09:00:00Z", "2021-03-02T10:35:00Z", "2021-03-02T09:00:00Z")
+	check("day 2021-03-01T09:00:00Z", "2021-03-02T08:35:00Z", "2021-03-01T09:00:00Z")
+	check("week 2021-03-03", "2021-03-02T10:35:00Z", "2021-02-24")
+	check("week 2021-03-03", "2021-03-10", "2021-03-10")
+	check("48h 2021-03-02", "2021-03-03T10:35:00Z", "2021-03-02")
+	check("48h 2021-03-03", "2021-03-03T10:35:00Z", "2021-03-03")
+	check("hour 2021-03-02T00:30:00Z", "2021-03-02T10:15:00Z", "2021-03-02T09:30:00Z")
+
+	for _, args := range [][]string{{}, {"day", "hour"}, {"day", "2021-03-01", "x"}, {"fortnight"}, {"-1h"}} {
+		if _, err := newWindower(args); err == nil {
+			t.Errorf("%v: want error", args)
+		}
+	}
+}
+
 func TestParseNum(t *testing.T) {
 	check := func(x string, want float64) {
 		t.Helper()
@@ -130,4 +439,151 @@ func TestParseNum(t *testing.T) {
 	check("1E", 1000000000000000000)
 	check("1Z", 1000000000000000000000)
 	check("1Y", 1000000000000000000000000)
+
+	check("100ms", 0.1)
+	check("2s", 2)
+	check("1m30s", 90)
+	check("1h", 3600)
+}
+
+func TestParseDate(t *testing.T) {
+	check := func(x string, want string) {
+		t.Helper()
+		got, err := parseDate(x)
+		if err != nil {
+			t.Errorf("%s: want %v, got error %s", x, want, err)
+		} else if got := got.UTC().Format(time.RFC3339Nano); want != got {
+			t.Errorf("%s: want %v, got %v", x, want, got)
+		}
+	}
+	checkErr := func(x string) {
+		t.Helper()
+		if got, err := parseDate(x); err == nil {
+			t.Errorf("%s: want error, got %v", x, got)
+		}
+	}
+
+	check("2021-01-02T03:04:05Z", "2021-01-02T03:04:05Z")
+	check("2021-01-02T03:04:05.5+01:00", "2021-01-02T02:04:05.5Z")
+	check("2021-01-02 03:04:05", "2021-01-02T03:04:05Z")
+	check("2021-01-02", "2021-01-02T00:00:00Z")
+	check("20210102", "2021-01-02T00:00:00Z")
+	check("1609459200", "2021-01-01T00:00:00Z")
+	check("1609459200.25", "2021-01-01T00:00:00.25Z")
+	check("Mon Jan  4 10:00:00 UTC 2021", "2021-01-04T10:00:00Z")
+	checkErr("")
+	checkErr("yesterday")
+	checkErr("NaN")
+}
+
+func TestParseSemver(t *testing.T) {
+	check := func(a, b string, want int) {
+		t.Helper()
+		aa, err := parseSemver(a)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %s", a, err)
+		}
+		bb, err := parseSemver(b)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %s", b, err)
+		}
+		got := aa.cmp(bb)
+		if (got < 0) != (want < 0) || (got > 0) != (want > 0) {
+			t.Errorf("cmp(%s, %s) = %d, want %d", a, b, got, want)
+		}
+	}
+	checkErr := func(x string) {
+		t.Helper()
+		if _, err := parseSemver(x); err == nil {
+			t.Errorf("%s: want error", x)
+		}
+	}
+
+	// Examples from the semver 2.0.0 specification.
+	vers := []string{"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "2.0.0", "2.1.0", "2.1.1"}
+	for i := range vers {
+		for j := range vers {
+			check(vers[i], vers[j], i-j)
+		}
+	}
+	check("1.2", "1.2.0", 0)
+	check("v1", "1.0.0", 0)
+	check("1.0.0+build.1", "1.0.0+build.2", 0)
+
+	checkErr("")
+	checkErr("v")
+	checkErr("1.2.3.4")
+	checkErr("1..2")
+	checkErr("1.2.3-")
+	checkErr("1.2.3-a..b")
+	checkErr("0x1")
+	checkErr("go1.2")
+}
+
+func TestParseGoVersion(t *testing.T) {
+	check := func(a, b string, want int) {
+		t.Helper()
+		aa, err := parseGoVersion(a)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %s", a, err)
+		}
+		bb, err := parseGoVersion(b)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %s", b, err)
+		}
+		got := aa.cmp(bb)
+		if (got < 0) != (want < 0) || (got > 0) != (want > 0) {
+			t.Errorf("cmp(%s, %s) = %d, want %d", a, b, got, want)
+		}
+	}
+	checkErr := func(x string) {
+		t.Helper()
+		if _, err := parseGoVersion(x); err == nil {
+			t.Errorf("%s: want error", x)
+		}
+	}
+
+	vers := []string{"go1", "go1.0.1", "go1.9", "go1.9.2rc2", "go1.9.2", "go1.10", "go1.21rc2", "go1.21.0", "go1.21.3", "go1.22beta1", "go1.22rc1", "go1.22rc2", "go1.22.0", "devel +abc Mon Jan 1 15:04:05 2024 +0000", "devel go1.23-abc Tue Jan 2 15:04:05 2024 +0000", "devel +def"}
+	for i := range vers {
+		for j := range vers {
+			check(vers[i], vers[j], i-j)
+		}
+	}
+	check("go1.21", "go1.21.0", 0)
+	check("go1.21.3 X:boringcrypto", "go1.21.3", 0)
+	check("devel +abc", "devel +def", 0)
+
+	checkErr("")
+	checkErr("1.21")
+	checkErr("go")
+	checkErr("go1.2.3.4")
+	checkErr("go1.22rc")
+	checkErr("go1..2")
+}
+
+func TestCmpNatural(t *testing.T) {
+	check := func(a, b string, want int) {
+		t.Helper()
+		got := cmpNatural(a, b)
+		if (got < 0) != (want < 0) || (got > 0) != (want > 0) {
+			t.Errorf("cmpNatural(%q, %q) = %d, want %d", a, b, got, want)
+		}
+	}
+	check("", "", 0)
+	check("", "a", -1)
+	check("a", "b", -1)
+	check("a2", "a10", -1)
+	check("a10", "a2", 1)
+	check("a02", "a2", 1)
+	check("a0", "a00", -1)
+	check("run1", "run01", -1)
+	check("run01", "run1", 1)
+	check("a01b", "a1c", -1)
+	check("a01", "a1b", -1)
+	check("a1b01", "a01b1", -1)
+	check("a2b", "a2c", -1)
+	check("a2", "a2b", -1)
+	check("1", "a", -1)
+	check("x99999999999999999999999", "x100000000000000000000000", -1)
+	check("v1.10.2", "v1.9.5", 1)
 }
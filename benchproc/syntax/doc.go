@@ -46,9 +46,25 @@
 // configuration of a benchmark. This isn't a string like the other
 // components, but rather a tuple.
 //
+// - ".namekeys" (only in projections) refers to all of the sub-name
+// configuration keys of a benchmark, such as "/size" and
+// "/gomaxprocs". Like ".config", this is a tuple, with one element
+// for each observed key. Projecting ".namekeys" excludes all sub-name
+// keys from ".fullname".
+//
 // - ".label" refers to the input file provided on the command line
 // (for command-line tools that use benchfmt.Files).
 //
+// - ".file-index" refers to the position of the input file on the
+// command line, starting at 0 (for command-line tools that use
+// benchfmt.Files). Unlike ".label", this doesn't depend on file
+// names or user-provided labels, so "-col .file-index@num" orders
+// columns by command-line position.
+//
+// - ".iters" refers to the iteration count of a result. For example,
+// the filter "-.iters:/^[0-9]$/" excludes results with fewer than 10
+// iterations.
+//
 // Filters
 //
 // Filters are boolean expressions that match or exclude benchmark
@@ -68,6 +84,11 @@
 // operator can be omitted, so "a:b AND c:d" is equivalent to "a:b
 // c:d".
 //
+// Tools may also define predicate functions, which are called like
+// "name(key1 key2 ...)" and match results based on the values of the
+// given keys. For example, a tool could define "primeSize(/size)".
+// Consult each tool's documentation for the predicates it defines.
+//
 // Detailed syntax:
 //
 //   expr     = andExpr {"OR" andExpr}
@@ -77,6 +98,7 @@
 //            | "*"
 //            | key ":" value
 //            | key ":" "(" value {value} ")"
+//            | name "(" key {key} ")"
 //   key      = word
 //   value    = word
 //            | "/" regexp "/"
@@ -96,26 +118,103 @@
 // - "key@order" specifies one of the built-in named sort orders. This
 // can be "alpha" or "num" for alphabetic or numeric sorting. "num"
 // understands basic use of metric and IEC prefixes like "2k" and
-// "1Mi".
+// "1Mi", as well as Go durations like "100ms" and "1m30s". "duration"
+// sorts only Go durations, with other values sorted last. "natural"
+// sorts alphabetically, but compares runs of digits numerically, so
+// "run2" sorts before "run10". "date" sorts
+// timestamps chronologically and understands RFC 3339 timestamps,
+// "YYYY-MM-DD", "YYYYMMDD", and Unix seconds; values that aren't
+// timestamps sort last. "semver" sorts semantic versions
+// like "v1.2.10" and "1.3.0-rc.1" according to semantic versioning
+// precedence. "goversion" sorts Go toolchain versions like "go1.21.3"
+// and "go1.22rc1" by release, followed by development builds like
+// "devel +0a1b2c3 Tue Jan 2 15:04:05 2024 +0000" ordered by their
+// commit date.
 //
 // - "key@(value value ...)" specifies a fixed value order for key.
 // It also specifies a filter: if key has a value that isn't any of
 // the specified values, the result is filtered out.
 //
+// - "key@bin(bound bound ...)" maps numeric values of key into
+// buckets with the given increasing boundaries, and orders the
+// buckets. Boundaries are parsed like "num" values. For example,
+// "/size@bin(1k 1M)" maps sizes to "<1k", "1k-1M", or ">=1M".
+// Values that aren't numbers are left as is and sorted after the
+// buckets. Binning isn't allowed for .config or .fullname.
+//
+// - "key@window(size)" maps timestamps of key to the start of the
+// fixed time window containing them, and orders the windows
+// chronologically. The size can be "hour", "day", "week", or a Go
+// duration like "6h". Timestamps are understood as in the "date"
+// order. Windows are in UTC and aligned to the Unix epoch, except
+// that weeks start on Monday. Windows of whole days are labeled like
+// "2021-03-01", and other windows are labeled with RFC 3339
+// timestamps. For example, "date@window(day)" groups results by day.
+// Windowing isn't allowed for .config or .fullname.
+//
+// - "key@window(size anchor)" is like "key@window(size)", but aligns
+// the windows so that one starts at the anchor timestamp, which is
+// parsed like a "date" value. For example,
+// `date@window(day "2021-03-01T09:00:00Z")` groups results into days
+// that start at 09:00 UTC, and "date@window(week 2021-03-03)" groups
+// them into weeks that start on Wednesday.
+//
+// - "key@-order" or "key@order:desc" reverses the sort order. This
+// works for named and fixed orders; for example, "/size@-num" sorts
+// the largest sizes first and "commit@-first" sorts the most recently
+// observed commit first. "key@order:asc" explicitly requests the
+// default ascending order.
+//
 // Syntax:
 //
 //   expr     = part {","? part}
 //   part     = key
-//            | key "@" order
-//            | key "@" "(" word {word} ")"
+//            | key "@" ["-"] order [dir]
+//            | key "@" ["-"] order "(" word {word} ")" [dir]
+//            | key "@" ["-"] "(" word {word} ")" [dir]
+//   dir      = ":" ("asc" | "desc")
 //   key      = word
 //   order    = word
 //
+// Queries
+//
+// A query combines a filter and several projections into a single
+// string, so a tool can accept one expression instead of several
+// coordinated flags. A query is a sequence of clauses, each
+// introduced by an upper-case keyword. The "FILTER" keyword
+// introduces a filter expression and the tool defines the keywords
+// that introduce projections. For example, a tool with "ROW" and
+// "COL" projections could accept the query
+//
+//   FILTER goos:linux ROW .name COL /format@(json gob)
+//
+// Clauses may appear in any order, but each at most once. Keywords
+// are reserved within a query, so they must be quoted to be used as
+// keys or values. A tool applies defaults for omitted clauses.
+//
+// Syntax:
+//
+//   query    = {clause}
+//   clause   = "FILTER" filter-expr
+//            | keyword projection-expr
+//
 // Common syntax
 //
 // Filters and projections share the following common base syntax:
 //
 //   word     = bareWord
 //            | double-quoted Go string
-//   bareWord = [^-*"():@,][^ ():@,]*
+//   bareWord = [^-*#"():@,][^ ():@,]*
+//
+// Whitespace, including newlines, is insignificant between words and
+// operators, so long expressions can be split across lines. A "#"
+// at the start of a word begins a comment that extends to the end of
+// the line. A "#" in the middle of a bare word is part of the word,
+// so "old.txt#0" is a single word. Words beginning with "#" must be
+// quoted. For example:
+//
+//   # Compare on linux only.
+//   goos:linux
+//   # Skip the small sizes.
+//   -/size:(1 2 4)
 package syntax
@@ -0,0 +1,115 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchproc
+
+import (
+	"sort"
+
+	"golang.org/x/perf/benchfmt"
+)
+
+// A TopK retains at most K Results for each Config of a Schema. This
+// can be used to trim unbalanced data sets, such as keeping only the
+// 10 most recent runs of each benchmark on each machine.
+type TopK struct {
+	schema *Schema
+	k      int
+	// better reports whether a should be retained in preference
+	// to b.
+	better func(a, b topKEntry) bool
+	groups map[Config][]topKEntry
+	seq    int
+}
+
+type topKEntry struct {
+	seq int // Order in which this Result was added
+	res *benchfmt.Result
+}
+
+// NewTopK returns a TopK that retains, for each Config of Schema s,
+// the k Results that sort first according to less. Results that are
+// unordered by less are retained in the order they were added. If
+// less is nil, NewTopK retains the first k Results added for each
+// Config.
+func NewTopK(s *Schema, k int, less func(a, b *benchfmt.Result) bool) *TopK {
+	better := func(a, b topKEntry) bool {
+		if less != nil {
+			if less(a.res, b.res) {
+				return true
+			} else if less(b.res, a.res) {
+				return false
+			}
+		}
+		return a.seq < b.seq
+	}
+	return newTopK(s, k, better)
+}
+
+// NewLastK returns a TopK that retains the last k Results added for
+// each Config of Schema s.
+func NewLastK(s *Schema, k int) *TopK {
+	return newTopK(s, k, func(a, b topKEntry) bool {
+		return a.seq > b.seq
+	})
+}
+
+func newTopK(s *Schema, k int, better func(a, b topKEntry) bool) *TopK {
+	if k < 0 {
+		panic("k must be >= 0")
+	}
+	return &TopK{schema: s, k: k, better: better, groups: make(map[Config][]topKEntry)}
+}
+
+// Add projects res using the TopK's Schema and retains a copy of it
+// if it is among the top K Results for its Config so far. It returns
+// the Config of res.
+func (t *TopK) Add(res *benchfmt.Result) Config {
+	cfg := t.schema.Project(res)
+	e := topKEntry{t.seq, res}
+	t.seq++
+
+	group := t.groups[cfg]
+	if len(group) < t.k {
+		e.res = res.Clone()
+		t.groups[cfg] = append(group, e)
+		return cfg
+	}
+	// Find the worst retained Result and replace it if e is
+	// better.
+	worst := -1
+	for i := range group {
+		if worst == -1 || t.better(group[worst], group[i]) {
+			worst = i
+		}
+	}
+	if worst >= 0 && t.better(e, group[worst]) {
+		e.res = res.Clone()
+		group[worst] = e
+	}
+	return cfg
+}
+
+// Results returns the retained Results. Results are grouped by
+// Config in sorted Config order, and within each group they are in
+// the order they were added.
+func (t *TopK) Results() []*benchfmt.Result {
+	cfgs := make([]Config, 0, len(t.groups))
+	for cfg := range t.groups {
+		cfgs = append(cfgs, cfg)
+	}
+	SortConfigs(cfgs)
+
+	var out []*benchfmt.Result
+	for _, cfg := range cfgs {
+		group := append([]topKEntry(nil), t.groups[cfg]...)
+		sort.Slice(group, func(i, j int) bool {
+			return group[i].seq < group[j].seq
+		})
+		for _, e := range group {
+			out = append(out, e.res)
+		}
+	}
+	return out
+}
@@ -0,0 +1,56 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchproc
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/perf/benchfmt"
+)
+
+func TestTopK(t *testing.T) {
+	s, _ := mustParse(t, ".name")
+	// Each result has a unique iteration count so we can
+	// identify it.
+	add := func(tk *TopK) {
+		res := r(t, "A")
+		for i, name := range []string{"A", "B", "A", "A", "B", "A"} {
+			res.Name = benchfmt.Name(name)
+			res.Iters = []int{5, 1, 3, 9, 2, 4}[i]
+			tk.Add(res)
+		}
+		// Modifying res must not affect the retained Results.
+		res.Iters = -1
+	}
+	check := func(tk *TopK, want ...string) {
+		t.Helper()
+		var got []string
+		for _, res := range tk.Results() {
+			got = append(got, string(res.Name)+":"+string(rune('0'+res.Iters)))
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	}
+
+	tk := NewTopK(s, 2, nil)
+	add(tk)
+	check(tk, "A:5", "A:3", "B:1", "B:2")
+
+	tk = NewLastK(s, 2)
+	add(tk)
+	check(tk, "A:9", "A:4", "B:1", "B:2")
+
+	tk = NewTopK(s, 2, func(a, b *benchfmt.Result) bool {
+		return a.Iters < b.Iters
+	})
+	add(tk)
+	check(tk, "A:3", "A:4", "B:1", "B:2")
+
+	tk = NewTopK(s, 0, nil)
+	add(tk)
+	check(tk)
+}
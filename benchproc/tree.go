@@ -0,0 +1,109 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchproc
+
+import "golang.org/x/perf/benchfmt"
+
+// A Tree groups values hierarchically by a sequence of Schemas. For
+// example, a Tree with levels "pkg", ".name", and "/size" groups
+// values first by package, then within each package by benchmark
+// name, then within each benchmark by size.
+//
+// The Schemas for the levels of a Tree should typically be parsed by
+// the same ProjectionParser, so that keys in one level are excluded
+// from group keys such as .fullname in another level.
+type Tree struct {
+	levels []*Schema
+	root   *TreeNode
+}
+
+// A TreeNode is a group of values in a Tree.
+type TreeNode struct {
+	// Config is the Config of this group at its level of the
+	// Tree. It is the zero Config for the root of the Tree.
+	Config Config
+
+	// Level is the depth of this node in the Tree. The root is
+	// level 0, and its children are grouped by the Tree's first
+	// Schema.
+	Level int
+
+	// Values is all of the values added to this group and its
+	// descendants, in the order they were added. This is useful
+	// for computing per-group summaries.
+	Values []interface{}
+
+	children map[Config]*TreeNode
+}
+
+// NewTree returns a new Tree whose levels are grouped by levels, in
+// order from the root.
+func NewTree(levels ...*Schema) *Tree {
+	return &Tree{levels, &TreeNode{}}
+}
+
+// Add projects res using each level of the Tree and adds val to the
+// group at each level.
+func (t *Tree) Add(res *benchfmt.Result, val interface{}) {
+	node := t.root
+	node.Values = append(node.Values, val)
+	for i, s := range t.levels {
+		cfg := s.Project(res)
+		child := node.children[cfg]
+		if child == nil {
+			if node.children == nil {
+				node.children = make(map[Config]*TreeNode)
+			}
+			child = &TreeNode{Config: cfg, Level: i + 1}
+			node.children[cfg] = child
+		}
+		child.Values = append(child.Values, val)
+		node = child
+	}
+}
+
+// Root returns the root of the Tree, which contains all values added
+// to the Tree.
+func (t *Tree) Root() *TreeNode {
+	return t.root
+}
+
+// Children returns the sub-groups of n, sorted by Config. It returns
+// nil if n is a leaf.
+func (n *TreeNode) Children() []*TreeNode {
+	if len(n.children) == 0 {
+		return nil
+	}
+	cfgs := make([]Config, 0, len(n.children))
+	for cfg := range n.children {
+		cfgs = append(cfgs, cfg)
+	}
+	SortConfigs(cfgs)
+	out := make([]*TreeNode, len(cfgs))
+	for i, cfg := range cfgs {
+		out[i] = n.children[cfg]
+	}
+	return out
+}
+
+// Walk calls fn for each node in the Tree, except the root, in
+// depth-first order with each node's children in sorted order. path
+// is the sequence of Configs from the first level of the Tree down to
+// and including node; fn must not retain it. If fn returns false,
+// Walk skips the children of node.
+func (t *Tree) Walk(fn func(path []Config, node *TreeNode) bool) {
+	var path []Config
+	var walk func(n *TreeNode)
+	walk = func(n *TreeNode) {
+		for _, child := range n.Children() {
+			path = append(path, child.Config)
+			if fn(path, child) {
+				walk(child)
+			}
+			path = path[:len(path)-1]
+		}
+	}
+	walk(t.root)
+}
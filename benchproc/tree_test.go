@@ -0,0 +1,72 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchproc
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTree(t *testing.T) {
+	var pp ProjectionParser
+	f, _ := NewFilter("*")
+	mustParse := func(proj string) *Schema {
+		s, err := pp.Parse(proj, f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	tree := NewTree(mustParse("pkg"), mustParse(".name"), mustParse("/size@num"))
+
+	add := func(name, pkg string, val int) {
+		tree.Add(r(t, name, "pkg", pkg), val)
+	}
+	add("A/size=10", "p1", 1)
+	add("A/size=2", "p1", 2)
+	add("B/size=1", "p1", 3)
+	add("A/size=2", "p2", 4)
+	add("A/size=10", "p1", 5)
+
+	if got, want := tree.Root().Values, []interface{}{1, 2, 3, 4, 5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("root values: got %v, want %v", got, want)
+	}
+
+	var got []string
+	tree.Walk(func(path []Config, node *TreeNode) bool {
+		var parts []string
+		for _, c := range path {
+			parts = append(parts, c.String())
+		}
+		got = append(got, fmt.Sprintf("%d %s %v", node.Level, strings.Join(parts, " "), node.Values))
+		return true
+	})
+	want := []string{
+		"1 pkg:p1 [1 2 3 5]",
+		"2 pkg:p1 .name:A [1 2 5]",
+		"3 pkg:p1 .name:A /size:2 [2]",
+		"3 pkg:p1 .name:A /size:10 [1 5]",
+		"2 pkg:p1 .name:B [3]",
+		"3 pkg:p1 .name:B /size:1 [3]",
+		"1 pkg:p2 [4]",
+		"2 pkg:p2 .name:A [4]",
+		"3 pkg:p2 .name:A /size:2 [4]",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// Skip children.
+	got = nil
+	tree.Walk(func(path []Config, node *TreeNode) bool {
+		got = append(got, node.Config.String())
+		return false
+	})
+	if want := []string{"pkg:p1", "pkg:p2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchunit
+
+import "fmt"
+
+// Better indicates whether higher or lower values of a unit are
+// better. Its value is the sign of an improvement, so a change in a
+// value multiplied by its Better is positive if it's an improvement.
+type Better int
+
+const (
+	// BetterLower indicates lower values are better, such as for
+	// time or memory per operation.
+	BetterLower Better = -1
+
+	// BetterUnknown indicates it's not known which direction is
+	// better.
+	BetterUnknown Better = 0
+
+	// BetterHigher indicates higher values are better, such as
+	// for throughput.
+	BetterHigher Better = 1
+)
+
+// String returns the name of b, which is the syntax of the "better"
+// unit metadata key.
+func (b Better) String() string {
+	switch b {
+	case BetterLower:
+		return "lower"
+	case BetterUnknown:
+		return "unknown"
+	case BetterHigher:
+		return "higher"
+	}
+	return fmt.Sprintf("Better(%d)", int(b))
+}
+
+// ParseBetter parses the value of the "better" unit metadata key,
+// which must be "higher" or "lower".
+func ParseBetter(s string) (Better, error) {
+	switch s {
+	case "higher":
+		return BetterHigher, nil
+	case "lower":
+		return BetterLower, nil
+	}
+	return BetterUnknown, fmt.Errorf("unknown better direction %q", s)
+}
+
+// betterDefaults gives the better direction of common tidied units.
+var betterDefaults = map[string]Better{
+	"sec/op":    BetterLower,
+	"B/op":      BetterLower,
+	"allocs/op": BetterLower,
+	"B/s":       BetterHigher,
+	"op/s":      BetterHigher,
+}
+
+// BetterOf returns whether higher or lower values of unit are better.
+//
+// If lookup is non-nil, it is used to look up unit metadata, such as
+// benchfmt.Units.Get, and a "better" metadata value overrides the
+// default. Otherwise, BetterOf uses a built-in table of common units
+// and heuristics. Rates, which have seconds in the denominator, are
+// better higher. Units that measure time, bytes, or allocations per
+// something are better lower. BetterOf returns BetterUnknown for
+// other units.
+func BetterOf(unit string, lookup func(unit, key string) (string, bool)) Better {
+	if lookup != nil {
+		if v, ok := lookup(unit, "better"); ok {
+			if b, err := ParseBetter(v); err == nil {
+				return b
+			}
+		}
+	}
+
+	tidied, _ := Tidy(unit)
+	if b, ok := betterDefaults[tidied]; ok {
+		return b
+	}
+	num, denom := unitFactors(tidied)
+	for _, d := range denom {
+		if d == "sec" {
+			return BetterHigher
+		}
+	}
+	if len(denom) == 0 {
+		// Totals, such as "B", could go either way.
+		return BetterUnknown
+	}
+	for _, n := range num {
+		if hasQuantity(n, "sec") || hasQuantity(n, "B") || hasQuantity(n, "allocs") {
+			return BetterLower
+		}
+	}
+	return BetterUnknown
+}
+
+// hasQuantity reports whether factor is quantity, possibly qualified
+// with a hyphenated prefix, such as "user-sec" for "sec".
+func hasQuantity(factor, quantity string) bool {
+	return factor == quantity || len(factor) > len(quantity) && factor[len(factor)-len(quantity)-1] == '-' && factor[len(factor)-len(quantity):] == quantity
+}
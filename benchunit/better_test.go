@@ -0,0 +1,62 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchunit
+
+import "testing"
+
+func TestBetterOf(t *testing.T) {
+	test := func(unit string, want Better) {
+		t.Helper()
+		if got := BetterOf(unit, nil); got != want {
+			t.Errorf("for %s, want %s, got %s", unit, want, got)
+		}
+	}
+	test("ns/op", BetterLower)
+	test("sec/op", BetterLower)
+	test("B/op", BetterLower)
+	test("allocs/op", BetterLower)
+	test("MB/s", BetterHigher)
+	test("B/s", BetterHigher)
+	test("op/s", BetterHigher)
+	test("user-sec/op", BetterLower)
+	test("disk-B/op", BetterLower)
+	test("rows/s", BetterHigher)
+	test("rows/op", BetterUnknown)
+	test("B", BetterUnknown)
+	test("misses", BetterUnknown)
+}
+
+func TestBetterOfMetadata(t *testing.T) {
+	meta := map[string]string{"rows/op": "higher", "B/s": "lower", "x/op": "sideways"}
+	lookup := func(unit, key string) (string, bool) {
+		if key != "better" {
+			return "", false
+		}
+		v, ok := meta[unit]
+		return v, ok
+	}
+	test := func(unit string, want Better) {
+		t.Helper()
+		if got := BetterOf(unit, lookup); got != want {
+			t.Errorf("for %s, want %s, got %s", unit, want, got)
+		}
+	}
+	test("rows/op", BetterHigher)
+	test("B/s", BetterLower)
+	test("sec/op", BetterLower)
+	// Invalid metadata is ignored.
+	test("x/op", BetterUnknown)
+}
+
+func TestParseBetter(t *testing.T) {
+	for _, b := range []Better{BetterLower, BetterHigher} {
+		if got, err := ParseBetter(b.String()); err != nil || got != b {
+			t.Errorf("ParseBetter(%q) = %v, %v", b.String(), got, err)
+		}
+	}
+	if _, err := ParseBetter("unknown"); err == nil {
+		t.Errorf("ParseBetter(%q): want error", "unknown")
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchunit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// convertBases maps the base quantities Convert understands to their
+// canonical names.
+var convertBases = map[string]string{
+	"sec": "sec",
+	"s":   "sec",
+	"B":   "B",
+}
+
+// Convert converts value in unit from to unit to, where the units
+// differ only in prefixes on seconds or bytes. For example, it
+// converts "MB/s" to "GiB/s" and "ns/op" to "ms/op". Seconds may be
+// written "s" or "sec", with SI prefixes, and bytes may be written
+// "B", with SI or IEC prefixes. All other parts of the units, such as
+// "op", must match exactly. Convert returns an error if the units
+// aren't compatible.
+func Convert(value float64, from, to string) (float64, error) {
+	dimFrom, scaleFrom, err1 := unitDimension(from)
+	dimTo, scaleTo, err2 := unitDimension(to)
+	if err1 != nil {
+		return 0, err1
+	}
+	if err2 != nil {
+		return 0, err2
+	}
+	if dimFrom != dimTo {
+		return 0, fmt.Errorf("cannot convert %s to %s", from, to)
+	}
+	return value * scaleFrom / scaleTo, nil
+}
+
+// unitDimension returns a canonical form of unit with prefixes
+// removed and the factor to convert a value in unit to that form.
+func unitDimension(unit string) (dim string, scale float64, err error) {
+	num, denom := unitFactors(unit)
+	scale = 1
+	canon := func(factors []string, inDenom bool) []string {
+		out := make([]string, len(factors))
+		for i, f := range factors {
+			base, s := splitPrefix(f)
+			out[i] = base
+			if inDenom {
+				scale /= s
+			} else {
+				scale *= s
+			}
+		}
+		sort.Strings(out)
+		return out
+	}
+	n, d := canon(num, false), canon(denom, true)
+	if len(n) == 0 && len(d) == 0 {
+		return "", 0, fmt.Errorf("empty unit %q", unit)
+	}
+	return strings.Join(n, "*") + "/" + strings.Join(d, "/"), scale, nil
+}
+
+// splitPrefix splits a unit factor name such as "MiB" or "disk-ms" into a
+// canonical base, such as "B" or "disk-sec", and the value of its
+// prefix. Factors that aren't a prefixed base quantity are returned
+// as is, with a scale of 1.
+func splitPrefix(name string) (base string, scale float64) {
+	qual, f := "", name
+	if i := strings.LastIndexByte(name, '-'); i >= 0 {
+		qual, f = name[:i+1], name[i+1:]
+	}
+	for b, canon := range convertBases {
+		if !strings.HasSuffix(f, b) {
+			continue
+		}
+		prefix := f[:len(f)-len(b)]
+		switch prefix {
+		case "u", "μ":
+			prefix = "µ"
+		}
+		// Binary prefixes only apply to bytes.
+		sets := [][]factor{siFactors}
+		if canon == "B" {
+			sets = append(sets, iecFactors)
+		}
+		for _, factors := range sets {
+			for _, fac := range factors {
+				if fac.prefix == prefix {
+					return qual + canon, fac.factor
+				}
+			}
+		}
+	}
+	return name, 1
+}
@@ -0,0 +1,46 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchunit
+
+import (
+	"math"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	test := func(value float64, from, to string, want float64) {
+		t.Helper()
+		got, err := Convert(value, from, to)
+		if err != nil {
+			t.Errorf("converting %v %s to %s: %v", value, from, to, err)
+		} else if math.Abs(got-want) > 1e-12*math.Abs(want) {
+			t.Errorf("converting %v %s to %s: got %v, want %v", value, from, to, got, want)
+		}
+	}
+	test(1500000, "ns/op", "ms/op", 1.5)
+	test(1.5, "ms/op", "sec/op", 0.0015)
+	test(2, "sec/op", "µs/op", 2e6)
+	test(2, "sec/op", "us/op", 2e6)
+	test(1024, "MB/s", "GiB/s", 1024e6/(1<<30))
+	test(1, "GiB", "B", 1<<30)
+	test(3, "B/op", "KiB/op", 3.0/1024)
+	test(1, "op/ns", "op/s", 1e9)
+	test(5, "allocs/op", "allocs/op", 5)
+	test(1, "disk-MB/op", "disk-kB/op", 1000)
+	test(1, "B*sec/op", "sec*kB/op", 1e-3)
+
+	for _, units := range [][2]string{
+		{"ns/op", "B/op"},
+		{"B/op", "B/s"},
+		{"allocs/op", "kallocs/op"},
+		{"disk-B/op", "B/op"},
+		{"Kis/op", "sec/op"},
+		{"", "sec/op"},
+	} {
+		if _, err := Convert(1, units[0], units[1]); err == nil {
+			t.Errorf("converting %s to %s: want error", units[0], units[1])
+		}
+	}
+}
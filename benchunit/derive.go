@@ -0,0 +1,106 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchunit
+
+import "strings"
+
+// Divide returns the unit of a value in unit1 divided by a value in
+// unit2, with common factors cancelled. For example, Divide("B/op",
+// "sec/op") is "B/s", and Divide("B/op", "B/op") is "", a
+// dimensionless ratio.
+//
+// unit1 and unit2 are tidied (see Tidy) first, so they may be
+// pre-scaled units like "ns/op". To convert values v1 and v2 to a
+// value in the derived unit, compute v1 / v2 * factor.
+//
+// Following the convention of the testing package, seconds are
+// written "sec" in a numerator and "s" in a denominator, and the two
+// cancel each other.
+func Divide(unit1, unit2 string) (unit string, factor float64) {
+	t1, f1 := Tidy(unit1)
+	t2, f2 := Tidy(unit2)
+	n1, d1 := unitFactors(t1)
+	n2, d2 := unitFactors(t2)
+	return formatFactors(append(n1, d2...), append(d1, n2...)), f1 / f2
+}
+
+// Multiply returns the unit of a value in unit1 multiplied by a value
+// in unit2, with common factors cancelled. For example,
+// Multiply("allocs/op", "op") is "allocs". Like Divide, it tidies
+// unit1 and unit2 first. To convert values v1 and v2 to a value in
+// the derived unit, compute v1 * v2 * factor.
+func Multiply(unit1, unit2 string) (unit string, factor float64) {
+	t1, f1 := Tidy(unit1)
+	t2, f2 := Tidy(unit2)
+	n1, d1 := unitFactors(t1)
+	n2, d2 := unitFactors(t2)
+	return formatFactors(append(n1, n2...), append(d1, d2...)), f1 * f2
+}
+
+// unitFactors splits unit into the factors of its numerator and
+// denominator. Unlike the parser used by ClassOf, this keeps
+// hyphenated names like "disk-B" together, since they name a single
+// quantity.
+func unitFactors(unit string) (num, denom []string) {
+	field, inDenom := "", false
+	flush := func() {
+		if field == "s" {
+			field = "sec"
+		}
+		if field != "" {
+			if inDenom {
+				denom = append(denom, field)
+			} else {
+				num = append(num, field)
+			}
+		}
+		field = ""
+	}
+	for _, r := range unit {
+		switch r {
+		case '*':
+			flush()
+			inDenom = false
+		case '/':
+			flush()
+			inDenom = true
+		default:
+			field += string(r)
+		}
+	}
+	flush()
+	return
+}
+
+// formatFactors cancels common factors of num and denom and formats
+// the result as a unit.
+func formatFactors(num, denom []string) string {
+	var keep []string
+	for _, n := range num {
+		cancelled := false
+		for i, d := range denom {
+			if n == d {
+				denom = append(denom[:i:i], denom[i+1:]...)
+				cancelled = true
+				break
+			}
+		}
+		if !cancelled {
+			keep = append(keep, n)
+		}
+	}
+	var b strings.Builder
+	if len(keep) == 0 && len(denom) > 0 {
+		b.WriteString("1")
+	}
+	b.WriteString(strings.Join(keep, "*"))
+	for _, d := range denom {
+		if d == "sec" {
+			d = "s"
+		}
+		b.WriteString("/" + d)
+	}
+	return b.String()
+}
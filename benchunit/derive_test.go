@@ -0,0 +1,43 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchunit
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDivide(t *testing.T) {
+	test := func(unit1, unit2, want string, wantFactor float64) {
+		t.Helper()
+		got, factor := Divide(unit1, unit2)
+		if got != want || math.Abs(factor-wantFactor) > 1e-12*wantFactor {
+			t.Errorf("for %s ÷ %s, want *%v %q, got *%v %q", unit1, unit2, wantFactor, want, factor, got)
+		}
+	}
+	test("B/op", "sec/op", "B/s", 1)
+	test("B/op", "ns/op", "B/s", 1e9)
+	test("allocs/op", "sec/op", "allocs/s", 1)
+	test("disk-B/op", "sec/op", "disk-B/s", 1)
+	test("B/op", "B/op", "", 1)
+	test("sec/op", "B/op", "sec/B", 1)
+	test("op", "sec", "op/s", 1)
+	test("B/s", "B", "1/s", 1)
+	test("MB/s", "B/op", "op/s", 1e6)
+}
+
+func TestMultiply(t *testing.T) {
+	test := func(unit1, unit2, want string, wantFactor float64) {
+		t.Helper()
+		got, factor := Multiply(unit1, unit2)
+		if got != want || math.Abs(factor-wantFactor) > 1e-12*wantFactor {
+			t.Errorf("for %s × %s, want *%v %q, got *%v %q", unit1, unit2, wantFactor, want, factor, got)
+		}
+	}
+	test("allocs/op", "op", "allocs", 1)
+	test("B/s", "sec/op", "B/op", 1)
+	test("MB/s", "ns/op", "B/op", 1e6*1e-9)
+	test("B/op", "B/op", "B*B/op/op", 1)
+}
@@ -0,0 +1,57 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchunit
+
+import "strings"
+
+// splitRatio splits a unit of the form "num/denom" into its numerator
+// and denominator. It returns ok=false if unit isn't a simple ratio,
+// such as if it has no denominator or contains a product.
+func splitRatio(unit string) (num, denom string, ok bool) {
+	i := strings.IndexByte(unit, '/')
+	if i <= 0 || i == len(unit)-1 || strings.ContainsAny(unit[i+1:], "/*") || strings.ContainsRune(unit[:i], '*') {
+		return "", "", false
+	}
+	return unit[:i], unit[i+1:], true
+}
+
+// Invert returns the reciprocal of a ratio unit, such as "op/s" for
+// "sec/op". A value x in unit is a value 1/x in the inverted unit.
+//
+// unit should be tidied (see Tidy). Following the convention of the
+// testing package, seconds are written "sec" in a numerator and "s"
+// in a denominator, so Invert("sec/op") is "op/s" and Invert("op/s")
+// is "sec/op". Invert returns ok=false if unit isn't of the form
+// "num/denom" with a single numerator and denominator.
+func Invert(unit string) (inverted string, ok bool) {
+	num, denom, ok := splitRatio(unit)
+	if !ok {
+		return "", false
+	}
+	switch {
+	case num == "sec":
+		num = "s"
+	case denom == "s":
+		denom = "sec"
+	}
+	return denom + "/" + num, true
+}
+
+// Rate returns the unit of a throughput computed by dividing a value
+// in unit amount by a value in unit duration, where both are measured
+// per the same thing. For example, Rate("B/op", "sec/op") is "B/s":
+// given b B/op and t sec/op, the throughput is b/t B/s.
+//
+// amount and duration should be tidied (see Tidy). Rate returns
+// ok=false if they aren't both simple ratios with the same
+// denominator, or if duration isn't measured in seconds.
+func Rate(amount, duration string) (rate string, ok bool) {
+	num, denom1, ok1 := splitRatio(amount)
+	sec, denom2, ok2 := splitRatio(duration)
+	if !ok1 || !ok2 || denom1 != denom2 || sec != "sec" {
+		return "", false
+	}
+	return num + "/s", true
+}
@@ -0,0 +1,59 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchunit
+
+import "testing"
+
+func TestInvert(t *testing.T) {
+	test := func(unit, want string, wantOK bool) {
+		t.Helper()
+		got, ok := Invert(unit)
+		if got != want || ok != wantOK {
+			t.Errorf("for %s, want %q, %v, got %q, %v", unit, want, wantOK, got, ok)
+		}
+	}
+	test("sec/op", "op/s", true)
+	test("op/s", "sec/op", true)
+	test("B/op", "op/B", true)
+	test("allocs/op", "op/allocs", true)
+	test("disk-B/op", "op/disk-B", true)
+
+	test("B", "", false)
+	test("sec/B/B", "", false)
+	test("B*B/s", "", false)
+	test("/op", "", false)
+
+	// Inverting twice gets back the original unit.
+	for _, unit := range []string{"sec/op", "op/s", "B/op", "x-sec/op"} {
+		inv, _ := Invert(unit)
+		if got, _ := Invert(inv); got != unit {
+			t.Errorf("for %s, inverted twice got %s", unit, got)
+		}
+	}
+}
+
+func TestRate(t *testing.T) {
+	test := func(amount, duration, want string, wantOK bool) {
+		t.Helper()
+		got, ok := Rate(amount, duration)
+		if got != want || ok != wantOK {
+			t.Errorf("for %s and %s, want %q, %v, got %q, %v", amount, duration, want, wantOK, got, ok)
+		}
+	}
+	test("B/op", "sec/op", "B/s", true)
+	test("disk-B/op", "sec/op", "disk-B/s", true)
+	test("allocs/op", "sec/op", "allocs/s", true)
+
+	test("B/op", "ns/op", "", false)
+	test("B/op", "sec/row", "", false)
+	test("B", "sec/op", "", false)
+
+	// Tidied units from the testing package compose.
+	amount, _ := Tidy("B/op")
+	duration, _ := Tidy("ns/op")
+	if got, _ := Rate(amount, duration); got != "B/s" {
+		t.Errorf("for tidied B/op and ns/op, got %s, want B/s", got)
+	}
+}
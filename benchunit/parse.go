@@ -8,6 +8,8 @@ package benchunit
 
 import (
 	"fmt"
+	"strings"
+	"sync"
 	"unicode"
 )
 
@@ -36,9 +38,87 @@ func (c Class) String() string {
 	return fmt.Sprintf("Class(%d)", int(c))
 }
 
-// ClassOf returns the Class of unit. If unit contains some measure of
-// bytes in the numerator, this is Binary. Otherwise, it is Decimal.
+// ParseClass returns the Class named name, which must be "decimal" or
+// "binary", ignoring case. This is the syntax of the "class" unit
+// metadata key, which overrides ClassOf for a unit.
+func ParseClass(name string) (Class, error) {
+	for _, c := range []Class{Decimal, Binary} {
+		if strings.EqualFold(c.String(), name) {
+			return c, nil
+		}
+	}
+	return Decimal, fmt.Errorf("unknown unit class %q", name)
+}
+
+var classOverrides struct {
+	sync.RWMutex
+	m map[string]Class
+}
+
+// SetClass overrides the Class ClassOf returns for unit. This is
+// useful for custom units that ClassOf's heuristics misclassify.
+func SetClass(unit string, cls Class) {
+	classOverrides.Lock()
+	defer classOverrides.Unlock()
+	if classOverrides.m == nil {
+		classOverrides.m = make(map[string]Class)
+	}
+	classOverrides.m[unit] = cls
+}
+
+// ClearClass removes any override set by SetClass for unit.
+func ClearClass(unit string) {
+	classOverrides.Lock()
+	defer classOverrides.Unlock()
+	delete(classOverrides.m, unit)
+}
+
+var displayNames struct {
+	sync.RWMutex
+	m map[string]string
+}
+
+// SetDisplayName registers name as the name to display for unit, such
+// as "time/op" for "sec/op". This lets tools keep established
+// terminology in their output. An empty name removes any registered
+// name.
+func SetDisplayName(unit, name string) {
+	displayNames.Lock()
+	defer displayNames.Unlock()
+	if name == "" {
+		delete(displayNames.m, unit)
+		return
+	}
+	if displayNames.m == nil {
+		displayNames.m = make(map[string]string)
+	}
+	displayNames.m[unit] = name
+}
+
+// DisplayName returns the name registered for unit by SetDisplayName,
+// or unit itself if no name is registered. unit should be tidied (see
+// Tidy), since display names are registered for tidied units.
+func DisplayName(unit string) string {
+	displayNames.RLock()
+	defer displayNames.RUnlock()
+	if name, ok := displayNames.m[unit]; ok {
+		return name
+	}
+	return unit
+}
+
+// ClassOf returns the Class of unit. If unit's Class was overridden by
+// SetClass, this returns that Class. Otherwise, if unit contains some
+// measure of bytes in the numerator, this is Binary, and if not, it is
+// Decimal.
 func ClassOf(unit string) Class {
+	classOverrides.RLock()
+	cls, ok := classOverrides.m[unit]
+	classOverrides.RUnlock()
+	if ok {
+		return cls
+	}
+
 	p := newParser(unit)
 	for p.next() {
 		if (p.tok == "B" || p.tok == "MB" || p.tok == "bytes") && !p.denom {
@@ -28,3 +28,47 @@ func TestClassOf(t *testing.T) {
 	test("disk-B/sec", Binary)
 	test("disk-B/sec", Binary)
 }
+
+func TestSetClass(t *testing.T) {
+	SetClass("pages/op", Binary)
+	SetClass("B/op", Decimal)
+	defer ClearClass("pages/op")
+	defer ClearClass("B/op")
+	if got := ClassOf("pages/op"); got != Binary {
+		t.Errorf("for overridden pages/op, want Binary, got %s", got)
+	}
+	if got := ClassOf("B/op"); got != Decimal {
+		t.Errorf("for overridden B/op, want Decimal, got %s", got)
+	}
+	ClearClass("B/op")
+	if got := ClassOf("B/op"); got != Binary {
+		t.Errorf("for cleared B/op, want Binary, got %s", got)
+	}
+}
+
+func TestParseClass(t *testing.T) {
+	for name, want := range map[string]Class{"decimal": Decimal, "Binary": Binary, "BINARY": Binary} {
+		got, err := ParseClass(name)
+		if err != nil || got != want {
+			t.Errorf("ParseClass(%q) = %v, %v; want %v", name, got, err, want)
+		}
+	}
+	if _, err := ParseClass("si"); err == nil {
+		t.Errorf("ParseClass(%q): want error", "si")
+	}
+}
+
+func TestDisplayName(t *testing.T) {
+	SetDisplayName("sec/op", "time/op")
+	defer SetDisplayName("sec/op", "")
+	if got := DisplayName("sec/op"); got != "time/op" {
+		t.Errorf("for sec/op, want time/op, got %s", got)
+	}
+	if got := DisplayName("B/op"); got != "B/op" {
+		t.Errorf("for unregistered B/op, want B/op, got %s", got)
+	}
+	SetDisplayName("sec/op", "")
+	if got := DisplayName("sec/op"); got != "sec/op" {
+		t.Errorf("for removed sec/op, want sec/op, got %s", got)
+	}
+}
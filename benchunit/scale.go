@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"math"
 	"strconv"
+	"strings"
 )
 
 // A Scaler represents a scaling factor for a number and
@@ -16,6 +17,7 @@ type Scaler struct {
 	Prec   int     // Digits after the decimal point
 	Factor float64 // Unscaled value of 1 Prefix (e.g., 1 k => 1000)
 	Prefix string  // Unit prefix ("k", "M", "Ki", etc)
+	Verb   byte    // strconv.FormatFloat format; 0 means 'f'
 }
 
 // Format formats val and appends the unit prefix according to the
@@ -23,16 +25,113 @@ type Scaler struct {
 // doesn't result in nonsense units like "megananoseconds".
 func (s Scaler) Format(val float64) string {
 	buf := make([]byte, 0, 20)
-	buf = strconv.AppendFloat(buf, val/s.Factor, 'f', s.Prec, 64)
+	buf = strconv.AppendFloat(buf, val/s.Factor, s.verb(), s.Prec, 64)
 	buf = append(buf, s.Prefix...)
 	return string(buf)
 }
 
+func (s Scaler) verb() byte {
+	if s.Verb == 0 {
+		return 'f'
+	}
+	return s.Verb
+}
+
+// A Locale specifies the separators used to format numbers, for
+// reports in locales that don't use the conventions of Format. The
+// zero Locale formats numbers like Format.
+type Locale struct {
+	// Decimal separates the integer and fractional parts of a
+	// number, such as "," in many European locales. If empty, it
+	// is ".".
+	Decimal string
+
+	// Group separates groups of three digits in the integer part
+	// of a number, such as "," or NarrowNoBreakSpace. If empty,
+	// digits aren't grouped.
+	Group string
+
+	// PrefixSep separates a number from its unit prefix, such as
+	// NarrowNoBreakSpace to format "1.423 µ". It is omitted if a
+	// number has no prefix.
+	PrefixSep string
+}
+
+// NarrowNoBreakSpace is the narrow no-break space character, which
+// is commonly used to group digits and to separate numbers from
+// units.
+const NarrowNoBreakSpace = "\u202f"
+
+// FormatLocale is like Format, but uses the separators of locale l.
+func (s Scaler) FormatLocale(val float64, l Locale) string {
+	num := strconv.FormatFloat(val/s.Factor, s.verb(), s.Prec, 64)
+	// Separators apply only to the mantissa, not an "e±dd" exponent.
+	exp := ""
+	if i := strings.IndexAny(num, "eE"); i >= 0 {
+		num, exp = num[:i], num[i:]
+	}
+	intPart, frac := num, ""
+	if i := strings.IndexByte(num, '.'); i >= 0 {
+		intPart, frac = num[:i], num[i+1:]
+	}
+
+	var buf strings.Builder
+	if l.Group != "" {
+		sign := ""
+		if strings.HasPrefix(intPart, "-") {
+			sign, intPart = "-", intPart[1:]
+		}
+		buf.WriteString(sign)
+		// Only group digits, not "Inf" or "NaN".
+		if intPart != "" && intPart[0] >= '0' && intPart[0] <= '9' {
+			for i := range intPart {
+				if i > 0 && (len(intPart)-i)%3 == 0 {
+					buf.WriteString(l.Group)
+				}
+				buf.WriteByte(intPart[i])
+			}
+		} else {
+			buf.WriteString(intPart)
+		}
+	} else {
+		buf.WriteString(intPart)
+	}
+	if frac != "" {
+		if l.Decimal == "" {
+			buf.WriteByte('.')
+		} else {
+			buf.WriteString(l.Decimal)
+		}
+		buf.WriteString(frac)
+	}
+	buf.WriteString(exp)
+	if s.Prefix != "" {
+		buf.WriteString(l.PrefixSep)
+		buf.WriteString(s.Prefix)
+	}
+	return buf.String()
+}
+
 // NoOpScaler is a Scaler that formats numbers with the smallest
 // number of digits necessary to capture the exact value, and no
 // prefix. This is intended for when the output will be consumed by
 // another program, such as when producing CSV format.
-var NoOpScaler = Scaler{-1, 1, ""}
+var NoOpScaler = Scaler{Prec: -1, Factor: 1}
+
+// SciScaler returns a Scaler that formats numbers in scientific
+// notation with no prefix, such as "1.423e-06". If digits is
+// positive, it formats numbers with that many significant digits.
+// Otherwise, like NoOpScaler, it uses the smallest number of digits
+// necessary to capture the exact value. Unlike prefixes, scientific
+// notation doesn't depend on the magnitude of other values, so it's
+// useful for output that will be consumed by another program.
+func SciScaler(digits int) Scaler {
+	prec := digits - 1
+	if digits <= 0 {
+		prec = -1
+	}
+	return Scaler{Prec: prec, Factor: 1, Verb: 'e'}
+}
 
 type factor struct {
 	factor float64
@@ -110,35 +209,20 @@ func Scale(val float64, cls Class) string {
 func CommonScale(vals []float64, cls Class) Scaler {
 	// The common scale is determined by the non-zero value
 	// closest to zero.
-	var min float64
-	for _, v := range vals {
-		v = math.Abs(v)
-		if v != 0 && (min == 0 || v < min) {
-			min = v
-		}
-	}
+	min := minAbs(vals)
 	if min == 0 {
-		return Scaler{3, 1, ""}
-	}
-
-	var factors []factor
-	switch cls {
-	default:
-		panic(fmt.Sprintf("bad Class %v", cls))
-	case Decimal:
-		factors = siFactors
-	case Binary:
-		factors = iecFactors
+		return Scaler{Prec: 3, Factor: 1}
 	}
 
+	factors := factorsOf(cls)
 	for _, factor := range factors {
 		switch {
 		case min >= factor.t100:
-			return Scaler{1, factor.factor, factor.prefix}
+			return Scaler{Prec: 1, Factor: factor.factor, Prefix: factor.prefix}
 		case min >= factor.t10:
-			return Scaler{2, factor.factor, factor.prefix}
+			return Scaler{Prec: 2, Factor: factor.factor, Prefix: factor.prefix}
 		case min >= factor.t1:
-			return Scaler{3, factor.factor, factor.prefix}
+			return Scaler{Prec: 3, Factor: factor.factor, Prefix: factor.prefix}
 		}
 	}
 
@@ -149,9 +233,100 @@ func CommonScale(vals []float64, cls Class) Scaler {
 	val := min / factor.factor
 	for i, thresh := range sigfigs {
 		if val >= thresh || i == len(sigfigs)-1 {
-			return Scaler{i + sigfigsBase, factor.factor, factor.prefix}
+			return Scaler{Prec: i + sigfigsBase, Factor: factor.factor, Prefix: factor.prefix}
 		}
 	}
 
 	panic("not reachable")
 }
+
+// ColumnScales returns a common Scaler for each column of a table of
+// values, where table[i][j] is the value in row i and column j. Rows
+// may have different lengths. Unlike scaling each row, this keeps
+// values in a column visually comparable, since every value in the
+// column uses the same prefix. To scale an entire table, pass all of
+// its values to CommonScale.
+func ColumnScales(table [][]float64, cls Class) []Scaler {
+	var cols [][]float64
+	for _, row := range table {
+		for j, v := range row {
+			if j == len(cols) {
+				cols = append(cols, nil)
+			}
+			cols[j] = append(cols[j], v)
+		}
+	}
+	scales := make([]Scaler, len(cols))
+	for j, col := range cols {
+		scales[j] = CommonScale(col, cls)
+	}
+	return scales
+}
+
+// CommonScaleDigits is like CommonScale, but shows at least digits
+// significant digits for every value. digits must be at least 1. For
+// example, with 6 digits, 1423.5 formats as "1.42350k". CommonScale
+// generally shows 4 significant digits.
+func CommonScaleDigits(vals []float64, cls Class, digits int) Scaler {
+	if digits < 1 {
+		panic(fmt.Sprintf("digits must be at least 1, got %d", digits))
+	}
+	min := minAbs(vals)
+	if min == 0 {
+		return Scaler{Prec: digits - 1, Factor: 1}
+	}
+	factor := scaleFactor(min, factorsOf(cls))
+	// Find the number of digits before the decimal point after
+	// rounding, which may carry into a new digit.
+	e := strconv.FormatFloat(min/factor.factor, 'e', digits-1, 64)
+	exp, _ := strconv.Atoi(e[strings.IndexByte(e, 'e')+1:])
+	prec := digits - 1 - exp
+	if prec < 0 {
+		prec = 0
+	}
+	return Scaler{Prec: prec, Factor: factor.factor, Prefix: factor.prefix}
+}
+
+// CommonScaleFixed is like CommonScale, but shows exactly prec digits
+// after the decimal point for every value, regardless of magnitude.
+// This is useful for aligning columns of values with a fixed number
+// of decimal places.
+func CommonScaleFixed(vals []float64, cls Class, prec int) Scaler {
+	s := CommonScale(vals, cls)
+	s.Prec = prec
+	return s
+}
+
+// minAbs returns the absolute value of the non-zero finite value in
+// vals closest to zero, or 0 if there are no such values.
+func minAbs(vals []float64) float64 {
+	var min float64
+	for _, v := range vals {
+		v = math.Abs(v)
+		if v > 0 && !math.IsInf(v, 0) && (min == 0 || v < min) {
+			min = v
+		}
+	}
+	return min
+}
+
+func factorsOf(cls Class) []factor {
+	switch cls {
+	case Decimal:
+		return siFactors
+	case Binary:
+		return iecFactors
+	}
+	panic(fmt.Sprintf("bad Class %v", cls))
+}
+
+// scaleFactor returns the largest factor that scales min to at least
+// 1, or the smallest factor if there is none.
+func scaleFactor(min float64, factors []factor) factor {
+	for _, factor := range factors {
+		if min >= factor.t1 {
+			return factor
+		}
+	}
+	return factors[len(factors)-1]
+}
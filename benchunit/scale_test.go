@@ -6,6 +6,7 @@ package benchunit
 
 import (
 	"math"
+	"reflect"
 	"testing"
 )
 
@@ -128,3 +129,121 @@ func TestNoOpScaler(t *testing.T) {
 	test(123456789, "123456789")
 	test(123.456789, "123.456789")
 }
+
+func TestCommonScaleDigits(t *testing.T) {
+	test := func(val float64, cls Class, digits int, want string) {
+		t.Helper()
+		got := CommonScaleDigits([]float64{val}, cls, digits).Format(val)
+		if got != want {
+			t.Errorf("for %v with %d digits, got %s, want %s", val, digits, got, want)
+		}
+	}
+	test(0, Decimal, 2, "0.0")
+	test(1423.5, Decimal, 6, "1.42350k")
+	test(1423.5, Decimal, 2, "1.4k")
+	test(1423.5, Decimal, 1, "1k")
+	test(142350, Decimal, 2, "142k")
+	test(99.96, Decimal, 3, "100")
+	test(0.0001423, Decimal, 3, "142µ")
+	test(1.5e-12, Decimal, 3, "0.00150n")
+	test(1536, Binary, 4, "1.500Ki")
+	test(-1423.5, Decimal, 3, "-1.42k")
+
+	// The scale is chosen by the smallest value.
+	vals := []float64{1423.5, 12.5}
+	if got := CommonScaleDigits(vals, Decimal, 3).Format(vals[0]); got != "1423.5" {
+		t.Errorf("for %v, got %s, want 1423.5", vals, got)
+	}
+}
+
+func TestCommonScaleFixed(t *testing.T) {
+	test := func(vals []float64, prec int, want string) {
+		t.Helper()
+		got := CommonScaleFixed(vals, Decimal, prec).Format(vals[0])
+		if got != want {
+			t.Errorf("for %v with precision %d, got %s, want %s", vals, prec, got, want)
+		}
+	}
+	test([]float64{1423.5}, 0, "1k")
+	test([]float64{1423.5}, 2, "1.42k")
+	test([]float64{142350}, 2, "142.35k")
+	test([]float64{0}, 1, "0.0")
+}
+
+func TestFormatLocale(t *testing.T) {
+	test := func(s Scaler, val float64, l Locale, want string) {
+		t.Helper()
+		got := s.FormatLocale(val, l)
+		if got != want {
+			t.Errorf("for %v with %+v, got %q, want %q", val, l, got, want)
+		}
+	}
+	micro := CommonScale([]float64{1.423e-6}, Decimal)
+	test(micro, 1.423e-6, Locale{}, micro.Format(1.423e-6))
+	test(micro, 1.423e-6, Locale{Decimal: ","}, "1,423µ")
+	test(micro, 1.423e-6, Locale{Decimal: ",", PrefixSep: NarrowNoBreakSpace}, "1,423\u202fµ")
+
+	test(NoOpScaler, 1234567.25, Locale{Group: ","}, "1,234,567.25")
+	test(NoOpScaler, -1234567, Locale{Group: "."}, "-1.234.567")
+	test(NoOpScaler, 123, Locale{Group: ","}, "123")
+	test(NoOpScaler, 1234.5, Locale{Decimal: ",", Group: NarrowNoBreakSpace, PrefixSep: " "}, "1\u202f234,5")
+	test(NoOpScaler, math.Inf(-1), Locale{Group: ","}, "-Inf")
+
+	test(SciScaler(1), 1.4e-6, Locale{Group: ","}, "1e-06")
+	test(SciScaler(1), 1.4e100, Locale{Group: ","}, "1e+100")
+	test(SciScaler(4), -1.4236e-6, Locale{Decimal: ",", Group: "."}, "-1,424e-06")
+	test(SciScaler(0), math.NaN(), Locale{Group: ","}, "NaN")
+}
+
+func TestColumnScales(t *testing.T) {
+	table := [][]float64{
+		{1.5e-6, 2000},
+		{2.5e-9, 3000, 7},
+		{math.NaN(), 4000},
+	}
+	scales := ColumnScales(table, Decimal)
+	if len(scales) != 3 {
+		t.Fatalf("got %d scales, want 3", len(scales))
+	}
+	var got []string
+	for _, row := range table {
+		for j, v := range row {
+			if !math.IsNaN(v) {
+				got = append(got, scales[j].Format(v))
+			}
+		}
+	}
+	want := []string{"1500.000n", "2.000k", "2.500n", "3.000k", "7.000", "4.000k"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestCommonScaleNonFinite(t *testing.T) {
+	// Non-finite values don't affect the scale.
+	vals := []float64{math.Inf(1), math.NaN(), 1500}
+	if got := CommonScale(vals, Decimal).Format(1500); got != "1.500k" {
+		t.Errorf("got %s, want 1.500k", got)
+	}
+}
+
+func TestSciScaler(t *testing.T) {
+	test := func(s Scaler, val float64, want string) {
+		t.Helper()
+		if got := s.Format(val); got != want {
+			t.Errorf("for %v, got %s, want %s", val, got, want)
+		}
+	}
+	test(SciScaler(4), 1.4235e-6, "1.424e-06")
+	test(SciScaler(4), 1423500, "1.424e+06")
+	test(SciScaler(1), 0, "0e+00")
+	test(SciScaler(0), 1.4235e-6, "1.4235e-06")
+	test(SciScaler(0), 1.0/3, "3.333333333333333e-01")
+
+	// NoOpScaler is the full-precision mode.
+	test(NoOpScaler, 1.0/3, "0.3333333333333333")
+
+	if got := SciScaler(3).FormatLocale(1.4235e-6, Locale{Decimal: ","}); got != "1,42e-06" {
+		t.Errorf("with locale, got %s, want 1,42e-06", got)
+	}
+}
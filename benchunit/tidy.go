@@ -43,12 +43,26 @@ func Tidy(unit string) (tidied string, factor float64) {
 	}
 
 	// Do the hard work and cache it.
-	tidied, factor = tidy(unit)
+	tidied, factor = tidy(unit, false)
 	tidyCache.Store(unit, &tidyEntry{tidied, factor})
 	return
 }
 
-func tidy(unit string) (tidied string, factor float64) {
+// TidyDenom is like Tidy, but also normalizes pre-scaled units in the
+// denominator, which Tidy leaves alone. For example, it tidies
+// "allocs/MB" to "allocs/B" with a factor of 1e-6, and "op/ns" to
+// "op/s" with a factor of 1e9. As in Invert, seconds in the
+// denominator are written "s". This is useful for consumers that
+// want fully canonical units, though the results can be less
+// readable, such as tiny values of "allocs/B".
+func TidyDenom(unit string) (tidied string, factor float64) {
+	if !(strings.Contains(unit, "ns") || strings.Contains(unit, "MB")) {
+		return unit, 1
+	}
+	return tidy(unit, true)
+}
+
+func tidy(unit string, denom bool) (tidied string, factor float64) {
 	type edit struct {
 		pos, len int
 		replace  string
@@ -59,17 +73,33 @@ func tidy(unit string) (tidied string, factor float64) {
 	p := newParser(unit)
 	edits := make([]edit, 0, 4)
 	for p.next() {
-		if p.denom {
+		if p.denom && !denom {
 			// Don't edit in the denominator.
 			continue
 		}
+		// scale is the number of untidied units in one
+		// tidied unit.
+		var scale float64
 		switch p.tok {
 		case "ns":
-			edits = append(edits, edit{p.pos, len("ns"), "sec"})
-			factor /= 1e9
+			sec := "sec"
+			if p.denom {
+				sec = "s"
+			}
+			edits = append(edits, edit{p.pos, len("ns"), sec})
+			scale = 1e9
 		case "MB":
 			edits = append(edits, edit{p.pos, len("MB"), "B"})
-			factor *= 1e6
+			scale = 1e-6
+		default:
+			continue
+		}
+		// In the denominator, the conversion is
+		// inverted: 1 allocs/MB is 1e-6 allocs/B.
+		if p.denom {
+			factor *= scale
+		} else {
+			factor /= scale
 		}
 	}
 	// Apply edits.
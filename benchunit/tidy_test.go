@@ -27,3 +27,22 @@ func TestTidy(t *testing.T) {
 	test("MB*MB/s", "B*B/s", 1e6*1e6)
 	test("MB/MB", "B/MB", 1e6)
 }
+
+func TestTidyDenom(t *testing.T) {
+	test := func(unit, tidied string, factor float64) {
+		t.Helper()
+		got, gotFactor := TidyDenom(unit)
+		if got != tidied || gotFactor != factor {
+			t.Errorf("for %s, want *%g %s, got *%g %s", unit, factor, tidied, gotFactor, got)
+		}
+	}
+
+	test("ns/op", "sec/op", 1e-9)
+	test("MB/s", "B/s", 1e6)
+	test("B/op", "B/op", 1)
+	test("allocs/MB", "allocs/B", 1e-6)
+	test("op/ns", "op/s", 1e9)
+	test("ns/ns", "sec/s", 1)
+	test("MB/MB", "B/B", 1)
+	test("ns/x-MB", "sec/x-B", 1e-9*1e-6)
+}
@@ -0,0 +1,80 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchunit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Parse parses a number with an optional unit prefix of class cls,
+// such as "1.5k" or "1.5Mi". It is the inverse of Scale: for any val,
+// Parse(Scale(val, cls), cls) is approximately val.
+//
+// Decimal numbers may use the SI prefixes used by Scale, and Binary
+// numbers may use the IEC prefixes. For convenience, Parse also
+// accepts "u" and "μ" (Greek mu) for the "µ" (micro sign) prefix.
+func Parse(s string, cls Class) (float64, error) {
+	num, prefix := splitNumber(s)
+	val, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+	switch prefix {
+	case "u", "μ":
+		prefix = "µ"
+	}
+	for _, f := range factorsOf(cls) {
+		if f.prefix == prefix {
+			return val * f.factor, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown %s prefix %q in %q", strings.ToLower(cls.String()), prefix, s)
+}
+
+// ParseUnit parses a number in unit, such as "1.423µs" in unit
+// "sec/op" or "1.5MiB" in unit "B/op", and returns its value in unit.
+// unit should be tidied (see Tidy), and its class determines the
+// accepted prefixes (see ClassOf and Parse).
+//
+// The number may be followed by a prefix, and then by the numerator of
+// unit and, optionally, the rest of the unit. For example, "2ms",
+// "2msec", and "2ms/op" are all 0.002 in unit "sec/op". Seconds may be
+// written "s" or "sec". The prefix and unit may also be omitted
+// entirely, such as "0.002".
+func ParseUnit(s, unit string) (float64, error) {
+	num, rest := splitNumber(s)
+	if rest != "" {
+		base, suffix := unit, ""
+		if i := strings.IndexAny(unit, "/*"); i >= 0 {
+			base, suffix = unit[:i], unit[i:]
+		}
+		rest = strings.TrimSuffix(rest, suffix)
+		// Try the longest matching quantity first, so "sec"
+		// isn't taken as a prefix followed by "ec".
+		bases := []string{base}
+		if base == "sec" {
+			bases = append(bases, "s")
+		}
+		for _, b := range bases {
+			if strings.HasSuffix(rest, b) {
+				rest = rest[:len(rest)-len(b)]
+				break
+			}
+		}
+	}
+	return Parse(num+rest, ClassOf(unit))
+}
+
+// splitNumber splits s into a leading number and the rest of s.
+func splitNumber(s string) (num, rest string) {
+	s = strings.TrimSpace(s)
+	i := 0
+	for i < len(s) && strings.IndexByte("0123456789.+-eE", s[i]) >= 0 {
+		i++
+	}
+	return s[:i], strings.TrimSpace(s[i:])
+}
@@ -0,0 +1,76 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchunit
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	test := func(s string, cls Class, want float64) {
+		t.Helper()
+		got, err := Parse(s, cls)
+		if err != nil {
+			t.Errorf("for %q, got error %v", s, err)
+		} else if math.Abs(got-want) > 1e-12*math.Abs(want) {
+			t.Errorf("for %q, got %v, want %v", s, got, want)
+		}
+	}
+	test("1.5", Decimal, 1.5)
+	test("-2k", Decimal, -2000)
+	test("1.423µ", Decimal, 1.423e-6)
+	test("1.423u", Decimal, 1.423e-6)
+	test("1.423μ", Decimal, 1.423e-6)
+	test("3 M", Decimal, 3e6)
+	test("1e3m", Decimal, 1)
+	test("1.5Mi", Binary, 1.5*(1<<20))
+	test("1023.9Ki", Binary, 1023.9*(1<<10))
+
+	for _, s := range []string{"", "k", "1.5Mi", "1.5Q", "1..5"} {
+		if _, err := Parse(s, Decimal); err == nil {
+			t.Errorf("for %q, want error", s)
+		}
+	}
+	if _, err := Parse("1.5k", Binary); err == nil {
+		t.Errorf("for %q in Binary, want error", "1.5k")
+	}
+
+	// Parse inverts Scale.
+	for _, val := range []float64{1, 1.5e-9, 123456, 0.75, 9.9995e12} {
+		for _, cls := range []Class{Decimal, Binary} {
+			s := Scale(val, cls)
+			got, err := Parse(s, cls)
+			if err != nil || math.Abs(got-val) > 1e-3*val {
+				t.Errorf("Parse(Scale(%v, %v)) = Parse(%q) = %v, %v", val, cls, s, got, err)
+			}
+		}
+	}
+}
+
+func TestParseUnit(t *testing.T) {
+	test := func(s, unit string, want float64) {
+		t.Helper()
+		got, err := ParseUnit(s, unit)
+		if err != nil {
+			t.Errorf("for %q in %s, got error %v", s, unit, err)
+		} else if math.Abs(got-want) > 1e-12*math.Abs(want) {
+			t.Errorf("for %q in %s, got %v, want %v", s, unit, got, want)
+		}
+	}
+	test("1.423µs", "sec/op", 1.423e-6)
+	test("2ms", "sec/op", 0.002)
+	test("2msec", "sec/op", 0.002)
+	test("2ms/op", "sec/op", 0.002)
+	test("2 sec", "sec/op", 2)
+	test("0.002", "sec/op", 0.002)
+	test("1.5MiB", "B/op", 1.5*(1<<20))
+	test("1.5Mi", "B/op", 1.5*(1<<20))
+	test("10k allocs/op", "allocs/op", 10000)
+
+	if _, err := ParseUnit("2ms", "B/op"); err == nil {
+		t.Errorf("for %q in B/op, want error", "2ms")
+	}
+}
@@ -25,6 +25,8 @@
 // 	.fullname     - The full name of a benchmark (including configuration)
 // 	.unit         - The name of a unit for a particular metric
 // 	.label        - The name of the input file or user-provided file label
+// 	.file-index   - The position of the input file on the command line
+// 	.iters        - The iteration count of a result
 // 	/{name-key}   - Per-benchmark sub-name configuration key
 // 	{file-key}    - File-level configuration key
 //
@@ -12,6 +12,7 @@ import (
 	"io"
 	"math"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -19,10 +20,9 @@ import (
 	"golang.org/x/perf/benchfmt"
 	"golang.org/x/perf/benchmath"
 	"golang.org/x/perf/benchproc"
+	"golang.org/x/perf/benchunit"
 )
 
-// TODO: Color by good/bad (or nothing for unknown units)
-
 // A Builder collects benchmark results into a Tables set.
 type Builder struct {
 	tableBy, rowBy, colBy *benchproc.Schema
@@ -32,6 +32,10 @@ type Builder struct {
 
 	// tables maps from tableBy to table.
 	tables map[benchproc.Config]*table
+
+	// nResults is the number of results added so far. This gives
+	// the position of each result in the overall run order.
+	nResults int
 }
 
 type table struct {
@@ -49,6 +53,9 @@ type table struct {
 type cell struct {
 	// values is the observed values in this cell.
 	values []float64
+	// runs is the position of each of values in the overall run
+	// order.
+	runs []int
 	// configs is the set of residue configs mapped to this cell.
 	// It is used to check for non-unique keys.
 	configs map[benchproc.Config]struct{}
@@ -97,8 +104,10 @@ func (b *Builder) Add(result *benchfmt.Result) {
 
 		// Add to the cell.
 		c.values = append(c.values, result.Values[unitI].Value)
+		c.runs = append(c.runs, b.nResults)
 		c.configs[residueCfg] = struct{}{}
 	}
+	b.nResults++
 }
 
 func (b *Builder) newTable() *table {
@@ -116,12 +125,196 @@ type TableOpts struct {
 	// intervals; e.g., 0.95 for 95%.
 	Confidence float64
 
+	// NoRange omits the confidence interval of each summary from
+	// the rendered tables, leaving only point estimates.
+	NoRange bool
+
+	// Sparklines, if true, shows a small histogram of each cell's
+	// sample next to its summary in text output, so bimodal
+	// samples and outliers are visible at a glance.
+	Sparklines bool
+
+	// ShowStats, if true, adds the minimum, quartiles, maximum, and
+	// count of each cell's sample to text and CSV output.
+	ShowStats bool
+
+	// Base, if non-empty, selects the baseline column of each
+	// table. The column whose values are Base, as given by
+	// benchproc.Config.StringValues, is moved to the front of the
+	// table. Tables without such a column use their first column.
+	Base string
+
 	// Thresholds is the thresholds to use for statistical tests.
 	Thresholds *benchmath.Thresholds
 
+	// UnitThresholds overrides Thresholds for specific units. It
+	// maps from unit name to the thresholds to use for that
+	// unit's statistical tests.
+	UnitThresholds map[string]*benchmath.Thresholds
+
 	// Units is the unit metadata. This gives distributional
 	// assumptions for units, among other properties.
 	Units benchfmt.Units
+
+	// Assumption is the distributional assumption to use for
+	// units that don't specify one in Units. If nil, this is
+	// benchmath.AssumeNothing.
+	Assumption benchmath.Assumption
+
+	// Summary is the statistic used for the summary row of each
+	// table. By default, this is SummaryGeoMean.
+	Summary SummaryFunc
+
+	// Correction, if non-nil, adjusts the p-values of all
+	// comparisons across all tables to account for multiple
+	// comparisons, such as benchmath.HolmBonferroni.
+	Correction func([]*benchmath.Comparison)
+
+	// Paired, if true, compares each cell with its baseline using
+	// a paired test, where the i'th value of each cell forms a
+	// pair. This is appropriate when the runs of each column were
+	// interleaved. Pairs are formed before outlier trimming, so
+	// paired comparisons include any outliers.
+	Paired bool
+
+	// ShowEffect, if true, adds an effect size column to each
+	// comparison. See benchmath.Comparison.Effect.
+	ShowEffect bool
+
+	// DeltaCI, if true, computes a confidence interval at level
+	// Confidence for the ratio of each comparison and reports it
+	// with the percent change. See benchmath.RatioCI. If Summary
+	// is SummaryGeoMean, it also computes a confidence interval
+	// for the geomean of the ratios in each column. See
+	// benchmath.SummarizeGeoMean.
+	DeltaCI bool
+
+	// DetectEffect, if positive, is a relative change, such as
+	// 0.01 for 1%. For each comparison that finds no significant
+	// difference, benchstat adds a warning giving the number of
+	// runs needed to detect a change of this size, and the
+	// smallest change the current number of runs can detect.
+	DetectEffect float64
+
+	// EquivMargin, if positive, is a relative margin, such as
+	// 0.02 for ±2%. Each comparison also tests whether the cell
+	// is equivalent to its baseline within this margin and adds
+	// a column reporting the result. See benchmath.Equivalent.
+	EquivMargin float64
+
+	// DeltaStyle is how changes from the baseline are shown. By
+	// default, this is DeltaSigned.
+	DeltaStyle DeltaStyle
+
+	// DeltaThreshold, if positive, is a relative change, such as
+	// 0.005 for 0.5%. Statistically significant changes smaller
+	// than this are considered negligible and shown as "~". See
+	// TableCell.Negligible.
+	DeltaThreshold float64
+
+	// ShowBayes, if true, adds a column to each comparison
+	// giving the posterior probability that the cell's median is
+	// lower than its baseline's and a credible interval for their
+	// ratio. See benchmath.CompareBayes.
+	ShowBayes bool
+
+	// ShowSpread, if true, compares the spread of each cell with
+	// its baseline and adds a column reporting whether it's
+	// noisier or less noisy. See benchmath.CompareSpread.
+	ShowSpread bool
+
+	// MaxHeaderLevels, if positive, limits the number of column
+	// header rows in text output. Any remaining column fields are
+	// merged into the last header row.
+	MaxHeaderLevels int
+
+	// Width, if positive, is the number of columns that text
+	// output should fit in, such as the width of the terminal.
+	// Tables that are too wide are shown with truncated row labels,
+	// with their comparisons split across several tables, or with
+	// each benchmark across several lines.
+	Width int
+
+	// Transpose, if true, swaps rows and columns in text output.
+	// This doesn't change which cells are compared: each column
+	// is still compared with the first column, which is shown as
+	// the first row.
+	Transpose bool
+
+	// GroupRows, if true, groups rows by all but the last field
+	// of the row projection, nesting one level per field, and
+	// summarizes each group like the table. Groups are shown in
+	// the order of their first row. This has no effect if Top is
+	// set, and only text output shows the groups.
+	GroupRows bool
+
+	// SortRows is the order of the rows in each table. By
+	// default, rows are in the order given by the row projection.
+	SortRows RowSort
+
+	// ReverseRows, if true, reverses the order of the rows in each
+	// table.
+	ReverseRows bool
+
+	// ChangedOnly, if true, omits rows that have no statistically
+	// significant changes from each table. The table summaries
+	// still include these rows. See Table.HiddenRows.
+	ChangedOnly bool
+
+	// Top, if positive, reduces each table to the Top rows with the
+	// worst statistically significant regressions and the Top rows
+	// with the best statistically significant improvements, and
+	// summarizes the remaining rows in Table.Others.
+	Top int
+
+	// Trend, if true, treats the columns of each table as an
+	// ordered series, such as by commit or date, and computes the
+	// trend of each row across them in Table.Trends. Text and CSV
+	// output then show these trends instead of comparing each
+	// column with the first, and ChangedOnly omits rows with no
+	// change points instead of rows with no significant changes.
+	Trend bool
+
+	// Overall, if true, summarizes each unit across all tables in
+	// Tables.Overall, such as to give one number per unit for a run
+	// over several packages. Only text output shows this summary.
+	Overall bool
+}
+
+// AssumptionByName returns the benchmath.Assumption for the given
+// name, as used in "assume" unit metadata, or nil if name is not a
+// known assumption. In addition to fixed names like "nothing" and
+// "normal", names of the form "pN", such as "p90" or "p99.9", select a
+// benchmath.Quantile assumption for the N'th percentile.
+func AssumptionByName(name string) benchmath.Assumption {
+	switch name {
+	case "nothing":
+		return benchmath.AssumeNothing
+	case "exact":
+		return benchmath.AssumeExact
+	case "count":
+		return benchmath.AssumeCount
+	case "normal":
+		return benchmath.AssumeNormal
+	case "lognormal":
+		return benchmath.AssumeLogNormal
+	case "bootstrap":
+		return benchmath.AssumeBootstrap
+	case "bootstrap-mean":
+		return benchmath.AssumeBootstrapMean
+	case "trimmed":
+		return benchmath.AssumeTrimmed
+	case "winsorized":
+		return benchmath.AssumeWinsorized
+	}
+	// Quantiles are written like "p90" or "p99.9".
+	if strings.HasPrefix(name, "p") {
+		pct, err := strconv.ParseFloat(name[1:], 64)
+		if err == nil && 0 < pct && pct < 100 {
+			return benchmath.Quantile{Q: pct / 100}
+		}
+	}
+	return nil
 }
 
 // Tables is a sequence of benchmark statistic tables.
@@ -133,6 +326,9 @@ type Tables struct {
 	// the Tables slice. These configs always end with a ".unit"
 	// config giving the unit.
 	Configs []benchproc.Config
+	// Overall summarizes each unit across all of Tables, or is nil
+	// if TableOpts.Overall is not set.
+	Overall *Overall
 }
 
 // ToTables finalizes a Builder into a sequence of statistic tables.
@@ -157,17 +353,35 @@ func (b *Builder) ToTables(opts TableOpts) *Tables {
 
 		// Get the configured assumption for this unit.
 		unit := k.Get(b.unitField)
-		var assumption benchmath.Assumption = benchmath.AssumeNothing
-		if dist, ok := opts.Units.Get(unit, "assume"); ok && dist == "exact" {
-			assumption = benchmath.AssumeExact
+		assumption := opts.Assumption
+		if assumption == nil {
+			assumption = benchmath.AssumeNothing
 		}
+		if dist, ok := opts.Units.Get(unit, "assume"); ok {
+			if a := AssumptionByName(dist); a != nil {
+				assumption = a
+			}
+		}
+		class := benchunit.ClassOf(unit)
+		if name, ok := opts.Units.Get(unit, "class"); ok {
+			if c, err := benchunit.ParseClass(name); err == nil {
+				class = c
+			}
+		}
+		ut := benchmath.UnitThresholds{Default: opts.Thresholds, Units: opts.UnitThresholds}
+		thresholds := ut.Get(unit)
 
 		// Sort the rows and columns.
 		rowCfgs, colCfgs := mapConfigs(cTable.rows), mapConfigs(cTable.cols)
+		if opts.Base != "" {
+			colCfgs = moveBase(colCfgs, opts.Base)
+		}
 		table := &Table{
 			Unit:       unit,
 			Opts:       opts,
 			Assumption: assumption,
+			Class:      class,
+			Better:     benchunit.BetterOf(unit, opts.Units.Get),
 			Rows:       rowCfgs,
 			Cols:       colCfgs,
 			Cells:      make(map[TableKey]*TableCell),
@@ -179,15 +393,40 @@ func (b *Builder) ToTables(opts TableOpts) *Tables {
 		// enables the second pass to look up baselines and
 		// their samples.
 		for k, cCell := range cTable.cells {
-			table.Cells[k] = &TableCell{
-				Sample: benchmath.NewSample(cCell.values, opts.Thresholds),
+			values := cCell.values
+			if opts.Paired {
+				// NewSample sorts values, but paired
+				// comparisons need them in run order.
+				values = append([]float64(nil), values...)
+			}
+			sample := benchmath.NewSample(values, thresholds)
+			for i, warn := range sample.Warnings {
+				// Show modes in the unit, like other values.
+				if bw, ok := warn.(*benchmath.BimodalWarning); ok {
+					sample.Warnings[i] = errors.New(bw.Format(func(v float64) string {
+						return benchunit.Scale(v, class)
+					}))
+				}
+			}
+			if thresholds.DriftAlpha > 0 {
+				// Checking run order is part of
+				// drift detection.
+				sample.Runs = cCell.runs
 			}
+			table.Cells[k] = &TableCell{Sample: sample}
 		}
 
 		// Populate cells.
 		baselineCfg := colCfgs[0]
 		wg.Add(len(cTable.cells))
 		for k, cCell := range cTable.cells {
+			// For paired comparisons, we need the
+			// baseline's values in run order.
+			var baseCCell *cell
+			if opts.Paired && k.Col != baselineCfg {
+				baseCCell = cTable.cells[TableKey{k.Row, baselineCfg}]
+			}
+
 			cell := table.Cells[k]
 
 			// Look up the baseline.
@@ -201,7 +440,7 @@ func (b *Builder) ToTables(opts TableOpts) *Tables {
 			limit <- struct{}{}
 			cCell := cCell
 			go func() {
-				summarizeCell(cCell, cell, assumption, opts.Confidence)
+				summarizeCell(cCell, baseCCell, cell, assumption, &opts)
 				<-limit
 				wg.Done()
 			}()
@@ -209,9 +448,25 @@ func (b *Builder) ToTables(opts TableOpts) *Tables {
 	}
 	wg.Wait()
 
+	// Correct for multiple comparisons across all tables.
+	if opts.Correction != nil {
+		var cmps []*benchmath.Comparison
+		for _, table := range tables {
+			for _, cell := range table.Cells {
+				if cell.Baseline != nil {
+					cmps = append(cmps, &cell.Comparison)
+				}
+			}
+		}
+		opts.Correction(cmps)
+	}
+
 	// Add summary rows to each table.
 	for _, table := range tables {
-		table.SummaryLabel = "geomean"
+		if opts.Summary == SummaryNone {
+			break
+		}
+		table.SummaryLabel = opts.Summary.String()
 		table.Summary = make(map[benchproc.Config]*TableSummary)
 
 		// Count the number of baseline benchmarks so we can
@@ -233,7 +488,7 @@ func (b *Builder) ToTables(opts TableOpts) *Tables {
 			table, col := table, col
 			wg.Add(1)
 			go func() {
-				summarizeCol(table, col, &s, nBase, isBase)
+				summarizeCol(table, col, &s, nBase, isBase, &opts)
 				<-limit
 				wg.Done()
 			}()
@@ -241,7 +496,66 @@ func (b *Builder) ToTables(opts TableOpts) *Tables {
 	}
 	wg.Wait()
 
-	return &Tables{tables, configs}
+	for _, table := range tables {
+		if opts.DeltaThreshold > 0 {
+			// This needs the summary of each baseline, so it
+			// must wait for all cells to be summarized.
+			for _, cell := range table.Cells {
+				if cell.Baseline == nil {
+					continue
+				}
+				old, new := cell.Baseline.Summary.Center, cell.Summary.Center
+				cell.Negligible = old == new || (old != 0 && math.Abs(new/old-1) < opts.DeltaThreshold)
+			}
+		}
+		table.sortRows(opts.SortRows, opts.ReverseRows)
+		if opts.GroupRows && opts.Top == 0 {
+			table.groupRows(&opts)
+		}
+		if opts.Trend {
+			table.trendRows()
+		}
+		if opts.ChangedOnly {
+			table.hideUnchanged()
+		}
+		if opts.Top > 0 {
+			table.keepTop(opts.Top, &opts)
+		}
+	}
+
+	t := &Tables{Tables: tables, Configs: configs}
+	if opts.Overall {
+		t.Overall = t.overall(&opts)
+	}
+	return t
+}
+
+// addPowerWarning adds a warning to cell's comparison giving the
+// number of runs needed to detect a relative change of effect and the
+// minimum change detectable with the current number of runs.
+func addPowerWarning(cell *TableCell, effect float64) {
+	base := cell.Baseline.Sample
+	need, err := benchmath.RunsToDetect(base, effect, benchmath.DefaultPower)
+	if err != nil {
+		// There's not enough information to make a
+		// recommendation. Other warnings will cover this.
+		return
+	}
+	n := len(base.Values)
+	if len(cell.Sample.Values) < n {
+		n = len(cell.Sample.Values)
+	}
+	mde, err := benchmath.MinDetectableEffect(base, n, benchmath.DefaultPower)
+	if err != nil {
+		return
+	}
+	msg := fmt.Errorf("need %d runs to detect a %s change with %v%% power; %d runs can detect %s", need, fmtPct(effect), benchmath.DefaultPower*100, n, fmtPct(mde))
+	cell.Comparison.Warnings = append(cell.Comparison.Warnings, msg)
+}
+
+// fmtPct formats a relative value as a percentage.
+func fmtPct(x float64) string {
+	return strconv.FormatFloat(x*100, 'g', 3, 64) + "%"
 }
 
 func mapConfigs(m map[benchproc.Config]struct{}) []benchproc.Config {
@@ -253,34 +567,80 @@ func mapConfigs(m map[benchproc.Config]struct{}) []benchproc.Config {
 	return cs
 }
 
-func summarizeCell(cCell *cell, cell *TableCell, assumption benchmath.Assumption, confidence float64) {
-	cell.Summary = assumption.Summary(cell.Sample, confidence)
+// summarizeCell computes the summary and comparison of cell. If
+// baseCell is non-nil, cell is compared with its baseline using a
+// paired comparison.
+func summarizeCell(cCell, baseCell *cell, cell *TableCell, assumption benchmath.Assumption, opts *TableOpts) {
+	cell.Summary = assumption.Summary(cell.Sample, opts.Confidence)
 
 	// If there's a baseline, compute comparison.
-	if cell.Baseline != nil {
+	if baseCell != nil {
+		cell.Comparison = benchmath.ComparePaired(assumption, baseCell.values, cCell.values, cell.Sample.Thresholds)
+	} else if cell.Baseline != nil {
 		cell.Comparison = assumption.Compare(cell.Baseline.Sample, cell.Sample)
 	}
+	if opts.DeltaCI && cell.Baseline != nil {
+		cell.Comparison.Ratio = benchmath.RatioCI(assumption, cell.Baseline.Sample, cell.Sample, opts.Confidence)
+		cell.Comparison.Warnings = append(cell.Comparison.Warnings, cell.Comparison.Ratio.Warnings...)
+	}
+	if opts.DetectEffect > 0 && cell.Baseline != nil && cell.Comparison.P > cell.Comparison.Alpha {
+		addPowerWarning(cell, opts.DetectEffect)
+	}
+	if opts.EquivMargin > 0 && cell.Baseline != nil {
+		cell.Equivalence = benchmath.Equivalent(assumption, cell.Baseline.Sample, cell.Sample, opts.EquivMargin)
+	}
+	if opts.ShowBayes && cell.Baseline != nil {
+		cell.Bayes = benchmath.CompareBayes(cell.Baseline.Sample, cell.Sample, opts.Confidence)
+	}
+	if opts.ShowSpread && cell.Baseline != nil {
+		cell.Spread = benchmath.CompareSpread(cell.Baseline.Sample, cell.Sample)
+	}
 
 	// Warn for non-singular configuration values in this cell.
-	nsk := benchproc.NonSingularFields(mapConfigs(cCell.configs))
+	nsk := benchproc.NonSingularKeys(mapConfigs(cCell.configs))
 	if len(nsk) > 0 {
 		// Emit a warning.
 		var warn strings.Builder
 		warn.WriteString("benchmarks vary in ")
-		for i, field := range nsk {
+		for i, key := range nsk {
 			if i > 0 {
 				warn.WriteString(", ")
 			}
-			warn.WriteString(field.Name)
+			warn.WriteString(key)
 		}
 
 		cell.Sample.Warnings = append(cell.Sample.Warnings, errors.New(warn.String()))
 	}
 }
 
-func summarizeCol(table *Table, col benchproc.Config, s *TableSummary, nBase int, isBase bool) {
-	// Collect cells.
-	//
+// moveBase moves the column whose values are base to the front of
+// cols, keeping the order of the other columns.
+func moveBase(cols []benchproc.Config, base string) []benchproc.Config {
+	for i, col := range cols {
+		if col.StringValues() != base {
+			continue
+		}
+		out := make([]benchproc.Config, 0, len(cols))
+		out = append(out, col)
+		out = append(out, cols[:i]...)
+		return append(out, cols[i+1:]...)
+	}
+	return cols
+}
+
+func summarizeCol(table *Table, col benchproc.Config, s *TableSummary, nBase int, isBase bool, opts *TableOpts) {
+	var cells []*TableCell
+	for _, row := range table.Rows {
+		if cell, ok := table.Cells[TableKey{row, col}]; ok {
+			cells = append(cells, cell)
+		}
+	}
+	summarizeCells(cells, s, nBase, isBase, opts)
+}
+
+// summarizeCells summarizes cells, which are the cells of one column,
+// into s. nBase is the number of cells in the baseline column.
+func summarizeCells(cells []*TableCell, s *TableSummary, nBase int, isBase bool, opts *TableOpts) {
 	// This computes the geomean of the summary ratios rather than
 	// ratio of the summary geomeans. These are identical *if* the
 	// benchmark sets are the same. But if the benchmark sets
@@ -288,15 +648,17 @@ func summarizeCol(table *Table, col benchproc.Config, s *TableSummary, nBase int
 	// still the geomean of the column, rather than being a
 	// comparison of two incomparable numbers. It's still easy to
 	// misinterpret, but at least it's not meaningless.
-	var summaries, ratios []float64
+	//
+	// Other summary functions instead compare the statistic of
+	// this column with the statistic of the baseline, using only
+	// the rows that appear in both.
+	var summaries, ratios, news, olds []float64
 	badRatio := false
-	for _, row := range table.Rows {
-		cell, ok := table.Cells[TableKey{row, col}]
-		if !ok {
-			continue
-		}
+	for _, cell := range cells {
 		summaries = append(summaries, cell.Summary.Center)
 		if cell.Baseline != nil {
+			news = append(news, cell.Summary.Center)
+			olds = append(olds, cell.Baseline.Summary.Center)
 			var ratio float64
 			a, b := cell.Summary.Center, cell.Baseline.Summary.Center
 			if a == b {
@@ -318,8 +680,32 @@ func summarizeCol(table *Table, col benchproc.Config, s *TableSummary, nBase int
 	// is the same as the total number of baselines, then we know
 	// the benchmark sets match. Otherwise, they don't and these
 	// numbers are probably misleading.
+	f := opts.Summary
+	if f == SummaryNone {
+		// Summaries of other rows are still shown.
+		f = SummaryGeoMean
+	}
 	if !isBase && nBase != len(ratios) {
-		s.Warnings = append(s.Warnings, fmt.Errorf("benchmark set differs from baseline; geomeans may not be comparable"))
+		s.Warnings = append(s.Warnings, fmt.Errorf("benchmark set differs from baseline; %ss may not be comparable", f))
+	}
+
+	if f != SummaryGeoMean {
+		if v := f.apply(summaries); !math.IsNaN(v) {
+			s.HasSummary = true
+			s.Summary = v
+		}
+		if !isBase && len(olds) > 0 {
+			newV, oldV := f.apply(news), f.apply(olds)
+			if newV == oldV {
+				// Treat 0/0 as 1.
+				s.HasRatio, s.Ratio = true, 1
+			} else if oldV == 0 {
+				s.Warnings = append(s.Warnings, fmt.Errorf("baseline %s is 0; cannot compute ratio", f))
+			} else {
+				s.HasRatio, s.Ratio = true, newV/oldV
+			}
+		}
+		return
 	}
 
 	// Summarize centers.
@@ -339,19 +725,31 @@ func summarizeCol(table *Table, col benchproc.Config, s *TableSummary, nBase int
 		} else {
 			s.HasRatio = true
 			s.Ratio = gm
+			if opts.DeltaCI {
+				s.RatioCI = benchmath.SummarizeGeoMean(ratios, opts.Confidence).Summary
+				s.Warnings = append(s.Warnings, s.RatioCI.Warnings...)
+			}
 		}
 	}
 }
 
 // ToText renders t to a textual representation, assuming a
-// fixed-width font.
+// fixed-width font. If color is true, it uses ANSI escape codes to
+// highlight changes (see Table.ToText).
 func (t *Tables) ToText(w io.Writer, color bool) error {
-	return t.printTables(func(hdr string) error {
+	err := t.printTables(func(hdr string) error {
 		_, err := fmt.Fprintf(w, "%s\n", hdr)
 		return err
 	}, func(table *Table) error {
 		return table.ToText(w, color)
 	})
+	if err != nil || t.Overall == nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "\n"); err != nil {
+		return err
+	}
+	return t.Overall.ToText(w)
 }
 
 // ToCSV returns t to CSV (comma-separated values) format.
@@ -359,7 +757,16 @@ func (t *Tables) ToText(w io.Writer, color bool) error {
 // Warnings are written to a separate stream so as not to interrupt
 // the regular format of the CSV table.
 func (t *Tables) ToCSV(w, warnings io.Writer) error {
+	return t.ToDelimited(w, warnings, ',')
+}
+
+// ToDelimited is like ToCSV, but separates fields with comma instead
+// of ','. For example, a comma of '\t' produces TSV (tab-separated
+// values). Fields containing comma, quotes, or newlines are quoted as
+// in CSV.
+func (t *Tables) ToDelimited(w, warnings io.Writer, comma rune) error {
 	o := csv.NewWriter(w)
+	o.Comma = comma
 	row := 1
 
 	err := t.printTables(func(hdr string) error {
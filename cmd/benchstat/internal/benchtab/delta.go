@@ -0,0 +1,116 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchtab
+
+import (
+	"fmt"
+
+	"golang.org/x/perf/benchmath"
+	"golang.org/x/perf/benchunit"
+)
+
+// DeltaStyle is how a Table shows changes from the baseline.
+type DeltaStyle int
+
+const (
+	// DeltaSigned shows the signed percent change, such as
+	// "-17.20%".
+	DeltaSigned DeltaStyle = iota
+
+	// DeltaAnnotated is like DeltaSigned, but follows each
+	// significant change with "better" or "worse", such as
+	// "-17.20% better", if the Table's unit says which direction
+	// is better.
+	DeltaAnnotated
+
+	// DeltaNormalized shows the percent change with its sign
+	// normalized so that improvements are positive and
+	// regressions are negative, if the Table's unit says which
+	// direction is better. For example, a 17.20% drop in sec/op
+	// is shown as "+17.20%".
+	DeltaNormalized
+)
+
+// ParseDeltaStyle parses the name of a DeltaStyle, which is one of
+// "signed", "annotated", or "normalized".
+func ParseDeltaStyle(s string) (DeltaStyle, error) {
+	switch s {
+	case "signed":
+		return DeltaSigned, nil
+	case "annotated":
+		return DeltaAnnotated, nil
+	case "normalized":
+		return DeltaNormalized, nil
+	}
+	return DeltaSigned, fmt.Errorf("unknown delta style %q", s)
+}
+
+// flipDelta reports whether t shows changes with the opposite sign.
+func (t *Table) flipDelta() bool {
+	return t.Opts.DeltaStyle == DeltaNormalized && t.Better == benchunit.BetterLower
+}
+
+// flipRatio returns the ratio whose percent change is the negation of
+// ratio's. This is not the reciprocal: a ratio of 0.8, or -20%,
+// becomes 1.2, or +20%.
+func flipRatio(ratio float64) float64 {
+	return 2 - ratio
+}
+
+// flipRange is like flipRatio, but for the bounds of an interval.
+func flipRange(s benchmath.Summary) benchmath.Summary {
+	s.Lo, s.Hi = flipRatio(s.Hi), flipRatio(s.Lo)
+	return s
+}
+
+// formatDelta formats the change from cell's baseline, as in
+// benchmath.Comparison.FormatDeltaCI, following t's DeltaStyle.
+// Negligible changes are shown as "~".
+func (t *Table) formatDelta(cell *TableCell) string {
+	c := cell.Comparison
+	old, new := cell.Baseline.Summary.Center, cell.Summary.Center
+	ratioCI := c.Ratio
+	if t.flipDelta() {
+		if old != 0 {
+			old, new = 1, flipRatio(new/old)
+		}
+		ratioCI = flipRange(ratioCI)
+	}
+
+	var d string
+	if cell.Negligible {
+		d = "~"
+	} else {
+		d = c.FormatDelta(old, new)
+	}
+	if ratioCI.Confidence != 0 {
+		d += " " + ratioCI.PctDeltaRangeString()
+	}
+
+	if t.Opts.DeltaStyle == DeltaAnnotated {
+		switch t.deltaColor(cell) {
+		case sgrBetter:
+			d += " better"
+		case sgrWorse:
+			d += " worse"
+		}
+	}
+	return d
+}
+
+// formatRatio formats s.Ratio as a percent change, along with its
+// confidence interval if it has one. Like formatDelta, this follows
+// t's DeltaStyle, but summaries are never annotated.
+func (t *Table) formatRatio(s *TableSummary) string {
+	ratio, ratioCI := s.Ratio, s.RatioCI
+	if t.flipDelta() {
+		ratio, ratioCI = flipRatio(ratio), flipRange(ratioCI)
+	}
+	pct := fmt.Sprintf("%+.2f%%", (ratio-1)*100)
+	if ratioCI.Confidence == 0 {
+		return pct
+	}
+	return pct + " " + ratioCI.PctDeltaRangeString()
+}
@@ -0,0 +1,126 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchtab
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/perf/benchproc"
+	"golang.org/x/perf/benchunit"
+)
+
+// A Threshold is a limit on the percent change in a unit, such as
+// "sec/op>+2%".
+type Threshold struct {
+	// Unit is the tidied unit this threshold applies to.
+	Unit string
+	// Less indicates that changes below Pct exceed this threshold.
+	// Otherwise, changes above Pct exceed it.
+	Less bool
+	// Pct is the limit on the change, as a percent.
+	Pct float64
+}
+
+// ParseThresholds parses a comma-separated list of thresholds. Each
+// threshold has the form "unit>pct%" or "unit<pct%", such as
+// "sec/op>+2%" or "B/s<-5%". Units may be given as they appear in
+// benchmark output, such as "ns/op", or as benchstat shows them.
+func ParseThresholds(s string) ([]Threshold, error) {
+	var out []Threshold
+	for _, expr := range strings.Split(s, ",") {
+		expr = strings.TrimSpace(expr)
+		i := strings.IndexAny(expr, "<>")
+		if i <= 0 {
+			return nil, fmt.Errorf("expected unit>pct%% or unit<pct%%, got %q", expr)
+		}
+		unit, _ := benchunit.Tidy(strings.TrimSpace(expr[:i]))
+		pct := strings.TrimSuffix(strings.TrimSpace(expr[i+1:]), "%")
+		v, err := strconv.ParseFloat(pct, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad percent in %q", expr)
+		}
+		out = append(out, Threshold{Unit: unit, Less: expr[i] == '<', Pct: v})
+	}
+	return out, nil
+}
+
+// String returns th in the form accepted by ParseThresholds.
+func (th Threshold) String() string {
+	op := ">"
+	if th.Less {
+		op = "<"
+	}
+	return fmt.Sprintf("%s%s%+g%%", th.Unit, op, th.Pct)
+}
+
+// exceeds reports whether a change of pct percent exceeds th.
+func (th Threshold) exceeds(pct float64) bool {
+	if th.Less {
+		return pct < th.Pct
+	}
+	return pct > th.Pct
+}
+
+// A Violation is a statistically significant change that exceeds a
+// Threshold.
+type Violation struct {
+	Unit      string
+	Row, Col  benchproc.Config
+	Cell      *TableCell
+	Threshold Threshold
+}
+
+// Violations returns the statistically significant changes in t that
+// exceed any of thresholds. This includes rows that aren't shown
+// because of TableOpts.ChangedOnly or TableOpts.Top. Within each
+// table, violations are ordered by row name and then by column.
+func (t *Tables) Violations(thresholds []Threshold) []Violation {
+	var out []Violation
+	for _, table := range t.Tables {
+		var tableOut []Violation
+		for _, th := range thresholds {
+			if th.Unit != table.Unit {
+				continue
+			}
+			for key, cell := range table.Cells {
+				if !cell.significant() {
+					continue
+				}
+				old := cell.Baseline.Summary.Center
+				if old == 0 {
+					continue
+				}
+				if th.exceeds((cell.Summary.Center/old - 1) * 100) {
+					tableOut = append(tableOut, Violation{table.Unit, key.Row, key.Col, cell, th})
+				}
+			}
+		}
+
+		colIndex := make(map[benchproc.Config]int)
+		for i, col := range table.Cols {
+			colIndex[col] = i
+		}
+		sort.SliceStable(tableOut, func(i, j int) bool {
+			a, b := tableOut[i], tableOut[j]
+			if a.Row != b.Row {
+				return a.Row.StringValues() < b.Row.StringValues()
+			}
+			return colIndex[a.Col] < colIndex[b.Col]
+		})
+		out = append(out, tableOut...)
+	}
+	return out
+}
+
+// String describes v, such as
+// "Encode new.txt sec/op: +17.20% (p=0.000 n=10) exceeds sec/op>+2%".
+func (v Violation) String() string {
+	cell := v.Cell
+	d := cell.Comparison.FormatDelta(cell.Baseline.Summary.Center, cell.Summary.Center)
+	return fmt.Sprintf("%s %s %s: %s (%s) exceeds %s", v.Row.StringValues(), v.Col.StringValues(), benchunit.DisplayName(v.Unit), d, cell.Comparison, v.Threshold)
+}
@@ -0,0 +1,133 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchtab
+
+import (
+	"sort"
+	"strings"
+
+	"golang.org/x/perf/benchproc"
+)
+
+// groupRows groups t's rows by all but the last field of the row
+// projection, nesting one level per field. It reorders t.Rows so
+// that the rows of each group are consecutive, keeping groups in the
+// order of their first row, and summarizes each group of more than
+// one row in t.groupSums.
+func (t *Table) groupRows(opts *TableOpts) {
+	if len(t.Rows) == 0 {
+		return
+	}
+	levels := len(t.Rows[0].Schema().Fields()) - 1
+	if levels < 1 {
+		return
+	}
+	t.GroupLevels = levels
+
+	// Order rows by the first appearance of each group at each
+	// level.
+	first := make(map[string]int)
+	for i, row := range t.Rows {
+		for level := 0; level < levels; level++ {
+			if _, ok := first[t.groupKey(row, level)]; !ok {
+				first[t.groupKey(row, level)] = i
+			}
+		}
+	}
+	sort.SliceStable(t.Rows, func(i, j int) bool {
+		for level := 0; level < levels; level++ {
+			a, b := first[t.groupKey(t.Rows[i], level)], first[t.groupKey(t.Rows[j], level)]
+			if a != b {
+				return a < b
+			}
+		}
+		return false
+	})
+
+	if t.Summary == nil {
+		return
+	}
+
+	// Summarize each group.
+	members := make(map[string][]benchproc.Config)
+	var keys []string
+	for _, row := range t.Rows {
+		for level := 0; level < levels; level++ {
+			key := t.groupKey(row, level)
+			if _, ok := members[key]; !ok {
+				keys = append(keys, key)
+			}
+			members[key] = append(members[key], row)
+		}
+	}
+	t.groupSums = make(map[string]map[benchproc.Config]*TableSummary)
+	for _, key := range keys {
+		rows := members[key]
+		if len(rows) < 2 {
+			continue
+		}
+		sub := &Table{Cols: t.Cols, Rows: rows, Cells: t.Cells}
+		nBase := 0
+		for _, row := range rows {
+			if _, ok := t.Cells[TableKey{row, t.Cols[0]}]; ok {
+				nBase++
+			}
+		}
+		sums := make(map[benchproc.Config]*TableSummary)
+		for i, col := range t.Cols {
+			var s TableSummary
+			summarizeCol(sub, col, &s, nBase, i == 0, opts)
+			sums[col] = &s
+		}
+		t.groupSums[key] = sums
+	}
+}
+
+// groupKey returns a key identifying row's group at the given level.
+func (t *Table) groupKey(row benchproc.Config, level int) string {
+	var key strings.Builder
+	for _, f := range row.Fields()[:level+1] {
+		key.WriteString(f.Value)
+		key.WriteByte(0)
+	}
+	return key.String()
+}
+
+// groupStart returns the first level at which row starts a new group,
+// given the previous row. If row is in all of the same groups as prev,
+// it returns t.GroupLevels.
+func (t *Table) groupStart(prev, row benchproc.Config) int {
+	if prev.IsZero() {
+		return 0
+	}
+	pf, rf := prev.Fields(), row.Fields()
+	for level := 0; level < t.GroupLevels; level++ {
+		if pf[level].Value != rf[level].Value {
+			return level
+		}
+	}
+	return t.GroupLevels
+}
+
+// groupIndent returns the indentation for a label at the given level.
+func groupIndent(level int) string {
+	return strings.Repeat("  ", level)
+}
+
+// rowLabel returns the label of row. If rows are grouped, this is
+// only the fields that aren't used for grouping, indented under the
+// row's groups.
+func (t *Table) rowLabel(row benchproc.Config) string {
+	if t.GroupLevels == 0 {
+		return row.StringValues()
+	}
+	var vals []string
+	for _, f := range row.Fields()[t.GroupLevels:] {
+		if f.Value != "" {
+			vals = append(vals, f.Value)
+		}
+	}
+	return groupIndent(t.GroupLevels) + strings.Join(vals, " ")
+}
@@ -0,0 +1,327 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchtab
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"strings"
+
+	"golang.org/x/perf/benchproc"
+	"golang.org/x/perf/benchunit"
+)
+
+var htmlTemplate = template.Must(template.New("").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>benchstat</title>
+<style>
+body { font-family: sans-serif; font-size: 14px; }
+table.benchstat { border-collapse: collapse; margin-bottom: 0.5em; }
+table.benchstat th, table.benchstat td { padding: 2px 8px; white-space: nowrap; }
+table.benchstat th { cursor: pointer; border-bottom: 1px solid #888; }
+table.benchstat th.sorted-asc::after { content: " ▲"; }
+table.benchstat th.sorted-desc::after { content: " ▼"; }
+table.benchstat td.num { text-align: right; font-family: monospace; }
+table.benchstat .ci { color: #666; }
+table.benchstat tr.summary td { border-top: 1px solid #888; font-weight: bold; }
+table.benchstat tr:hover td { background: #f0f0f0; }
+.better { color: #080; }
+.worse { color: #c00; }
+.unchanged { color: #888; }
+.dist { vertical-align: middle; }
+.dist circle { fill: #36c; fill-opacity: 0.6; }
+.dist line { stroke: #000; }
+details { margin-bottom: 1em; }
+summary { cursor: pointer; font-weight: bold; }
+ol.warnings { color: #a60; font-size: 12px; }
+p.hidden { color: #888; font-size: 12px; }
+</style>
+</head>
+<body>
+<p><input id="filter" type="search" placeholder="Filter benchmarks (regexp)" size="40"></p>
+{{- range $ti, $t := .}}
+<details open>
+<summary>{{$t.Unit}}{{range $t.Config}} · {{.}}{{end}}</summary>
+<table class="benchstat">
+<thead><tr><th data-type="text">{{$t.RowLabel}}</th>
+{{- range $ci, $c := $t.Cols}}<th data-type="num">{{$c}}</th>{{if $ci}}<th data-type="num">vs base</th>{{end}}{{end}}</tr></thead>
+<tbody>
+{{- range $t.Rows}}
+<tr class="bench" data-name="{{.Name}}"><td>{{.Name}}</td>
+{{- range $ci, $c := .Cells}}
+{{- if $c}}<td class="num" data-sort="{{$c.Sort}}">{{$c.Value}} {{with $c.CI}}<span class="ci">± {{.}}</span> {{end}}{{$c.Dist}}{{range $c.Notes}}<sup>{{.}}</sup>{{end}}</td>
+{{- if $ci}}<td class="num {{$c.Change}}" data-sort="{{$c.DeltaSort}}" title="{{$c.Stats}}">{{$c.Delta}}{{range $c.DeltaNotes}}<sup>{{.}}</sup>{{end}}</td>{{end}}
+{{- else}}<td data-sort="NaN"></td>{{if $ci}}<td data-sort="NaN"></td>{{end}}{{end}}
+{{- end}}</tr>
+{{- end}}
+</tbody>
+{{- with $t.Footer}}
+<tfoot>
+{{- range .}}
+<tr class="summary"><td>{{.Name}}</td>
+{{- range $ci, $c := .Cells}}<td class="num">{{if $c}}{{$c.Value}}{{range $c.Notes}}<sup>{{.}}</sup>{{end}}{{end}}</td>{{if $ci}}<td class="num">{{if $c}}{{$c.Delta}}{{end}}</td>{{end}}{{end}}</tr>
+{{- end}}
+</tfoot>
+{{- end}}
+</table>
+{{- with $t.HiddenNote}}
+<p class="hidden">{{.}}</p>
+{{- end}}
+{{- with $t.Warnings}}
+<ol class="warnings">{{range .}}<li>{{.}}</li>{{end}}</ol>
+{{- end}}
+</details>
+{{- end}}
+<script>
+(function() {
+	// Sort a table by a column when its header is clicked.
+	document.querySelectorAll("table.benchstat").forEach(function(table) {
+		var ths = table.querySelectorAll("thead th");
+		ths.forEach(function(th, col) {
+			th.addEventListener("click", function() {
+				var asc = !th.classList.contains("sorted-asc");
+				ths.forEach(function(h) { h.classList.remove("sorted-asc", "sorted-desc"); });
+				th.classList.add(asc ? "sorted-asc" : "sorted-desc");
+				var tbody = table.tBodies[0];
+				var rows = Array.prototype.slice.call(tbody.rows);
+				var key = function(row) {
+					var td = row.cells[col];
+					if (th.dataset.type === "text") return td.textContent;
+					var v = parseFloat(td.dataset.sort);
+					return isNaN(v) ? null : v;
+				};
+				rows.sort(function(a, b) {
+					var ka = key(a), kb = key(b);
+					// Missing values always sort last.
+					if (ka === null || kb === null) return (ka === null) - (kb === null);
+					var c = ka < kb ? -1 : ka > kb ? 1 : 0;
+					return asc ? c : -c;
+				});
+				rows.forEach(function(row) { tbody.appendChild(row); });
+			});
+		});
+	});
+
+	// Filter rows by benchmark name.
+	document.getElementById("filter").addEventListener("input", function(e) {
+		var re;
+		try {
+			re = new RegExp(e.target.value, "i");
+		} catch (err) {
+			return;
+		}
+		document.querySelectorAll("tr.bench").forEach(function(row) {
+			row.style.display = re.test(row.dataset.name) ? "" : "none";
+		});
+	});
+})();
+</script>
+</body>
+</html>
+`))
+
+type htmlTable struct {
+	Unit     string
+	Config   []string
+	RowLabel string
+	Cols     []string
+	Rows     []htmlRow
+	// Footer is the summary rows of the table.
+	Footer   []htmlRow
+	Warnings []string
+	// HiddenNote, if non-empty, says how many rows were hidden.
+	HiddenNote string
+}
+
+type htmlRow struct {
+	Name  string
+	Cells []*htmlCell
+}
+
+type htmlCell struct {
+	Value string
+	Sort  float64
+	CI    string
+	// Dist is an SVG strip plot of the sample's values.
+	Dist  template.HTML
+	Notes []int
+
+	Delta      string
+	DeltaSort  float64
+	Change     string // "better", "worse", or "unchanged"
+	Stats      string
+	DeltaNotes []int
+}
+
+// ToHTML renders t to a self-contained interactive HTML page. Each
+// table is collapsible, rows can be sorted by clicking a column
+// header and filtered by benchmark name, and each cell includes a
+// plot of the distribution of its sample. Significant changes are
+// colored according to each table's Better direction.
+func (t *Tables) ToHTML(w io.Writer) error {
+	var tables []*htmlTable
+	for i, table := range t.Tables {
+		var config []string
+		cfg := t.Configs[i]
+		for _, f := range cfg.Schema().Fields() {
+			if f.Name == ".unit" {
+				continue
+			}
+			if val := cfg.Get(f); val != "" {
+				config = append(config, f.Name+": "+val)
+			}
+		}
+		ht := table.toHTML()
+		ht.Config = config
+		tables = append(tables, ht)
+	}
+	return htmlTemplate.Execute(w, tables)
+}
+
+func (t *Table) toHTML() *htmlTable {
+	ht := &htmlTable{Unit: benchunit.DisplayName(t.Unit)}
+	if t.HiddenRows > 0 {
+		ht.HiddenNote = t.hiddenNote()
+	}
+
+	var fields []string
+	if len(t.Rows) > 0 {
+		for _, f := range t.Rows[0].Schema().Fields() {
+			fields = append(fields, f.Name)
+		}
+	}
+	ht.RowLabel = strings.Join(fields, " ")
+	for _, col := range t.Cols {
+		ht.Cols = append(ht.Cols, col.StringValues())
+	}
+
+	warningSet := make(map[string]int)
+	notes := func(msgs ...[]error) []int {
+		var out []int
+		for _, msgs1 := range msgs {
+			for _, msg := range msgs1 {
+				s := msg.Error()
+				i, ok := warningSet[s]
+				if !ok {
+					i = len(ht.Warnings)
+					warningSet[s] = i
+					ht.Warnings = append(ht.Warnings, s)
+				}
+				out = append(out, i+1)
+			}
+		}
+		return out
+	}
+
+	for _, row := range t.Rows {
+		hr := htmlRow{Name: row.StringValues()}
+		scalar := benchunit.CommonScale(t.RowValues(row), t.Class)
+
+		// Plot all cells in the row on the same axis.
+		lo, hi := math.Inf(1), math.Inf(-1)
+		for _, col := range t.Cols {
+			if cell, ok := t.Cells[TableKey{row, col}]; ok {
+				for _, v := range cell.Sample.Values {
+					lo, hi = math.Min(lo, v), math.Max(hi, v)
+				}
+			}
+		}
+
+		for _, col := range t.Cols {
+			cell, ok := t.Cells[TableKey{row, col}]
+			if !ok {
+				hr.Cells = append(hr.Cells, nil)
+				continue
+			}
+			hc := &htmlCell{
+				Value: scalar.Format(cell.Summary.Center),
+				Sort:  cell.Summary.Center,
+				Dist:  stripPlot(cell.Sample.Values, cell.Summary.Center, lo, hi),
+				Notes: notes(cell.Sample.Warnings, cell.Summary.Warnings),
+			}
+			if !t.Opts.NoRange {
+				hc.CI = cell.Summary.PctRangeString()
+			}
+			if cell.Baseline != nil {
+				old := cell.Baseline.Summary.Center
+				hc.Delta = t.formatDelta(cell)
+				hc.DeltaSort = cell.Summary.Center/old - 1
+				hc.Stats = cell.Comparison.String()
+				hc.DeltaNotes = notes(cell.Comparison.Warnings, cell.Spread.Warnings, cell.Equivalence.Warnings, cell.Bayes.Warnings)
+				switch t.deltaColor(cell) {
+				case sgrBetter:
+					hc.Change = "better"
+				case sgrWorse:
+					hc.Change = "worse"
+				default:
+					hc.Change = "unchanged"
+				}
+				if !cell.significant() {
+					// Sort unchanged cells as 0.
+					hc.DeltaSort = 0
+				}
+			}
+			hr.Cells = append(hr.Cells, hc)
+		}
+		ht.Rows = append(ht.Rows, hr)
+	}
+
+	summaryRow := func(label string, sums map[benchproc.Config]*TableSummary) {
+		sum := htmlRow{Name: label}
+		for _, col := range t.Cols {
+			tsum, ok := sums[col]
+			if !ok {
+				sum.Cells = append(sum.Cells, nil)
+				continue
+			}
+			hc := &htmlCell{}
+			if tsum.HasSummary {
+				hc.Value = benchunit.Scale(tsum.Summary, t.Class)
+			}
+			if tsum.HasRatio {
+				hc.Delta = t.formatRatio(tsum)
+			}
+			hc.Notes = notes(tsum.Warnings)
+			sum.Cells = append(sum.Cells, hc)
+		}
+		ht.Footer = append(ht.Footer, sum)
+	}
+	if t.Others != nil {
+		summaryRow(t.OthersLabel, t.Others)
+	}
+	if t.showSummary() {
+		summaryRow(t.SummaryLabel, t.Summary)
+	}
+
+	return ht
+}
+
+// stripPlot returns an SVG strip plot of values on an axis from lo to
+// hi, with a tick mark at center.
+func stripPlot(values []float64, center, lo, hi float64) template.HTML {
+	const width, height, pad = 80, 12, 3
+	x := func(v float64) float64 {
+		if hi <= lo {
+			return width / 2
+		}
+		return pad + (v-lo)/(hi-lo)*(width-2*pad)
+	}
+	var buf strings.Builder
+	fmt.Fprintf(&buf, `<svg class="dist" width="%d" height="%d">`, width, height)
+	if !math.IsNaN(center) && !math.IsInf(center, 0) {
+		cx := x(center)
+		fmt.Fprintf(&buf, `<line x1="%.1f" y1="0" x2="%.1f" y2="%d"/>`, cx, cx, height)
+	}
+	for _, v := range values {
+		fmt.Fprintf(&buf, `<circle cx="%.1f" cy="%d" r="2"/>`, x(v), height/2)
+	}
+	buf.WriteString(`</svg>`)
+	// The SVG contains only numbers we formatted, so it's safe.
+	return template.HTML(buf.String())
+}
@@ -0,0 +1,246 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchtab
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+
+	"golang.org/x/perf/benchproc"
+	"golang.org/x/perf/benchunit"
+)
+
+// The JSON schema of Tables.ToJSON. Non-finite numbers, such as the
+// bounds of an unbounded confidence interval, are encoded as null.
+
+type jsonTables struct {
+	Tables []jsonTable `json:"tables"`
+}
+
+type jsonTable struct {
+	// Config is the table's configuration, not including the
+	// unit.
+	Config map[string]string `json:"config"`
+	Unit   string            `json:"unit"`
+	// Assumption is the label of the table's summary statistic,
+	// such as "median".
+	Assumption string `json:"assumption"`
+	// Columns gives the configuration of each column. The first
+	// column is the baseline of the comparisons.
+	Columns []map[string]string `json:"columns"`
+	Rows    []jsonRow           `json:"rows"`
+	// HiddenRows is the number of rows omitted because
+	// TableOpts.ChangedOnly is set.
+	HiddenRows int `json:"hiddenRows,omitempty"`
+	// SummaryLabel describes Summary, such as "geomean".
+	SummaryLabel string `json:"summaryLabel,omitempty"`
+	// Summary summarizes each column, aligned with Columns.
+	Summary []*jsonSummary `json:"summary,omitempty"`
+	// Others summarizes the rows omitted because TableOpts.Top is
+	// set, like Summary. OthersLabel describes these rows, such as
+	// "12 others".
+	OthersLabel string         `json:"othersLabel,omitempty"`
+	Others      []*jsonSummary `json:"others,omitempty"`
+
+	class benchunit.Class
+}
+
+// Scale formats v in the table's unit class, like the values in text
+// output. This is meant for use by templates.
+func (t jsonTable) Scale(v jsonFloat) string {
+	return benchunit.Scale(float64(v), t.class)
+}
+
+type jsonRow struct {
+	// Name is the row's configuration, formatted as in text
+	// output.
+	Name   string            `json:"name"`
+	Config map[string]string `json:"config"`
+	// Cells is aligned with the table's Columns. A cell is null
+	// if there are no results for that row and column.
+	Cells []*jsonCell `json:"cells"`
+}
+
+type jsonCell struct {
+	Center     jsonFloat `json:"center"`
+	Lo         jsonFloat `json:"lo"`
+	Hi         jsonFloat `json:"hi"`
+	Confidence float64   `json:"confidence"`
+	N          int       `json:"n"`
+	Warnings   []string  `json:"warnings,omitempty"`
+	// Comparison is the comparison with the baseline column, or
+	// null if there is none.
+	Comparison *jsonComparison `json:"comparison,omitempty"`
+}
+
+type jsonComparison struct {
+	// Ratio is the ratio of the cell's center to the baseline's.
+	Ratio jsonFloat `json:"ratio"`
+	// Delta is the change as formatted in text output, such as
+	// "-7.25%" or "~".
+	Delta       string `json:"delta"`
+	Significant bool   `json:"significant"`
+	// Negligible indicates that the change is smaller than
+	// TableOpts.DeltaThreshold, so Delta is "~".
+	Negligible bool      `json:"negligible,omitempty"`
+	P          float64   `json:"p"`
+	Alpha      float64   `json:"alpha"`
+	N1         int       `json:"n1"`
+	N2         int       `json:"n2"`
+	Test       string    `json:"test,omitempty"`
+	Correction string    `json:"correction,omitempty"`
+	RatioLo    jsonFloat `json:"ratioLo,omitempty"`
+	RatioHi    jsonFloat `json:"ratioHi,omitempty"`
+	Warnings   []string  `json:"warnings,omitempty"`
+}
+
+type jsonSummary struct {
+	Summary  *jsonFloat `json:"summary,omitempty"`
+	Ratio    *jsonFloat `json:"ratio,omitempty"`
+	Warnings []string   `json:"warnings,omitempty"`
+}
+
+// jsonFloat is a float64 that encodes non-finite values as null.
+type jsonFloat float64
+
+func (f jsonFloat) MarshalJSON() ([]byte, error) {
+	if math.IsInf(float64(f), 0) || math.IsNaN(float64(f)) {
+		return []byte("null"), nil
+	}
+	return json.Marshal(float64(f))
+}
+
+func configMap(cfg benchproc.Config) map[string]string {
+	m := make(map[string]string)
+	for _, f := range cfg.Schema().Fields() {
+		m[f.Name] = cfg.Get(f)
+	}
+	return m
+}
+
+func errorStrings(errs []error) []string {
+	var ss []string
+	for _, err := range errs {
+		ss = append(ss, err.Error())
+	}
+	return ss
+}
+
+// ToJSON renders t to JSON. The output is an object with a "tables"
+// list. Each table gives its "config", "unit", the configuration of
+// each of its "columns", its "rows", and the "summary" of each
+// column, described by "summaryLabel". Each row gives its "name" and
+// "config" and a list of "cells" aligned with the columns, which are
+// null if there are no results for that row and column. Each cell gives its "center", the
+// "lo" and "hi" bounds of its confidence interval, its "confidence"
+// level, the number of results "n", any "warnings", and, if it isn't
+// in the baseline column, a "comparison" with the baseline. Unlike
+// text and CSV output, numbers are not scaled or rounded. Non-finite
+// numbers, such as the bounds of an unbounded interval, are null.
+func (t *Tables) ToJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(t.toJSON())
+}
+
+// toJSON returns the data model of t used by ToJSON and ToTemplate.
+func (t *Tables) toJSON() jsonTables {
+	out := jsonTables{Tables: []jsonTable{}}
+	for i, table := range t.Tables {
+		cfg := configMap(t.Configs[i])
+		delete(cfg, ".unit")
+		jt := jsonTable{
+			Config:       cfg,
+			Unit:         table.Unit,
+			Assumption:   table.Assumption.SummaryLabel(),
+			Rows:         []jsonRow{},
+			SummaryLabel: table.SummaryLabel,
+			HiddenRows:   table.HiddenRows,
+			class:        table.Class,
+		}
+		for _, col := range table.Cols {
+			jt.Columns = append(jt.Columns, configMap(col))
+			if table.Summary != nil {
+				jt.Summary = append(jt.Summary, summaryJSON(table.Summary[col]))
+			}
+		}
+		if table.Others != nil {
+			jt.OthersLabel = table.OthersLabel
+			for _, col := range table.Cols {
+				jt.Others = append(jt.Others, summaryJSON(table.Others[col]))
+			}
+		}
+		for _, row := range table.Rows {
+			jr := jsonRow{Name: row.StringValues(), Config: configMap(row)}
+			for _, col := range table.Cols {
+				cell, ok := table.Cells[TableKey{row, col}]
+				if !ok {
+					jr.Cells = append(jr.Cells, nil)
+					continue
+				}
+				jr.Cells = append(jr.Cells, cell.toJSON())
+			}
+			jt.Rows = append(jt.Rows, jr)
+		}
+		out.Tables = append(out.Tables, jt)
+	}
+	return out
+}
+
+// summaryJSON returns the JSON form of tsum, which may be nil.
+func summaryJSON(tsum *TableSummary) *jsonSummary {
+	if tsum == nil {
+		return nil
+	}
+	js := &jsonSummary{Warnings: errorStrings(tsum.Warnings)}
+	if tsum.HasSummary {
+		s := jsonFloat(tsum.Summary)
+		js.Summary = &s
+	}
+	if tsum.HasRatio {
+		r := jsonFloat(tsum.Ratio)
+		js.Ratio = &r
+	}
+	return js
+}
+
+func (cell *TableCell) toJSON() *jsonCell {
+	s := cell.Summary
+	jc := &jsonCell{
+		Center:     jsonFloat(s.Center),
+		Lo:         jsonFloat(s.Lo),
+		Hi:         jsonFloat(s.Hi),
+		Confidence: s.Confidence,
+		N:          len(cell.Sample.Values),
+		Warnings:   errorStrings(append(append([]error(nil), cell.Sample.Warnings...), s.Warnings...)),
+	}
+	if cell.Baseline == nil {
+		return jc
+	}
+	c := cell.Comparison
+	old, new := cell.Baseline.Summary.Center, s.Center
+	jc.Comparison = &jsonComparison{
+		Ratio:       jsonFloat(new / old),
+		Delta:       c.FormatDelta(old, new),
+		Significant: c.P <= c.Alpha,
+		Negligible:  cell.Negligible,
+		P:           c.P,
+		Alpha:       c.Alpha,
+		N1:          c.N1,
+		N2:          c.N2,
+		Test:        c.Test,
+		Correction:  c.Correction,
+		Warnings:    errorStrings(c.Warnings),
+	}
+	if cell.Negligible {
+		jc.Comparison.Delta = "~"
+	}
+	if c.Ratio.Confidence != 0 {
+		jc.Comparison.RatioLo = jsonFloat(c.Ratio.Lo)
+		jc.Comparison.RatioHi = jsonFloat(c.Ratio.Hi)
+	}
+	return jc
+}
@@ -0,0 +1,277 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchtab
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/perf/benchproc"
+	"golang.org/x/perf/benchunit"
+)
+
+// latexEscaper escapes LaTeX special characters, as well as the
+// non-ASCII characters benchstat uses in its output.
+var latexEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`&`, `\&`,
+	`%`, `\%`,
+	`$`, `\$`,
+	`#`, `\#`,
+	`_`, `\_`,
+	`~`, `\textasciitilde{}`,
+	`^`, `\textasciicircum{}`,
+	`<`, `\textless{}`,
+	`>`, `\textgreater{}`,
+	"µ", `\textmu{}`,
+	"μ", `\textmu{}`,
+	"±", `\ensuremath{\pm}`,
+	"–", `--`,
+	"∞", `\ensuremath{\infty}`,
+	"δ", `\ensuremath{\delta}`,
+	"×", `\ensuremath{\times}`,
+)
+
+// latexEscape escapes s for use in LaTeX text mode.
+func latexEscape(s string) string {
+	return latexEscaper.Replace(s)
+}
+
+// ToLaTeX renders t to a sequence of LaTeX tabular environments.
+// Table configuration is written as LaTeX comments. The tables use
+// the rules from the booktabs package, so documents using them must
+// include "\usepackage{booktabs}".
+func (t *Tables) ToLaTeX(w io.Writer) error {
+	return t.printTables(func(hdr string) error {
+		if hdr != "" {
+			hdr = "% " + hdr
+		}
+		_, err := fmt.Fprintf(w, "%s\n", hdr)
+		return err
+	}, func(table *Table) error {
+		return table.ToLaTeX(w)
+	})
+}
+
+// ToLaTeX renders t to a LaTeX tabular environment using booktabs
+// rules. Like ToText, each row uses a common scale for its values,
+// and the unit is shown using its display name over each column.
+// Warnings are marked with superscript footnote numbers and listed
+// after the table.
+func (t *Table) ToLaTeX(w io.Writer) error {
+	// Each logical column expands to centerCols columns, plus
+	// deltaCols columns if there's a baseline.
+	const labelCols = 1
+	centerCols := 2 // <center> <±CI>
+	centerAlign := "rl"
+	if t.Opts.NoRange {
+		centerCols-- // No <±CI>
+		centerAlign = "r"
+	}
+	deltaCols := 2 // <P%> <(p=0.PPP n=N)>
+	var deltaAlign strings.Builder
+	deltaAlign.WriteString("rl")
+	if t.Opts.ShowEffect {
+		deltaCols++ // <effect>
+		deltaAlign.WriteString("r")
+	}
+	if t.Opts.ShowSpread {
+		deltaCols++ // <spread>
+		deltaAlign.WriteString("l")
+	}
+	if t.Opts.EquivMargin > 0 {
+		deltaCols++ // <equiv>
+		deltaAlign.WriteString("l")
+	}
+	if t.Opts.ShowBayes {
+		deltaCols++ // <bayes>
+		deltaAlign.WriteString("l")
+	}
+	startCol := func(exp int) int {
+		if exp == 0 {
+			return labelCols
+		}
+		return labelCols + centerCols + (exp-1)*(centerCols+deltaCols)
+	}
+	nCols := startCol(len(t.Cols))
+
+	var warningList []string
+	warningSet := make(map[string]int)
+	footnotes := func(msgs ...[]error) string {
+		var marks []string
+		for _, msgs1 := range msgs {
+			for _, msg := range msgs1 {
+				s := msg.Error()
+				i, ok := warningSet[s]
+				if !ok {
+					i = len(warningList)
+					warningSet[s] = i
+					warningList = append(warningList, s)
+				}
+				marks = append(marks, fmt.Sprint(i+1))
+			}
+		}
+		if len(marks) == 0 {
+			return ""
+		}
+		return `\textsuperscript{` + strings.Join(marks, ",") + `}`
+	}
+
+	var buf strings.Builder
+	row := make([]string, nCols)
+	emit := func() {
+		buf.WriteString(strings.Join(row, " & "))
+		buf.WriteString(` \\` + "\n")
+		for i := range row {
+			row[i] = ""
+		}
+	}
+
+	// Column specification.
+	buf.WriteString(`\begin{tabular}{l`)
+	for exp := range t.Cols {
+		buf.WriteString(centerAlign)
+		if exp > 0 {
+			buf.WriteString(deltaAlign.String())
+		}
+	}
+	buf.WriteString("}\n\\toprule\n")
+
+	// Construct the header. Each header cell spans its logical
+	// columns and is underlined with a partial rule.
+	hdr, _ := benchproc.NewConfigHeaderOpts(t.Cols, benchproc.ConfigHeaderOpts{MaxLevels: t.Opts.MaxHeaderLevels})
+	for _, hdrRow := range hdr {
+		cells := []string{""}
+		var rules []string
+		for _, hdrCell := range hdrRow {
+			l := startCol(hdrCell.Start)
+			r := startCol(hdrCell.Start + hdrCell.Len)
+			cells = append(cells, fmt.Sprintf(`\multicolumn{%d}{c}{%s}`, r-l, latexEscape(hdrCell.Value)))
+			rules = append(rules, fmt.Sprintf(`\cmidrule(lr){%d-%d}`, l+1, r))
+		}
+		buf.WriteString(strings.Join(cells, " & "))
+		buf.WriteString(` \\` + "\n")
+		buf.WriteString(strings.Join(rules, " "))
+		buf.WriteString("\n")
+	}
+
+	// Add the column labels row. Show the unit over each center
+	// column group, since these are values in that unit.
+	unit := latexEscape(benchunit.DisplayName(t.Unit))
+	labels := []string{""}
+	for exp := range t.Cols {
+		labels = append(labels, fmt.Sprintf(`\multicolumn{%d}{c}{%s}`, centerCols, unit))
+		if exp > 0 {
+			labels = append(labels, fmt.Sprintf(`\multicolumn{%d}{l}{vs base}`, deltaCols))
+		}
+	}
+	buf.WriteString(strings.Join(labels, " & "))
+	buf.WriteString(` \\` + "\n\\midrule\n")
+
+	// Emit measurements.
+	for _, rowCfg := range t.Rows {
+		row[0] = latexEscape(rowCfg.StringValues())
+
+		// Get a common scalar across this row.
+		scalar := benchunit.CommonScale(t.RowValues(rowCfg), t.Class)
+
+		for exp, colCfg := range t.Cols {
+			cell, ok := t.Cells[TableKey{rowCfg, colCfg}]
+			if !ok {
+				continue
+			}
+
+			c := startCol(exp)
+			row[c] = latexEscape(scalar.Format(cell.Summary.Center))
+			if !t.Opts.NoRange {
+				row[c+1] = `\ensuremath{\pm}` + latexEscape(cell.Summary.PctRangeString())
+			}
+			row[c+centerCols-1] += footnotes(cell.Sample.Warnings, cell.Summary.Warnings)
+			if exp > 0 && cell.Baseline != nil {
+				c += centerCols
+				row[c] = latexDelta(t.formatDelta(cell))
+				row[c+1] = "(" + latexEscape(cell.Comparison.String()) + ")"
+				c += 2
+				if t.Opts.ShowEffect {
+					row[c] = latexEscape(cell.Comparison.FormatEffect())
+					c++
+				}
+				if t.Opts.ShowSpread {
+					row[c] = latexDelta(cell.Spread.FormatDelta())
+					c++
+				}
+				if t.Opts.EquivMargin > 0 {
+					row[c] = latexEscape(cell.Equivalence.String())
+					c++
+				}
+				if t.Opts.ShowBayes {
+					row[c] = latexEscape(cell.Bayes.String())
+					c++
+				}
+				row[c-1] += footnotes(cell.Comparison.Warnings, cell.Spread.Warnings, cell.Equivalence.Warnings, cell.Bayes.Warnings)
+			}
+		}
+		emit()
+	}
+
+	// Emit summary rows.
+	summaryRow := func(label string, sums map[benchproc.Config]*TableSummary) {
+		row[0] = latexEscape(label)
+		for exp, col := range t.Cols {
+			tsum, ok := sums[col]
+			if !ok {
+				continue
+			}
+
+			if tsum.HasSummary {
+				row[startCol(exp)] = latexEscape(benchunit.Scale(tsum.Summary, t.Class))
+			}
+			if exp > 0 {
+				if tsum.HasRatio {
+					row[startCol(exp)+centerCols] = latexEscape(t.formatRatio(tsum))
+				} else {
+					row[startCol(exp)+centerCols] = "?"
+				}
+			}
+			row[startCol(exp)+centerCols-1] += footnotes(tsum.Warnings)
+		}
+		emit()
+	}
+	if t.Others != nil || t.showSummary() {
+		buf.WriteString("\\midrule\n")
+	}
+	if t.Others != nil {
+		summaryRow(t.OthersLabel, t.Others)
+	}
+	if t.showSummary() {
+		summaryRow(t.SummaryLabel, t.Summary)
+	}
+
+	buf.WriteString("\\bottomrule\n\\end{tabular}\n")
+
+	if t.HiddenRows > 0 {
+		fmt.Fprintf(&buf, "\\par %s\n", latexEscape(t.hiddenNote()))
+	}
+
+	// Emit warnings.
+	for i, msg := range warningList {
+		fmt.Fprintf(&buf, "\\par\\textsuperscript{%d} %s\n", i+1, latexEscape(msg))
+	}
+
+	_, err := io.WriteString(w, buf.String())
+	return err
+}
+
+// latexDelta formats a delta string such as "-17.20%" or "~" for
+// LaTeX.
+func latexDelta(d string) string {
+	if d == "~" {
+		return `\ensuremath{\sim}`
+	}
+	return latexEscape(d)
+}
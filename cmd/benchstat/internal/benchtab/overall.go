@@ -0,0 +1,196 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchtab
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/perf/benchproc"
+	"golang.org/x/perf/benchunit"
+	"golang.org/x/perf/cmd/benchstat/internal/texttab"
+)
+
+// Overall summarizes each unit across all of the tables in a Tables,
+// such as across packages.
+type Overall struct {
+	// Label describes the summaries, such as "geomean".
+	Label string
+
+	// Cols is the label of each column, as given by
+	// benchproc.Config.StringValues, in the order they first
+	// appear in the tables. Cells are compared with the baseline
+	// of their own table, which is normally the first column.
+	Cols []string
+
+	// Units summarizes each unit, in the order they first appear
+	// in the tables.
+	Units []*OverallUnit
+
+	opts TableOpts
+}
+
+// OverallUnit summarizes one unit across all tables.
+type OverallUnit struct {
+	Unit   string
+	Class  benchunit.Class
+	Better benchunit.Better
+
+	// Summaries summarizes all cells with this unit in each
+	// column, including rows that aren't shown. It is aligned with
+	// Overall.Cols, and is nil where the unit has no cells in a
+	// column.
+	Summaries []*TableSummary
+}
+
+// overall summarizes each unit across all of t's tables.
+func (t *Tables) overall(opts *TableOpts) *Overall {
+	o := &Overall{Label: opts.Summary.String(), opts: *opts}
+	if opts.Summary == SummaryNone {
+		o.Label = SummaryGeoMean.String()
+	}
+
+	colIndex := make(map[string]int)
+	units := make(map[string]*OverallUnit)
+	cells := make(map[*OverallUnit][][]*TableCell)
+	for _, table := range t.Tables {
+		u, ok := units[table.Unit]
+		if !ok {
+			u = &OverallUnit{Unit: table.Unit, Class: table.Class, Better: table.Better}
+			units[table.Unit] = u
+			o.Units = append(o.Units, u)
+		}
+
+		// Visit cells in a consistent order, including rows
+		// that were removed from table.Rows.
+		rowSet := make(map[benchproc.Config]struct{})
+		for k := range table.Cells {
+			rowSet[k.Row] = struct{}{}
+		}
+		rows := mapConfigs(rowSet)
+		for _, col := range table.Cols {
+			label := col.StringValues()
+			i, ok := colIndex[label]
+			if !ok {
+				i = len(o.Cols)
+				colIndex[label] = i
+				o.Cols = append(o.Cols, label)
+			}
+			for len(cells[u]) <= i {
+				cells[u] = append(cells[u], nil)
+			}
+			for _, row := range rows {
+				if cell, ok := table.Cells[TableKey{row, col}]; ok {
+					cells[u][i] = append(cells[u][i], cell)
+				}
+			}
+		}
+	}
+
+	for _, u := range o.Units {
+		u.Summaries = make([]*TableSummary, len(o.Cols))
+		nBase := len(cells[u][0])
+		for i, colCells := range cells[u] {
+			if len(colCells) == 0 {
+				continue
+			}
+			var s TableSummary
+			summarizeCells(colCells, &s, nBase, i == 0, opts)
+			u.Summaries[i] = &s
+		}
+	}
+	return o
+}
+
+// ToText renders o as a text table with a row for each unit, like
+// Table.ToText.
+func (o *Overall) ToText(w io.Writer) error {
+	var tab texttab.Table
+
+	const labelCols = 1
+	const centerCols = 2 // <summary> <warnings>
+	const deltaCols = 1  // <P%>
+	startCol := func(i int) int {
+		if i == 0 {
+			return labelCols
+		}
+		return labelCols + centerCols + (i-1)*(centerCols+deltaCols)
+	}
+	rEdge := startCol(len(o.Cols))
+
+	var warningList []string
+	warningSet := make(map[string]int)
+	warn := func(msgs []error) {
+		var footnotes []string
+		for _, msg := range msgs {
+			s := msg.Error()
+			i, ok := warningSet[s]
+			if !ok {
+				i = len(warningList)
+				warningSet[s] = i
+				warningList = append(warningList, s)
+			}
+			footnotes = append(footnotes, superscript(i+1))
+		}
+		tab.Cell(strings.Join(footnotes, " "))
+	}
+
+	// Emit the header.
+	tab.Row()
+	for i, col := range o.Cols {
+		l, r := startCol(i), startCol(i+1)
+		tab.Col(l).Span(r-l, col, texttab.Center, texttab.LeftMargin(" │ "))
+	}
+	tab.Col(rEdge).Cell("", texttab.LeftMargin(" │"))
+	tab.Row()
+	for i := range o.Cols {
+		tab.Col(startCol(i)).Span(centerCols, o.Label, texttab.Center, texttab.LeftMargin(" │ "))
+		if i > 0 {
+			tab.Span(deltaCols, "vs base", texttab.Left, texttab.LeftMargin("  "))
+		}
+	}
+	tab.Col(rEdge).Cell("", texttab.LeftMargin(" │"))
+
+	// Emit a row for each unit.
+	for _, u := range o.Units {
+		// Format deltas following the unit's direction.
+		t := &Table{Opts: o.opts, Better: u.Better}
+		tab.Row()
+		tab.Cell(benchunit.DisplayName(u.Unit))
+		for i, s := range u.Summaries {
+			if s == nil {
+				continue
+			}
+			tab.Col(startCol(i))
+			if s.HasSummary {
+				tab.Cell(benchunit.Scale(s.Summary, u.Class), texttab.Right)
+			} else {
+				tab.Cell("")
+			}
+			warn(s.Warnings)
+			if i > 0 {
+				if s.HasRatio {
+					tab.Cell(t.formatRatio(s), texttab.Right)
+				} else {
+					tab.Cell("?")
+				}
+			}
+		}
+	}
+
+	if _, err := fmt.Fprintf(w, "overall:\n"); err != nil {
+		return err
+	}
+	if err := tab.Format(w); err != nil {
+		return err
+	}
+	for i, msg := range warningList {
+		if _, err := fmt.Fprintf(w, "%s %s\n", superscript(i+1), msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchtab
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"golang.org/x/perf/benchproc"
+)
+
+// RowSort is an order for the rows of a Table.
+type RowSort int
+
+const (
+	// SortNone leaves rows in the order given by the row
+	// projection, which is by default the order they were first
+	// observed.
+	SortNone RowSort = iota
+
+	// SortName sorts rows alphabetically by name.
+	SortName
+
+	// SortValue sorts rows by the summary value in their first
+	// column, largest first.
+	SortValue
+
+	// SortDelta sorts rows by the magnitude of the largest
+	// change from the baseline in each row, largest first.
+	// Changes are compared on a log scale, so halving and
+	// doubling are equally large changes.
+	SortDelta
+)
+
+// ParseRowSort parses the name of a RowSort, which is one of "none",
+// "name", "value", or "delta".
+func ParseRowSort(s string) (RowSort, error) {
+	switch s {
+	case "none":
+		return SortNone, nil
+	case "name":
+		return SortName, nil
+	case "value":
+		return SortValue, nil
+	case "delta":
+		return SortDelta, nil
+	}
+	return SortNone, fmt.Errorf("unknown row sort %q", s)
+}
+
+// sortRows sorts t.Rows according to by, reversing the order if
+// reverse is set. Rows that have no value to sort by, such as rows
+// without any comparisons when sorting by delta, always come last.
+// Rows that compare equal keep their relative order.
+func (t *Table) sortRows(by RowSort, reverse bool) {
+	var key func(row benchproc.Config) (float64, bool)
+	switch by {
+	case SortNone:
+		if reverse {
+			for i, j := 0, len(t.Rows)-1; i < j; i, j = i+1, j-1 {
+				t.Rows[i], t.Rows[j] = t.Rows[j], t.Rows[i]
+			}
+		}
+		return
+	case SortName:
+		sort.SliceStable(t.Rows, func(i, j int) bool {
+			if reverse {
+				return t.Rows[i].StringValues() > t.Rows[j].StringValues()
+			}
+			return t.Rows[i].StringValues() < t.Rows[j].StringValues()
+		})
+		return
+	case SortValue:
+		key = func(row benchproc.Config) (float64, bool) {
+			for _, col := range t.Cols {
+				if cell, ok := t.Cells[TableKey{row, col}]; ok {
+					return cell.Summary.Center, true
+				}
+			}
+			return 0, false
+		}
+	case SortDelta:
+		key = func(row benchproc.Config) (float64, bool) {
+			max, ok := 0.0, false
+			for _, col := range t.Cols {
+				cell, found := t.Cells[TableKey{row, col}]
+				if !found || cell.Baseline == nil {
+					continue
+				}
+				d := math.Abs(math.Log(cell.Summary.Center / cell.Baseline.Summary.Center))
+				if math.IsNaN(d) {
+					continue
+				}
+				if !ok || d > max {
+					max, ok = d, true
+				}
+			}
+			return max, ok
+		}
+	}
+
+	type keyed struct {
+		row benchproc.Config
+		key float64
+		ok  bool
+	}
+	rows := make([]keyed, len(t.Rows))
+	for i, row := range t.Rows {
+		k, ok := key(row)
+		rows[i] = keyed{row, k, ok}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if a.ok != b.ok {
+			return a.ok
+		}
+		if reverse {
+			return a.key < b.key
+		}
+		return a.key > b.key
+	})
+	for i := range rows {
+		t.Rows[i] = rows[i].row
+	}
+}
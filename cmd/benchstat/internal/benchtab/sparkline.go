@@ -0,0 +1,66 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchtab
+
+import (
+	"math"
+	"strings"
+
+	"golang.org/x/perf/benchmath"
+)
+
+// sparkBins is the number of histogram bins in a sparkline.
+const sparkBins = 8
+
+// sparkLevels are the bars of a sparkline, from shortest to tallest.
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline returns a small histogram of the values in s, including
+// any outliers, as a string of sparkBins block characters spanning
+// the range of s. Empty bins are shown as spaces, so gaps in a
+// bimodal sample or before an outlier stand out. If all values are
+// the same, it returns a single bar.
+func sparkline(s *benchmath.Sample) string {
+	vals := append(append([]float64(nil), s.Values...), s.Outliers...)
+	if len(vals) == 0 {
+		return ""
+	}
+	lo, hi := vals[0], vals[0]
+	for _, v := range vals {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	if lo == hi {
+		return string(sparkLevels[len(sparkLevels)-1])
+	}
+
+	var counts [sparkBins]int
+	for _, v := range vals {
+		i := int((v - lo) / (hi - lo) * sparkBins)
+		if i == sparkBins {
+			// The maximum goes in the last bin.
+			i--
+		}
+		counts[i]++
+	}
+	max := 0
+	for _, n := range counts {
+		if n > max {
+			max = n
+		}
+	}
+
+	var buf strings.Builder
+	for _, n := range counts {
+		if n == 0 {
+			buf.WriteByte(' ')
+			continue
+		}
+		// Scale so that any non-empty bin gets at least the
+		// shortest bar.
+		level := (n*len(sparkLevels) + max - 1) / max
+		buf.WriteRune(sparkLevels[level-1])
+	}
+	return buf.String()
+}
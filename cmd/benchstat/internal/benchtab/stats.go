@@ -0,0 +1,66 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchtab
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/aclements/go-moremath/stats"
+	"golang.org/x/perf/benchmath"
+	"golang.org/x/perf/benchunit"
+)
+
+// statsLabels are the headers of the columns added for each cell by
+// TableOpts.ShowStats, in the order of cellStats.values.
+var statsLabels = []string{"min", "Q1", "median", "Q3", "max", "n"}
+
+// cellStats describes the distribution of a cell's sample.
+type cellStats struct {
+	Min, Q1, Median, Q3, Max float64
+	N                        int
+}
+
+// statsOf returns descriptive statistics of the values in s. Like
+// the cell's summary, these exclude any outliers trimmed from s and
+// ignore any weights.
+func statsOf(s *benchmath.Sample) cellStats {
+	// s.Values is already sorted.
+	xs := stats.Sample{Xs: s.Values, Sorted: true}
+	min, max := xs.Bounds()
+	return cellStats{
+		Min:    min,
+		Q1:     xs.Quantile(0.25),
+		Median: xs.Quantile(0.5),
+		Q3:     xs.Quantile(0.75),
+		Max:    max,
+		N:      len(s.Values),
+	}
+}
+
+// format returns s's values formatted using scalar, in the order of
+// statsLabels.
+func (s cellStats) format(scalar benchunit.Scaler) []string {
+	return []string{
+		scalar.Format(s.Min),
+		scalar.Format(s.Q1),
+		scalar.Format(s.Median),
+		scalar.Format(s.Q3),
+		scalar.Format(s.Max),
+		strconv.Itoa(s.N),
+	}
+}
+
+// values returns s's unscaled values, in the order of statsLabels.
+func (s cellStats) values() []string {
+	return []string{
+		fmt.Sprint(s.Min),
+		fmt.Sprint(s.Q1),
+		fmt.Sprint(s.Median),
+		fmt.Sprint(s.Q3),
+		fmt.Sprint(s.Max),
+		strconv.Itoa(s.N),
+	}
+}
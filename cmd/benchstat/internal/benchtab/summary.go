@@ -0,0 +1,81 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchtab
+
+import (
+	"fmt"
+
+	"github.com/aclements/go-moremath/stats"
+)
+
+// SummaryFunc is a statistic used to summarize each column of a Table
+// in its summary row.
+type SummaryFunc int
+
+const (
+	// SummaryGeoMean summarizes each column by the geometric mean
+	// of its values, and each comparison by the geometric mean of
+	// its ratios. This is the default because it treats a
+	// proportional change in any benchmark equally.
+	SummaryGeoMean SummaryFunc = iota
+
+	// SummaryMean summarizes each column by the arithmetic mean
+	// of its values.
+	SummaryMean
+
+	// SummaryMedian summarizes each column by the median of its
+	// values.
+	SummaryMedian
+
+	// SummarySum summarizes each column by the sum of its values.
+	// This is useful for sizes and counts.
+	SummarySum
+
+	// SummaryNone omits the summary row.
+	SummaryNone
+)
+
+// ParseSummaryFunc parses the name of a SummaryFunc, which is one of
+// "geomean", "mean", "median", "sum", or "none".
+func ParseSummaryFunc(s string) (SummaryFunc, error) {
+	for f := SummaryGeoMean; f <= SummaryNone; f++ {
+		if f.String() == s {
+			return f, nil
+		}
+	}
+	return SummaryGeoMean, fmt.Errorf("unknown summary %q", s)
+}
+
+// String returns the name of f, which is also the label of the
+// summary row.
+func (f SummaryFunc) String() string {
+	switch f {
+	case SummaryGeoMean:
+		return "geomean"
+	case SummaryMean:
+		return "mean"
+	case SummaryMedian:
+		return "median"
+	case SummarySum:
+		return "sum"
+	case SummaryNone:
+		return "none"
+	}
+	return fmt.Sprintf("SummaryFunc(%d)", int(f))
+}
+
+// apply computes statistic f of xs. It returns NaN if xs is empty
+// and f is not SummarySum.
+func (f SummaryFunc) apply(xs []float64) float64 {
+	switch f {
+	case SummaryMean:
+		return stats.Mean(xs)
+	case SummaryMedian:
+		return stats.Sample{Xs: xs}.Quantile(0.5)
+	case SummarySum:
+		return stats.Sample{Xs: xs}.Sum()
+	}
+	return stats.GeoMean(xs)
+}
@@ -32,6 +32,16 @@ type Table struct {
 	// samples in this table.
 	Assumption benchmath.Assumption
 
+	// Class is the class of unit prefixes used to format values
+	// in this table. This is benchunit.ClassOf(Unit), unless the
+	// unit's "class" metadata overrides it.
+	Class benchunit.Class
+
+	// Better is whether higher or lower values of Unit are
+	// better. This is benchunit.BetterOf(Unit), using the unit's
+	// "better" metadata if any.
+	Better benchunit.Better
+
 	// Rows and Cols give the sequence of row and column Configs
 	// in this table. All row Configs have the same schema and all
 	// col Configs have the same schema.
@@ -45,11 +55,43 @@ type Table struct {
 
 	// Summary is the final row of this table, which gives summary
 	// information across all benchmarks in this table. It is
-	// keyed by Cols.
+	// keyed by Cols. It is nil if TableOpts.Summary is
+	// SummaryNone.
 	Summary map[benchproc.Config]*TableSummary
 
 	// SummaryLabel is the label for the summary row.
 	SummaryLabel string
+
+	// HiddenRows is the number of rows omitted from Rows because
+	// TableOpts.ChangedOnly is set. These rows are still included
+	// in Summary.
+	HiddenRows int
+
+	// Others, if non-nil, summarizes the rows omitted from Rows
+	// because TableOpts.Top is set, like Summary. It is keyed by
+	// Cols. OtherRows is the number of these rows, and OthersLabel
+	// is the label for this summary.
+	Others      map[benchproc.Config]*TableSummary
+	OtherRows   int
+	OthersLabel string
+
+	// GroupLevels is the number of leading row fields used to
+	// group rows because TableOpts.GroupRows is set, or 0 if rows
+	// aren't grouped. Rows in the same group are consecutive in
+	// Rows.
+	GroupLevels int
+
+	// groupSums summarizes each group of more than one row, like
+	// Summary. It is keyed by groupKey.
+	groupSums map[string]map[benchproc.Config]*TableSummary
+
+	// Trends is the trend of each row across the columns, keyed by
+	// row. It is only computed if TableOpts.Trend is set.
+	Trends map[benchproc.Config]*TableTrend
+
+	// labelWidth, if positive, is the width to truncate row labels
+	// to in text output. See fitLabel.
+	labelWidth int
 }
 
 // TableKey is a map key used to index a single cell in a Table.
@@ -76,6 +118,27 @@ type TableCell struct {
 	// computed by the Table's distributional assumption. If
 	// Baseline is nil, this value is meaningless.
 	Comparison benchmath.Comparison
+
+	// Negligible indicates that the change from the Baseline cell
+	// is smaller than TableOpts.DeltaThreshold. Such changes are
+	// shown as "~" and never considered significant, regardless
+	// of Comparison.
+	Negligible bool
+
+	// Spread is the comparison of the spread of this cell with
+	// the Baseline cell. This is only computed if
+	// TableOpts.ShowSpread is set and Baseline is non-nil.
+	Spread benchmath.SpreadComparison
+
+	// Equivalence is the result of testing whether this cell is
+	// equivalent to the Baseline cell. This is only computed if
+	// TableOpts.EquivMargin is set and Baseline is non-nil.
+	Equivalence benchmath.Equivalence
+
+	// Bayes is the Bayesian comparison of this cell with the
+	// Baseline cell. This is only computed if TableOpts.ShowBayes
+	// is set and Baseline is non-nil.
+	Bayes benchmath.BayesComparison
 }
 
 // TableSummary summarizes a column of a Table.
@@ -92,6 +155,11 @@ type TableSummary struct {
 	// this column.
 	Ratio float64
 
+	// RatioCI is the confidence interval of Ratio, which is only
+	// computed if TableOpts.DeltaCI is set. Its Confidence is 0
+	// if it hasn't been computed.
+	RatioCI benchmath.Summary
+
 	// Warnings is a list of warnings for this summary cell.
 	Warnings []error
 }
@@ -111,16 +179,99 @@ func (t *Table) RowValues(row benchproc.Config) []float64 {
 	return out
 }
 
+// showSummary reports whether t's summary row should be shown. The
+// summary is only interesting if it summarizes more than one row.
+func (t *Table) showSummary() bool {
+	return t.Summary != nil && len(t.Rows)+t.HiddenRows+t.OtherRows > 1
+}
+
+// hideUnchanged removes rows from t that have no statistically
+// significant changes from the baseline, and counts them in
+// t.HiddenRows. If t has only one column, there are no comparisons,
+// so it leaves t alone. If t.Trends is set, it instead removes rows
+// with no change points.
+func (t *Table) hideUnchanged() {
+	if len(t.Cols) < 2 {
+		return
+	}
+	rows := t.Rows[:0]
+	for _, row := range t.Rows {
+		changed := false
+		if t.Trends != nil {
+			changed = len(t.Trends[row].ChangePoints.Points) > 0
+		} else {
+			for _, col := range t.Cols[1:] {
+				if cell, ok := t.Cells[TableKey{row, col}]; ok && cell.significant() {
+					changed = true
+					break
+				}
+			}
+		}
+		if changed {
+			rows = append(rows, row)
+		} else {
+			t.HiddenRows++
+		}
+	}
+	t.Rows = rows
+}
+
 // ToText renders t to a textual representation, assuming a
-// fixed-width font.
+// fixed-width font. The unit is shown using its display name (see
+// benchunit.DisplayName).
+//
+// If t.Opts.Transpose is set, ToText shows each column of t as a row
+// and each row as a column.
+//
+// If t.Opts.Width is set, ToText adapts the layout of t to fit in
+// that many columns.
+//
+// If t.Trends is set, ToText shows the trend of each row instead of
+// comparing each column with the first.
+//
+// If color is true, ToText uses ANSI escape codes to show
+// statistically significant improvements in green and regressions in
+// red, according to t.Better, and dims rows with no significant
+// changes.
 func (t *Table) ToText(w io.Writer, color bool) error {
+	if t.Trends != nil {
+		return t.toTextTrend(w, color)
+	}
+	if t.Opts.Width > 0 {
+		return t.toTextWidth(w, color)
+	}
+	if t.Opts.Transpose {
+		return t.toTextTransposed(w, color)
+	}
+
 	var o texttab.Table
 
 	// Each logical column expands to centerCols columns, plus
 	// deltaCols columns if there's a baseline.
 	const labelCols = 1
-	const centerCols = 3 // <center ±> <CI> <warnings>
-	const deltaCols = 3  // <P%> <(p=0.PPP n=N)> <warnings>
+	centerCols := 3 // <center ±> <CI> <warnings>
+	if t.Opts.NoRange {
+		centerCols-- // No <CI>
+	}
+	if t.Opts.Sparklines {
+		centerCols++ // <sparkline>
+	}
+	if t.Opts.ShowStats {
+		centerCols += len(statsLabels) // <min> <Q1> <median> <Q3> <max> <n>
+	}
+	deltaCols := 3 // <P%> <(p=0.PPP n=N)> <warnings>
+	if t.Opts.ShowEffect {
+		deltaCols++ // <effect>
+	}
+	if t.Opts.ShowSpread {
+		deltaCols++ // <spread>
+	}
+	if t.Opts.EquivMargin > 0 {
+		deltaCols++ // <equiv>
+	}
+	if t.Opts.ShowBayes {
+		deltaCols++ // <bayes>
+	}
 
 	// startCol returns the index of the first centerCol of
 	// logical column exp.
@@ -160,7 +311,7 @@ func (t *Table) ToText(w io.Writer, color bool) error {
 	// it over the center columns, then anything that spans
 	// multiple centers looks weird. Maybe we need to mark the
 	// boundaries of these cells, e.g., with vertical rules?
-	hdr := benchproc.NewConfigHeader(t.Cols)
+	hdr, _ := benchproc.NewConfigHeaderOpts(t.Cols, benchproc.ConfigHeaderOpts{MaxLevels: t.Opts.MaxHeaderLevels})
 	rEdge := startCol(len(t.Cols) + 1)
 	for _, hdrRow := range hdr {
 		o.Row()
@@ -189,7 +340,7 @@ func (t *Table) ToText(w io.Writer, color bool) error {
 
 		// Show the unit over the center column group, since
 		// these are values in that unit.
-		o.Span(centerCols, t.Unit, texttab.Center, texttab.LeftMargin(" │ "))
+		o.Span(centerCols, benchunit.DisplayName(t.Unit), texttab.Center, texttab.LeftMargin(" │ "))
 
 		if i > 0 {
 			// All but the first column will have A/B
@@ -209,17 +360,74 @@ func (t *Table) ToText(w io.Writer, color bool) error {
 	}
 	o.Col(rEdge).Cell("", texttab.LeftMargin(" │"))
 
+	// Label the statistics columns.
+	if t.Opts.ShowStats {
+		o.Row()
+		for i := range t.Cols {
+			o.Col(startCol(i)).Cell("", texttab.LeftMargin(" │ "))
+			o.Col(startCol(i) + centerCols - 1 - len(statsLabels))
+			for _, label := range statsLabels {
+				o.Cell(label, texttab.Right)
+			}
+		}
+		o.Col(rEdge).Cell("", texttab.LeftMargin(" │"))
+	}
+
+	// summaryRow emits a summary row, such as the geomean.
+	summaryRow := func(label string, sums map[benchproc.Config]*TableSummary) {
+		o.Row()
+		o.Cell(label)
+		for exp, col := range t.Cols {
+			tsum, ok := sums[col]
+			if !ok {
+				continue
+			}
+
+			if tsum.HasSummary {
+				o.Col(startCol(exp))
+				o.Cell(benchunit.Scale(tsum.Summary, t.Class), texttab.Right)
+			}
+			if exp > 0 {
+				o.Col(startCol(exp) + centerCols)
+				if tsum.HasRatio {
+					o.Cell(t.formatRatio(tsum), texttab.Right)
+				} else {
+					o.Cell("?")
+				}
+			}
+
+			o.Col(startCol(exp+1) - 1)
+			warn(tsum.Warnings)
+		}
+	}
+
 	// Emit measurements.
-	unitClass := benchunit.ClassOf(t.Unit)
-	for _, row := range t.Rows {
+	var prev benchproc.Config
+	for i, row := range t.Rows {
+		// Start any new row groups.
+		for level := t.groupStart(prev, row); level < t.GroupLevels; level++ {
+			o.Row()
+			o.Cell(t.fitLabel(groupIndent(level) + row.Fields()[level].Value))
+		}
+		prev = row
+
 		o.Row()
 
+		// Dim rows where nothing changed.
+		var rowColor []texttab.CellOption
+		if color && !t.rowChanged(row) {
+			rowColor = []texttab.CellOption{texttab.Color(sgrDim)}
+		}
+		cellOpts := func(opts ...texttab.CellOption) []texttab.CellOption {
+			return append(opts, rowColor...)
+		}
+
 		// TODO: Should I put each row config value in a
 		// column? With the keys as headers?
-		o.Cell(row.StringValues())
+		o.Cell(t.fitLabel(t.rowLabel(row)), rowColor...)
 
 		// Get a common scalar across this row.
-		scalar := benchunit.CommonScale(t.RowValues(row), unitClass)
+		scalar := benchunit.CommonScale(t.RowValues(row), t.Class)
 
 		for exp, col := range t.Cols {
 			cell, ok := t.Cells[TableKey{row, col}]
@@ -228,56 +436,79 @@ func (t *Table) ToText(w io.Writer, color bool) error {
 			}
 
 			o.Col(startCol(exp))
-			o.Cell(scalar.Format(cell.Summary.Center), texttab.Right)
+			o.Cell(scalar.Format(cell.Summary.Center), cellOpts(texttab.Right)...)
 			// Put ± in the margin so 1) the ±s line up,
 			// 2) the geomean value (which doesn't have ±)
 			// aligns with the summary column, 3) we can
 			// right align the range column.
-			o.Cell(cell.Summary.PctRangeString(), texttab.Right, texttab.LeftMargin(" ± "))
-			warn(cell.Sample.Warnings, cell.Summary.Warnings)
-			if exp > 0 && cell.Baseline != nil {
-				d := cell.Comparison.FormatDelta(cell.Baseline.Summary.Center, cell.Summary.Center)
-				// TODO: Color the delta for whether
-				// it's good or bad.
-				o.Cell(d, texttab.Right)
-				o.Cell("(" + cell.Comparison.String() + ")")
-				warn(cell.Comparison.Warnings)
+			if !t.Opts.NoRange {
+				o.Cell(cell.Summary.PctRangeString(), cellOpts(texttab.Right, texttab.LeftMargin(" ± "))...)
 			}
-		}
-	}
-
-	// Emit summary row.
-	if len(t.Rows) > 1 {
-		o.Row()
-		o.Cell(t.SummaryLabel)
-		for exp, col := range t.Cols {
-			tsum, ok := t.Summary[col]
-			if !ok {
-				continue
+			if t.Opts.Sparklines {
+				o.Cell(sparkline(cell.Sample), rowColor...)
 			}
-
-			if tsum.HasSummary {
-				o.Col(startCol(exp))
-				o.Cell(benchunit.Scale(tsum.Summary, unitClass), texttab.Right)
+			if t.Opts.ShowStats {
+				for _, v := range statsOf(cell.Sample).format(scalar) {
+					o.Cell(v, cellOpts(texttab.Right)...)
+				}
 			}
-			if exp > 0 {
-				o.Col(startCol(exp) + centerCols)
-				if tsum.HasRatio {
-					o.Cell(fmt.Sprintf("%+.2f%%", (tsum.Ratio-1)*100), texttab.Right)
-				} else {
-					o.Cell("?")
+			warn(cell.Sample.Warnings, cell.Summary.Warnings)
+			if exp > 0 && cell.Baseline != nil {
+				d := t.formatDelta(cell)
+				dOpts := cellOpts(texttab.Right)
+				if color {
+					if sgr := t.deltaColor(cell); sgr != "" {
+						dOpts = append(dOpts, texttab.Color(sgr))
+					}
+				}
+				o.Cell(d, dOpts...)
+				o.Cell("("+cell.Comparison.String()+")", rowColor...)
+				if t.Opts.ShowEffect {
+					o.Cell(cell.Comparison.FormatEffect(), cellOpts(texttab.Right)...)
+				}
+				if t.Opts.ShowSpread {
+					o.Cell(cell.Spread.FormatDelta(), cellOpts(texttab.Right)...)
+				}
+				if t.Opts.EquivMargin > 0 {
+					o.Cell(cell.Equivalence.String(), rowColor...)
 				}
+				if t.Opts.ShowBayes {
+					o.Cell(cell.Bayes.String(), rowColor...)
+				}
+				warn(cell.Comparison.Warnings, cell.Spread.Warnings, cell.Equivalence.Warnings, cell.Bayes.Warnings)
 			}
+		}
 
-			o.Col(startCol(exp+1) - 1)
-			warn(tsum.Warnings)
+		// Summarize any row groups that end with this row,
+		// innermost first.
+		end := 0
+		if i+1 < len(t.Rows) {
+			end = t.groupStart(row, t.Rows[i+1])
+		}
+		for level := t.GroupLevels - 1; level >= end; level-- {
+			if sums, ok := t.groupSums[t.groupKey(row, level)]; ok {
+				summaryRow(groupIndent(level+1)+t.SummaryLabel, sums)
+			}
 		}
 	}
 
+	// Emit summary rows.
+	if t.Others != nil {
+		summaryRow(t.OthersLabel, t.Others)
+	}
+	if t.showSummary() {
+		summaryRow(t.SummaryLabel, t.Summary)
+	}
+
 	// Emit table.
 	if err := o.Format(w); err != nil {
 		return err
 	}
+	if t.HiddenRows > 0 {
+		if _, err := fmt.Fprintf(w, "%s\n", t.hiddenNote()); err != nil {
+			return err
+		}
+	}
 
 	// Emit warnings.
 	if len(warningList) > 0 {
@@ -291,8 +522,79 @@ func (t *Table) ToText(w io.Writer, color bool) error {
 	return nil
 }
 
+// hiddenNote returns a note saying how many rows were hidden.
+func (t *Table) hiddenNote() string {
+	if t.HiddenRows == 1 {
+		return "1 benchmark with no significant change not shown"
+	}
+	return fmt.Sprintf("%d benchmarks with no significant change not shown", t.HiddenRows)
+}
+
+// ANSI SGR parameters used by ToText.
+const (
+	sgrDim    = "2"
+	sgrBetter = "32" // Green
+	sgrWorse  = "31" // Red
+)
+
+// significant reports whether cell has a statistically significant
+// difference from its baseline.
+func (cell *TableCell) significant() bool {
+	return cell.Baseline != nil && cell.Comparison.P <= cell.Comparison.Alpha && !cell.Negligible
+}
+
+// rowChanged reports whether any cell in row is significantly
+// different from its baseline, or if row has no comparisons at all.
+func (t *Table) rowChanged(row benchproc.Config) bool {
+	compared := false
+	for _, col := range t.Cols {
+		cell, ok := t.Cells[TableKey{row, col}]
+		if !ok || cell.Baseline == nil {
+			continue
+		}
+		if cell.significant() {
+			return true
+		}
+		compared = true
+	}
+	return !compared
+}
+
+// deltaColor returns the SGR parameters to color the delta of cell,
+// or "" if it shouldn't be colored.
+func (t *Table) deltaColor(cell *TableCell) string {
+	if !cell.significant() || t.Better == benchunit.BetterUnknown {
+		return ""
+	}
+	diff := cell.Summary.Center - cell.Baseline.Summary.Center
+	switch {
+	case diff*float64(t.Better) > 0:
+		return sgrBetter
+	case diff*float64(t.Better) < 0:
+		return sgrWorse
+	}
+	return ""
+}
+
 var superDigits = []rune("⁰¹²³⁴⁵⁶⁷⁸⁹")
 
+// csvCellName returns a spreadsheet-style label for the cell at the
+// given 0-based column and 1-based row, such as "C12".
+func csvCellName(col, row int) string {
+	colName := make([]byte, 10)
+	colNamePos := len(colName)
+	for x := col; x > 0; {
+		colNamePos--
+		colName[colNamePos] = 'A' + byte(x%26)
+		x /= 26
+	}
+	if colNamePos == len(colName) {
+		colNamePos--
+		colName[colNamePos] = 'A'
+	}
+	return fmt.Sprintf("%s%d", colName[colNamePos:], row)
+}
+
 func superscript(i int) string {
 	if i == 0 {
 		return string(superDigits[0])
@@ -311,11 +613,33 @@ func superscript(i int) string {
 // ToCSV renders t to CSV format. Warnings are written in text format
 // to the "warnings" Writer, and prefixed with spreadsheet-style cell
 // references. These references assume the table begins on row
-// "startRow".
+// "startRow". Unlike ToText, this always uses the real unit name, since
+// CSV is meant to be consumed by other programs.
 func (t *Table) ToCSV(o *csv.Writer, startRow int, warnings io.Writer) (rowCount int) {
+	if t.Trends != nil {
+		return t.trendToCSV(o, startRow, warnings)
+	}
 	const labelCols = 1
-	const centerCols = 2 // <center> <CI>
-	const deltaCols = 2  // <P%> <(p=0.PPP n=N)>
+	centerCols := 2 // <center> <CI>
+	if t.Opts.NoRange {
+		centerCols-- // No <CI>
+	}
+	if t.Opts.ShowStats {
+		centerCols += len(statsLabels) // <min> <Q1> <median> <Q3> <max> <n>
+	}
+	deltaCols := 2 // <P%> <(p=0.PPP n=N)>
+	if t.Opts.ShowEffect {
+		deltaCols++ // <effect>
+	}
+	if t.Opts.ShowSpread {
+		deltaCols++ // <spread>
+	}
+	if t.Opts.EquivMargin > 0 {
+		deltaCols++ // <equiv>
+	}
+	if t.Opts.ShowBayes {
+		deltaCols++ // <bayes>
+	}
 	startCol := func(exp int) int {
 		if exp == 0 {
 			// Baseline, so no delta.
@@ -338,22 +662,9 @@ func (t *Table) ToCSV(o *csv.Writer, startRow int, warnings io.Writer) (rowCount
 		rowCount++
 	}
 	warn := func(msgs []error) {
-		// Construct a spreadsheet-style cell label.
-		colName := make([]byte, 10)
-		colNamePos := len(colName)
-		for x := len(row); x > 0; {
-			colNamePos--
-			colName[colNamePos] = 'A' + byte(x%26)
-			x /= 26
-		}
-		if colNamePos == len(colName) {
-			colNamePos--
-			colName[colNamePos] = 'A'
-		}
-		colName = colName[colNamePos:]
-		// Print warnings.
+		cellName := csvCellName(len(row), startRow+rowCount)
 		for _, msg := range msgs {
-			fmt.Fprintf(warnings, "%s%d: %s\n", colName, startRow+rowCount, msg)
+			fmt.Fprintf(warnings, "%s: %s\n", cellName, msg)
 		}
 	}
 
@@ -370,9 +681,27 @@ func (t *Table) ToCSV(o *csv.Writer, startRow int, warnings io.Writer) (rowCount
 	// Emit column headers.
 	for exp := range t.Cols {
 		clearTo(startCol(exp))
-		row = append(row, t.Unit, "CI")
+		row = append(row, t.Unit)
+		if !t.Opts.NoRange {
+			row = append(row, "CI")
+		}
+		if t.Opts.ShowStats {
+			row = append(row, statsLabels...)
+		}
 		if exp > 0 {
 			row = append(row, "vs base", "P")
+			if t.Opts.ShowEffect {
+				row = append(row, "effect")
+			}
+			if t.Opts.ShowSpread {
+				row = append(row, "spread")
+			}
+			if t.Opts.EquivMargin > 0 {
+				row = append(row, "equiv")
+			}
+			if t.Opts.ShowBayes {
+				row = append(row, "bayes")
+			}
 		}
 	}
 	emit()
@@ -389,44 +718,70 @@ func (t *Table) ToCSV(o *csv.Writer, startRow int, warnings io.Writer) (rowCount
 			clearTo(startCol(exp))
 			warn(cell.Sample.Warnings)
 			warn(cell.Summary.Warnings)
-			row = append(row,
-				fmt.Sprint(cell.Summary.Center),
-				cell.Summary.PctRangeString(),
-			)
+			row = append(row, fmt.Sprint(cell.Summary.Center))
+			if !t.Opts.NoRange {
+				row = append(row, cell.Summary.PctRangeString())
+			}
+			if t.Opts.ShowStats {
+				row = append(row, statsOf(cell.Sample).values()...)
+			}
 			if exp > 0 && cell.Baseline != nil {
 				warn(cell.Comparison.Warnings)
+				warn(cell.Spread.Warnings)
+				warn(cell.Equivalence.Warnings)
+				warn(cell.Bayes.Warnings)
 				row = append(row,
-					cell.Comparison.FormatDelta(cell.Baseline.Summary.Center, cell.Summary.Center),
+					t.formatDelta(cell),
 					cell.Comparison.String(),
 				)
+				if t.Opts.ShowEffect {
+					row = append(row, cell.Comparison.FormatEffect())
+				}
+				if t.Opts.ShowSpread {
+					row = append(row, cell.Spread.FormatDelta())
+				}
+				if t.Opts.EquivMargin > 0 {
+					row = append(row, cell.Equivalence.String())
+				}
+				if t.Opts.ShowBayes {
+					row = append(row, cell.Bayes.String())
+				}
 			}
 		}
 		emit()
 	}
 
-	// Emit summary row.
-	row = append(row, t.SummaryLabel)
-	for exp, cfg := range t.Cols {
-		tsum, ok := t.Summary[cfg]
-		if !ok {
-			continue
-		}
+	// Emit summary rows.
+	summaryRow := func(label string, sums map[benchproc.Config]*TableSummary) {
+		row = append(row, label)
+		for exp, cfg := range t.Cols {
+			tsum, ok := sums[cfg]
+			if !ok {
+				continue
+			}
 
-		clearTo(startCol(exp))
-		warn(tsum.Warnings)
-		if tsum.HasSummary {
-			row = append(row, fmt.Sprint(tsum.Summary))
-		}
-		if exp > 0 {
-			clearTo(startCol(exp) + centerCols)
-			if tsum.HasRatio {
-				row = append(row, fmt.Sprintf("%+.2f%%", (tsum.Ratio-1)*100))
-			} else {
-				row = append(row, "?")
+			clearTo(startCol(exp))
+			warn(tsum.Warnings)
+			if tsum.HasSummary {
+				row = append(row, fmt.Sprint(tsum.Summary))
+			}
+			if exp > 0 {
+				clearTo(startCol(exp) + centerCols)
+				if tsum.HasRatio {
+					row = append(row, t.formatRatio(tsum))
+				} else {
+					row = append(row, "?")
+				}
 			}
 		}
+		emit()
+	}
+	if t.Others != nil {
+		summaryRow(t.OthersLabel, t.Others)
+	}
+	if t.Summary != nil {
+		summaryRow(t.SummaryLabel, t.Summary)
 	}
-	emit()
 
 	return
 }
@@ -0,0 +1,40 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchtab
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// templateFuncs are the functions available to templates, in addition
+// to the text/template builtins.
+var templateFuncs = template.FuncMap{
+	// pct formats a ratio as a percent change, such as "-7.25%".
+	"pct": func(ratio jsonFloat) string {
+		return fmt.Sprintf("%+.2f%%", (float64(ratio)-1)*100)
+	},
+	"join": strings.Join,
+}
+
+// ParseTemplate parses text as a template for ToTemplate. The
+// template has access to the functions "pct", which formats a ratio
+// such as a comparison's Ratio as a percent change, and "join",
+// which is strings.Join.
+func ParseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(templateFuncs).Parse(text)
+}
+
+// ToTemplate renders t by executing tmpl, which must have been
+// created by ParseTemplate. The template's data follows the same
+// schema as ToJSON, with each JSON key capitalized to form a field
+// name. For example, "{{range .Tables}}{{.Unit}}{{end}}" prints the
+// unit of each table. Each table also has a Scale method that formats
+// a value with an SI or binary prefix, as in text output.
+func (t *Tables) ToTemplate(w io.Writer, tmpl *template.Template) error {
+	return tmpl.Execute(w, t.toJSON())
+}
@@ -0,0 +1,111 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchtab
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"golang.org/x/perf/benchproc"
+	"golang.org/x/perf/benchunit"
+)
+
+// keepTop reduces t to the n rows with the worst statistically
+// significant regressions and the n rows with the best statistically
+// significant improvements. The remaining rows are summarized in
+// t.Others.
+//
+// Regressions come first, worst first, followed by improvements,
+// best first. For units where it's not known whether higher or lower
+// is better, increases are treated as regressions.
+func (t *Table) keepTop(n int, opts *TableOpts) {
+	if len(t.Cols) < 2 {
+		return
+	}
+	better := t.Better
+	if better == benchunit.BetterUnknown {
+		better = benchunit.BetterLower
+	}
+
+	// Score each row by its most extreme significant change,
+	// oriented so that regressions are positive.
+	type scored struct {
+		row   benchproc.Config
+		score float64
+	}
+	var worse, improved []scored
+	var others []benchproc.Config
+	for _, row := range t.Rows {
+		score, ok := 0.0, false
+		for _, col := range t.Cols[1:] {
+			cell, found := t.Cells[TableKey{row, col}]
+			if !found || !cell.significant() {
+				continue
+			}
+			s := -float64(better) * math.Log(cell.Summary.Center/cell.Baseline.Summary.Center)
+			if math.IsNaN(s) || s == 0 {
+				continue
+			}
+			if !ok || math.Abs(s) > math.Abs(score) {
+				score, ok = s, true
+			}
+		}
+		switch {
+		case !ok:
+			others = append(others, row)
+		case score > 0:
+			worse = append(worse, scored{row, score})
+		default:
+			improved = append(improved, scored{row, score})
+		}
+	}
+	sort.SliceStable(worse, func(i, j int) bool { return worse[i].score > worse[j].score })
+	sort.SliceStable(improved, func(i, j int) bool { return improved[i].score < improved[j].score })
+	if len(worse) > n {
+		for _, s := range worse[n:] {
+			others = append(others, s.row)
+		}
+		worse = worse[:n]
+	}
+	if len(improved) > n {
+		for _, s := range improved[n:] {
+			others = append(others, s.row)
+		}
+		improved = improved[:n]
+	}
+
+	t.Rows = t.Rows[:0]
+	for _, s := range worse {
+		t.Rows = append(t.Rows, s.row)
+	}
+	for _, s := range improved {
+		t.Rows = append(t.Rows, s.row)
+	}
+	if len(others) == 0 {
+		return
+	}
+
+	// Summarize the other rows.
+	t.OtherRows = len(others)
+	if len(others) == 1 {
+		t.OthersLabel = "1 other"
+	} else {
+		t.OthersLabel = fmt.Sprintf("%d others", len(others))
+	}
+	sub := &Table{Cols: t.Cols, Rows: others, Cells: t.Cells}
+	nBase := 0
+	for _, row := range others {
+		if _, ok := t.Cells[TableKey{row, t.Cols[0]}]; ok {
+			nBase++
+		}
+	}
+	t.Others = make(map[benchproc.Config]*TableSummary)
+	for i, col := range t.Cols {
+		var s TableSummary
+		summarizeCol(sub, col, &s, nBase, i == 0, opts)
+		t.Others[col] = &s
+	}
+}
@@ -0,0 +1,245 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchtab
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/perf/benchproc"
+	"golang.org/x/perf/benchunit"
+	"golang.org/x/perf/cmd/benchstat/internal/texttab"
+)
+
+// toTextTransposed is like ToText, but shows each of t's columns as
+// a row and each of t's rows as a column. Comparisons are still
+// between each of t's columns and its first column, which is now the
+// first row. Summaries, such as the geomean, are shown as the final
+// columns.
+func (t *Table) toTextTransposed(w io.Writer, color bool) error {
+	var o texttab.Table
+
+	// Each benchmark expands to centerCols columns plus deltaCols
+	// columns. The summary expands to sumCols columns.
+	const labelCols = 1
+	centerCols := 3 // <center ±> <CI> <warnings>
+	if t.Opts.NoRange {
+		centerCols-- // No <CI>
+	}
+	if t.Opts.Sparklines {
+		centerCols++ // <sparkline>
+	}
+	if t.Opts.ShowStats {
+		centerCols += len(statsLabels) // <min> <Q1> <median> <Q3> <max> <n>
+	}
+	deltaCols := 3 // <P%> <(p=0.PPP n=N)> <warnings>
+	if t.Opts.ShowEffect {
+		deltaCols++ // <effect>
+	}
+	if t.Opts.ShowSpread {
+		deltaCols++ // <spread>
+	}
+	if t.Opts.EquivMargin > 0 {
+		deltaCols++ // <equiv>
+	}
+	if t.Opts.ShowBayes {
+		deltaCols++ // <bayes>
+	}
+	const sumCols = 3 // <summary> <ratio> <warnings>
+
+	// startCol returns the index of the first column of benchmark
+	// i. startCol(len(t.Rows)) is the first summary column.
+	startCol := func(i int) int {
+		return labelCols + i*(centerCols+deltaCols)
+	}
+
+	// Collect the summaries to show as columns.
+	type summary struct {
+		label string
+		sums  map[benchproc.Config]*TableSummary
+	}
+	var summaries []summary
+	if t.Others != nil {
+		summaries = append(summaries, summary{t.OthersLabel, t.Others})
+	}
+	if t.showSummary() {
+		summaries = append(summaries, summary{t.SummaryLabel, t.Summary})
+	}
+	sumCol := func(i int) int {
+		return startCol(len(t.Rows)) + i*sumCols
+	}
+
+	var warningList []string
+	warningSet := make(map[string]int)
+	warn := func(msgs ...[]error) {
+		var footnotes []string
+		for _, msgs1 := range msgs {
+			for _, msg := range msgs1 {
+				s := msg.Error()
+				i, ok := warningSet[s]
+				if !ok {
+					i = len(warningList)
+					warningSet[s] = i
+					warningList = append(warningList, s)
+				}
+				footnotes = append(footnotes, superscript(i+1))
+			}
+		}
+		s := strings.Join(footnotes, " ")
+		o.Cell(s)
+	}
+
+	// Construct the header from the row configs.
+	hdr, _ := benchproc.NewConfigHeaderOpts(t.Rows, benchproc.ConfigHeaderOpts{MaxLevels: t.Opts.MaxHeaderLevels})
+	rEdge := sumCol(len(summaries))
+	for _, hdrRow := range hdr {
+		o.Row()
+		for _, hdrCell := range hdrRow {
+			l := startCol(hdrCell.Start)
+			r := startCol(hdrCell.Start + hdrCell.Len)
+			o.Col(l).Span(r-l, hdrCell.Value, texttab.Center, texttab.LeftMargin(" │ "))
+		}
+		for i := range summaries {
+			o.Col(sumCol(i)).Span(sumCols, "", texttab.LeftMargin(" │ "))
+		}
+		o.Col(rEdge).Cell("", texttab.LeftMargin(" │"))
+	}
+
+	// Add the unit labels row, set margins, and create stretch
+	// columns.
+	unit := benchunit.DisplayName(t.Unit)
+	o.Row()
+	for i := range t.Rows {
+		l := startCol(i)
+		o.Col(l)
+		o.Span(centerCols, unit, texttab.Center, texttab.LeftMargin(" │ "))
+		o.Span(deltaCols, "vs base", texttab.Left, texttab.LeftMargin("  "))
+		for j := l + 1; j < o.CurCol(); j++ {
+			o.SetShrink(j, true)
+		}
+	}
+	for i, sum := range summaries {
+		o.Col(sumCol(i)).Span(sumCols, sum.label, texttab.Center, texttab.LeftMargin(" │ "))
+	}
+	o.Col(rEdge).Cell("", texttab.LeftMargin(" │"))
+
+	// Label the statistics columns.
+	if t.Opts.ShowStats {
+		o.Row()
+		for i := range t.Rows {
+			o.Col(startCol(i)).Cell("", texttab.LeftMargin(" │ "))
+			o.Col(startCol(i) + centerCols - 1 - len(statsLabels))
+			for _, label := range statsLabels {
+				o.Cell(label, texttab.Right)
+			}
+		}
+		for i := range summaries {
+			o.Col(sumCol(i)).Cell("", texttab.LeftMargin(" │ "))
+		}
+		o.Col(rEdge).Cell("", texttab.LeftMargin(" │"))
+	}
+
+	// Get a common scalar for each benchmark.
+	scalars := make([]benchunit.Scaler, len(t.Rows))
+	for i, row := range t.Rows {
+		scalars[i] = benchunit.CommonScale(t.RowValues(row), t.Class)
+	}
+
+	// Emit measurements.
+	for exp, col := range t.Cols {
+		o.Row()
+		o.Cell(col.StringValues())
+
+		for i, row := range t.Rows {
+			cell, ok := t.Cells[TableKey{row, col}]
+			if !ok {
+				continue
+			}
+
+			o.Col(startCol(i))
+			o.Cell(scalars[i].Format(cell.Summary.Center), texttab.Right)
+			if !t.Opts.NoRange {
+				o.Cell(cell.Summary.PctRangeString(), texttab.Right, texttab.LeftMargin(" ± "))
+			}
+			if t.Opts.Sparklines {
+				o.Cell(sparkline(cell.Sample))
+			}
+			if t.Opts.ShowStats {
+				for _, v := range statsOf(cell.Sample).format(scalars[i]) {
+					o.Cell(v, texttab.Right)
+				}
+			}
+			warn(cell.Sample.Warnings, cell.Summary.Warnings)
+			if exp > 0 && cell.Baseline != nil {
+				d := t.formatDelta(cell)
+				var dOpts []texttab.CellOption
+				if color {
+					if sgr := t.deltaColor(cell); sgr != "" {
+						dOpts = append(dOpts, texttab.Color(sgr))
+					}
+				}
+				o.Cell(d, append(dOpts, texttab.Right)...)
+				o.Cell("(" + cell.Comparison.String() + ")")
+				if t.Opts.ShowEffect {
+					o.Cell(cell.Comparison.FormatEffect(), texttab.Right)
+				}
+				if t.Opts.ShowSpread {
+					o.Cell(cell.Spread.FormatDelta(), texttab.Right)
+				}
+				if t.Opts.EquivMargin > 0 {
+					o.Cell(cell.Equivalence.String())
+				}
+				if t.Opts.ShowBayes {
+					o.Cell(cell.Bayes.String())
+				}
+				warn(cell.Comparison.Warnings, cell.Spread.Warnings, cell.Equivalence.Warnings, cell.Bayes.Warnings)
+			}
+		}
+
+		// Emit summary columns.
+		for i, sum := range summaries {
+			tsum, ok := sum.sums[col]
+			if !ok {
+				continue
+			}
+			o.Col(sumCol(i))
+			if tsum.HasSummary {
+				o.Cell(benchunit.Scale(tsum.Summary, t.Class), texttab.Right)
+			} else {
+				o.Cell("")
+			}
+			if exp > 0 {
+				if tsum.HasRatio {
+					o.Cell(t.formatRatio(tsum), texttab.Right)
+				} else {
+					o.Cell("?")
+				}
+			} else {
+				o.Cell("")
+			}
+			warn(tsum.Warnings)
+		}
+	}
+
+	// Emit table.
+	if err := o.Format(w); err != nil {
+		return err
+	}
+	if t.HiddenRows > 0 {
+		if _, err := fmt.Fprintf(w, "%s\n", t.hiddenNote()); err != nil {
+			return err
+		}
+	}
+
+	// Emit warnings.
+	for i, msg := range warningList {
+		if _, err := fmt.Fprintf(w, "%s %s\n", superscript(i+1), msg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
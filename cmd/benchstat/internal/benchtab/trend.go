@@ -0,0 +1,335 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchtab
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"golang.org/x/perf/benchmath"
+	"golang.org/x/perf/benchproc"
+	"golang.org/x/perf/benchunit"
+	"golang.org/x/perf/cmd/benchstat/internal/texttab"
+)
+
+// A TableTrend is the trend of one row of a Table across its columns,
+// which are taken to be in order, such as by commit or date.
+type TableTrend struct {
+	// Cols are the columns with a cell in this row, in order, and
+	// Cells are the corresponding cells.
+	Cols  []benchproc.Config
+	Cells []*TableCell
+
+	// ChangePoints are the step changes in the sequence of Cells.
+	// The Index of each point is an index into Cols and Cells.
+	ChangePoints benchmath.ChangePoints
+}
+
+// trendRows computes the trend of each of t's rows in t.Trends.
+func (t *Table) trendRows() {
+	t.Trends = make(map[benchproc.Config]*TableTrend)
+	for _, row := range t.Rows {
+		tr := new(TableTrend)
+		var samples []*benchmath.Sample
+		for _, col := range t.Cols {
+			if cell, ok := t.Cells[TableKey{row, col}]; ok {
+				tr.Cols = append(tr.Cols, col)
+				tr.Cells = append(tr.Cells, cell)
+				samples = append(samples, cell.Sample)
+			}
+		}
+		tr.ChangePoints = benchmath.DetectChangePoints(samples)
+		t.Trends[row] = tr
+	}
+}
+
+// centers returns the summary of each cell in tr.
+func (tr *TableTrend) centers() []float64 {
+	vals := make([]float64, len(tr.Cells))
+	for i, cell := range tr.Cells {
+		vals[i] = cell.Summary.Center
+	}
+	return vals
+}
+
+// formatChange returns a description of cp, such as
+// "@c3.txt +5.20% (p=0.001)", naming the first column after the
+// change. This is like ChangePoint.String, but with column labels
+// instead of indexes.
+func (tr *TableTrend) formatChange(cp benchmath.ChangePoint) string {
+	return fmt.Sprintf("@%s %+.2f%% (p=%0.3f)", tr.Cols[cp.Index].StringValues(), (cp.After/cp.Before-1)*100, cp.P)
+}
+
+// changeColor returns the SGR parameters to color a change point
+// from before to after, or "" if it shouldn't be colored.
+func (t *Table) changeColor(before, after float64) string {
+	switch {
+	case t.Better == benchunit.BetterUnknown || before == after:
+		return ""
+	case (after-before)*float64(t.Better) > 0:
+		return sgrBetter
+	}
+	return sgrWorse
+}
+
+// trendLine returns a line of block characters plotting vals, scaled
+// to the range of vals, with a "|" before each index in marks.
+func trendLine(vals []float64, marks []int) string {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range vals {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	var buf strings.Builder
+	for i, v := range vals {
+		for _, m := range marks {
+			if m == i {
+				buf.WriteByte('|')
+			}
+		}
+		level := len(sparkLevels) / 2
+		if hi > lo {
+			level = int((v - lo) / (hi - lo) * float64(len(sparkLevels)-1))
+		}
+		buf.WriteRune(sparkLevels[level])
+	}
+	return buf.String()
+}
+
+// formatTrendDelta returns the change from the first to the last of
+// vals, such as "+5.20%", or "?" if it can't be computed.
+func formatTrendDelta(vals []float64) string {
+	if len(vals) < 2 {
+		return ""
+	}
+	first, last := vals[0], vals[len(vals)-1]
+	if first == last {
+		return "0.00%"
+	}
+	if first == 0 {
+		return "?"
+	}
+	return fmt.Sprintf("%+.2f%%", (last/first-1)*100)
+}
+
+// toTextTrend is like ToText, but shows the trend of each row across
+// t's columns instead of comparing each column with the first. Each
+// row shows its first and last values, a line plotting all of its
+// values with a "|" at each change point, and a list of the change
+// points.
+func (t *Table) toTextTrend(w io.Writer, color bool) error {
+	var o texttab.Table
+
+	var warningList []string
+	warningSet := make(map[string]int)
+	warn := func(msgs ...[]error) {
+		var footnotes []string
+		for _, msgs1 := range msgs {
+			for _, msg := range msgs1 {
+				s := msg.Error()
+				i, ok := warningSet[s]
+				if !ok {
+					i = len(warningList)
+					warningSet[s] = i
+					warningList = append(warningList, s)
+				}
+				footnotes = append(footnotes, superscript(i+1))
+			}
+		}
+		s := strings.Join(footnotes, " ")
+		o.Cell(s)
+	}
+
+	// Each row has <label> <first> <trend> <last> <vs first>
+	// <warnings> <change points>. Both header rows close the value
+	// columns with a "│" in the empty rEdge column.
+	const valueCols = 5
+	const rEdge = 1 + valueCols
+	first, last := "", ""
+	if len(t.Cols) > 0 {
+		first, last = t.Cols[0].StringValues(), t.Cols[len(t.Cols)-1].StringValues()
+	}
+	o.Row()
+	o.Col(1).Span(valueCols, benchunit.DisplayName(t.Unit), texttab.Center, texttab.LeftMargin(" │ "))
+	o.Col(rEdge).Cell("", texttab.LeftMargin(" │"))
+	o.Row()
+	o.Col(1).Cell(first, texttab.Right, texttab.LeftMargin(" │ "))
+	o.Cell("trend", texttab.Center)
+	o.Cell(last, texttab.Right)
+	o.Cell("vs first", texttab.Right)
+	o.Col(rEdge).Cell("", texttab.LeftMargin(" │"))
+	o.Cell("change points")
+
+	for _, row := range t.Rows {
+		tr := t.Trends[row]
+		if len(tr.Cells) == 0 {
+			continue
+		}
+		points := tr.ChangePoints.Points
+
+		var rowColor []texttab.CellOption
+		if color && len(points) == 0 {
+			rowColor = []texttab.CellOption{texttab.Color(sgrDim)}
+		}
+		cellOpts := func(opts ...texttab.CellOption) []texttab.CellOption {
+			return append(opts, rowColor...)
+		}
+
+		o.Row()
+		o.Cell(row.StringValues(), rowColor...)
+
+		vals := tr.centers()
+		scalar := benchunit.CommonScale(vals, t.Class)
+		var marks []int
+		for _, cp := range points {
+			marks = append(marks, cp.Index)
+		}
+		o.Cell(scalar.Format(vals[0]), cellOpts(texttab.Right)...)
+		o.Cell(trendLine(vals, marks), rowColor...)
+		o.Cell(scalar.Format(vals[len(vals)-1]), cellOpts(texttab.Right)...)
+		o.Cell(formatTrendDelta(vals), cellOpts(texttab.Right)...)
+		var msgs [][]error
+		for _, cell := range tr.Cells {
+			msgs = append(msgs, cell.Sample.Warnings)
+		}
+		warn(append(msgs, tr.ChangePoints.Warnings)...)
+
+		// List the change points, separated by commas.
+		o.Col(rEdge + 1)
+		for i, cp := range points {
+			s := tr.formatChange(cp)
+			if i+1 < len(points) {
+				s += ","
+			}
+			var opts []texttab.CellOption
+			if sgr := t.changeColor(cp.Before, cp.After); color && sgr != "" {
+				opts = append(opts, texttab.Color(sgr))
+			}
+			o.Cell(s, opts...)
+		}
+	}
+
+	// Plot the summary, such as the geomean, across columns.
+	if t.showSummary() {
+		var vals []float64
+		var msgs [][]error
+		for _, col := range t.Cols {
+			if tsum, ok := t.Summary[col]; ok && tsum.HasSummary {
+				vals = append(vals, tsum.Summary)
+				msgs = append(msgs, tsum.Warnings)
+			}
+		}
+		if len(vals) > 0 {
+			o.Row()
+			o.Cell(t.SummaryLabel)
+			o.Cell(benchunit.Scale(vals[0], t.Class), texttab.Right)
+			o.Cell(trendLine(vals, nil))
+			o.Cell(benchunit.Scale(vals[len(vals)-1], t.Class), texttab.Right)
+			o.Cell(formatTrendDelta(vals), texttab.Right)
+			warn(msgs...)
+		}
+	}
+
+	if err := o.Format(w); err != nil {
+		return err
+	}
+	if t.HiddenRows > 0 {
+		if _, err := fmt.Fprintf(w, "%s\n", t.hiddenTrendNote()); err != nil {
+			return err
+		}
+	}
+	for i, msg := range warningList {
+		if _, err := fmt.Fprintf(w, "%s %s\n", superscript(i+1), msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hiddenTrendNote returns a note saying how many rows were hidden
+// because they had no change points.
+func (t *Table) hiddenTrendNote() string {
+	if t.HiddenRows == 1 {
+		return "1 benchmark with no change points not shown"
+	}
+	return fmt.Sprintf("%d benchmarks with no change points not shown", t.HiddenRows)
+}
+
+// trendToCSV is like ToCSV, but writes the trend of each row as a
+// time series, with the summary of each column followed by the
+// row's change points.
+func (t *Table) trendToCSV(o *csv.Writer, startRow int, warnings io.Writer) (rowCount int) {
+	var row []string
+	emit := func() {
+		o.Write(row)
+		row = row[:0]
+		rowCount++
+	}
+	warn := func(msgs []error) {
+		cellName := csvCellName(len(row), startRow+rowCount)
+		for _, msg := range msgs {
+			fmt.Fprintf(warnings, "%s: %s\n", cellName, msg)
+		}
+	}
+
+	// Emit column configurations header.
+	colSchema := t.Cols[0].Schema()
+	for _, field := range colSchema.Fields() {
+		row = append(row, "")
+		for _, cfg := range t.Cols {
+			row = append(row, cfg.Get(field))
+		}
+		emit()
+	}
+
+	// Emit column headers.
+	row = append(row, "")
+	for range t.Cols {
+		row = append(row, t.Unit)
+	}
+	row = append(row, "change points")
+	emit()
+
+	// Emit a series for each row.
+	for _, rowCfg := range t.Rows {
+		tr := t.Trends[rowCfg]
+		row = append(row, rowCfg.StringValues())
+		for _, colCfg := range t.Cols {
+			cell, ok := t.Cells[TableKey{rowCfg, colCfg}]
+			if !ok {
+				row = append(row, "")
+				continue
+			}
+			warn(cell.Sample.Warnings)
+			row = append(row, fmt.Sprint(cell.Summary.Center))
+		}
+		warn(tr.ChangePoints.Warnings)
+		var changes []string
+		for _, cp := range tr.ChangePoints.Points {
+			changes = append(changes, tr.formatChange(cp))
+		}
+		row = append(row, strings.Join(changes, "; "))
+		emit()
+	}
+
+	// Emit the summary series.
+	if t.Summary != nil {
+		row = append(row, t.SummaryLabel)
+		for _, colCfg := range t.Cols {
+			tsum, ok := t.Summary[colCfg]
+			if !ok || !tsum.HasSummary {
+				row = append(row, "")
+				continue
+			}
+			warn(tsum.Warnings)
+			row = append(row, fmt.Sprint(tsum.Summary))
+		}
+		emit()
+	}
+
+	return rowCount
+}
@@ -0,0 +1,369 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchtab
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/perf/benchproc"
+	"golang.org/x/perf/benchunit"
+	"golang.org/x/perf/cmd/benchstat/internal/texttab"
+)
+
+// minLabelWidth is the narrowest that toTextWidth will truncate row
+// labels to before trying other layouts.
+const minLabelWidth = 16
+
+// toTextWidth is like ToText, but adapts the layout of t to fit in
+// t.Opts.Width columns. It tries, in order:
+//
+//  1. The usual layout.
+//  2. Truncating long row labels in the middle with "…", if this
+//     doesn't make any two labels the same.
+//  3. Stacking the comparison columns into several tables, each of
+//     which starts with the baseline column.
+//  4. A vertical layout, with each benchmark followed by a line for
+//     each column.
+//
+// If t is transposed, it only tries the first and last of these.
+func (t *Table) toTextWidth(w io.Writer, color bool) error {
+	width := t.Opts.Width
+	t1 := *t
+	t1.Opts.Width = 0
+
+	wide := textWidth(&t1)
+	if wide <= width {
+		return t1.ToText(w, color)
+	}
+	if t.Opts.Transpose {
+		return t1.toTextVertical(w, color)
+	}
+
+	// Truncate row labels by however much the table is too wide.
+	labels := 0
+	for _, row := range t1.Rows {
+		if n := utf8.RuneCountInString(t1.rowLabel(row)); n > labels {
+			labels = n
+		}
+	}
+	limit := labels - (wide - width)
+	if limit < minLabelWidth {
+		limit = minLabelWidth
+	}
+	if limit < labels {
+		t1.labelWidth = limit
+		if t1.labelsDistinct() && textWidth(&t1) <= width {
+			return t1.ToText(w, color)
+		}
+		t1.labelWidth = 0
+	}
+
+	// Stack the comparison columns, putting as many in each table
+	// as fit.
+	var parts []Table
+	for i := 1; i < len(t1.Cols); {
+		part := t1
+		j := i + 1
+		for ; j <= len(t1.Cols); j++ {
+			part.Cols = append([]benchproc.Config{t1.Cols[0]}, t1.Cols[i:j]...)
+			if textWidth(&part) > width {
+				break
+			}
+		}
+		if j == i+1 {
+			// Even one comparison is too wide.
+			return t1.toTextVertical(w, color)
+		}
+		part.Cols = append([]benchproc.Config{t1.Cols[0]}, t1.Cols[i:j-1]...)
+		parts = append(parts, part)
+		i = j - 1
+	}
+	if len(parts) == 0 {
+		// There are no comparisons to stack.
+		return t1.toTextVertical(w, color)
+	}
+	for i := range parts {
+		if i > 0 {
+			if _, err := fmt.Fprintf(w, "\n"); err != nil {
+				return err
+			}
+		}
+		if err := parts[i].ToText(w, color); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// textWidth returns the width of the widest line of t rendered by
+// ToText without color.
+func textWidth(t *Table) int {
+	var buf bytes.Buffer
+	t.ToText(&buf, false)
+	width := 0
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if n := utf8.RuneCountInString(line); n > width {
+			width = n
+		}
+	}
+	return width
+}
+
+// fitLabel truncates a row label to t.labelWidth, if set, by
+// replacing its middle with "…". This keeps both the start and the
+// end of the label, which are usually the most distinctive parts of
+// a benchmark name.
+func (t *Table) fitLabel(label string) string {
+	n := utf8.RuneCountInString(label)
+	if t.labelWidth <= 0 || n <= t.labelWidth {
+		return label
+	}
+	r := []rune(label)
+	head := (t.labelWidth - 1) / 2
+	tail := t.labelWidth - 1 - head
+	return string(r[:head]) + "…" + string(r[n-tail:])
+}
+
+// labelsDistinct reports whether fitLabel keeps distinct row and
+// group labels distinct. Truncating the middle of a label can drop
+// the only part that distinguishes it, such as a sub-benchmark
+// parameter.
+func (t *Table) labelsDistinct() bool {
+	full := make(map[string]string)
+	check := func(label string) bool {
+		fit := t.fitLabel(label)
+		if prev, ok := full[fit]; ok && prev != label {
+			return false
+		}
+		full[fit] = label
+		return true
+	}
+	for _, row := range t.Rows {
+		for level := 0; level < t.GroupLevels; level++ {
+			if !check(groupIndent(level) + row.Fields()[level].Value) {
+				return false
+			}
+		}
+		if !check(t.rowLabel(row)) {
+			return false
+		}
+	}
+	return true
+}
+
+// toTextVertical renders t with each benchmark on its own line,
+// followed by a line for each column. This is much narrower than
+// ToText's layout, since it's only as wide as one comparison and row
+// labels are truncated to t.Opts.Width.
+func (t *Table) toTextVertical(w io.Writer, color bool) error {
+	t1 := *t
+	t1.labelWidth = t.Opts.Width
+	t = &t1
+
+	var o texttab.Table
+
+	const labelCols = 1
+	centerCols := 3 // <center ±> <CI> <warnings>
+	if t.Opts.NoRange {
+		centerCols-- // No <CI>
+	}
+	if t.Opts.Sparklines {
+		centerCols++ // <sparkline>
+	}
+	if t.Opts.ShowStats {
+		centerCols += len(statsLabels) // <min> <Q1> <median> <Q3> <max> <n>
+	}
+	deltaCols := 3 // <P%> <(p=0.PPP n=N)> <warnings>
+	if t.Opts.ShowEffect {
+		deltaCols++ // <effect>
+	}
+	if t.Opts.ShowSpread {
+		deltaCols++ // <spread>
+	}
+	if t.Opts.EquivMargin > 0 {
+		deltaCols++ // <equiv>
+	}
+	if t.Opts.ShowBayes {
+		deltaCols++ // <bayes>
+	}
+	allCols := labelCols + centerCols + deltaCols
+
+	var warningList []string
+	warningSet := make(map[string]int)
+	warn := func(msgs ...[]error) {
+		var footnotes []string
+		for _, msgs1 := range msgs {
+			for _, msg := range msgs1 {
+				s := msg.Error()
+				i, ok := warningSet[s]
+				if !ok {
+					i = len(warningList)
+					warningSet[s] = i
+					warningList = append(warningList, s)
+				}
+				footnotes = append(footnotes, superscript(i+1))
+			}
+		}
+		s := strings.Join(footnotes, " ")
+		o.Cell(s)
+	}
+
+	// Show the unit over the values and label the comparisons.
+	o.Row()
+	o.Col(labelCols).Span(centerCols, benchunit.DisplayName(t.Unit), texttab.Center, texttab.LeftMargin("  "))
+	o.Span(deltaCols, "vs base", texttab.Left, texttab.LeftMargin("  "))
+	for j := labelCols + 1; j < allCols-1; j++ {
+		o.SetShrink(j, true)
+	}
+	if t.Opts.ShowStats {
+		o.Row()
+		o.Col(labelCols + centerCols - 1 - len(statsLabels))
+		for _, label := range statsLabels {
+			o.Cell(label, texttab.Right)
+		}
+	}
+
+	// colLabel returns the label of column exp, indented under the
+	// benchmark's label.
+	colLabel := func(exp int) string {
+		return "  " + t.Cols[exp].StringValues()
+	}
+
+	// summaryBlock emits a summary, such as the geomean, followed by
+	// a line for each column.
+	summaryBlock := func(label string, sums map[benchproc.Config]*TableSummary) {
+		o.Row()
+		o.Span(allCols, label)
+		for exp, col := range t.Cols {
+			tsum, ok := sums[col]
+			if !ok {
+				continue
+			}
+			o.Row()
+			o.Cell(colLabel(exp))
+			if tsum.HasSummary {
+				o.Cell(benchunit.Scale(tsum.Summary, t.Class), texttab.Right)
+			}
+			if exp > 0 {
+				o.Col(labelCols + centerCols)
+				if tsum.HasRatio {
+					o.Cell(t.formatRatio(tsum), texttab.Right)
+				} else {
+					o.Cell("?")
+				}
+			}
+			o.Col(allCols - 1)
+			warn(tsum.Warnings)
+		}
+	}
+
+	// Emit measurements.
+	var prev benchproc.Config
+	for i, row := range t.Rows {
+		for level := t.groupStart(prev, row); level < t.GroupLevels; level++ {
+			o.Row()
+			o.Span(allCols, t.fitLabel(groupIndent(level)+row.Fields()[level].Value))
+		}
+		prev = row
+
+		var rowColor []texttab.CellOption
+		if color && !t.rowChanged(row) {
+			rowColor = []texttab.CellOption{texttab.Color(sgrDim)}
+		}
+		cellOpts := func(opts ...texttab.CellOption) []texttab.CellOption {
+			return append(opts, rowColor...)
+		}
+
+		o.Row()
+		o.Span(allCols, t.fitLabel(t.rowLabel(row)), rowColor...)
+
+		scalar := benchunit.CommonScale(t.RowValues(row), t.Class)
+		for exp, col := range t.Cols {
+			cell, ok := t.Cells[TableKey{row, col}]
+			if !ok {
+				continue
+			}
+
+			o.Row()
+			o.Cell(colLabel(exp), rowColor...)
+			o.Cell(scalar.Format(cell.Summary.Center), cellOpts(texttab.Right)...)
+			if !t.Opts.NoRange {
+				o.Cell(cell.Summary.PctRangeString(), cellOpts(texttab.Right, texttab.LeftMargin(" ± "))...)
+			}
+			if t.Opts.Sparklines {
+				o.Cell(sparkline(cell.Sample), rowColor...)
+			}
+			if t.Opts.ShowStats {
+				for _, v := range statsOf(cell.Sample).format(scalar) {
+					o.Cell(v, cellOpts(texttab.Right)...)
+				}
+			}
+			warn(cell.Sample.Warnings, cell.Summary.Warnings)
+			if exp > 0 && cell.Baseline != nil {
+				dOpts := cellOpts(texttab.Right)
+				if color {
+					if sgr := t.deltaColor(cell); sgr != "" {
+						dOpts = append(dOpts, texttab.Color(sgr))
+					}
+				}
+				o.Cell(t.formatDelta(cell), dOpts...)
+				o.Cell("("+cell.Comparison.String()+")", rowColor...)
+				if t.Opts.ShowEffect {
+					o.Cell(cell.Comparison.FormatEffect(), cellOpts(texttab.Right)...)
+				}
+				if t.Opts.ShowSpread {
+					o.Cell(cell.Spread.FormatDelta(), cellOpts(texttab.Right)...)
+				}
+				if t.Opts.EquivMargin > 0 {
+					o.Cell(cell.Equivalence.String(), rowColor...)
+				}
+				if t.Opts.ShowBayes {
+					o.Cell(cell.Bayes.String(), rowColor...)
+				}
+				warn(cell.Comparison.Warnings, cell.Spread.Warnings, cell.Equivalence.Warnings, cell.Bayes.Warnings)
+			}
+		}
+
+		// Summarize any row groups that end with this row,
+		// innermost first.
+		end := 0
+		if i+1 < len(t.Rows) {
+			end = t.groupStart(row, t.Rows[i+1])
+		}
+		for level := t.GroupLevels - 1; level >= end; level-- {
+			if sums, ok := t.groupSums[t.groupKey(row, level)]; ok {
+				summaryBlock(groupIndent(level+1)+t.SummaryLabel, sums)
+			}
+		}
+	}
+
+	// Emit summaries.
+	if t.Others != nil {
+		summaryBlock(t.OthersLabel, t.Others)
+	}
+	if t.showSummary() {
+		summaryBlock(t.SummaryLabel, t.Summary)
+	}
+
+	// Emit table.
+	if err := o.Format(w); err != nil {
+		return err
+	}
+	if t.HiddenRows > 0 {
+		if _, err := fmt.Fprintf(w, "%s\n", t.hiddenNote()); err != nil {
+			return err
+		}
+	}
+	for i, msg := range warningList {
+		if _, err := fmt.Fprintf(w, "%s %s\n", superscript(i+1), msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
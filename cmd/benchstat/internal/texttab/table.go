@@ -30,6 +30,7 @@ type textCell struct {
 	value          string
 	leftMargin     string
 	alignment      align
+	color          string
 }
 
 type CellOption func(c *textCell)
@@ -40,6 +41,16 @@ func LeftMargin(x string) CellOption {
 	}
 }
 
+// Color sets the ANSI SGR (Select Graphic Rendition) parameters used
+// to display a cell, such as "32" for green or "2" for dim. The
+// escape sequences don't count toward the width of the cell, and
+// only apply to the cell's contents, not its left margin.
+func Color(sgr string) CellOption {
+	return func(c *textCell) {
+		c.color = sgr
+	}
+}
+
 var (
 	Left   CellOption = func(c *textCell) { c.alignment = alignLeft }
 	Center            = func(c *textCell) { c.alignment = alignCenter }
@@ -103,7 +114,7 @@ func (t *Table) Span(cols int, value string, opts ...CellOption) *Table {
 		// to no left margin.
 		lMargin = ""
 	}
-	t.cells = append(t.cells, textCell{t.curRow, t.curCol, cols, value, lMargin, alignLeft})
+	t.cells = append(t.cells, textCell{t.curRow, t.curCol, cols, value, lMargin, alignLeft, ""})
 	for _, o := range opts {
 		o(&t.cells[len(t.cells)-1])
 	}
@@ -280,10 +291,13 @@ func (t *Table) Format(w io.Writer) error {
 
 		// Print cell contents.
 		s := cell.alignment.lpad(cell.value, tw)
+		off += utf8.RuneCountInString(s)
+		if cell.color != "" {
+			s = "\x1b[" + cell.color + "m" + s + "\x1b[0m"
+		}
 		if _, err := fmt.Fprintf(w, "%s", s); err != nil {
 			return err
 		}
-		off += utf8.RuneCountInString(s)
 	}
 	if len(t.cells) > 0 {
 		if _, err := fmt.Fprintf(w, "\n"); err != nil {
@@ -61,6 +61,11 @@ func TestTable(t *testing.T) {
 	tab.Row().Cell("e").Cell("f", LeftMargin("|"))
 	check("a  b\nc  d\ne |f\n")
 
+	// Colors don't count toward width.
+	tab.Row().Cell("a", Color("32")).Cell("b")
+	tab.Row().Cell("long").Cell("c", Right, Color("2"))
+	check("\x1b[32ma\x1b[0m    b\nlong \x1b[2mc\x1b[0m\n")
+
 	// Missing cell in the middle.
 	tab.Row().Cell("a").Col(2).Cell("c")
 	tab.Row().Cell("d").Cell("e").Cell("f")
@@ -126,6 +126,19 @@
 // of them increases by a factor of 2, then the sec/op geomean will
 // increase by a factor of ⁿ√2.
 //
+// The -summary flag selects a different statistic for the last row:
+// "mean", "median", or "sum", which are often more meaningful for
+// sizes and counts, or "none" to omit the row. For these, the change
+// compares the statistic of each column with that of the baseline,
+// using only the benchmarks present in both.
+//
+// When results span several tables, such as one per package, the
+// -overall flag adds a final "overall" table with one row per unit.
+// Each row summarizes every benchmark with that unit across all of the
+// tables, including rows hidden by -changed or -top, so a run ends
+// with one headline change per metric. -overall only applies to the
+// text format.
+//
 //
 // Configuring comparisons
 //
@@ -135,6 +148,12 @@
 // as the benchfilter tool (see
 // https://pkg.go.dev/golang.org/x/perf/cmd/benchfilter).
 //
+// The most common filter is to show only some units. The -unit flag
+// does this more concisely than a ".unit" filter. It accepts a
+// comma-separated list of units, such as "-unit sec/op,B/op", and may
+// be repeated. Units may be given as they appear in benchmark output,
+// such as "ns/op", or as benchstat shows them, such as "sec/op".
+//
 // After filtering, it treats its inputs as a multi-dimensional
 // database, where each benchmark result is associated with its name,
 // file-level configuration, and sub-name configuration. These
@@ -146,9 +165,12 @@
 // 	.name         - The base name of a benchmark
 // 	.fullname     - The full name of a benchmark (including configuration)
 // 	.label        - The name of the input file or user-provided file label
+// 	.file-index   - The position of the input file on the command line
+// 	.iters        - The iteration count of a result
 // 	/{name-key}   - Per-benchmark sub-name configuration key
 // 	{file-key}    - File-level configuration key
 //	.config       - All file-level configuration keys
+//	.namekeys     - All sub-name configuration keys
 //
 // A projection is a comma- or space-separated list of dimensions,
 // each of which may have an optional sort order. See
@@ -171,6 +193,56 @@
 // an input argument of the form "label=path" instead of just "path".
 // This is particularly useful for shortening long file names.
 //
+// If the row projection has more than one field, such as
+// "-row pkg,.name", text output groups rows by all but the last
+// field, with one level of nesting per field. Each group is labeled
+// on its own line, its rows are indented under it, and each group of
+// more than one row ends with its own summary row, such as a
+// per-package geomean. Other output formats show a single flattened
+// label for each row.
+//
+// By default, benchstat compares each column with the first column.
+// The -base flag selects a different baseline column by its value,
+// such as "-base new.txt", or "-base gob" with "-col /format". The
+// baseline column is moved to the front of each table, and the other
+// columns keep their order. Tables without a matching column still
+// compare with their first column.
+//
+// If the column projection has many fields, each field normally gets
+// its own header row. The -header-levels flag limits the number of
+// header rows; any remaining fields are merged into the last row as
+// "key=value" pairs.
+//
+// With many inputs and few benchmarks, tables can become very wide.
+// The -transpose flag swaps the rows and columns of text output, so
+// each column becomes a row and each benchmark becomes a column.
+// Comparisons are still against the first column, which is now the
+// first row, and summaries such as the geomean appear as the final
+// columns. -transpose only applies to the text format.
+//
+// Given many inputs in order, such as from a series of nightly runs,
+// the -trend flag shows how each benchmark evolved rather than
+// comparing each input with the first. The columns are the series, in
+// the order of the column projection, so "-col .file" uses the order
+// of the files and "-col /commit@commit" orders commits with
+// -commits. Each benchmark is shown with its first and last values,
+// a line plotting every value, and its change points: the inputs
+// where the benchmark stepped to a new level, found with a
+// permutation test at the -alpha level. Each change point is marked
+// in the line with "|". In CSV output, each benchmark is a row
+// giving the series of values followed by its change points. With
+// -trend, -changed shows only benchmarks with change points.
+//
+// When writing to a terminal, benchstat fits text output to the
+// width of the terminal, or to the COLUMNS environment variable if
+// it's set. The -width flag sets a different width, or "-width -1"
+// disables this. A table that's too wide first has its row labels
+// shortened in the middle with "…", unless that would make two
+// labels the same. If that isn't enough, its comparisons are split
+// across several tables that each repeat the baseline column. Failing
+// that, each benchmark is shown on its own line, followed by a line
+// for each column.
+//
 // When projections overlap, benchstat assigns dimensions to the most
 // specific projection. For example, if the table projection is the
 // full file-level configuration ".config", and the column projection
@@ -180,6 +252,12 @@
 // Finally, the -ignore projection tells benchstat to group results
 // *despite* any differences in the ignored keys.
 //
+// The filter and all of the projections can also be given as a
+// single -query, such as "FILTER /align:0 ROW /size COL /poly". The
+// query's FILTER, TABLE, ROW, COL, and IGNORE clauses override the
+// corresponding flags, and omitted clauses take their value from the
+// flags.
+//
 //
 // Projection example
 //
@@ -237,11 +315,11 @@
 //	       │    new.txt     │
 //	       │     sec/op     │
 //	Encode   2.253µ ± 37% ¹
-//	¹ benchmarks vary in .fullname
+//	¹ benchmarks vary in /format
 //
 // Since this is probably not a meaningful comparison, benchstat warns
 // that the benchmarks it grouped together vary in a hidden dimension.
-// If this really were our intent, we could -ignore .fullname.
+// If this really were our intent, we could -ignore /format.
 //
 //
 // Sorting
@@ -253,12 +331,37 @@
 // {key}@{order} - specifies one of the built-in named sort orders.
 // This can be "alpha" or "num" for alphabetic or numeric sorting.
 // "num" understands basic use of metric and IEC prefixes like "2k"
-// and "1Mi".
+// and "1Mi", and Go durations like "1m30s". "duration" sorts Go
+// durations. "natural" sorts alphabetically, but compares embedded
+// numbers numerically, so "run2" sorts before "run10". "date" sorts
+// timestamps such as "2021-01-02" or Unix seconds chronologically.
+// "semver" sorts semantic versions like "v1.2.10". "goversion" sorts
+// Go toolchain versions like "go1.21.3", followed by development
+// builds.
 //
 // {key}@({value} {value} ...) - specifies a fixed value order for
 // key. It also specifies a filter: if key has a value that isn't any
 // of the specified values, the result is filtered out.
 //
+// {key}@bin({bound} {bound} ...) - groups numeric values of key into
+// buckets with the given boundaries. For example, "/size@bin(1k 1M)"
+// groups sizes into "<1k", "1k-1M", and ">=1M".
+//
+// {key}@commit - sorts commit hashes in repository order, oldest
+// first. This requires the -commits flag, which gives either a path
+// to a git repository, in which case benchstat orders commits
+// reachable from HEAD topologically, or a file listing commit hashes
+// one per line, oldest first. Abbreviated hashes are supported.
+//
+// {key}@window({size}) - groups timestamps into fixed time windows
+// of "hour", "day", "week", or a Go duration like "6h". For example,
+// "-col date@window(day)" shows one column per day. An optional
+// anchor timestamp aligns the windows to start at that time, as in
+// '-col date@window(day "2021-03-01T09:00:00Z")'.
+//
+// {key}@-{order} or {key}@{order}:desc - reverses a named or fixed
+// sort order. For example, "/size@-num" puts the largest sizes first.
+//
 // For example, we can use a fixed order to compare the improvement of
 // json over gob rather than the other way around:
 //
@@ -270,6 +373,18 @@
 //	       │   sec/op    │   sec/op     vs base                │
 //	Encode   3.070µ ± 2%   1.423µ ± 1%  -53.66% (p=0.000 n=10)
 //
+// Rather than sorting rows by their configuration, the -sort flag
+// can sort the rows of each table by their results. "-sort delta"
+// puts the rows with the largest change from the base column first,
+// whether it's an increase or a decrease, so the biggest regressions
+// and improvements float to the top of large tables. Changes are
+// measured by ratio, so halving counts the same as doubling. "-sort
+// value" sorts by the value in the first column, largest first, and
+// "-sort name" sorts rows alphabetically. Rows with nothing to sort
+// by, such as rows without comparisons for "-sort delta", come last.
+// The -reverse flag reverses the order of the rows, including the
+// default order.
+//
 //
 // Units
 //
@@ -293,6 +408,155 @@
 // show A/B comparisons even if there's only one before and after
 // measurement.
 //
+// Units that count discrete events, such as allocs/op, can set
+// "assume=count". Like "assume=exact", if every measurement is the
+// same, benchstat reports that value with no uncertainty, so a change
+// from 3 to 4 allocations is reported as a precise +33.33%. If the
+// counts vary, benchstat summarizes them using the mean with a
+// Poisson confidence interval and compares them using an exact test
+// of Poisson rates.
+//
+// Units whose measurements are known to be normally distributed can
+// set "assume=normal". For these units, benchstat summarizes samples
+// using the mean and a confidence interval derived from the standard
+// error, and compares them using Welch's t-test. This is more
+// statistically powerful than the default, but can be misleading if
+// the measurements aren't actually normal.
+//
+// Latencies and similar measurements are often right-skewed, with a
+// long tail of slow values. For these, "assume=lognormal" is usually
+// a better fit. This summarizes samples using the geometric mean
+// with a multiplicative confidence interval and compares the
+// logarithms of the measurements using Welch's t-test.
+//
+// With very few samples, the default non-parametric statistics can't
+// produce a meaningful confidence interval or comparison at all. For
+// these cases, "assume=bootstrap" (median) or "assume=bootstrap-mean"
+// estimates intervals and p-values by bootstrap resampling. These are
+// less reliable than the other methods with few samples, but are
+// better than nothing.
+//
+// For measurements that are roughly symmetric but have heavy tails,
+// "assume=trimmed" summarizes samples using the 20% trimmed mean,
+// which discards the highest and lowest 20% of values, and
+// "assume=winsorized" uses the 20% winsorized mean, which instead
+// clamps them to the most extreme remaining values. Both compare
+// samples using Yuen's test on trimmed means. These are less
+// sensitive to occasional extreme values than the mean, but use more
+// of the sample than the median.
+//
+// When the tail of a distribution matters more than its center, such
+// as for tail latency, "assume=pN" summarizes samples by their N'th
+// percentile. For example, "assume=p90" or "assume=p99.9". The
+// confidence interval is computed from order statistics, so extreme
+// percentiles need many samples to get a finite interval. A/B
+// comparisons test whether the percentiles differ using a bootstrap
+// test.
+//
+// The -assume flag sets the assumption for all units that don't
+// specify their own "assume" metadata. It accepts any of the above
+// values.
+//
+// benchstat scales values of units that measure bytes, such as "B/op",
+// using binary prefixes like "Ki", and all other units using SI
+// prefixes like "k". A unit can override this with "class=binary" or
+// "class=decimal" metadata.
+//
+// Units can also differ in how much evidence a change should need.
+// The -unit-alpha flag overrides the -alpha significance level for
+// specific units. It accepts a comma-separated list of unit=α pairs,
+// such as "-unit-alpha sec/op=0.01,B/op=0.1".
+//
+//
+// Output formats
+//
+// By default, benchstat prints results as plain text tables. When
+// writing to a terminal, benchstat colors statistically significant
+// changes green if they are improvements and red if they are
+// regressions, and dims benchmarks that didn't change. Whether higher
+// or lower is better is determined by the unit's "better" metadata
+// (for example, "Unit pages/op better=lower"), or inferred for common
+// units such as sec/op, B/op, and B/s. Changes in units where it
+// can't be determined aren't colored. The -color flag controls this:
+// "-color always" and "-color never" override the default of "auto".
+// Color can also be disabled by setting the NO_COLOR environment
+// variable.
+//
+// Without color, it can be hard to tell at a glance whether a change
+// is good: +115% is an improvement in B/s but a regression in sec/op.
+// "-delta-style annotated" follows each significant change with
+// "better" or "worse", such as "+115.00% better". "-delta-style
+// normalized" instead flips the sign of changes in units where lower
+// is better, so improvements are always positive and regressions are
+// always negative. For example, a 17.20% drop in sec/op is shown as
+// "+17.20%". Both use the same "better" metadata as color, and leave
+// changes in other units as they are.
+//
+// The -format flag selects another format. "-format csv" prints
+// comma-separated values, with warnings written to stderr. "-format
+// tsv" is the same, but separates values with tabs, which is easier
+// to process with tools like awk and cut. "-format json" prints the
+// full results as a JSON object, which is useful for dashboards and
+// bots. The object has a "tables" list; each table gives its
+// "config", "unit", "assumption", the configuration of its
+// "columns", its "rows", and a "summary" of each column, described by
+// "summaryLabel". Each row has a "name", a "config", and a list of
+// "cells" aligned with the columns, where missing cells are null. Each cell gives its
+// "center", the "lo" and "hi" bounds of its confidence interval, its
+// "confidence" level, the number of results "n", and any "warnings".
+// Cells other than the base column also have a "comparison" giving
+// the "ratio" to the base, the "delta" as shown in text output, the
+// "p" value, the "alpha" it was tested at, whether the change is
+// "significant", whether it's "negligible" because of
+// -delta-threshold, the sample sizes "n1" and "n2", the "test" used,
+// and any "warnings". With -top, each table also has an "others" list
+// summarizing the omitted rows, described by "othersLabel". Numbers
+// are not rounded, and non-finite numbers are null.
+//
+// "-format latex" prints each table as a LaTeX tabular environment
+// for inclusion in papers. The tables use rules from the booktabs
+// package, so the document must include "\usepackage{booktabs}".
+// Special characters are escaped, table configuration is written as
+// LaTeX comments, and warnings are listed as numbered notes after
+// each table.
+//
+// "-format html-interactive" prints a self-contained HTML page for
+// sharing large comparisons. Each table can be collapsed, clicking a
+// column header sorts the rows by that column, and a search box
+// filters benchmarks by a regular expression. Each cell includes a
+// small plot of the distribution of its results, with a tick at the
+// summary value, and significant changes are colored as in text
+// output.
+//
+// For other formats, such as wiki markup or chat messages, "-format
+// template" executes a Go text/template (see
+// https://pkg.go.dev/text/template) read from the file given by
+// -template. The template's data has the same structure as the JSON
+// output, with each key capitalized to form a field name. Each table
+// also has a Scale method that formats a value like text output does,
+// and templates can use the "pct" function to format a ratio as a
+// percent change and the "join" function, which joins a list of
+// strings with a separator. For example, this template prints a
+// line for each comparison:
+//
+//	{{range .Tables}}{{$t := .}}{{range .Rows}}{{$r := .}}{{range .Cells}}{{with .Comparison}}
+//	{{$r.Name}} {{$t.Unit}}: {{.Delta}} (p={{printf "%.3f" .P}}){{end}}{{end}}{{end}}{{end}}
+//
+// The -o flag writes each table to a separate file, rather than
+// writing all tables to stdout, and prints the path of each file. This
+// is useful for archiving results, for example, one file per package.
+// By default, the flag's value is a directory, and benchstat names
+// each file after the values of the table's configuration and the
+// output format, such as
+// "goos=linux,goarch=amd64,pkg=example.com_foo,unit=sec_op.txt". If
+// the value contains "{{", it's instead a Go text/template that
+// computes each file's path from the table's configuration. Keys that
+// begin with "." must be accessed with index. For example, "-o
+// 'out/{{.pkg}}/{{index . ".unit"}}.csv'" creates a directory for each
+// package. If several tables map to the same path, benchstat adds a
+// number to the names of all but the first. Directories are created
+// as needed.
+//
 //
 // Tips
 //
@@ -325,26 +589,225 @@
 // As an extension of this, if you compare a large number of
 // benchmarks, you should expect that about 5% of them will report a
 // statistically significant change even if there is no difference
-// between the before and after.
+// between the before and after. The -correction flag adjusts p-values
+// to account for this. With "-correction holm", benchstat applies the
+// Holm–Bonferroni correction across all comparisons it reports, so
+// the chance of reporting *any* spurious change is at most ɑ. This
+// is conservative, so real but small changes may be reported as "~".
+// With "-correction fdr", benchstat instead controls the false
+// discovery rate using the Benjamini–Hochberg procedure: on average,
+// at most a fraction ɑ of the reported changes are spurious. In this
+// mode, benchstat reports q-values (e.g., "q=0.012") instead of
+// p-values. This is usually a better fit for large benchmark suites.
+//
+// In a large sweep, most benchmarks usually don't change, which can
+// make the ones that did hard to find. The -changed flag shows only
+// benchmarks with a statistically significant change in at least one
+// column, and notes how many were hidden. The geomean row still
+// summarizes all benchmarks. Combine this with -correction so the
+// remaining rows aren't dominated by spurious changes.
+//
+// For a summary like a nightly report, "-top N" shows only the N
+// benchmarks with the worst statistically significant regressions
+// and the N with the best statistically significant improvements in
+// each table, worst regression first. All other benchmarks are
+// combined into a single row, such as "12 others", which gives the
+// geomean of their values and of their changes. Whether a change is a
+// regression depends on whether higher or lower is better for the
+// unit (see "Output formats"); if that isn't known, increases are
+// considered regressions.
+//
+// To choose a number of runs more precisely, decide on the smallest
+// change you care about and use the -detect flag. For example, with
+// "-detect 1", for each comparison that isn't significant, benchstat
+// estimates how many runs are needed to detect a 1% change with 80%
+// probability given the noise in the base measurements, as well as
+// the smallest change the current number of runs can detect. These
+// estimates are based on a normal approximation and are only a guide.
+// Choose the number of runs *before* collecting the measurements you
+// will compare.
+//
+// p-values are notoriously easy to misinterpret. The -bayes flag adds
+// a column giving the probability that each median is lower than
+// the base median, along with a credible interval for their ratio
+// at the -confidence level, such as "Pr(lower)=97% ratio=0.95
+// (0.93–0.98)". Unlike a confidence interval, a credible interval
+// can be read directly: there's a 95% probability that the true
+// ratio is in the interval. These are computed using the Bayesian
+// bootstrap, which makes no distributional assumptions.
+//
+// A "~" in the delta column means benchstat failed to find a
+// difference, which isn't the same as finding that there is no
+// difference: there may simply be too few runs or too much noise. To
+// positively confirm that a change is no larger than some margin, use
+// the -equiv flag. For example, "-equiv 2" tests whether each column
+// is equivalent to the base column within ±2% using two one-sided
+// tests (TOST), and reports "equiv" or "not equiv" for each
+// comparison, along with the p-value.
+//
+// If you run the before and after benchmarks interleaved (for example,
+// alternating runs of each binary), each before run is naturally
+// paired with the after run next to it, and both runs of a pair are
+// affected by the same slow changes in machine conditions. The
+// -paired flag compares columns using a paired test that pairs the
+// i'th result for each benchmark in each column. With the default
+// assumptions, this is the Wilcoxon signed-rank test; with
+// "assume=normal", it is a paired t-test. Paired tests can be much
+// more sensitive than the default tests, but are only valid if the
+// runs really were interleaved; every column must have the same
+// number of results for each benchmark.
+//
+// Sometimes the goal of a change is to reduce noise rather than to
+// improve the typical value, for example to reduce jitter. The
+// -spread flag adds a column comparing the spread of each sample
+// with the base column using the Brown–Forsythe test, which compares
+// the mean absolute deviations from the median. If the difference is
+// significant, it reports "noisier" or "less noisy" along with the
+// ratio of the spreads; otherwise it reports "~".
+//
+// benchstat's comparisons test for a change in the center of a
+// distribution, such as the median. A change that affects only the
+// spread or the tail of a distribution, such as one that doubles tail
+// latency but leaves the median unchanged, will be reported as "~".
+// The -shape-alpha flag additionally compares the whole distributions
+// using the Kolmogorov–Smirnov test and warns if they differ even
+// though the centers don't. For example, "-shape-alpha 0.05" warns
+// if the KS test p-value is less than 0.05.
+//
+// A single anomalous run, such as one affected by thermal throttling
+// or a background process, can widen confidence intervals enough to
+// hide real changes. The -outliers flag detects such values using
+// either Tukey's fences ("tukey") or the median absolute deviation
+// ("mad"), and reports how many were found in each sample. Adding
+// -trim-outliers excludes them from summaries and comparisons. It's
+// better to fix the source of the noise, and trimming should be used
+// with care: it makes results look more certain than they are if the
+// "outliers" are really part of the benchmark's behavior.
+//
+// benchstat's tests assume that each run of a benchmark is
+// independent of the others, but a warming file cache or a thermal
+// ramp can make results drift over the course of a run, and slow
+// changes in machine conditions can make consecutive runs more alike.
+// The -drift-alpha flag checks the results for each benchmark, in the
+// order they appear in the input, for a monotonic trend using the
+// Mann-Kendall test and for dependence between consecutive runs using
+// the Wald-Wolfowitz runs test. If either test's p-value is less than
+// the given alpha, benchstat warns and, if it can find one, suggests
+// how many of the last runs appear stable. With -drift-alpha,
+// benchstat also warns about comparisons where all of the runs of one
+// column came before all of the runs of the other, such as when each
+// column is a separate file, since any drift between the two blocks
+// of runs would look like a difference.
+//
+// A sample with two clear modes, such as from grouping together
+// benchmarks that differ in a hidden dimension, has a median that
+// describes neither mode. The -bimodality flag warns about samples
+// that split into two groups whose separation, measured by Ashman's
+// D, is greater than the given value. A value of 8 rarely flags
+// unimodal samples, even skewed ones.
+//
+// A statistically significant change isn't necessarily an important
+// one: with enough runs, even a tiny change will be significant. The
+// -effect flag adds an effect size to each comparison, which
+// measures how large the change is relative to the noise in the
+// measurements, independent of the number of runs. With the default
+// assumptions, this is Cliff's delta (e.g., "δ=+0.42"), which ranges
+// from -1 to +1 and conventionally is negligible below about 0.15 and
+// large above about 0.47. With "assume=normal" and "assume=lognormal",
+// this is Cohen's d (e.g., "d=+1.20"), which is negligible below about
+// 0.2 and large above about 0.8.
+//
+// Similarly, in reports that are compared over time, tiny but real
+// changes can be distracting. The -delta-threshold flag shows
+// significant changes smaller than the given percent as "~", and
+// treats them as unchanged for -changed, -top, and colors. For
+// example, "-delta-threshold 0.5" hides changes within ±0.5%. The
+// p-value is still shown.
+//
+// The percent change in the delta column is only an estimate. The
+// -delta-ci flag adds a confidence interval for the change at the
+// -confidence level, such as "-17.20% [-19.10%, -15.00%]". This is
+// computed according to the distributional assumption of each unit;
+// with the default assumption, it's a bootstrap interval for the
+// ratio of the medians. -delta-ci also adds an interval to the change
+// in the geomean. This interval reflects how much the changes vary
+// between benchmarks, rather than the noise in each benchmark, so a
+// wide interval means the benchmarks changed by very different
+// amounts.
+//
+// For narrow terminals, or for CSV consumers that only want point
+// estimates, "-confidence none" omits the "± x%" range of each
+// summary, like the -norange flag of older versions of benchstat.
+// Comparisons are unaffected, and -delta-ci still uses a 95% level.
+//
+// A summary and its range don't show the shape of a sample. The
+// -sparklines flag adds a small histogram of each sample, including
+// any outliers, after its summary, such as "1.718µ ± 1% █ ▅▂   ▂".
+// Each bar is a bin across the range of the sample, so gaps reveal
+// bimodal samples and lone bars at either end reveal outliers.
+// -sparklines only applies to the text format.
+//
+// For a closer look at measurement quality, the -stats flag adds
+// columns giving the minimum, quartiles, maximum, and count of each
+// sample, after any trimmed outliers are removed. These use the same
+// scale as the summary in text output and full precision in CSV and
+// TSV output.
+//
+// In continuous integration, the -fail-on flag makes benchstat exit
+// with status 1 if any statistically significant change exceeds a
+// threshold. For example, "-fail-on sec/op>+2%,B/s<-5%" fails if any
+// benchmark got more than 2% slower or lost more than 5% throughput.
+// benchstat prints the table as usual and then lists each offending
+// benchmark on standard error. Rows hidden by -changed or -top are
+// still checked. benchstat also exits with status 1 on other errors.
 package main
 
 import (
+	"bytes"
 	"flag"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
 
 	"golang.org/x/perf/benchfmt"
 	"golang.org/x/perf/benchmath"
 	"golang.org/x/perf/benchproc"
+	"golang.org/x/perf/benchunit"
 	"golang.org/x/perf/cmd/benchstat/internal/benchtab"
 )
 
-// TODO: Add a flag to perform Holm–Bonferroni correction.
+// unitList is a flag.Value that collects a comma-separated list of
+// units, tidied so that both tidied and original unit names match.
+// It may be given more than once.
+type unitList map[string]bool
 
-// TODO: -unit flag.
+func (l unitList) String() string {
+	var units []string
+	for unit := range l {
+		units = append(units, unit)
+	}
+	sort.Strings(units)
+	return strings.Join(units, ",")
+}
 
-// TODO: Support sorting by commit order.
+func (l unitList) Set(s string) error {
+	for _, unit := range strings.Split(s, ",") {
+		unit = strings.TrimSpace(unit)
+		if unit == "" {
+			return fmt.Errorf("empty unit in %q", s)
+		}
+		tidied, _ := benchunit.Tidy(unit)
+		l[tidied] = true
+	}
+	return nil
+}
 
 func usage() {
 	fmt.Fprintf(flag.CommandLine.Output(), `Usage: benchstat [flags] inputs...
@@ -363,6 +826,7 @@ For details, see https://pkg.go.dev/golang.org/x/perf/cmd/benchstat.
 func main() {
 	if err := benchstat(os.Stdout, os.Stderr, os.Args[1:]); err != nil {
 		fmt.Fprintf(os.Stderr, "benchstat: %s\n", err)
+		os.Exit(1)
 	}
 }
 
@@ -372,13 +836,49 @@ func benchstat(w, wErr io.Writer, args []string) error {
 	flagTable := flags.String("table", ".config", "split results into tables by distinct values of `projection`")
 	flagRow := flags.String("row", ".fullname", "split results into rows by distinct values of `projection`")
 	flagCol := flags.String("col", ".label", "split results into columns by distinct values of `projection`")
+	flagBase := flags.String("base", "", "compare with the column whose values are `value` instead of the first column")
 	flagIgnore := flags.String("ignore", "", "ignore variations in `keys`")
 	flagFilter := flags.String("filter", "*", "use only benchmarks matching benchfilter `query`")
+	flagUnit := make(unitList)
+	flags.Var(flagUnit, "unit", "show only `units`, as a comma-separated list (may be repeated)")
+	flagQuery := flags.String("query", "", "combined filter and projection `query` with FILTER, TABLE, ROW, COL, and IGNORE clauses")
 	flags.Float64Var(&thresholds.CompareAlpha, "alpha", thresholds.CompareAlpha, "consider change significant if p < `α`")
-	// TODO: Support -confidence none to disable CI column? This
-	// would be equivalent to benchstat v1's -norange for CSV.
-	flagConfidence := flags.Float64("confidence", 0.95, "confidence `level` for ranges")
-	flagFormat := flags.String("format", "text", "print results in `format`:\n  text - plain text\n  csv  - comma-separated values (warnings will be written to stderr)\n")
+	flagUnitAlpha := flags.String("unit-alpha", "", "override -alpha for specific units, as a comma-separated `list` of unit=α")
+	flags.Float64Var(&thresholds.ShapeAlpha, "shape-alpha", thresholds.ShapeAlpha, "warn if distributions differ in shape with KS test p < `α` (0 disables)")
+	flags.Float64Var(&thresholds.DriftAlpha, "drift-alpha", thresholds.DriftAlpha, "warn if results drift or depend on run order with p < `α` (0 disables)")
+	flags.Float64Var(&thresholds.Bimodality, "bimodality", thresholds.Bimodality, "warn if a sample appears bimodal with Ashman's D > `d` (0 disables)")
+	flagConfidence := flags.String("confidence", "0.95", "confidence `level` for ranges, or \"none\" to omit ranges")
+	flagAssume := flags.String("assume", "nothing", "default distributional `assumption` for units without \"assume\" metadata:\n  nothing        - no assumptions; median and Mann-Whitney U-test\n  exact          - no variation expected\n  count          - constant or Poisson counts, such as allocs/op\n  normal         - mean and Welch's t-test\n  lognormal      - geometric mean and t-test in log space\n  bootstrap      - median with bootstrap intervals and tests\n  bootstrap-mean - mean with bootstrap intervals and tests\n  trimmed        - 20% trimmed mean and Yuen's test\n  winsorized     - 20% winsorized mean and Yuen's test\n  pN             - N'th percentile (e.g., p90) and bootstrap test\n")
+	flagCorrection := flags.String("correction", "none", "adjust p-values for multiple comparisons using `method`:\n  none - no correction\n  holm - Holm–Bonferroni correction\n  fdr  - Benjamini–Hochberg false discovery rate\n")
+	flagOutliers := flags.String("outliers", "none", "detect outliers using `method`:\n  none  - no outlier detection\n  tukey - Tukey's fences (1.5×IQR beyond the quartiles)\n  mad   - more than 3.5 scaled MADs from the median\n")
+	flagTrimOutliers := flags.Bool("trim-outliers", false, "exclude detected outliers from summaries and comparisons")
+	flagPaired := flags.Bool("paired", false, "compare columns using paired tests, pairing results by run order")
+	flagEffect := flags.Bool("effect", false, "show effect sizes of comparisons")
+	flagDeltaCI := flags.Bool("delta-ci", false, "show confidence intervals for percent changes")
+	flagDetect := flags.Float64("detect", 0, "for changes that aren't significant, estimate the runs needed to detect a `pct`% change (0 disables)")
+	flagDeltaStyle := flags.String("delta-style", "signed", "show changes from the base column using `style`:\n  signed     - signed percent change\n  annotated  - signed percent change followed by \"better\" or \"worse\"\n  normalized - percent change signed so improvements are positive\n")
+	flagDeltaThreshold := flags.Float64("delta-threshold", 0, "show significant changes smaller than ±`pct`% as \"~\" (0 disables)")
+	flagEquiv := flags.Float64("equiv", 0, "test whether each column is equivalent to the base column within ±`pct`% (0 disables)")
+	flagBayes := flags.Bool("bayes", false, "show the probability that each column is lower than the base column and a credible interval for their ratio")
+	flagSpread := flags.Bool("spread", false, "compare the spread (noise) of each column with the base column")
+	flagHeaderLevels := flags.Int("header-levels", 0, "limit column headers to `n` rows, merging remaining fields into the last row (0 means no limit)")
+	flagSort := flags.String("sort", "none", "sort rows by `order`:\n  none  - as given by the row projection\n  name  - alphabetically by name\n  value - by the value in the first column, largest first\n  delta - by the largest change in the row, largest first\n")
+	flagSummary := flags.String("summary", "geomean", "summarize each column in the last row using `func`:\n  geomean - geometric mean\n  mean    - arithmetic mean\n  median  - median\n  sum     - sum, such as for sizes and counts\n  none    - omit the summary row\n")
+	flagReverse := flags.Bool("reverse", false, "reverse the order of rows")
+	flagTop := flags.Int("top", 0, "show only the `n` worst regressions and n best improvements in each table (0 shows all)")
+	flagChanged := flags.Bool("changed", false, "show only benchmarks with statistically significant changes")
+	flagTranspose := flags.Bool("transpose", false, "swap rows and columns in text output")
+	flagTrend := flags.Bool("trend", false, "show the trend of each benchmark across the columns, in order, with change points")
+	flagOverall := flags.Bool("overall", false, "end with a summary of each unit across all tables")
+	flagWidth := flags.Int("width", 0, "fit text output in `n` columns (0 means the terminal width when writing to a terminal, -1 means no limit)")
+	flagStats := flags.Bool("stats", false, "show the min, quartiles, max, and count of each sample in text and CSV output")
+	flagSparklines := flags.Bool("sparklines", false, "show a histogram of each sample next to its summary in text output")
+	flagFailOn := flags.String("fail-on", "", "exit with status 1 if any significant change exceeds a threshold in `list`, a comma-separated list such as \"sec/op>+2%,B/s<-5%\"")
+	flagCommits := flags.String("commits", "", "order commit hashes for @commit using git repository or commit list `path`")
+	flagFormat := flags.String("format", "text", "print results in `format`:\n  text - plain text\n  csv  - comma-separated values (warnings will be written to stderr)\n  tsv  - tab-separated values (warnings will be written to stderr)\n  json - JSON (see the package documentation for the schema)\n  latex - LaTeX tables using booktabs rules\n  html-interactive - self-contained HTML page with sorting and filtering\n  template - use the template given by -template\n")
+	flagTemplate := flags.String("template", "", "read the template for -format template from `file`")
+	flagOut := flags.String("o", "", "write each table to a separate file in directory `dir`, or to the path given by a template like \"{{.pkg}}/{{index . \".unit\"}}.txt\"")
+	flagColor := flags.String("color", "auto", "color text output (`when`):\n  auto   - if writing to a terminal\n  always - always\n  never  - never\n")
 	flags.Parse(args)
 
 	if flags.NArg() == 0 {
@@ -392,37 +892,211 @@ func benchstat(w, wErr io.Writer, args []string) error {
 	}
 
 	var parser benchproc.ProjectionParser
-	var parseErr error
-	mustParse := func(name, val string) *benchproc.Schema {
-		schema, err := parser.Parse(val, filter)
-		if err != nil && parseErr == nil {
-			parseErr = fmt.Errorf("parsing %s: %s", name, err)
+	if *flagCommits != "" {
+		commits, err := loadCommits(*flagCommits, nil)
+		if err != nil {
+			return fmt.Errorf("loading -commits: %s", err)
 		}
-		return schema
+		parser.AddOrder("commit", benchproc.CommitOrder(commits))
 	}
-	tableBy := mustParse("-table", *flagTable)
-	rowBy := mustParse("-row", *flagRow)
-	colBy := mustParse("-col", *flagCol)
-	mustParse("-ignore", *flagIgnore)
-	residue := parser.Residue()
-	if parseErr != nil {
-		return parseErr
+	var tableBy, rowBy, colBy *benchproc.Schema
+	if *flagQuery != "" {
+		// The query's clauses override the corresponding
+		// flags.
+		q, err := parser.ParseQuery(*flagQuery, *flagFilter, []benchproc.QueryClause{
+			{Keyword: "TABLE", Default: *flagTable},
+			{Keyword: "ROW", Default: *flagRow},
+			{Keyword: "COL", Default: *flagCol},
+			{Keyword: "IGNORE", Default: *flagIgnore},
+		})
+		if err != nil {
+			return fmt.Errorf("parsing -query: %s", err)
+		}
+		filter = q.Filter
+		tableBy, rowBy, colBy = q.Schemas[0], q.Schemas[1], q.Schemas[2]
+	} else {
+		var parseErr error
+		mustParse := func(name, val string) *benchproc.Schema {
+			schema, err := parser.Parse(val, filter)
+			if err != nil && parseErr == nil {
+				parseErr = fmt.Errorf("parsing %s: %s", name, err)
+			}
+			return schema
+		}
+		tableBy = mustParse("-table", *flagTable)
+		rowBy = mustParse("-row", *flagRow)
+		colBy = mustParse("-col", *flagCol)
+		mustParse("-ignore", *flagIgnore)
+		if parseErr != nil {
+			return parseErr
+		}
 	}
+	residue := parser.Residue()
 
 	if thresholds.CompareAlpha < 0 || thresholds.CompareAlpha > 1 {
 		return fmt.Errorf("-alpha must be in range [0, 1]")
 	}
-	if *flagConfidence < 0 || *flagConfidence > 1 {
-		return fmt.Errorf("-confidence must be in range [0, 1]")
+	// With -confidence none, summaries are still computed at the
+	// default level for comparisons that need one, such as
+	// -delta-ci, but ranges are omitted from the tables.
+	confidence, noRange := 0.95, *flagConfidence == "none"
+	if !noRange {
+		confidence, err = strconv.ParseFloat(*flagConfidence, 64)
+		if err != nil || confidence < 0 || confidence > 1 {
+			return fmt.Errorf("-confidence must be in range [0, 1] or \"none\"")
+		}
+	}
+	thresholds.Outliers, err = benchmath.ParseOutlierMethod(*flagOutliers)
+	if err != nil {
+		return fmt.Errorf("-outliers must be none, tukey, or mad")
+	}
+	thresholds.TrimOutliers = *flagTrimOutliers
+	if thresholds.TrimOutliers && thresholds.Outliers == benchmath.OutliersNone {
+		return fmt.Errorf("-trim-outliers requires -outliers")
+	}
+	unitThresholds, err := parseUnitAlpha(*flagUnitAlpha, &thresholds)
+	if err != nil {
+		return err
+	}
+	assumption := benchtab.AssumptionByName(*flagAssume)
+	if assumption == nil {
+		return fmt.Errorf("-assume must be nothing, exact, count, normal, lognormal, bootstrap, bootstrap-mean, trimmed, winsorized, or pN")
+	}
+	var correction func([]*benchmath.Comparison)
+	switch *flagCorrection {
+	default:
+		return fmt.Errorf("-correction must be none, holm, or fdr")
+	case "none":
+	case "holm":
+		correction = benchmath.HolmBonferroni
+	case "fdr":
+		correction = benchmath.BenjaminiHochberg
+	}
+	if *flagDetect < 0 {
+		return fmt.Errorf("-detect must be >= 0")
+	}
+	if *flagDeltaThreshold < 0 {
+		return fmt.Errorf("-delta-threshold must be >= 0")
+	}
+	if *flagEquiv < 0 || *flagEquiv >= 100 {
+		return fmt.Errorf("-equiv must be in range [0, 100)")
+	}
+	if *flagTop < 0 {
+		return fmt.Errorf("-top must be >= 0")
+	}
+	if *flagHeaderLevels < 0 {
+		return fmt.Errorf("-header-levels must be >= 0")
+	}
+	sortRows, err := benchtab.ParseRowSort(*flagSort)
+	if err != nil {
+		return fmt.Errorf("-sort must be none, name, value, or delta")
+	}
+	deltaStyle, err := benchtab.ParseDeltaStyle(*flagDeltaStyle)
+	if err != nil {
+		return fmt.Errorf("-delta-style must be signed, annotated, or normalized")
 	}
-	var format func(t *benchtab.Tables) error
+	summary, err := benchtab.ParseSummaryFunc(*flagSummary)
+	if err != nil {
+		return fmt.Errorf("-summary must be geomean, mean, median, sum, or none")
+	}
+	var failOn []benchtab.Threshold
+	if *flagFailOn != "" {
+		failOn, err = benchtab.ParseThresholds(*flagFailOn)
+		if err != nil {
+			return fmt.Errorf("parsing -fail-on: %s", err)
+		}
+	}
+	var color bool
+	switch *flagColor {
+	default:
+		return fmt.Errorf("-color must be auto, always, or never")
+	case "auto":
+		color = *flagOut == "" && isTerminal(w)
+	case "always":
+		color = true
+	case "never":
+	}
+	width := *flagWidth
+	if width == 0 && *flagOut == "" {
+		width = terminalWidth(w)
+	}
+	// format writes tables to w in the selected format. ext is the
+	// file extension for -o.
+	var format func(w io.Writer, t *benchtab.Tables) error
+	var ext string
 	switch *flagFormat {
 	default:
-		return fmt.Errorf("-format must be text or csv")
+		return fmt.Errorf("-format must be text, csv, tsv, json, latex, html-interactive, or template")
 	case "text":
-		format = func(t *benchtab.Tables) error { return t.ToText(w, false) }
+		format = func(w io.Writer, t *benchtab.Tables) error { return t.ToText(w, color) }
+		ext = ".txt"
 	case "csv":
-		format = func(t *benchtab.Tables) error { return t.ToCSV(w, wErr) }
+		format = func(w io.Writer, t *benchtab.Tables) error { return t.ToCSV(w, wErr) }
+		ext = ".csv"
+	case "tsv":
+		format = func(w io.Writer, t *benchtab.Tables) error { return t.ToDelimited(w, wErr, '\t') }
+		ext = ".tsv"
+	case "json":
+		format = func(w io.Writer, t *benchtab.Tables) error { return t.ToJSON(w) }
+		ext = ".json"
+	case "latex":
+		format = func(w io.Writer, t *benchtab.Tables) error { return t.ToLaTeX(w) }
+		ext = ".tex"
+	case "html-interactive":
+		format = func(w io.Writer, t *benchtab.Tables) error { return t.ToHTML(w) }
+		ext = ".html"
+	case "template":
+		if *flagTemplate == "" {
+			return fmt.Errorf("-format template requires -template")
+		}
+		text, err := ioutil.ReadFile(*flagTemplate)
+		if err != nil {
+			return err
+		}
+		tmpl, err := benchtab.ParseTemplate(filepath.Base(*flagTemplate), string(text))
+		if err != nil {
+			return err
+		}
+		format = func(w io.Writer, t *benchtab.Tables) error { return t.ToTemplate(w, tmpl) }
+		// Use the extension of the template file, without
+		// any ".tmpl" suffix.
+		ext = filepath.Ext(strings.TrimSuffix(*flagTemplate, ".tmpl"))
+	}
+	var outPath *template.Template
+	if strings.Contains(*flagOut, "{{") {
+		outPath, err = template.New("-o").Option("missingkey=zero").Parse(*flagOut)
+		if err != nil {
+			return fmt.Errorf("parsing -o: %s", err)
+		}
+	}
+	if *flagTranspose && *flagFormat != "text" {
+		return fmt.Errorf("-transpose requires -format text")
+	}
+	if *flagStats {
+		switch *flagFormat {
+		case "text", "csv", "tsv":
+		default:
+			return fmt.Errorf("-stats requires -format text, csv, or tsv")
+		}
+	}
+	if *flagTrend {
+		switch {
+		case *flagFormat != "text" && *flagFormat != "csv" && *flagFormat != "tsv":
+			return fmt.Errorf("-trend requires -format text, csv, or tsv")
+		case *flagTranspose:
+			return fmt.Errorf("-trend cannot be used with -transpose")
+		case *flagTop > 0:
+			return fmt.Errorf("-trend cannot be used with -top")
+		}
+	}
+	if *flagSparklines && *flagFormat != "text" {
+		return fmt.Errorf("-sparklines requires -format text")
+	}
+	if *flagOverall && (*flagFormat != "text" || *flagOut != "") {
+		return fmt.Errorf("-overall requires -format text and cannot be used with -o")
+	}
+	if *flagTemplate != "" && *flagFormat != "template" {
+		return fmt.Errorf("-template requires -format template")
 	}
 
 	stat := benchtab.NewBuilder(tableBy, rowBy, colBy, residue)
@@ -439,6 +1113,20 @@ func benchstat(w, wErr io.Writer, args []string) error {
 		if !filter.Apply(res) {
 			continue
 		}
+		if len(flagUnit) > 0 {
+			// Keep only the requested units. Value.Unit
+			// is tidied, like the units in flagUnit.
+			vals := res.Values[:0]
+			for _, v := range res.Values {
+				if flagUnit[v.Unit] {
+					vals = append(vals, v)
+				}
+			}
+			if len(vals) == 0 {
+				continue
+			}
+			res.Values = vals
+		}
 
 		stat.Add(res)
 	}
@@ -447,9 +1135,243 @@ func benchstat(w, wErr io.Writer, args []string) error {
 	}
 
 	tables := stat.ToTables(benchtab.TableOpts{
-		Confidence: *flagConfidence,
+		Confidence: confidence,
+		NoRange:    noRange,
+		Base:       *flagBase,
 		Thresholds: &thresholds,
-		Units:      files.Units(),
+
+		UnitThresholds: unitThresholds,
+		Units:          files.Units(),
+		Assumption:     assumption,
+		Summary:        summary,
+		Correction:     correction,
+		Paired:         *flagPaired,
+		ShowEffect:     *flagEffect,
+		DeltaCI:        *flagDeltaCI,
+		ShowSpread:     *flagSpread,
+		ShowBayes:      *flagBayes,
+
+		DetectEffect:   *flagDetect / 100,
+		EquivMargin:    *flagEquiv / 100,
+		DeltaThreshold: *flagDeltaThreshold / 100,
+		DeltaStyle:     deltaStyle,
+
+		MaxHeaderLevels: *flagHeaderLevels,
+		Transpose:       *flagTranspose,
+		Sparklines:      *flagSparklines,
+		ShowStats:       *flagStats,
+		Width:           width,
+
+		// Only text output shows row groups, so don't reorder
+		// rows for other formats.
+		GroupRows: *flagFormat == "text" && !*flagTranspose && !*flagTrend,
+
+		SortRows:    sortRows,
+		ReverseRows: *flagReverse,
+		ChangedOnly: *flagChanged,
+		Top:         *flagTop,
+		Trend:       *flagTrend,
+		Overall:     *flagOverall,
 	})
-	return format(tables)
+	if *flagBase != "" {
+		found := false
+		for _, table := range tables.Tables {
+			if len(table.Cols) > 0 && table.Cols[0].StringValues() == *flagBase {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("-base %q does not match any column", *flagBase)
+		}
+	}
+	if *flagOut != "" {
+		err = writeTables(w, *flagOut, outPath, ext, tables, format)
+	} else {
+		err = format(w, tables)
+	}
+	if err != nil || failOn == nil {
+		return err
+	}
+
+	// Check for changes that exceed -fail-on, including in rows
+	// that weren't shown.
+	violations := tables.Violations(failOn)
+	if len(violations) == 0 {
+		return nil
+	}
+	for _, v := range violations {
+		fmt.Fprintf(wErr, "%s\n", v)
+	}
+	if len(violations) == 1 {
+		return fmt.Errorf("1 change exceeds -fail-on")
+	}
+	return fmt.Errorf("%d changes exceed -fail-on", len(violations))
+}
+
+// writeTables writes each table in tables to a separate file using
+// format and prints the path of each file to w. If outPath is
+// non-nil, it's executed with a map from each table config key to its
+// value to produce the path. Otherwise, each file is written to the
+// directory dir and named after the table's config and ext.
+func writeTables(w io.Writer, dir string, outPath *template.Template, ext string, tables *benchtab.Tables, format func(io.Writer, *benchtab.Tables) error) error {
+	seen := make(map[string]bool)
+	for i, table := range tables.Tables {
+		config := tables.Configs[i]
+		var path string
+		if outPath != nil {
+			vals := make(map[string]string)
+			for _, f := range config.Schema().Fields() {
+				vals[f.Name] = config.Get(f)
+			}
+			var buf strings.Builder
+			if err := outPath.Execute(&buf, vals); err != nil {
+				return fmt.Errorf("-o: %s", err)
+			}
+			path = buf.String()
+		} else {
+			var parts []string
+			for _, f := range config.Schema().Fields() {
+				if val := config.Get(f); val != "" {
+					parts = append(parts, strings.TrimPrefix(f.Name, ".")+"="+val)
+				}
+			}
+			path = filepath.Join(dir, fileNameEscaper.Replace(strings.Join(parts, ","))+ext)
+		}
+
+		// If tables map to the same path, number them.
+		orig := path
+		for n := 2; seen[path]; n++ {
+			e := filepath.Ext(orig)
+			path = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(orig, e), n, e)
+		}
+		seen[path] = true
+
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			return err
+		}
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		one := &benchtab.Tables{Tables: []*benchtab.Table{table}, Configs: []benchproc.Config{config}}
+		err = format(f, one)
+		if err1 := f.Close(); err == nil {
+			err = err1
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, path)
+	}
+	return nil
+}
+
+// fileNameEscaper replaces characters that are unsafe or awkward in
+// file names.
+var fileNameEscaper = strings.NewReplacer(
+	"/", "_", `\`, "_", ":", "_", "*", "_", "?", "_",
+	`"`, "_", "<", "_", ">", "_", "|", "_", " ", "_",
+)
+
+// isTerminal reports whether w is a terminal that supports color.
+// Following https://no-color.org, setting the NO_COLOR environment
+// variable disables color.
+func isTerminal(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	st, err := f.Stat()
+	return err == nil && st.Mode()&os.ModeCharDevice != 0
+}
+
+// terminalWidth returns the width of the terminal w writes to, or 0
+// if w isn't a terminal or its width is unknown. The COLUMNS
+// environment variable overrides the width reported by the terminal.
+func terminalWidth(w io.Writer) int {
+	f, ok := w.(*os.File)
+	if !ok {
+		return 0
+	}
+	st, err := f.Stat()
+	if err != nil || st.Mode()&os.ModeCharDevice == 0 {
+		return 0
+	}
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		return n
+	}
+	return fileWidth(f)
+}
+
+// loadCommits returns a list of commit hashes in order from oldest to
+// newest. If path is a directory, it's treated as a git repository
+// and loadCommits returns the commits reachable from HEAD in
+// topological order. Otherwise, path is a file containing one commit
+// hash per line. Any text following the hash on a line, such as in
+// "git log --oneline" output, is ignored. If env is non-nil, it's
+// the environment git runs in; otherwise git inherits this process's
+// environment.
+func loadCommits(path string, env []string) ([]string, error) {
+	st, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	var data []byte
+	if st.IsDir() {
+		cmd := exec.Command("git", "rev-list", "--topo-order", "--reverse", "HEAD")
+		cmd.Dir = path
+		cmd.Env = env
+		data, err = cmd.Output()
+		if err != nil {
+			// Include git's explanation, such as that path
+			// isn't a repository.
+			if ee, ok := err.(*exec.ExitError); ok && len(ee.Stderr) > 0 {
+				return nil, fmt.Errorf("git rev-list: %s: %s", err, bytes.TrimSpace(ee.Stderr))
+			}
+			return nil, fmt.Errorf("git rev-list: %s", err)
+		}
+	} else {
+		data, err = ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var commits []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			commits = append(commits, fields[0])
+		}
+	}
+	return commits, nil
+}
+
+// parseUnitAlpha parses the -unit-alpha flag. It returns a copy of
+// base for each listed unit with the given alpha level.
+func parseUnitAlpha(flag string, base *benchmath.Thresholds) (map[string]*benchmath.Thresholds, error) {
+	if flag == "" {
+		return nil, nil
+	}
+	m := make(map[string]*benchmath.Thresholds)
+	for _, pair := range strings.Split(flag, ",") {
+		i := strings.LastIndex(pair, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("-unit-alpha: expected unit=α, got %q", pair)
+		}
+		// Accept units as they appear in benchmark output,
+		// such as "ns/op", and as benchstat shows them.
+		unit, _ := benchunit.Tidy(strings.TrimSpace(pair[:i]))
+		alpha, err := strconv.ParseFloat(strings.TrimSpace(pair[i+1:]), 64)
+		if err != nil || alpha < 0 || alpha > 1 {
+			return nil, fmt.Errorf("-unit-alpha: α for %s must be in range [0, 1]", unit)
+		}
+		t := *base
+		t.CompareAlpha = alpha
+		m[unit] = &t
+	}
+	return m, nil
 }
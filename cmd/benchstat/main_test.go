@@ -9,6 +9,7 @@ import (
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -30,9 +31,45 @@ func TestDoc(t *testing.T) {
 
 func TestCSV(t *testing.T) {
 	golden(t, "csvOldNew", "-format", "csv", "old.txt", "new.txt")
+	golden(t, "tsvOldNew", "-format", "tsv", "old.txt", "new.txt")
 	golden(t, "csvErrors", "-format", "csv", "-row", ".name", "new.txt")
 }
 
+func TestSummary(t *testing.T) {
+	golden(t, "summarySum", "-summary", "sum", "-col", "/format", "-ignore", ".label", "-row", "/tag", "top.txt")
+	golden(t, "summaryMedian", "-summary", "median", "old.txt", "new.txt")
+	golden(t, "summaryNone", "-summary", "none", "old.txt", "new.txt")
+	golden(t, "summaryNoneCSV", "-summary", "none", "-format", "csv", "old.txt", "new.txt")
+}
+
+func TestBase(t *testing.T) {
+	golden(t, "baseNew", "-base", "new.txt", "old.txt", "new.txt")
+	golden(t, "baseFormat", "-base", "gob", "-col", "/format", "-row", ".name", "-ignore", ".label", "new.txt")
+}
+
+func TestDeltaStyle(t *testing.T) {
+	golden(t, "deltaAnnotated", "-delta-style", "annotated", "-col", "note", "-ignore", ".label", "better.txt")
+	golden(t, "deltaNormalized", "-delta-style", "normalized", "-col", "note", "-ignore", ".label", "better.txt")
+	golden(t, "deltaNormalizedCI", "-delta-style", "normalized", "-delta-ci", "old.txt", "new.txt")
+}
+
+func TestRowGroups(t *testing.T) {
+	golden(t, "rowGroups", "-ignore", "note", "-filter", "/align:0 .unit:sec/op", "-row", "/poly,/size", "crc-old.txt", "crc-new.txt")
+	golden(t, "rowGroupsNested", "-ignore", "note", "-filter", "(/size:15 OR /size:40) .unit:sec/op", "-row", "/poly,/size,/align", "crc-old.txt", "crc-new.txt")
+	golden(t, "rowGroupsChanged", "-changed", "-ignore", "note", "-filter", "/align:0 .unit:sec/op", "-row", "/poly,/size", "crc-old.txt", "crc-new.txt")
+}
+
+func TestDeltaThreshold(t *testing.T) {
+	golden(t, "deltaThreshold", "-delta-threshold", "2.5", "-col", "/format", "-ignore", ".label", "-row", "/tag", "top.txt")
+	golden(t, "deltaThresholdChanged", "-delta-threshold", "2.5", "-changed", "-col", "/format", "-ignore", ".label", "-row", "/tag", "top.txt")
+}
+
+func TestNoRange(t *testing.T) {
+	golden(t, "noRange", "-confidence", "none", "old.txt", "new.txt")
+	golden(t, "noRangeCSV", "-confidence", "none", "-format", "csv", "old.txt", "new.txt")
+	golden(t, "noRangeLatex", "-confidence", "none", "-format", "latex", "-col", "note", "-ignore", ".label", "allocs.txt")
+}
+
 func TestCRC(t *testing.T) {
 	// These have a "note" that "unexpectedly" splits the tables,
 	// and also two units.
@@ -42,12 +79,27 @@ func TestCRC(t *testing.T) {
 
 	// Filter to aligned, put size on the X axis and poly on the Y axis.
 	golden(t, "crcSizeVsPoly", "-filter", "/align:0", "-row", "/size", "-col", "/poly", "crc-new.txt")
+	// The same, as a single query.
+	golden(t, "crcSizeVsPoly", "-query", "FILTER /align:0 ROW /size COL /poly", "crc-new.txt")
+
+	// Merge a two-field column header into one row.
+	golden(t, "crcHeaderLevels", "-filter", "/size:512", "-row", ".name", "-col", "/poly,/align", "-header-levels", "1", "crc-new.txt")
 }
 
 func TestUnits(t *testing.T) {
 	// Test unit metadata. This tests exact assumptions and
 	// warnings for inexact distributions.
 	golden(t, "units", "-col", "note", "units.txt")
+
+	// Test normal and log-normal assumptions.
+	golden(t, "unitsNormal", "-col", "note", "unitsNormal.txt")
+	golden(t, "unitsLogNormal", "-col", "note", "unitsLogNormal.txt")
+	golden(t, "unitsClass", "-col", "note", "unitsClass.txt")
+
+	// Test other assumptions via the default assumption.
+	golden(t, "trimmed", "-assume", "trimmed", "-col", "note", "-ignore", ".label", "outliers.txt")
+	golden(t, "quantile", "-assume", "p90", "-col", "note", "-ignore", ".label", "shape.txt")
+	golden(t, "bootstrap", "-assume", "bootstrap", "-col", "note", "-ignore", ".label", "bootstrap.txt")
 }
 
 func TestZero(t *testing.T) {
@@ -72,6 +124,158 @@ func TestIssue19634(t *testing.T) {
 	golden(t, "issue19634", "-col", "note", "-ignore", ".label", "issue19634.txt")
 }
 
+func TestCorrection(t *testing.T) {
+	// Compare to crcIgnore, which has many comparisons.
+	golden(t, "correctionHolm", "-correction", "holm", "-ignore", "note", "crc-old.txt", "crc-new.txt")
+	golden(t, "correctionFDR", "-correction", "fdr", "-ignore", "note", "crc-old.txt", "crc-new.txt")
+}
+
+func TestShape(t *testing.T) {
+	golden(t, "shape", "-shape-alpha", "0.05", "-col", "note", "-ignore", ".label", "shape.txt")
+}
+
+func TestDetect(t *testing.T) {
+	golden(t, "detect", "-detect", "1", "-col", "note", "-ignore", ".label", "paired.txt")
+}
+
+func TestEquiv(t *testing.T) {
+	golden(t, "equiv", "-equiv", "5", "-filter", "/size:(15 40 1k)", "-ignore", "note", "crc-old.txt", "crc-new.txt")
+}
+
+func TestDeltaCI(t *testing.T) {
+	golden(t, "deltaCI", "-delta-ci", "-col", "note", "-ignore", ".label", "outliers.txt")
+	golden(t, "deltaCINormal", "-delta-ci", "-assume", "normal", "-col", "note", "-ignore", ".label", "outliers.txt")
+	golden(t, "deltaCIGeomean", "-delta-ci", "old.txt", "new.txt")
+}
+
+func TestUnitAlpha(t *testing.T) {
+	golden(t, "unitAlpha", "-unit-alpha", "ns/op=0.000001", "old.txt", "new.txt")
+	golden(t, "unitFlag", "-col", "note", "-unit", "ns/op", "-unit", "B/op,allocs/op", "allocs.txt")
+}
+
+func TestBayes(t *testing.T) {
+	golden(t, "bayes", "-bayes", "-col", "note", "-ignore", ".label", "outliers.txt")
+}
+
+func TestSpread(t *testing.T) {
+	golden(t, "spread", "-spread", "-col", "note", "-ignore", ".label", "shape.txt")
+	golden(t, "spreadCSV", "-spread", "-format", "csv", "-col", "note", "-ignore", ".label", "shape.txt")
+}
+
+func TestPaired(t *testing.T) {
+	// The runs in paired.txt are interleaved and drift, which
+	// hides the difference from an unpaired test.
+	golden(t, "unpaired", "-col", "note", "-ignore", ".label", "paired.txt")
+	golden(t, "paired", "-paired", "-col", "note", "-ignore", ".label", "paired.txt")
+}
+
+func TestBimodal(t *testing.T) {
+	golden(t, "bimodal", "-bimodality", "8", "-row", ".name", "new.txt")
+}
+
+func TestDrift(t *testing.T) {
+	golden(t, "drift", "-drift-alpha", "0.05", "-col", "note", "-ignore", ".label", "paired.txt")
+	// Separate files aren't interleaved.
+	golden(t, "driftInterleaved", "-drift-alpha", "0.05", "old.txt", "new.txt")
+}
+
+func TestOutliers(t *testing.T) {
+	golden(t, "outliers", "-outliers", "tukey", "-assume", "normal", "-col", "note", "-ignore", ".label", "outliers.txt")
+	golden(t, "outliersTrim", "-outliers", "tukey", "-trim-outliers", "-assume", "normal", "-col", "note", "-ignore", ".label", "outliers.txt")
+}
+
+func TestEffect(t *testing.T) {
+	golden(t, "effect", "-effect", "-ignore", "note", "crc-old.txt", "crc-new.txt")
+	golden(t, "json", "-format", "json", "old.txt", "new.txt")
+	golden(t, "latex", "-format", "latex", "old.txt", "new.txt")
+	golden(t, "latexWarnings", "-format", "latex", "-col", "note", "-ignore", ".label", "-effect", "allocs.txt")
+	golden(t, "template", "-format", "template", "-template", "template.tmpl", "old.txt", "new.txt")
+	golden(t, "color", "-color", "always", "old.txt", "new.txt")
+	golden(t, "colorBetter", "-color", "always", "-col", "note", "better.txt")
+	golden(t, "htmlInteractive", "-format", "html-interactive", "-col", "note", "better.txt")
+	golden(t, "sortDelta", "-sort", "delta", "-col", "/format", "-ignore", ".label", "-row", "/tag", "sort.txt")
+	golden(t, "sortValueReverse", "-sort", "value", "-reverse", "-col", "/format", "-ignore", ".label", "-row", "/tag", "sort.txt")
+	golden(t, "changed", "-changed", "-col", "/format", "-ignore", ".label", "-row", "/tag", "sort.txt")
+	golden(t, "changedOldNew", "-changed", "old.txt", "new.txt")
+	golden(t, "top", "-top", "2", "-col", "/format", "-ignore", ".label", "-row", "/tag", "top.txt")
+	golden(t, "topCSV", "-top", "1", "-format", "csv", "-col", "/format", "-ignore", ".label", "-row", "/tag", "top.txt")
+	golden(t, "transpose", "-transpose", "old.txt", "new.txt")
+	golden(t, "transposeTop", "-transpose", "-top", "1", "-col", "/format", "-ignore", ".label", "-row", "/tag", "top.txt")
+	golden(t, "effectCSV", "-effect", "-format", "csv", "-ignore", "note", "crc-old.txt", "crc-new.txt")
+	golden(t, "sparklines", "-sparklines", "old.txt", "new.txt")
+	golden(t, "sparklinesTranspose", "-sparklines", "-transpose", "old.txt", "new.txt")
+	golden(t, "stats", "-stats", "old.txt", "new.txt")
+	golden(t, "statsCSV", "-stats", "-format", "csv", "old.txt", "new.txt")
+	golden(t, "statsTranspose", "-stats", "-transpose", "old.txt", "new.txt")
+	golden(t, "statsVertical", "-stats", "-width", "60", "old.txt", "new.txt")
+	golden(t, "widthLabels", "-width", "82", "-ignore", "note", "crc-old.txt", "crc-new.txt")
+	golden(t, "widthStack", "-width", "100", "-col", "/poly", "-row", "/size", "-ignore", "note,.file", "crc-old.txt")
+	// Truncating these labels would drop the distinguishing /size.
+	golden(t, "widthUnique", "-width", "90", "-col", "/poly", "crc-new.txt")
+	golden(t, "widthVertical", "-width", "60", "old.txt", "new.txt")
+	golden(t, "trend", "-trend", "-col", "nightly@num", "-ignore", ".file", "trend.txt")
+	golden(t, "trendCSV", "-trend", "-format", "csv", "-col", "nightly@num", "-ignore", ".file", "trend.txt")
+	golden(t, "trendChanged", "-trend", "-changed", "-col", "nightly@num", "-ignore", ".file", "trend.txt")
+	golden(t, "overall", "-overall", "-ignore", "note", "-table", "/poly", "-row", "/size", "crc-old.txt", "crc-new.txt")
+	golden(t, "overallChanged", "-overall", "-changed", "-ignore", "note", "-table", "/poly", "-row", "/size", "crc-old.txt", "crc-new.txt")
+}
+
+func TestCommits(t *testing.T) {
+	// Order columns by an explicit commit list, including
+	// abbreviated hashes.
+	golden(t, "commits", "-commits", "commits.list", "-col", "commit@commit", "-ignore", ".label", "commits.txt")
+}
+
+func TestLoadCommitsError(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	dir, err := ioutil.TempDir("", "benchstat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// git's stderr should be in the error, not on os.Stderr.
+	env := append(os.Environ(), "GIT_CEILING_DIRECTORIES="+filepath.Dir(dir))
+	_, err = loadCommits(dir, env)
+	if err == nil || !strings.Contains(err.Error(), "not a git repository") {
+		t.Errorf("want error from git, got %v", err)
+	}
+}
+
+func TestFailOn(t *testing.T) {
+	if err := os.Chdir("testdata"); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir("..")
+
+	check := func(failOn string, want ...string) {
+		t.Helper()
+		var got, gotErr bytes.Buffer
+		err := benchstat(&got, &gotErr, []string{"-fail-on", failOn, "-col", "/format", "-ignore", ".label", "-row", "/tag", "top.txt"})
+		if len(want) == 0 {
+			if err != nil || gotErr.Len() != 0 {
+				t.Errorf("-fail-on %s: want success, got error %v and stderr:\n%s", failOn, err, gotErr.String())
+			}
+			return
+		}
+		if err == nil {
+			t.Errorf("-fail-on %s: want error, got success", failOn)
+		}
+		if got, want := gotErr.String(), strings.Join(want, "\n")+"\n"; got != want {
+			t.Errorf("-fail-on %s: want stderr:\n%sgot:\n%s", failOn, want, got)
+		}
+	}
+	check("sec/op>+90%")
+	check("ns/op>+20%",
+		"c new sec/op: +80.00% (p=0.002 n=6) exceeds sec/op>+20%",
+		"e new sec/op: +30.00% (p=0.002 n=6) exceeds sec/op>+20%")
+	check("sec/op<-40%",
+		"b new sec/op: -49.98% (p=0.002 n=6) exceeds sec/op<-40%")
+	check("B/op>+1%")
+}
+
 func golden(t *testing.T, name string, args ...string) {
 	t.Helper()
 	// TODO: If benchfmt.Files supported fs.FS, we wouldn't need this.
@@ -124,3 +328,45 @@ func compare(t *testing.T, name, sub string, got []byte) {
 	// Most likely, "diff not found" so print the bad output so there is something.
 	t.Errorf("want:\n%sgot:\n%s", string(want), string(got))
 }
+
+func TestOutputFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "benchstat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	check := func(out string, want ...string) {
+		t.Helper()
+		var got, gotErr bytes.Buffer
+		args := []string{"-o", out, "-col", "note", "testdata/better.txt"}
+		if err := benchstat(&got, &gotErr, args); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		paths := strings.Fields(got.String())
+		if len(paths) != len(want) {
+			t.Fatalf("want %d files, got %q", len(want), paths)
+		}
+		for i, path := range paths {
+			if path != filepath.Join(dir, want[i]) {
+				t.Errorf("want file %s, got %s", filepath.Join(dir, want[i]), path)
+			}
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Contains(data, []byte("vs base")) {
+				t.Errorf("%s doesn't contain a table:\n%s", path, data)
+			}
+		}
+	}
+
+	check(dir,
+		"label=testdata_better.txt,unit=sec_op.txt",
+		"label=testdata_better.txt,unit=B_s.txt",
+		"label=testdata_better.txt,unit=pages_op.txt")
+	check(filepath.Join(dir, "{{index . \".label\"}}", "out.txt"),
+		"testdata/better.txt/out.txt",
+		"testdata/better.txt/out-2.txt",
+		"testdata/better.txt/out-3.txt")
+}
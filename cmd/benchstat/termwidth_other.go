@@ -0,0 +1,17 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package main
+
+import "os"
+
+// fileWidth returns the width in columns of the terminal f, or 0 if
+// it can't be determined. On this platform, it's never known, so
+// only the COLUMNS environment variable sets the width.
+func fileWidth(f *os.File) int {
+	return 0
+}